@@ -0,0 +1,124 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"knative.dev/serving-operator/pkg/scope"
+	"knative.dev/serving-operator/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const leaderLockName = "knative-serving-operator-lock"
+
+var (
+	leaderElect = flag.Bool("leader-elect", false,
+		"Hold a renewable lease while reconciling so several operator replicas can run with fast failover; "+
+			"when disabled the leader-for-life lock is used")
+	leaderElectionNamespace = flag.String("leader-election-namespace", "",
+		"Namespace of the leader election ConfigMap; defaults to the operator's namespace")
+	leaderElectionID = flag.String("leader-election-id", leaderLockName,
+		"Name of the leader election ConfigMap")
+	leaseDuration = flag.Duration("leader-election-lease-duration", 15*time.Second,
+		"How long non-leaders wait before taking over an expired lease")
+	renewDeadline = flag.Duration("leader-election-renew-deadline", 10*time.Second,
+		"How long the leader retries renewing the lease before giving it up")
+	retryPeriod = flag.Duration("leader-election-retry-period", 2*time.Second,
+		"How long to wait between attempts to acquire or renew the lease")
+)
+
+// startLeaderElected runs the manager only while this replica holds the
+// lease. Losing the lease returns an error so the process exits rather
+// than reconciling alongside the new leader. The webhook server, unless
+// it's nil, runs all along.
+func startLeaderElected(cfg *rest.Config, mgr manager.Manager, hook *webhook.Server, stop <-chan struct{}) error {
+	namespace := *leaderElectionNamespace
+	if namespace == "" {
+		var err error
+		if namespace, err = k8sutil.GetOperatorNamespace(); err != nil {
+			return fmt.Errorf("unable to find the leader election namespace: %v", err)
+		}
+	}
+	id, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	kc, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	// Replicas of the same shard contend for its lease
+	lockName := scope.LockName(*leaderElectionID)
+	lock, err := resourcelock.New(resourcelock.ConfigMapsResourceLock, namespace, lockName, kc.CoreV1(),
+		resourcelock.ResourceLockConfig{
+			Identity:      id,
+			EventRecorder: mgr.GetRecorder(lockName),
+		})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	done := make(chan error, 3)
+	if hook != nil {
+		go func() {
+			done <- hook.Start(ctx.Done())
+		}()
+	}
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: *leaseDuration,
+		RenewDeadline: *renewDeadline,
+		RetryPeriod:   *retryPeriod,
+		Name:          lockName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leading context.Context) {
+				log.Info("Became the leader", "lock", lock.Describe(), "identity", id)
+				done <- mgr.Start(leading.Done())
+			},
+			OnStoppedLeading: func() {
+				if ctx.Err() != nil {
+					// Shutting down
+					done <- nil
+					return
+				}
+				done <- fmt.Errorf("lost the leader lease %s", lock.Describe())
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	log.Info("Waiting for the leader lease", "lock", lock.Describe(), "identity", id)
+	go elector.Run(ctx)
+	return <-done
+}
@@ -25,7 +25,10 @@ import (
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"knative.dev/serving-operator/pkg/apis"
+	"knative.dev/serving-operator/pkg/health"
 	"knative.dev/serving-operator/pkg/reconciler"
+	"knative.dev/serving-operator/pkg/scope"
+	"knative.dev/serving-operator/pkg/webhook"
 
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
 	"github.com/operator-framework/operator-sdk/pkg/leader"
@@ -45,6 +48,16 @@ var (
 	metricsHost       = "0.0.0.0"
 	metricsPort int32 = 8383
 )
+
+// Client-side rate limits of the operator's requests to the apiserver,
+// above client-go's defaults of 5 and 10 to keep large installs quick
+var (
+	kubeAPIQPS = flag.Float64("kube-api-qps", 20,
+		"Maximum sustained queries per second to the apiserver")
+	kubeAPIBurst = flag.Int("kube-api-burst", 30,
+		"Maximum burst of queries to the apiserver above --kube-api-qps")
+)
+
 var log = logf.Log.WithName("cmd")
 
 func printVersion() {
@@ -62,6 +75,10 @@ func main() {
 	// controller-runtime)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 
+	rendering := len(os.Args) > 1 && os.Args[1] == renderCommand
+	if rendering {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
 	pflag.Parse()
 
 	// Use a zap logr.Logger implementation. If none of the zap
@@ -74,8 +91,28 @@ func main() {
 	// uniform and structured logs.
 	logf.SetLogger(zap.Logger())
 
+	if rendering {
+		if err := render(pflag.Args(), os.Stdin, os.Stdout); err != nil {
+			log.Error(err, "Failed to render")
+			os.Exit(1)
+		}
+		return
+	}
+
 	printVersion()
 
+	shard, err := scope.ShardIndex()
+	if err != nil {
+		log.Error(err, "Failed to find the operator's shard")
+		os.Exit(1)
+	}
+	if scope.Sharded() {
+		log.Info("Reconciling a shard of the KnativeServing resources", "shard", shard)
+	}
+
+	// Answer the probes while waiting to lead and loading the manifest
+	health.ListenAndServe()
+
 	namespace, err := k8sutil.GetWatchNamespace()
 	if err != nil {
 		log.Error(err, "Failed to get watch namespace")
@@ -88,14 +125,19 @@ func main() {
 		log.Error(err, "")
 		os.Exit(1)
 	}
+	cfg.QPS = float32(*kubeAPIQPS)
+	cfg.Burst = *kubeAPIBurst
 
 	ctx := context.TODO()
 
-	// Become the leader before proceeding
-	err = leader.Become(ctx, "knative-serving-operator-lock")
-	if err != nil {
-		log.Error(err, "")
-		os.Exit(1)
+	// Become the leader before proceeding, unless a renewable lease is
+	// acquired once the manager is set up
+	if !*leaderElect {
+		err = leader.Become(ctx, scope.LockName(leaderLockName))
+		if err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
 	}
 
 	// Create a new Cmd to provide shared dependencies and start components
@@ -123,6 +165,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Setup the admission webhook. Every replica serves it, since its
+	// Service selects them all, so with a renewable lease it runs
+	// outside the manager only the leader starts.
+	var hook *webhook.Server
+	if *leaderElect {
+		hook, err = webhook.NewServer(mgr)
+	} else {
+		err = webhook.AddToManager(mgr)
+	}
+	if err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
 	// Create Service object to expose the metrics port.
 	_, err = metrics.ExposeMetricsPort(ctx, metricsPort)
 	if err != nil {
@@ -132,7 +188,13 @@ func main() {
 	log.Info("Starting the Cmd.")
 
 	// Start the Cmd
-	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
+	start := mgr.Start
+	if *leaderElect {
+		start = func(stop <-chan struct{}) error {
+			return startLeaderElected(cfg, mgr, hook, stop)
+		}
+	}
+	if err := start(signals.SetupSignalHandler()); err != nil {
 		log.Error(err, "Manager exited non-zero")
 		os.Exit(1)
 	}
@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	servingv1beta1 "knative.dev/serving-operator/pkg/apis/serving/v1beta1"
+	"knative.dev/serving-operator/pkg/reconciler/knativeserving"
+	"sigs.k8s.io/yaml"
+)
+
+// The subcommand printing the manifest a KnativeServing would install,
+// e.g. serving-operator render knativeserving.yaml
+const renderCommand = "render"
+
+// Render the KnativeServing in the file, or stdin if none or "-", to
+// out as a stream of YAML documents
+func render(args []string, in io.Reader, out io.Writer) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: %s [flags] [FILE]", renderCommand)
+	}
+	if len(args) == 1 && args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	instance, err := decodeKnativeServing(data)
+	if err != nil {
+		return err
+	}
+	resources, err := knativeserving.Render(instance)
+	if err != nil {
+		return err
+	}
+	for _, u := range resources {
+		doc, err := yaml.Marshal(u.Object)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(out, "---\n%s", doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode a KnativeServing of either version into its v1alpha1 equivalent
+func decodeKnativeServing(data []byte) (*servingv1alpha1.KnativeServing, error) {
+	meta := &metav1.TypeMeta{}
+	if err := yaml.Unmarshal(data, meta); err != nil {
+		return nil, err
+	}
+	instance := &servingv1alpha1.KnativeServing{}
+	switch {
+	case meta.Kind != "KnativeServing":
+		return nil, fmt.Errorf("expected a KnativeServing, got %q", meta.Kind)
+	case meta.APIVersion == servingv1beta1.SchemeGroupVersion.String():
+		beta := &servingv1beta1.KnativeServing{}
+		if err := yaml.Unmarshal(data, beta); err != nil {
+			return nil, err
+		}
+		if err := instance.ConvertDown(context.TODO(), beta); err != nil {
+			return nil, err
+		}
+	case meta.APIVersion == servingv1alpha1.SchemeGroupVersion.String():
+		if err := yaml.Unmarshal(data, instance); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported KnativeServing apiVersion %q", meta.APIVersion)
+	}
+	return instance, nil
+}
@@ -61,6 +61,12 @@ export KO_DOCKER_REPO
 
 cd "${YAML_REPO_ROOT}"
 
+# Bundle the releases of the optional ingresses into the image, unless
+# they already are
+[[ -d cmd/manager/kodata/ingress/kourier ]] || ./hack/update-kourier.sh ${KOURIER_VERSION:-v0.3.12}
+[[ -d cmd/manager/kodata/ingress/ambassador ]] || ./hack/update-ambassador.sh ${AMBASSADOR_VERSION:-1.0.0}
+[[ -d cmd/manager/kodata/ingress/contour ]] || ./hack/update-contour.sh ${CONTOUR_VERSION:-v0.10.0}
+
 echo "Building Knative Serving Operator"
 ko resolve ${KO_YAML_FLAGS} -f config/ > "${SERVING_OPERATOR_YAML}"
 
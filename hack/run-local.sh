@@ -19,4 +19,4 @@ DIR=${DIR:-$(cd $(dirname "$0")/.. && pwd)}
 export KO_DATA_PATH=${KO_DATA_PATH:-$DIR/cmd/manager/kodata}
 export WATCH_NAMESPACE=""
 
-go run $DIR/cmd/manager $@
+go run $DIR/cmd/manager --webhook=false --probe-serving=false $@
@@ -0,0 +1,44 @@
+#!/usr/bin/env bash
+
+# Copyright 2019 The Knative Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Bundles the Ambassador release the operator installs when
+# spec.ingress.ambassador.enabled is set, e.g.
+#
+#   ./hack/update-ambassador.sh 1.0.0
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+readonly AMBASSADOR_VERSION=${1:?"First argument must be the Ambassador release, e.g. 1.0.0"}
+readonly ROOT_DIR=$(dirname $0)/..
+readonly AMBASSADOR_DIR=${ROOT_DIR}/cmd/manager/kodata/ingress/ambassador
+readonly AMBASSADOR_URL=https://github.com/datawire/ambassador/raw/v${AMBASSADOR_VERSION}/docs/yaml/ambassador
+
+mkdir -p ${AMBASSADOR_DIR}
+rm -f ${AMBASSADOR_DIR}/*.yaml
+
+# The release leaves its namespace to kubectl, so the operator creates
+# it and places the resources in it when applying them
+cat > ${AMBASSADOR_DIR}/00-namespace.yaml <<EOF
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ambassador
+EOF
+for f in ambassador-crds ambassador-rbac ambassador-service; do
+  curl -fsSL "${AMBASSADOR_URL}/${f}.yaml" -o ${AMBASSADOR_DIR}/${f}.yaml
+done
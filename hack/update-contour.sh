@@ -0,0 +1,38 @@
+#!/usr/bin/env bash
+
+# Copyright 2019 The Knative Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Bundles the net-contour release the operator installs when
+# spec.ingress.contour.enabled is set, e.g.
+#
+#   ./hack/update-contour.sh v0.10.0
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+readonly CONTOUR_VERSION=${1:?"First argument must be the net-contour release, e.g. v0.10.0"}
+readonly ROOT_DIR=$(dirname $0)/..
+readonly CONTOUR_DIR=${ROOT_DIR}/cmd/manager/kodata/ingress/contour
+readonly CONTOUR_URL=https://github.com/knative/net-contour/releases/download/${CONTOUR_VERSION}
+
+mkdir -p ${CONTOUR_DIR}
+rm -f ${CONTOUR_DIR}/*.yaml
+
+# contour.yaml installs the external and internal Contours, each with
+# an envoy DaemonSet, net-contour.yaml the controller and config-contour
+for f in contour net-contour; do
+  curl -fsSL "${CONTOUR_URL}/${f}.yaml" -o ${CONTOUR_DIR}/${f}.yaml
+done
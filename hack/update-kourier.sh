@@ -0,0 +1,36 @@
+#!/usr/bin/env bash
+
+# Copyright 2019 The Knative Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Bundles the Kourier release the operator installs when
+# spec.ingress.kourier.enabled is set, e.g.
+#
+#   ./hack/update-kourier.sh v0.3.12
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+readonly KOURIER_VERSION=${1:?"First argument must be the Kourier release, e.g. v0.3.12"}
+readonly ROOT_DIR=$(dirname $0)/..
+readonly KOURIER_DIR=${ROOT_DIR}/cmd/manager/kodata/ingress/kourier
+
+mkdir -p ${KOURIER_DIR}
+rm -f ${KOURIER_DIR}/*.yaml
+
+# The release creates the kourier-system namespace along with the
+# gateway and its control plane
+curl -fsSL "https://github.com/3scale/kourier/releases/download/${KOURIER_VERSION}/kourier.yaml" \
+  -o ${KOURIER_DIR}/kourier.yaml
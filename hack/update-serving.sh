@@ -0,0 +1,32 @@
+#!/usr/bin/env bash
+
+# Copyright 2019 The Knative Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Bundles a Knative Serving release next to the others, selectable with
+# spec.version, e.g.
+#
+#   ./hack/update-serving.sh v0.8.1
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+readonly SERVING_VERSION=${1:?"First argument must be the Knative Serving release, e.g. v0.8.1"}
+readonly ROOT_DIR=$(dirname $0)/..
+readonly RELEASES_DIR=${ROOT_DIR}/cmd/manager/kodata/knative-serving
+
+# The file is named for the version, without the v, e.g. 0.8.1.yaml
+curl -fsSL "https://github.com/knative/serving/releases/download/${SERVING_VERSION}/serving.yaml" \
+  -o ${RELEASES_DIR}/${SERVING_VERSION#v}.yaml
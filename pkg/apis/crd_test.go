@@ -0,0 +1,206 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package apis
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	knativeapis "knative.dev/pkg/apis"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	servingv1beta1 "knative.dev/serving-operator/pkg/apis/serving/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+const crdPath = "../../config/crds/serving_v1alpha1_knativeserving_crd.yaml"
+
+var (
+	timeType        = reflect.TypeOf(metav1.Time{})
+	durationType    = reflect.TypeOf(metav1.Duration{})
+	volatileType    = reflect.TypeOf(knativeapis.VolatileTime{})
+	objectMetaType  = reflect.TypeOf(metav1.ObjectMeta{})
+	intOrStringType = reflect.TypeOf(intstr.IntOrString{})
+	quantityType    = reflect.TypeOf(resource.Quantity{})
+)
+
+// The apiserver only accepts a conversion webhook for a CRD that prunes
+// unknown fields, which takes a structural schema per version. Pruning
+// in turn drops whatever field of the types the schema lacks.
+func TestCRDSchemas(t *testing.T) {
+	b, err := ioutil.ReadFile(crdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crd := map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &crd); err != nil {
+		t.Fatal(err)
+	}
+	spec := crd["spec"].(map[string]interface{})
+	if preserve, ok := spec["preserveUnknownFields"]; !ok || preserve != false {
+		t.Errorf("preserveUnknownFields = %v, want false", preserve)
+	}
+	if _, ok := spec["validation"]; ok {
+		t.Error("the schema of spec.validation applies to every version, want one per version")
+	}
+	types := map[string]reflect.Type{
+		"v1alpha1": reflect.TypeOf(servingv1alpha1.KnativeServing{}),
+		"v1beta1":  reflect.TypeOf(servingv1beta1.KnativeServing{}),
+	}
+	for _, v := range spec["versions"].([]interface{}) {
+		version := v.(map[string]interface{})
+		name := version["name"].(string)
+		schema, _ := version["schema"].(map[string]interface{})
+		root, ok := schema["openAPIV3Schema"].(map[string]interface{})
+		if !ok {
+			t.Errorf("%s has no schema", name)
+			continue
+		}
+		checkStructural(t, name, root)
+		checkCovers(t, name, types[name], root)
+	}
+}
+
+// Every node has a type, and objects either list their properties or
+// share one schema among them
+func checkStructural(t *testing.T, path string, node map[string]interface{}) {
+	t.Helper()
+	if _, ok := node["type"]; !ok && node["x-kubernetes-int-or-string"] != true {
+		t.Errorf("%s has no type", path)
+	}
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf", "not"} {
+		if _, ok := node[keyword]; ok {
+			t.Errorf("%s uses %s", path, keyword)
+		}
+	}
+	properties, _ := node["properties"].(map[string]interface{})
+	if _, ok := node["additionalProperties"]; ok && properties != nil {
+		t.Errorf("%s has both properties and additionalProperties", path)
+	}
+	for name, p := range properties {
+		checkStructural(t, path+"."+name, p.(map[string]interface{}))
+	}
+	if items, ok := node["items"].(map[string]interface{}); ok {
+		checkStructural(t, path+"[]", items)
+	}
+	if additional, ok := node["additionalProperties"].(map[string]interface{}); ok {
+		checkStructural(t, path+"{}", additional)
+	}
+}
+
+// The schema describes every field of the type, down to those whose
+// unknown fields it preserves
+func checkCovers(t *testing.T, path string, typ reflect.Type, node map[string]interface{}) {
+	t.Helper()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if node["x-kubernetes-preserve-unknown-fields"] == true || typ == objectMetaType {
+		return
+	}
+	want := ""
+	switch {
+	case typ == timeType || typ == durationType || typ == volatileType:
+		want = "string"
+	case typ == intOrStringType || typ == quantityType:
+		if node["x-kubernetes-int-or-string"] != true {
+			t.Errorf("%s isn't x-kubernetes-int-or-string", path)
+		}
+		return
+	case typ.Kind() == reflect.Struct || typ.Kind() == reflect.Map:
+		want = "object"
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
+		want = "string"
+	case typ.Kind() == reflect.Slice:
+		want = "array"
+	case typ.Kind() == reflect.String:
+		want = "string"
+	case typ.Kind() == reflect.Bool:
+		want = "boolean"
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Uint64:
+		want = "integer"
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		want = "number"
+	}
+	if got := node["type"]; got != want {
+		t.Errorf("%s has type %v, want %s", path, got, want)
+		return
+	}
+	switch want {
+	case "object":
+		if typ.Kind() == reflect.Map {
+			additional, ok := node["additionalProperties"].(map[string]interface{})
+			if !ok {
+				t.Errorf("%s has no additionalProperties, so its entries are pruned", path)
+				return
+			}
+			checkCovers(t, path+"{}", typ.Elem(), additional)
+			return
+		}
+		properties, _ := node["properties"].(map[string]interface{})
+		seen := map[string]bool{}
+		checkFields(t, path, typ, properties, seen)
+		for name := range properties {
+			if !seen[name] {
+				t.Errorf("%s.%s isn't a field of %s", path, name, typ)
+			}
+		}
+	case "array":
+		if typ.Kind() != reflect.Slice || typ.Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		items, ok := node["items"].(map[string]interface{})
+		if !ok {
+			t.Errorf("%s has no items, so they're pruned", path)
+			return
+		}
+		checkCovers(t, path+"[]", typ.Elem(), items)
+	}
+}
+
+func checkFields(t *testing.T, path string, typ reflect.Type, properties map[string]interface{}, seen map[string]bool) {
+	t.Helper()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		switch {
+		case name == "-" || (f.PkgPath != "" && !f.Anonymous):
+			continue
+		case name == "":
+			// Inlined
+			inner := f.Type
+			for inner.Kind() == reflect.Ptr {
+				inner = inner.Elem()
+			}
+			if inner.Kind() == reflect.Struct && inner != timeType {
+				checkFields(t, path, inner, properties, seen)
+				continue
+			}
+			name = f.Name
+		}
+		seen[name] = true
+		p, ok := properties[name].(map[string]interface{})
+		if !ok {
+			t.Errorf("%s.%s is missing from the schema, so it's pruned", path, name)
+			continue
+		}
+		checkCovers(t, path+"."+name, f.Type, p)
+	}
+}
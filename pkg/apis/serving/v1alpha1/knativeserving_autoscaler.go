@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// The entries of config-autoscaler the fields of Autoscaler replace
+	containerConcurrencyTargetDefaultKey = "container-concurrency-target-default"
+	stableWindowKey                      = "stable-window"
+	scaleToZeroGracePeriodKey            = "scale-to-zero-grace-period"
+	enableScaleToZeroKey                 = "enable-scale-to-zero"
+
+	minStableWindow           = 6 * time.Second
+	maxStableWindow           = time.Hour
+	minScaleToZeroGracePeriod = 6 * time.Second
+)
+
+// Config returns the entries of config-autoscaler the set fields replace
+func (a *Autoscaler) Config() map[string]string {
+	result := map[string]string{}
+	if a == nil {
+		return result
+	}
+	if a.ContainerConcurrencyTargetDefault != nil {
+		result[containerConcurrencyTargetDefaultKey] = strconv.Itoa(int(*a.ContainerConcurrencyTargetDefault))
+	}
+	if a.StableWindow != nil {
+		result[stableWindowKey] = a.StableWindow.Duration.String()
+	}
+	if a.ScaleToZeroGracePeriod != nil {
+		result[scaleToZeroGracePeriodKey] = a.ScaleToZeroGracePeriod.Duration.String()
+	}
+	if a.EnableScaleToZero != nil {
+		result[enableScaleToZeroKey] = strconv.FormatBool(*a.EnableScaleToZero)
+	}
+	return result
+}
+
+// SetDefaults sets the unset fields to Knative's defaults, unless the
+// entries they replace are set in config
+func (a *Autoscaler) SetDefaults(config map[string]string) {
+	if _, ok := config[containerConcurrencyTargetDefaultKey]; !ok && a.ContainerConcurrencyTargetDefault == nil {
+		target := int32(100)
+		a.ContainerConcurrencyTargetDefault = &target
+	}
+	if _, ok := config[stableWindowKey]; !ok && a.StableWindow == nil {
+		a.StableWindow = &metav1.Duration{Duration: 60 * time.Second}
+	}
+	if _, ok := config[scaleToZeroGracePeriodKey]; !ok && a.ScaleToZeroGracePeriod == nil {
+		a.ScaleToZeroGracePeriod = &metav1.Duration{Duration: 30 * time.Second}
+	}
+	if _, ok := config[enableScaleToZeroKey]; !ok && a.EnableScaleToZero == nil {
+		enabled := true
+		a.EnableScaleToZero = &enabled
+	}
+}
+
+// Validate checks the fields are within the ranges Knative accepts
+func (a *Autoscaler) Validate(ctx context.Context) *apis.FieldError {
+	if a == nil {
+		return nil
+	}
+	var errs *apis.FieldError
+	if t := a.ContainerConcurrencyTargetDefault; t != nil && *t < 1 {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*t, 1, "unbounded", "containerConcurrencyTargetDefault"))
+	}
+	if w := a.StableWindow; w != nil && (w.Duration < minStableWindow || w.Duration > maxStableWindow) {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(w.Duration, minStableWindow, maxStableWindow, "stableWindow"))
+	}
+	if p := a.ScaleToZeroGracePeriod; p != nil && p.Duration < minScaleToZeroGracePeriod {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(p.Duration, minScaleToZeroGracePeriod, "unbounded", "scaleToZeroGracePeriod"))
+	}
+	return errs
+}
@@ -0,0 +1,75 @@
+package v1alpha1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAutoscalerDefaults(t *testing.T) {
+	ks := &KnativeServing{
+		Spec: KnativeServingSpec{
+			Config:     map[string]map[string]string{"autoscaler": {"stable-window": "2m"}},
+			Autoscaler: &Autoscaler{},
+		},
+	}
+	ks.SetDefaults(context.Background())
+	want := map[string]string{
+		"container-concurrency-target-default": "100",
+		"scale-to-zero-grace-period":           "30s",
+		"enable-scale-to-zero":                 "true",
+	}
+	if got := ks.Spec.Autoscaler.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %v, want %v leaving stable-window to spec.config", got, want)
+	}
+
+	ks = &KnativeServing{}
+	ks.SetDefaults(context.Background())
+	if ks.Spec.Autoscaler != nil {
+		t.Errorf("Autoscaler = %v, want it left unset", ks.Spec.Autoscaler)
+	}
+}
+
+func TestAutoscalerValidate(t *testing.T) {
+	duration := func(d time.Duration) *metav1.Duration {
+		return &metav1.Duration{Duration: d}
+	}
+	zero := int32(0)
+	tests := []struct {
+		name       string
+		autoscaler *Autoscaler
+		wantErr    bool
+	}{{
+		name: "unset",
+	}, {
+		name:       "in range",
+		autoscaler: &Autoscaler{StableWindow: duration(time.Minute), ScaleToZeroGracePeriod: duration(30 * time.Second)},
+	}, {
+		name:       "no concurrency",
+		autoscaler: &Autoscaler{ContainerConcurrencyTargetDefault: &zero},
+		wantErr:    true,
+	}, {
+		name:       "stable window too short",
+		autoscaler: &Autoscaler{StableWindow: duration(time.Second)},
+		wantErr:    true,
+	}, {
+		name:       "stable window too long",
+		autoscaler: &Autoscaler{StableWindow: duration(2 * time.Hour)},
+		wantErr:    true,
+	}, {
+		name:       "grace period too short",
+		autoscaler: &Autoscaler{ScaleToZeroGracePeriod: duration(time.Second)},
+		wantErr:    true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.autoscaler.Validate(context.Background())
+			if got := err != nil; got != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+// The prefix of the labels and annotations the operator manages itself
+const operatorPrefix = "operator.knative.dev/"
+
+// Labels and annotations must be valid and leave the operator's own be
+func validateCommonMetadata(spec *KnativeServingSpec) *apis.FieldError {
+	var errs *apis.FieldError
+	for _, field := range []struct {
+		name   string
+		values map[string]string
+		labels bool
+	}{
+		{"commonLabels", spec.CommonLabels, true},
+		{"commonAnnotations", spec.CommonAnnotations, false},
+	} {
+		keys := make([]string, 0, len(field.values))
+		for k := range field.values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			msgs := validation.IsQualifiedName(k)
+			if strings.HasPrefix(k, operatorPrefix) {
+				msgs = append(msgs, "the "+operatorPrefix+" prefix is reserved for the operator")
+			}
+			if len(msgs) > 0 {
+				errs = errs.Also(apis.ErrInvalidKeyName(k, field.name, msgs...))
+				continue
+			}
+			if field.labels {
+				if msgs := validation.IsValidLabelValue(field.values[k]); len(msgs) > 0 {
+					err := apis.ErrInvalidValue(field.values[k], field.name+"."+k)
+					err.Details = strings.Join(msgs, ", ")
+					errs = errs.Also(err)
+				}
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"knative.dev/pkg/apis"
+)
+
+// The names of the optional components that may be disabled
+const (
+	CertManagerComponent         = "cert-manager"
+	CustomMetricsComponent       = "custom-metrics"
+	AutoscalerHPAComponent       = "autoscaler-hpa"
+	MonitoringComponent          = "monitoring"
+	ClusterLocalGatewayComponent = "cluster-local-gateway"
+)
+
+// OptionalComponents lists the components spec.disabledComponents accepts
+var OptionalComponents = []string{
+	CertManagerComponent,
+	CustomMetricsComponent,
+	AutoscalerHPAComponent,
+	MonitoringComponent,
+	ClusterLocalGatewayComponent,
+}
+
+// IsDisabled returns whether the optional component is left out of the install
+func (s *KnativeServingSpec) IsDisabled(component string) bool {
+	for _, c := range s.DisabledComponents {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+func validateDisabledComponents(components []string) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, c := range components {
+		known := false
+		for _, o := range OptionalComponents {
+			known = known || c == o
+		}
+		if !known {
+			errs = errs.Also(apis.ErrInvalidArrayValue(c, "disabledComponents", i))
+		}
+	}
+	return errs
+}
+
+func validateCertManager(spec *KnativeServingSpec) *apis.FieldError {
+	if spec.CertManager == nil || !spec.CertManager.Enabled {
+		return nil
+	}
+	var errs *apis.FieldError
+	if spec.CertManager.ClusterIssuer == "" {
+		errs = errs.Also(apis.ErrMissingField("certManager.clusterIssuer"))
+	}
+	if spec.IsDisabled(CertManagerComponent) {
+		errs = errs.Also(&apis.FieldError{
+			Message: "cert-manager may not be both enabled and disabled",
+			Paths:   []string{"certManager.enabled", "disabledComponents"},
+		})
+	}
+	return errs
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"knative.dev/pkg/apis"
+	"knative.dev/serving-operator/pkg/apis/serving/v1beta1"
+)
+
+// ConvertUp implements apis.Convertible
+func (source *KnativeServing) ConvertUp(ctx context.Context, obj apis.Convertible) error {
+	switch sink := obj.(type) {
+	case *v1beta1.KnativeServing:
+		in := source.DeepCopy()
+		sink.ObjectMeta = in.ObjectMeta
+		in.Spec.ConvertUp(ctx, &sink.Spec)
+		in.Status.ConvertUp(ctx, &sink.Status)
+		return nil
+	default:
+		return fmt.Errorf("unknown version, got: %T", sink)
+	}
+}
+
+// ConvertUp helps implement apis.Convertible
+func (source *KnativeServingSpec) ConvertUp(ctx context.Context, sink *v1beta1.KnativeServingSpec) {
+	sink.Config = source.Config
+	sink.Registry = v1beta1.Registry(source.Registry)
+	// The gateway overrides move under the istio ingress
+	gateways := !isZeroGatewayOverride(source.KnativeIngressGateway) || !isZeroGatewayOverride(source.ClusterLocalGateway)
+	if source.Ingress != nil || gateways {
+		sink.Ingress = &v1beta1.IngressConfigs{}
+		if source.Ingress != nil {
+			sink.Ingress.Istio.Enabled = source.Ingress.Istio.Enabled
+			sink.Ingress.Istio.InstallGateways = source.Ingress.Istio.InstallGateways
+			sink.Ingress.Kourier = v1beta1.KourierIngressConfiguration(source.Ingress.Kourier)
+			sink.Ingress.Contour = v1beta1.ContourIngressConfiguration{
+				Enabled:  source.Ingress.Contour.Enabled,
+				External: (*v1beta1.ContourVisibility)(source.Ingress.Contour.External),
+				Internal: (*v1beta1.ContourVisibility)(source.Ingress.Contour.Internal),
+			}
+			sink.Ingress.Gloo = v1beta1.GlooIngressConfiguration(source.Ingress.Gloo)
+			sink.Ingress.Ambassador = v1beta1.AmbassadorIngressConfiguration(source.Ingress.Ambassador)
+		}
+		sink.Ingress.Istio.KnativeIngressGateway = v1beta1.IstioGatewayOverride(source.KnativeIngressGateway)
+		sink.Ingress.Istio.ClusterLocalGateway = v1beta1.IstioGatewayOverride(source.ClusterLocalGateway)
+	}
+	for _, d := range source.DeploymentOverrides {
+		sink.Deployments = append(sink.Deployments, v1beta1.DeploymentOverride(d))
+	}
+	for _, r := range source.Resources {
+		sink.Resources = append(sink.Resources, v1beta1.ResourceRequirementsOverride(r))
+	}
+	for _, p := range source.Probes {
+		sink.Probes = append(sink.Probes, v1beta1.ProbeOverride{
+			Container:  p.Container,
+			Deployment: p.Deployment,
+			Readiness:  (*v1beta1.ProbeTuning)(p.Readiness),
+			Liveness:   (*v1beta1.ProbeTuning)(p.Liveness),
+		})
+	}
+	sink.Autoscaler = (*v1beta1.Autoscaler)(source.Autoscaler)
+	sink.GC = (*v1beta1.GC)(source.GC)
+	sink.Tracing = (*v1beta1.Tracing)(source.Tracing)
+	sink.Defaults = (*v1beta1.Defaults)(source.Defaults)
+	sink.OverridesFrom = (*v1beta1.OverridesSource)(source.OverridesFrom)
+	sink.Logging = (*v1beta1.Logging)(source.Logging)
+	sink.RestartOnConfigChange = source.RestartOnConfigChange
+	sink.Monitoring = (*v1beta1.Monitoring)(source.Monitoring)
+	sink.QueueSidecar = (*v1beta1.QueueSidecar)(source.QueueSidecar)
+	if source.HighAvailability != nil {
+		sink.HighAvailability = &v1beta1.HighAvailability{
+			Replicas:    source.HighAvailability.Replicas,
+			Autoscaling: (*v1beta1.HighAvailabilityAutoscaling)(source.HighAvailability.Autoscaling),
+		}
+	}
+	for _, b := range source.PodDisruptionBudgets {
+		sink.PodDisruptionBudgets = append(sink.PodDisruptionBudgets, v1beta1.PodDisruptionBudget(b))
+	}
+	sink.Hibernate = source.Hibernate
+	sink.ControlPlanePriorityClass = source.ControlPlanePriorityClass
+	sink.UninstallPolicy = source.UninstallPolicy
+	sink.AdoptExisting = source.AdoptExisting
+	sink.CleanupLegacyResources = source.CleanupLegacyResources
+	sink.Profile = source.Profile
+	sink.CRDsOnly = source.CRDsOnly
+	sink.ControllerCustomCerts = (*v1beta1.CustomCerts)(source.ControllerCustomCerts)
+	sink.InstallTimeout = source.InstallTimeout
+	sink.DisabledComponents = source.DisabledComponents
+	if source.Domain != nil {
+		sink.Domain = make(map[string]v1beta1.DomainSelector, len(source.Domain))
+		for k, v := range source.Domain {
+			sink.Domain[k] = v1beta1.DomainSelector(v)
+		}
+	}
+	sink.CertManager = (*v1beta1.CertManager)(source.CertManager)
+	sink.Proxy = (*v1beta1.Proxy)(source.Proxy)
+	sink.Security = (*v1beta1.Security)(source.Security)
+	sink.Istio = (*v1beta1.Istio)(source.Istio)
+	sink.CommonLabels = source.CommonLabels
+	sink.CommonAnnotations = source.CommonAnnotations
+	sink.DigestPinning = (*v1beta1.DigestPinning)(source.DigestPinning)
+	sink.Export = (*v1beta1.ManifestExport)(source.Export)
+	sink.Namespace = source.Namespace
+	if source.ManifestPolicy != nil {
+		sink.ManifestPolicy = &v1beta1.ManifestPolicy{Default: source.ManifestPolicy.Default}
+		for _, p := range source.ManifestPolicy.Resources {
+			sink.ManifestPolicy.Resources = append(sink.ManifestPolicy.Resources, v1beta1.ResourcePolicy(p))
+		}
+	}
+	for _, m := range source.AdditionalManifests {
+		sink.AdditionalManifests = append(sink.AdditionalManifests, v1beta1.AdditionalManifest(m))
+	}
+	sink.Version = source.Version
+}
+
+// ConvertUp helps implement apis.Convertible
+func (source *KnativeServingStatus) ConvertUp(ctx context.Context, sink *v1beta1.KnativeServingStatus) {
+	sink.Version = source.Version
+	sink.Phase = source.Phase
+	sink.TargetVersion = source.TargetVersion
+	sink.OperatorVersion = source.OperatorVersion
+	sink.ServingVersion = source.ServingVersion
+	sink.ObservedGeneration = source.ObservedGeneration
+	sink.InstallStartTime = source.InstallStartTime
+	sink.ManifestHash = source.ManifestHash
+	sink.LastAppliedTime = source.LastAppliedTime
+	sink.InstallGeneration = source.InstallGeneration
+	for _, d := range source.Deployments {
+		sink.Deployments = append(sink.Deployments, v1beta1.DeploymentStatus(d))
+	}
+	for _, d := range source.DaemonSets {
+		sink.DaemonSets = append(sink.DaemonSets, v1beta1.DaemonSetStatus(d))
+	}
+	for _, f := range source.FailedResources {
+		sink.FailedResources = append(sink.FailedResources, v1beta1.FailedResource(f))
+	}
+	for _, m := range source.Manifests {
+		sink.Manifests = append(sink.Manifests, v1beta1.AppliedManifest(m))
+	}
+	for _, r := range source.Resources {
+		sink.Resources = append(sink.Resources, v1beta1.AppliedResource(r))
+	}
+	for _, i := range source.Images {
+		sink.Images = append(sink.Images, v1beta1.PinnedImage(i))
+	}
+	sink.Export = (*v1beta1.ExportStatus)(source.Export)
+	sink.URL = source.URL
+	sink.Address = source.Address
+	sink.Conditions = source.Conditions
+}
+
+// ConvertDown implements apis.Convertible
+func (sink *KnativeServing) ConvertDown(ctx context.Context, obj apis.Convertible) error {
+	switch source := obj.(type) {
+	case *v1beta1.KnativeServing:
+		in := source.DeepCopy()
+		sink.ObjectMeta = in.ObjectMeta
+		sink.Spec.ConvertDown(ctx, in.Spec)
+		sink.Status.ConvertDown(ctx, in.Status)
+		return nil
+	default:
+		return fmt.Errorf("unknown version, got: %T", source)
+	}
+}
+
+// ConvertDown helps implement apis.Convertible
+func (sink *KnativeServingSpec) ConvertDown(ctx context.Context, source v1beta1.KnativeServingSpec) {
+	sink.Config = source.Config
+	sink.Registry = Registry(source.Registry)
+	if source.Ingress != nil {
+		sink.Ingress = &IngressConfigs{
+			Istio: IstioIngressConfiguration{
+				Enabled:         source.Ingress.Istio.Enabled,
+				InstallGateways: source.Ingress.Istio.InstallGateways,
+			},
+			Kourier: KourierIngressConfiguration(source.Ingress.Kourier),
+			Contour: ContourIngressConfiguration{
+				Enabled:  source.Ingress.Contour.Enabled,
+				External: (*ContourVisibility)(source.Ingress.Contour.External),
+				Internal: (*ContourVisibility)(source.Ingress.Contour.Internal),
+			},
+			Gloo:       GlooIngressConfiguration(source.Ingress.Gloo),
+			Ambassador: AmbassadorIngressConfiguration(source.Ingress.Ambassador),
+		}
+		sink.KnativeIngressGateway = IstioGatewayOverride(source.Ingress.Istio.KnativeIngressGateway)
+		sink.ClusterLocalGateway = IstioGatewayOverride(source.Ingress.Istio.ClusterLocalGateway)
+	}
+	for _, d := range source.Deployments {
+		sink.DeploymentOverrides = append(sink.DeploymentOverrides, DeploymentOverride(d))
+	}
+	for _, r := range source.Resources {
+		sink.Resources = append(sink.Resources, ResourceRequirementsOverride(r))
+	}
+	for _, p := range source.Probes {
+		sink.Probes = append(sink.Probes, ProbeOverride{
+			Container:  p.Container,
+			Deployment: p.Deployment,
+			Readiness:  (*ProbeTuning)(p.Readiness),
+			Liveness:   (*ProbeTuning)(p.Liveness),
+		})
+	}
+	sink.Autoscaler = (*Autoscaler)(source.Autoscaler)
+	sink.GC = (*GC)(source.GC)
+	sink.Tracing = (*Tracing)(source.Tracing)
+	sink.Defaults = (*Defaults)(source.Defaults)
+	sink.OverridesFrom = (*OverridesSource)(source.OverridesFrom)
+	sink.Logging = (*Logging)(source.Logging)
+	sink.RestartOnConfigChange = source.RestartOnConfigChange
+	sink.Monitoring = (*Monitoring)(source.Monitoring)
+	sink.QueueSidecar = (*QueueSidecar)(source.QueueSidecar)
+	if source.HighAvailability != nil {
+		sink.HighAvailability = &HighAvailability{
+			Replicas:    source.HighAvailability.Replicas,
+			Autoscaling: (*HighAvailabilityAutoscaling)(source.HighAvailability.Autoscaling),
+		}
+	}
+	for _, b := range source.PodDisruptionBudgets {
+		sink.PodDisruptionBudgets = append(sink.PodDisruptionBudgets, PodDisruptionBudget(b))
+	}
+	sink.Hibernate = source.Hibernate
+	sink.ControlPlanePriorityClass = source.ControlPlanePriorityClass
+	sink.UninstallPolicy = source.UninstallPolicy
+	sink.AdoptExisting = source.AdoptExisting
+	sink.CleanupLegacyResources = source.CleanupLegacyResources
+	sink.Profile = source.Profile
+	sink.CRDsOnly = source.CRDsOnly
+	sink.ControllerCustomCerts = (*CustomCerts)(source.ControllerCustomCerts)
+	sink.InstallTimeout = source.InstallTimeout
+	sink.DisabledComponents = source.DisabledComponents
+	if source.Domain != nil {
+		sink.Domain = make(map[string]DomainSelector, len(source.Domain))
+		for k, v := range source.Domain {
+			sink.Domain[k] = DomainSelector(v)
+		}
+	}
+	sink.CertManager = (*CertManager)(source.CertManager)
+	sink.Proxy = (*Proxy)(source.Proxy)
+	sink.Security = (*Security)(source.Security)
+	sink.Istio = (*Istio)(source.Istio)
+	sink.CommonLabels = source.CommonLabels
+	sink.CommonAnnotations = source.CommonAnnotations
+	sink.DigestPinning = (*DigestPinning)(source.DigestPinning)
+	sink.Export = (*ManifestExport)(source.Export)
+	sink.Namespace = source.Namespace
+	if source.ManifestPolicy != nil {
+		sink.ManifestPolicy = &ManifestPolicy{Default: source.ManifestPolicy.Default}
+		for _, p := range source.ManifestPolicy.Resources {
+			sink.ManifestPolicy.Resources = append(sink.ManifestPolicy.Resources, ResourcePolicy(p))
+		}
+	}
+	for _, m := range source.AdditionalManifests {
+		sink.AdditionalManifests = append(sink.AdditionalManifests, AdditionalManifest(m))
+	}
+	sink.Version = source.Version
+}
+
+// ConvertDown helps implement apis.Convertible
+func (sink *KnativeServingStatus) ConvertDown(ctx context.Context, source v1beta1.KnativeServingStatus) {
+	sink.Version = source.Version
+	sink.Phase = source.Phase
+	sink.TargetVersion = source.TargetVersion
+	sink.OperatorVersion = source.OperatorVersion
+	sink.ServingVersion = source.ServingVersion
+	sink.ObservedGeneration = source.ObservedGeneration
+	sink.InstallStartTime = source.InstallStartTime
+	sink.ManifestHash = source.ManifestHash
+	sink.LastAppliedTime = source.LastAppliedTime
+	sink.InstallGeneration = source.InstallGeneration
+	for _, d := range source.Deployments {
+		sink.Deployments = append(sink.Deployments, DeploymentStatus(d))
+	}
+	for _, d := range source.DaemonSets {
+		sink.DaemonSets = append(sink.DaemonSets, DaemonSetStatus(d))
+	}
+	for _, f := range source.FailedResources {
+		sink.FailedResources = append(sink.FailedResources, FailedResource(f))
+	}
+	for _, m := range source.Manifests {
+		sink.Manifests = append(sink.Manifests, AppliedManifest(m))
+	}
+	for _, r := range source.Resources {
+		sink.Resources = append(sink.Resources, AppliedResource(r))
+	}
+	for _, i := range source.Images {
+		sink.Images = append(sink.Images, PinnedImage(i))
+	}
+	sink.Export = (*ExportStatus)(source.Export)
+	sink.URL = source.URL
+	sink.Address = source.Address
+	sink.Conditions = source.Conditions
+}
+
+func isZeroGatewayOverride(o IstioGatewayOverride) bool {
+	return o.Name == "" && o.Namespace == "" && len(o.Selector) == 0
+}
@@ -0,0 +1,102 @@
+package v1alpha1
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	fuzz "github.com/google/gofuzz"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving-operator/pkg/apis/serving/v1beta1"
+)
+
+var conversionOptions = []cmp.Option{
+	cmpopts.EquateEmpty(),
+	cmp.Comparer(func(a, b resource.Quantity) bool { return a.Cmp(b) == 0 }),
+}
+
+func newConversionFuzzer(seed int64) *fuzz.Fuzzer {
+	return fuzz.New().RandSource(rand.NewSource(seed)).NilChance(0.3).NumElements(0, 2).MaxDepth(8)
+}
+
+func TestConversionRoundTripUp(t *testing.T) {
+	for i := int64(0); i < 200; i++ {
+		want := &KnativeServing{}
+		newConversionFuzzer(i).Fuzz(want)
+		want.TypeMeta = metav1.TypeMeta{}
+		// Without the ingress section v1beta1 can't hold the gateway overrides,
+		// so it comes back empty, which selects istio just the same
+		if want.Spec.Ingress == nil && (!isZeroGatewayOverride(want.Spec.KnativeIngressGateway) || !isZeroGatewayOverride(want.Spec.ClusterLocalGateway)) {
+			want.Spec.Ingress = &IngressConfigs{}
+		}
+
+		beta := &v1beta1.KnativeServing{}
+		if err := want.ConvertUp(context.Background(), beta); err != nil {
+			t.Fatalf("ConvertUp() = %v", err)
+		}
+		got := &KnativeServing{}
+		if err := got.ConvertDown(context.Background(), beta); err != nil {
+			t.Fatalf("ConvertDown() = %v", err)
+		}
+		if diff := cmp.Diff(want, got, conversionOptions...); diff != "" {
+			t.Fatalf("round trip with seed %d (-want, +got): %s", i, diff)
+		}
+	}
+}
+
+func TestConversionRoundTripDown(t *testing.T) {
+	for i := int64(0); i < 200; i++ {
+		want := &v1beta1.KnativeServing{}
+		newConversionFuzzer(i).Fuzz(want)
+		want.TypeMeta = metav1.TypeMeta{}
+
+		alpha := &KnativeServing{}
+		if err := alpha.ConvertDown(context.Background(), want); err != nil {
+			t.Fatalf("ConvertDown() = %v", err)
+		}
+		got := &v1beta1.KnativeServing{}
+		if err := alpha.ConvertUp(context.Background(), got); err != nil {
+			t.Fatalf("ConvertUp() = %v", err)
+		}
+		if diff := cmp.Diff(want, got, conversionOptions...); diff != "" {
+			t.Fatalf("round trip with seed %d (-want, +got): %s", i, diff)
+		}
+	}
+}
+
+func TestConvertUpMovesGatewaysUnderIstio(t *testing.T) {
+	alpha := &KnativeServing{
+		Spec: KnativeServingSpec{
+			KnativeIngressGateway: IstioGatewayOverride{Selector: map[string]string{"istio": "custom"}},
+			DeploymentOverrides:   []DeploymentOverride{{Name: "controller"}},
+		},
+	}
+	beta := &v1beta1.KnativeServing{}
+	if err := alpha.ConvertUp(context.Background(), beta); err != nil {
+		t.Fatalf("ConvertUp() = %v", err)
+	}
+	want := v1beta1.KnativeServingSpec{
+		Ingress: &v1beta1.IngressConfigs{
+			Istio: v1beta1.IstioIngressConfiguration{
+				KnativeIngressGateway: v1beta1.IstioGatewayOverride{Selector: map[string]string{"istio": "custom"}},
+			},
+		},
+		Deployments: []v1beta1.DeploymentOverride{{Name: "controller"}},
+	}
+	if diff := cmp.Diff(want, beta.Spec, conversionOptions...); diff != "" {
+		t.Errorf("ConvertUp() (-want, +got): %s", diff)
+	}
+}
+
+func TestConvertUnknownVersion(t *testing.T) {
+	alpha := &KnativeServing{}
+	if err := alpha.ConvertUp(context.Background(), &KnativeServing{}); err == nil {
+		t.Error("ConvertUp() to v1alpha1 should fail")
+	}
+	if err := alpha.ConvertDown(context.Background(), &KnativeServing{}); err == nil {
+		t.Error("ConvertDown() from v1alpha1 should fail")
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"context"
+)
+
+const (
+	// Knative's default template of the hostnames of routes
+	defaultDomainTemplate = "{{.Name}}.{{.Namespace}}.{{.Domain}}"
+)
+
+// SetDefaults implements apis.Defaultable, spelling out what the
+// operator would otherwise configure implicitly
+func (ks *KnativeServing) SetDefaults(ctx context.Context) {
+	if ks.Spec.Autoscaler != nil {
+		ks.Spec.Autoscaler.SetDefaults(ks.Spec.Config["autoscaler"])
+	}
+	if ks.Spec.GC != nil {
+		ks.Spec.GC.SetDefaults(ks.Spec.Config["gc"])
+	}
+	if ks.Spec.Defaults != nil {
+		ks.Spec.Defaults.SetDefaults(ks.Spec.Config["defaults"])
+	}
+	if ks.Spec.HighAvailability != nil {
+		ks.Spec.HighAvailability.SetDefaults()
+	}
+
+	network := ks.Spec.Config["network"]
+	if network == nil {
+		network = map[string]string{}
+	}
+	// The operator points the network at the selected ingress regardless
+	network["ingress.class"] = ks.Spec.Ingress.Class()
+	if _, ok := network["domainTemplate"]; !ok {
+		network["domainTemplate"] = defaultDomainTemplate
+	}
+	if ks.Spec.Config == nil {
+		ks.Spec.Config = map[string]map[string]string{}
+	}
+	ks.Spec.Config["network"] = network
+}
@@ -0,0 +1,57 @@
+package v1alpha1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSetDefaults(t *testing.T) {
+	ks := &KnativeServing{
+		Spec: KnativeServingSpec{
+			Ingress:          &IngressConfigs{Kourier: KourierIngressConfiguration{Enabled: true}},
+			HighAvailability: &HighAvailability{},
+		},
+	}
+	ks.SetDefaults(context.Background())
+	want := map[string]string{
+		"ingress.class":  "kourier.ingress.networking.knative.dev",
+		"domainTemplate": defaultDomainTemplate,
+	}
+	if got := ks.Spec.Config["network"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("config.network = %v, want %v", got, want)
+	}
+	if got := ks.Spec.HighAvailability.Replicas; got != 2 {
+		t.Errorf("highAvailability.replicas = %d, want 2", got)
+	}
+
+	ks = &KnativeServing{
+		Spec: KnativeServingSpec{
+			Config: map[string]map[string]string{
+				"network": {"domainTemplate": "{{.Name}}-{{.Namespace}}.{{.Domain}}"},
+			},
+			HighAvailability: &HighAvailability{Replicas: 3},
+		},
+	}
+	ks.SetDefaults(context.Background())
+	if got := ks.Spec.Config["network"]["domainTemplate"]; got != "{{.Name}}-{{.Namespace}}.{{.Domain}}" {
+		t.Errorf("domainTemplate = %q, want the one set", got)
+	}
+	if got := ks.Spec.Config["network"]["ingress.class"]; got != "istio.ingress.networking.knative.dev" {
+		t.Errorf("ingress.class = %q, want istio's", got)
+	}
+	if got := ks.Spec.HighAvailability.Replicas; got != 3 {
+		t.Errorf("highAvailability.replicas = %d, want 3", got)
+	}
+
+	ks = &KnativeServing{
+		Spec: KnativeServingSpec{
+			HighAvailability: &HighAvailability{Replicas: 3, Autoscaling: &HighAvailabilityAutoscaling{MaxReplicas: 10}},
+		},
+	}
+	ks.SetDefaults(context.Background())
+	wantAutoscaling := HighAvailabilityAutoscaling{MinReplicas: 3, MaxReplicas: 10, TargetCPUUtilizationPercentage: 100}
+	if got := *ks.Spec.HighAvailability.Autoscaling; got != wantAutoscaling {
+		t.Errorf("highAvailability.autoscaling = %+v, want %+v", got, wantAutoscaling)
+	}
+}
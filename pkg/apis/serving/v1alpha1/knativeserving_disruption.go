@@ -0,0 +1,69 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/pkg/apis"
+)
+
+func validatePodDisruptionBudgets(budgets []PodDisruptionBudget) *apis.FieldError {
+	var errs *apis.FieldError
+	seen := map[string]bool{}
+	for i, b := range budgets {
+		var err *apis.FieldError
+		switch {
+		case b.Deployment == "":
+			err = apis.ErrMissingField("deployment")
+		case seen[b.Deployment]:
+			err = &apis.FieldError{
+				Message: fmt.Sprintf("deployment %q has more than one budget", b.Deployment),
+				Paths:   []string{"deployment"},
+			}
+		}
+		seen[b.Deployment] = true
+		switch {
+		case b.MinAvailable == nil && b.MaxUnavailable == nil:
+			err = err.Also(apis.ErrMissingOneOf("minAvailable", "maxUnavailable"))
+		case b.MinAvailable != nil && b.MaxUnavailable != nil:
+			err = err.Also(apis.ErrMultipleOneOf("minAvailable", "maxUnavailable"))
+		}
+		err = err.Also(validateIntOrPercent(b.MinAvailable, "minAvailable"))
+		err = err.Also(validateIntOrPercent(b.MaxUnavailable, "maxUnavailable"))
+		errs = errs.Also(err.ViaFieldIndex("podDisruptionBudgets", i))
+	}
+	return errs
+}
+
+// A count of pods mustn't be negative, nor a percentage exceed 100%
+func validateIntOrPercent(value *intstr.IntOrString, field string) *apis.FieldError {
+	if value == nil {
+		return nil
+	}
+	if value.Type == intstr.Int {
+		if value.IntValue() < 0 {
+			return apis.ErrInvalidValue(value.IntValue(), field)
+		}
+		return nil
+	}
+	percent, err := intstr.GetValueFromIntOrPercent(value, 100, false)
+	if err != nil || percent < 0 || percent > 100 {
+		return apis.ErrInvalidValue(value.String(), field)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+func validateDomain(domain map[string]DomainSelector) *apis.FieldError {
+	if len(domain) == 0 {
+		return nil
+	}
+	var errs *apis.FieldError
+	hasDefault := false
+	for name, d := range domain {
+		if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+			errs = errs.Also(apis.ErrInvalidKeyName(name, "domain", msgs...))
+		}
+		for k, v := range d.Selector {
+			msgs := append(validation.IsQualifiedName(k), validation.IsValidLabelValue(v)...)
+			if len(msgs) > 0 {
+				errs = errs.Also(apis.ErrInvalidKeyName(k, "selector", msgs...).ViaKey(name).ViaField("domain"))
+			}
+		}
+		hasDefault = hasDefault || len(d.Selector) == 0
+	}
+	if !hasDefault {
+		errs = errs.Also(&apis.FieldError{
+			Message: "at least one domain must have an empty selector to serve as the default",
+			Paths:   []string{"domain"},
+		})
+	}
+	return errs
+}
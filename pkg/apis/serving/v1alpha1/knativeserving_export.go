@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+// DefaultExportConfigMapName is the name the ConfigMaps of an export are
+// numbered after by default
+const DefaultExportConfigMapName = "knative-serving-manifest"
+
+// Name is the name the ConfigMaps of the export are numbered after
+func (e *ManifestExport) Name() string {
+	if e.ConfigMapName == "" {
+		return DefaultExportConfigMapName
+	}
+	return e.ConfigMapName
+}
+
+// ExportOnly reports whether the KnativeServing only exports its manifest, installing nothing
+func (ks *KnativeServing) ExportOnly() bool {
+	return ks.Spec.Export != nil && ks.Spec.Export.Only
+}
+
+func (e *ManifestExport) validate() *apis.FieldError {
+	if e == nil || e.ConfigMapName == "" {
+		return nil
+	}
+	// Leave room for the number of the ConfigMap
+	if msgs := validation.IsDNS1123Subdomain(e.ConfigMapName + "-999"); len(msgs) > 0 {
+		err := apis.ErrInvalidValue(e.ConfigMapName, "configMapName")
+		err.Details = strings.Join(msgs, ", ")
+		return err
+	}
+	return nil
+}
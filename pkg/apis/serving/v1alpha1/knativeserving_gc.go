@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// The entries of config-gc the fields of GC replace
+	staleRevisionCreateDelayKey        = "stale-revision-create-delay"
+	staleRevisionTimeoutKey            = "stale-revision-timeout"
+	staleRevisionMinimumGenerationsKey = "stale-revision-minimum-generations"
+	staleRevisionLastpinnedDebounceKey = "stale-revision-lastpinned-debounce"
+
+	// The controller's resync period, which Knative requires the
+	// timeout to exceed the debounce by
+	minStaleRevisionTimeout = 10 * time.Hour
+)
+
+// Config returns the entries of config-gc the set fields replace
+func (gc *GC) Config() map[string]string {
+	result := map[string]string{}
+	if gc == nil {
+		return result
+	}
+	if gc.StaleRevisionCreateDelay != nil {
+		result[staleRevisionCreateDelayKey] = gc.StaleRevisionCreateDelay.Duration.String()
+	}
+	if gc.StaleRevisionTimeout != nil {
+		result[staleRevisionTimeoutKey] = gc.StaleRevisionTimeout.Duration.String()
+	}
+	if gc.StaleRevisionMinimumGenerations != nil {
+		result[staleRevisionMinimumGenerationsKey] = strconv.FormatInt(*gc.StaleRevisionMinimumGenerations, 10)
+	}
+	if gc.StaleRevisionLastpinnedDebounce != nil {
+		result[staleRevisionLastpinnedDebounceKey] = gc.StaleRevisionLastpinnedDebounce.Duration.String()
+	}
+	return result
+}
+
+// SetDefaults sets the unset fields to Knative's defaults, unless the
+// entries they replace are set in config
+func (gc *GC) SetDefaults(config map[string]string) {
+	if _, ok := config[staleRevisionCreateDelayKey]; !ok && gc.StaleRevisionCreateDelay == nil {
+		gc.StaleRevisionCreateDelay = &metav1.Duration{Duration: 24 * time.Hour}
+	}
+	if _, ok := config[staleRevisionTimeoutKey]; !ok && gc.StaleRevisionTimeout == nil {
+		gc.StaleRevisionTimeout = &metav1.Duration{Duration: 15 * time.Hour}
+	}
+	if _, ok := config[staleRevisionMinimumGenerationsKey]; !ok && gc.StaleRevisionMinimumGenerations == nil {
+		generations := int64(1)
+		gc.StaleRevisionMinimumGenerations = &generations
+	}
+	if _, ok := config[staleRevisionLastpinnedDebounceKey]; !ok && gc.StaleRevisionLastpinnedDebounce == nil {
+		gc.StaleRevisionLastpinnedDebounce = &metav1.Duration{Duration: 5 * time.Hour}
+	}
+}
+
+// Validate checks the fields are within the ranges Knative accepts
+func (gc *GC) Validate(ctx context.Context) *apis.FieldError {
+	if gc == nil {
+		return nil
+	}
+	var errs *apis.FieldError
+	if d := gc.StaleRevisionCreateDelay; d != nil && d.Duration < 0 {
+		errs = errs.Also(apis.ErrInvalidValue(d.Duration, "staleRevisionCreateDelay"))
+	}
+	if g := gc.StaleRevisionMinimumGenerations; g != nil && *g < 0 {
+		errs = errs.Also(apis.ErrInvalidValue(*g, "staleRevisionMinimumGenerations"))
+	}
+	if d := gc.StaleRevisionLastpinnedDebounce; d != nil && d.Duration < 0 {
+		errs = errs.Also(apis.ErrInvalidValue(d.Duration, "staleRevisionLastpinnedDebounce"))
+	}
+	if t, d := gc.StaleRevisionTimeout, gc.StaleRevisionLastpinnedDebounce; t != nil && d != nil && t.Duration-d.Duration < minStaleRevisionTimeout {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(t.Duration, d.Duration+minStaleRevisionTimeout, "unbounded", "staleRevisionTimeout"))
+	}
+	return errs
+}
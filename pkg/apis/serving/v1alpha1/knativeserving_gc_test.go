@@ -0,0 +1,71 @@
+package v1alpha1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGCDefaults(t *testing.T) {
+	ks := &KnativeServing{
+		Spec: KnativeServingSpec{
+			Config: map[string]map[string]string{"gc": {"stale-revision-timeout": "20h"}},
+			GC:     &GC{},
+		},
+	}
+	ks.SetDefaults(context.Background())
+	want := map[string]string{
+		"stale-revision-create-delay":        "24h0m0s",
+		"stale-revision-minimum-generations": "1",
+		"stale-revision-lastpinned-debounce": "5h0m0s",
+	}
+	if got := ks.Spec.GC.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %v, want %v leaving stale-revision-timeout to spec.config", got, want)
+	}
+
+	ks = &KnativeServing{}
+	ks.SetDefaults(context.Background())
+	if ks.Spec.GC != nil {
+		t.Errorf("GC = %v, want it left unset", ks.Spec.GC)
+	}
+}
+
+func TestGCValidate(t *testing.T) {
+	duration := func(d time.Duration) *metav1.Duration {
+		return &metav1.Duration{Duration: d}
+	}
+	negative := int64(-1)
+	tests := []struct {
+		name    string
+		gc      *GC
+		wantErr bool
+	}{{
+		name: "unset",
+	}, {
+		name: "in range",
+		gc:   &GC{StaleRevisionTimeout: duration(16 * time.Hour), StaleRevisionLastpinnedDebounce: duration(6 * time.Hour)},
+	}, {
+		name:    "negative generations",
+		gc:      &GC{StaleRevisionMinimumGenerations: &negative},
+		wantErr: true,
+	}, {
+		name:    "negative create delay",
+		gc:      &GC{StaleRevisionCreateDelay: duration(-time.Hour)},
+		wantErr: true,
+	}, {
+		name:    "timeout within the debounce and resync",
+		gc:      &GC{StaleRevisionTimeout: duration(12 * time.Hour), StaleRevisionLastpinnedDebounce: duration(5 * time.Hour)},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.gc.Validate(context.Background())
+			if got := err != nil; got != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
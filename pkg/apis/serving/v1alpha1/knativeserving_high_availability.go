@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// The fewest replicas that make a deployment highly available
+	defaultHighAvailabilityReplicas = 2
+	// Knative's CPU target for the activator
+	defaultTargetCPUUtilizationPercentage = 100
+)
+
+// SetDefaults sets the replicas to the fewest that make a deployment
+// highly available, and the unset autoscaling fields from them
+func (ha *HighAvailability) SetDefaults() {
+	if ha.Replicas == 0 {
+		ha.Replicas = defaultHighAvailabilityReplicas
+	}
+	if a := ha.Autoscaling; a != nil {
+		if a.MinReplicas == 0 {
+			a.MinReplicas = ha.Replicas
+		}
+		if a.TargetCPUUtilizationPercentage == 0 {
+			a.TargetCPUUtilizationPercentage = defaultTargetCPUUtilizationPercentage
+		}
+	}
+}
+
+// Validate checks the autoscaling bounds are consistent
+func (ha *HighAvailability) Validate(ctx context.Context) *apis.FieldError {
+	if ha == nil || ha.Autoscaling == nil {
+		return nil
+	}
+	a := ha.Autoscaling
+	var errs *apis.FieldError
+	if a.MinReplicas < 0 {
+		errs = errs.Also(apis.ErrInvalidValue(a.MinReplicas, "autoscaling.minReplicas"))
+	}
+	if a.MaxReplicas < 1 {
+		errs = errs.Also(apis.ErrInvalidValue(a.MaxReplicas, "autoscaling.maxReplicas"))
+	} else if a.MinReplicas > a.MaxReplicas {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("minReplicas %d exceeds maxReplicas %d", a.MinReplicas, a.MaxReplicas),
+			Paths:   []string{"autoscaling.minReplicas"},
+		})
+	}
+	if a.TargetCPUUtilizationPercentage < 0 {
+		errs = errs.Also(apis.ErrInvalidValue(a.TargetCPUUtilizationPercentage, "autoscaling.targetCPUUtilizationPercentage"))
+	}
+	return errs
+}
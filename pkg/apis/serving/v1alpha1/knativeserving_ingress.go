@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+// The names of the supported ingress implementations
+const (
+	IstioIngress      = "istio"
+	KourierIngress    = "kourier"
+	ContourIngress    = "contour"
+	GlooIngress       = "gloo"
+	AmbassadorIngress = "ambassador"
+
+	// Appended to the name of an ingress to form its ingress.class
+	ingressClassSuffix = ".ingress.networking.knative.dev"
+)
+
+// IngressNames returns the names of every supported ingress
+func IngressNames() []string {
+	return []string{IstioIngress, KourierIngress, ContourIngress, GlooIngress, AmbassadorIngress}
+}
+
+// Enabled returns the names of the enabled ingresses
+func (ic *IngressConfigs) Enabled() []string {
+	var result []string
+	if ic == nil {
+		return result
+	}
+	if ic.Istio.Enabled {
+		result = append(result, IstioIngress)
+	}
+	if ic.Kourier.Enabled {
+		result = append(result, KourierIngress)
+	}
+	if ic.Contour.Enabled {
+		result = append(result, ContourIngress)
+	}
+	if ic.Gloo.Enabled {
+		result = append(result, GlooIngress)
+	}
+	if ic.Ambassador.Enabled {
+		result = append(result, AmbassadorIngress)
+	}
+	return result
+}
+
+// Name returns the selected ingress, istio unless another is enabled
+func (ic *IngressConfigs) Name() string {
+	if enabled := ic.Enabled(); len(enabled) > 0 {
+		return enabled[0]
+	}
+	return IstioIngress
+}
+
+// Class returns the ingress.class of the selected ingress, e.g.
+// istio.ingress.networking.knative.dev
+func (ic *IngressConfigs) Class() string {
+	return ic.Name() + ingressClassSuffix
+}
+
+// GatewaysInstalled reports whether the istio Gateways are installed
+// rather than brought by the user
+func (ic *IngressConfigs) GatewaysInstalled() bool {
+	return ic == nil || ic.Istio.InstallGateways == nil || *ic.Istio.InstallGateways
+}
+
+// Validate implements apis.Validatable
+func (ic *IngressConfigs) Validate(ctx context.Context) *apis.FieldError {
+	if enabled := ic.Enabled(); len(enabled) > 1 {
+		return &apis.FieldError{
+			Message: "Only one ingress may be enabled, found " + strings.Join(enabled, ", "),
+			Paths:   enabled,
+		}
+	}
+	if ic == nil {
+		return nil
+	}
+	if ic.Gloo.Enabled {
+		// Until a net-gloo release is bundled, it can't be installed
+		return &apis.FieldError{
+			Message: "The gloo ingress is not supported yet",
+			Paths:   []string{"gloo.enabled"},
+		}
+	}
+	return ic.Contour.External.Validate(ctx).ViaField("contour", "external").Also(
+		ic.Contour.Internal.Validate(ctx).ViaField("contour", "internal"))
+}
+
+// Validate implements apis.Validatable
+func (cv *ContourVisibility) Validate(ctx context.Context) *apis.FieldError {
+	if cv == nil || cv.Service == "" {
+		return nil
+	}
+	if parts := strings.Split(cv.Service, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return apis.ErrInvalidValue(cv.Service, "service")
+	}
+	return nil
+}
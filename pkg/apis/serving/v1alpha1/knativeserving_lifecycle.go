@@ -16,12 +16,23 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
 	"knative.dev/pkg/apis"
 )
 
 var conditions = apis.NewLivingConditionSet(
 	DeploymentsAvailable,
+	ProbesSucceeded,
 	InstallSucceeded,
+	SpecValidated,
+	PreflightSucceeded,
+	VersionMigrationEligible,
+	Transformed,
+	Applied,
 )
 
 // GetConditions implements apis.ConditionsAccessor
@@ -38,6 +49,26 @@ func (is *KnativeServingStatus) IsReady() bool {
 	return conditions.Manage(is).IsHappy()
 }
 
+// Summarize the conditions in the phase: those of an uninstall or
+// error first, then of an upgrade
+func (is *KnativeServingStatus) UpdatePhase() {
+	installed := is.GetCondition(InstallSucceeded)
+	switch {
+	case installed != nil && installed.Reason == "Uninstalling":
+		is.Phase = PhaseDeleting
+	case is.IsFailedPermanently() || installed.IsFalse() || is.GetCondition(SpecValidated).IsFalse() ||
+		is.GetCondition(PreflightSucceeded).IsFalse() || is.GetCondition(Transformed).IsFalse() ||
+		is.GetCondition(Applied).IsFalse():
+		is.Phase = PhaseError
+	case is.IsUpgradeInProgress():
+		is.Phase = PhaseUpgrading
+	case is.IsReady():
+		is.Phase = PhaseReady
+	default:
+		is.Phase = PhaseInstalling
+	}
+}
+
 func (is *KnativeServingStatus) IsInstalled() bool {
 	return is.GetCondition(InstallSucceeded).IsTrue()
 }
@@ -72,10 +103,96 @@ func (is *KnativeServingStatus) MarkIgnored(msg string) {
 		"Install not attempted: %s", msg)
 }
 
+func (is *KnativeServingStatus) MarkUninstalling() {
+	conditions.Manage(is).MarkFalse(
+		InstallSucceeded,
+		"Uninstalling",
+		"Deleting installed resources")
+}
+
 func (is *KnativeServingStatus) MarkInstallSucceeded() {
 	conditions.Manage(is).MarkTrue(InstallSucceeded)
 }
 
+func (is *KnativeServingStatus) MarkSpecValidated() {
+	conditions.Manage(is).MarkTrue(SpecValidated)
+}
+
+func (is *KnativeServingStatus) MarkSpecInvalid(msg string) {
+	conditions.Manage(is).MarkFalse(
+		SpecValidated,
+		"InvalidSpec",
+		"The spec is invalid: %s", msg)
+}
+
+func (is *KnativeServingStatus) MarkPreflightSucceeded() {
+	conditions.Manage(is).MarkTrue(PreflightSucceeded)
+}
+
+func (is *KnativeServingStatus) MarkPreflightFailed(msg string) {
+	conditions.Manage(is).MarkFalse(
+		PreflightSucceeded,
+		"PreflightFailed",
+		"The cluster can't run Knative Serving: %s", msg)
+}
+
+func (is *KnativeServingStatus) MarkTransformed() {
+	conditions.Manage(is).MarkTrue(Transformed)
+}
+
+func (is *KnativeServingStatus) MarkTransformFailed(msg string) {
+	conditions.Manage(is).MarkFalse(
+		Transformed,
+		"Error",
+		"Transforming the manifest failed with message: %s", msg)
+}
+
+func (is *KnativeServingStatus) MarkApplied() {
+	conditions.Manage(is).MarkTrue(Applied)
+}
+
+func (is *KnativeServingStatus) MarkApplyFailed(msg string) {
+	conditions.Manage(is).MarkFalse(
+		Applied,
+		"Error",
+		"Applying the manifest failed with message: %s", msg)
+}
+
+func (is *KnativeServingStatus) IsVersionMigrationEligible() bool {
+	return !is.GetCondition(VersionMigrationEligible).IsFalse()
+}
+
+// IsVersionMigrating reports whether migration Jobs are running or failed
+func (is *KnativeServingStatus) IsVersionMigrating() bool {
+	condition := is.GetCondition(VersionMigrationEligible)
+	return condition != nil && (condition.Reason == "MigrationRunning" || condition.Reason == "MigrationFailed")
+}
+
+func (is *KnativeServingStatus) MarkVersionMigrationEligible() {
+	conditions.Manage(is).MarkTrue(VersionMigrationEligible)
+}
+
+func (is *KnativeServingStatus) MarkVersionMigrationNotEligible(msg string) {
+	conditions.Manage(is).MarkFalse(
+		VersionMigrationEligible,
+		"NotEligible",
+		"%s", msg)
+}
+
+func (is *KnativeServingStatus) MarkVersionMigrationRunning(jobs string) {
+	conditions.Manage(is).MarkUnknown(
+		VersionMigrationEligible,
+		"MigrationRunning",
+		"Waiting on storage version migration Jobs %s", jobs)
+}
+
+func (is *KnativeServingStatus) MarkVersionMigrationFailed(msg string) {
+	conditions.Manage(is).MarkFalse(
+		VersionMigrationEligible,
+		"MigrationFailed",
+		"%s", msg)
+}
+
 func (is *KnativeServingStatus) MarkDeploymentsAvailable() {
 	conditions.Manage(is).MarkTrue(DeploymentsAvailable)
 }
@@ -86,3 +203,181 @@ func (is *KnativeServingStatus) MarkDeploymentsNotReady() {
 		"NotReady",
 		"Waiting on deployments")
 }
+
+func (is *KnativeServingStatus) MarkDeploymentsProgressDeadlineExceeded(deadline time.Duration, names []string) {
+	conditions.Manage(is).MarkFalse(
+		DeploymentsAvailable,
+		"ProgressDeadlineExceeded",
+		"Deployments not available within %v: %s", deadline, strings.Join(names, ", "))
+}
+
+// IsProgressDeadlineExceeded reports whether the deployments have been
+// unavailable for longer than their progress deadline
+func (is *KnativeServingStatus) IsProgressDeadlineExceeded() bool {
+	condition := is.GetCondition(DeploymentsAvailable)
+	return condition != nil && condition.Reason == "ProgressDeadlineExceeded"
+}
+
+func (is *KnativeServingStatus) MarkProbesSucceeded() {
+	conditions.Manage(is).MarkTrue(ProbesSucceeded)
+}
+
+func (is *KnativeServingStatus) MarkProbesFailed(msg string) {
+	conditions.Manage(is).MarkFalse(
+		ProbesSucceeded,
+		"ProbeFailed",
+		"%s", msg)
+}
+
+func (is *KnativeServingStatus) IsInstallDeadlineExceeded() bool {
+	return is.GetCondition(InstallDeadlineExceeded).IsTrue()
+}
+
+func (is *KnativeServingStatus) MarkInstallDeadlineExceeded(timeout time.Duration) {
+	conditions.Manage(is).SetCondition(apis.Condition{
+		Type:     InstallDeadlineExceeded,
+		Status:   corev1.ConditionTrue,
+		Reason:   "DeadlineExceeded",
+		Message:  fmt.Sprintf("Install did not become ready within %v", timeout),
+		Severity: apis.ConditionSeverityWarning,
+	})
+}
+
+func (is *KnativeServingStatus) ClearInstallDeadlineExceeded() {
+	is.clearCondition(InstallDeadlineExceeded)
+}
+
+func (is *KnativeServingStatus) IsUpgradeInProgress() bool {
+	return is.GetCondition(UpgradeInProgress).IsTrue()
+}
+
+func (is *KnativeServingStatus) MarkUpgradeInProgress(from, to string) {
+	conditions.Manage(is).SetCondition(apis.Condition{
+		Type:     UpgradeInProgress,
+		Status:   corev1.ConditionTrue,
+		Reason:   "Upgrading",
+		Message:  fmt.Sprintf("Upgrading from %s to %s", from, to),
+		Severity: apis.ConditionSeverityInfo,
+	})
+}
+
+func (is *KnativeServingStatus) ClearUpgradeInProgress() {
+	is.clearCondition(UpgradeInProgress)
+}
+
+func (is *KnativeServingStatus) MarkCertManagerAvailable() {
+	conditions.Manage(is).SetCondition(apis.Condition{
+		Type:     CertManagerAvailable,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+	})
+}
+
+func (is *KnativeServingStatus) MarkCertManagerUnavailable(reason, msg string) {
+	conditions.Manage(is).SetCondition(apis.Condition{
+		Type:     CertManagerAvailable,
+		Status:   corev1.ConditionFalse,
+		Reason:   reason,
+		Message:  msg,
+		Severity: apis.ConditionSeverityWarning,
+	})
+}
+
+func (is *KnativeServingStatus) ClearCertManagerAvailable() {
+	is.clearCondition(CertManagerAvailable)
+}
+
+func (is *KnativeServingStatus) MarkGatewaysAvailable() {
+	conditions.Manage(is).SetCondition(apis.Condition{
+		Type:     GatewaysAvailable,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+	})
+}
+
+func (is *KnativeServingStatus) MarkGatewaysUnavailable(reason, msg string) {
+	conditions.Manage(is).SetCondition(apis.Condition{
+		Type:     GatewaysAvailable,
+		Status:   corev1.ConditionFalse,
+		Reason:   reason,
+		Message:  msg,
+		Severity: apis.ConditionSeverityWarning,
+	})
+}
+
+func (is *KnativeServingStatus) ClearGatewaysAvailable() {
+	is.clearCondition(GatewaysAvailable)
+}
+
+func (is *KnativeServingStatus) MarkManifestVersionMatches() {
+	conditions.Manage(is).SetCondition(apis.Condition{
+		Type:     ManifestVersionMatches,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+	})
+}
+
+func (is *KnativeServingStatus) MarkManifestVersionMismatch(operatorVersion, servingVersion string) {
+	conditions.Manage(is).SetCondition(apis.Condition{
+		Type:     ManifestVersionMatches,
+		Status:   corev1.ConditionFalse,
+		Reason:   "VersionMismatch",
+		Message:  fmt.Sprintf("The operator %s bundles the manifest of Serving %s", operatorVersion, servingVersion),
+		Severity: apis.ConditionSeverityWarning,
+	})
+}
+
+func (is *KnativeServingStatus) ClearManifestVersionMatches() {
+	is.clearCondition(ManifestVersionMatches)
+}
+
+func (is *KnativeServingStatus) MarkTracingCollectorReachable() {
+	conditions.Manage(is).SetCondition(apis.Condition{
+		Type:     TracingCollectorReachable,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+	})
+}
+
+func (is *KnativeServingStatus) MarkTracingCollectorUnreachable(msg string) {
+	conditions.Manage(is).SetCondition(apis.Condition{
+		Type:     TracingCollectorReachable,
+		Status:   corev1.ConditionFalse,
+		Reason:   "CollectorUnreachable",
+		Message:  msg,
+		Severity: apis.ConditionSeverityWarning,
+	})
+}
+
+func (is *KnativeServingStatus) ClearTracingCollectorReachable() {
+	is.clearCondition(TracingCollectorReachable)
+}
+
+func (is *KnativeServingStatus) IsFailedPermanently() bool {
+	return is.GetCondition(FailedPermanently).IsTrue()
+}
+
+func (is *KnativeServingStatus) MarkFailedPermanently(msg string) {
+	conditions.Manage(is).SetCondition(apis.Condition{
+		Type:     FailedPermanently,
+		Status:   corev1.ConditionTrue,
+		Reason:   "PermanentError",
+		Message:  msg,
+		Severity: apis.ConditionSeverityError,
+	})
+}
+
+func (is *KnativeServingStatus) ClearFailedPermanently() {
+	is.clearCondition(FailedPermanently)
+}
+
+// Remove a condition that isn't part of the living condition set
+func (is *KnativeServingStatus) clearCondition(t apis.ConditionType) {
+	var result apis.Conditions
+	for _, c := range is.Conditions {
+		if c.Type != t {
+			result = append(result, c)
+		}
+	}
+	is.Conditions = result
+}
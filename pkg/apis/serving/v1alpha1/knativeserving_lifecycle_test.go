@@ -0,0 +1,121 @@
+package v1alpha1
+
+import (
+	"testing"
+)
+
+func TestReadyRequiresEveryStage(t *testing.T) {
+	status := &KnativeServingStatus{}
+	status.InitializeConditions()
+	stages := []func(){
+		status.MarkSpecValidated,
+		status.MarkPreflightSucceeded,
+		status.MarkVersionMigrationEligible,
+		status.MarkTransformed,
+		status.MarkApplied,
+		status.MarkInstallSucceeded,
+		status.MarkDeploymentsAvailable,
+		status.MarkProbesSucceeded,
+	}
+	for i, mark := range stages {
+		if status.IsReady() {
+			t.Fatalf("Ready after %d of %d stages", i, len(stages))
+		}
+		mark()
+	}
+	if !status.IsReady() {
+		t.Error("expected Ready after every stage")
+	}
+
+	status.MarkApplyFailed("boom")
+	if status.IsReady() {
+		t.Error("a failed apply should not be Ready")
+	}
+}
+
+func TestVersionMigrationEligibleGatesReadiness(t *testing.T) {
+	status := &KnativeServingStatus{}
+	status.InitializeConditions()
+	if !status.IsVersionMigrationEligible() {
+		t.Error("expected eligibility until proven otherwise")
+	}
+	status.MarkTransformed()
+	status.MarkApplied()
+	status.MarkInstallSucceeded()
+	status.MarkDeploymentsAvailable()
+	status.MarkSpecValidated()
+	status.MarkPreflightSucceeded()
+	status.MarkProbesSucceeded()
+	status.MarkVersionMigrationEligible()
+	if !status.IsReady() {
+		t.Error("expected Ready once eligible")
+	}
+
+	status.MarkVersionMigrationRunning("storage-version-migration-serving")
+	if status.IsReady() || !status.IsVersionMigrating() {
+		t.Error("running migration Jobs should hold back readiness")
+	}
+	status.MarkVersionMigrationNotEligible("stored version dropped")
+	if status.IsVersionMigrationEligible() {
+		t.Error("expected VersionMigrationEligible to be False")
+	}
+	if status.IsReady() || status.IsVersionMigrating() {
+		t.Error("an ineligible migration should not be Ready")
+	}
+}
+
+func TestUpdatePhase(t *testing.T) {
+	status := &KnativeServingStatus{}
+	status.InitializeConditions()
+	status.UpdatePhase()
+	if status.Phase != PhaseInstalling {
+		t.Errorf("Phase = %s, want %s", status.Phase, PhaseInstalling)
+	}
+
+	status.MarkTransformed()
+	status.MarkApplied()
+	status.MarkInstallSucceeded()
+	status.MarkDeploymentsAvailable()
+	status.MarkSpecValidated()
+	status.MarkPreflightSucceeded()
+	status.MarkVersionMigrationEligible()
+	status.MarkProbesSucceeded()
+	status.UpdatePhase()
+	if status.Phase != PhaseReady {
+		t.Errorf("Phase = %s, want %s", status.Phase, PhaseReady)
+	}
+
+	status.MarkUpgradeInProgress("0.6.0", "0.7.0")
+	status.UpdatePhase()
+	if status.Phase != PhaseUpgrading {
+		t.Errorf("Phase = %s, want %s", status.Phase, PhaseUpgrading)
+	}
+
+	status.MarkApplyFailed("boom")
+	status.UpdatePhase()
+	if status.Phase != PhaseError {
+		t.Errorf("Phase = %s, want %s", status.Phase, PhaseError)
+	}
+
+	status.MarkUninstalling()
+	status.UpdatePhase()
+	if status.Phase != PhaseDeleting {
+		t.Errorf("Phase = %s, want %s", status.Phase, PhaseDeleting)
+	}
+}
+
+func TestSpecInvalid(t *testing.T) {
+	status := &KnativeServingStatus{}
+	status.InitializeConditions()
+	status.MarkSpecInvalid("unknown ConfigMap config-netwrk")
+	if status.IsReady() {
+		t.Error("expected an invalid spec not to be Ready")
+	}
+	status.UpdatePhase()
+	if status.Phase != PhaseError {
+		t.Errorf("Phase = %s, want %s", status.Phase, PhaseError)
+	}
+	if c := status.GetCondition(SpecValidated); c.Reason != "InvalidSpec" {
+		t.Errorf("SpecValidated reason = %q, want InvalidSpec", c.Reason)
+	}
+}
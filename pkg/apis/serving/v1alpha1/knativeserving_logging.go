@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// The entries of config-logging the fields of Logging replace
+	logLevelKeyPrefix  = "loglevel."
+	zapLoggerConfigKey = "zap-logger-config"
+)
+
+var (
+	// The levels zap accepts
+	logLevels = map[string]bool{
+		"debug":  true,
+		"info":   true,
+		"warn":   true,
+		"error":  true,
+		"dpanic": true,
+		"panic":  true,
+		"fatal":  true,
+	}
+)
+
+// Config returns the entries of config-logging the set fields replace
+func (l *Logging) Config() map[string]string {
+	result := map[string]string{}
+	if l == nil {
+		return result
+	}
+	for component, level := range l.Levels {
+		result[logLevelKeyPrefix+component] = level
+	}
+	if l.ZapLoggerConfig != "" {
+		result[zapLoggerConfigKey] = l.ZapLoggerConfig
+	}
+	return result
+}
+
+// Validate checks the levels are zap's and the zap config is JSON
+func (l *Logging) Validate(ctx context.Context) *apis.FieldError {
+	if l == nil {
+		return nil
+	}
+	var errs *apis.FieldError
+	for component, level := range l.Levels {
+		if component == "" {
+			errs = errs.Also(apis.ErrInvalidKeyName(component, "levels"))
+		} else if !logLevels[level] {
+			errs = errs.Also(apis.ErrInvalidValue(level, "levels."+component))
+		}
+	}
+	if l.ZapLoggerConfig != "" && !json.Valid([]byte(l.ZapLoggerConfig)) {
+		errs = errs.Also(apis.ErrInvalidValue(l.ZapLoggerConfig, "zapLoggerConfig"))
+	}
+	return errs
+}
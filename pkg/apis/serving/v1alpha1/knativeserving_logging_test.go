@@ -0,0 +1,47 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLoggingValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		logging *Logging
+		wantErr bool
+	}{{
+		name: "unset",
+	}, {
+		name:    "levels and zap config",
+		logging: &Logging{Levels: map[string]string{"controller": "debug"}, ZapLoggerConfig: `{"level": "info"}`},
+	}, {
+		name:    "unknown level",
+		logging: &Logging{Levels: map[string]string{"controller": "verbose"}},
+		wantErr: true,
+	}, {
+		name:    "unnamed component",
+		logging: &Logging{Levels: map[string]string{"": "debug"}},
+		wantErr: true,
+	}, {
+		name:    "zap config isn't JSON",
+		logging: &Logging{ZapLoggerConfig: "level: info"},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.logging.Validate(context.Background())
+			if got := err != nil; got != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoggingConfig(t *testing.T) {
+	logging := &Logging{Levels: map[string]string{"activator": "warn"}, ZapLoggerConfig: "{}"}
+	config := logging.Config()
+	if len(config) != 2 || config["loglevel.activator"] != "warn" || config["zap-logger-config"] != "{}" {
+		t.Errorf("Config() = %v", config)
+	}
+}
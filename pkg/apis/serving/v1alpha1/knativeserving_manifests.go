@@ -0,0 +1,55 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"net/url"
+
+	"knative.dev/pkg/apis"
+)
+
+func validateAdditionalManifests(manifests []AdditionalManifest) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, m := range manifests {
+		errs = errs.Also(m.validate().ViaFieldIndex("additionalManifests", i))
+	}
+	return errs
+}
+
+func (m *AdditionalManifest) validate() *apis.FieldError {
+	switch {
+	case m.ConfigMap != "" && m.URL != "":
+		return apis.ErrMultipleOneOf("configMap", "url")
+	case m.ConfigMap != "":
+		if m.SHA256 != "" {
+			return apis.ErrDisallowedFields("sha256")
+		}
+		return nil
+	case m.URL != "":
+		var errs *apis.FieldError
+		if u, err := url.Parse(m.URL); err != nil || u.Scheme != "https" {
+			errs = errs.Also(&apis.FieldError{
+				Message: "the manifest URL must use https",
+				Paths:   []string{"url"},
+			})
+		}
+		if m.SHA256 == "" {
+			errs = errs.Also(apis.ErrMissingField("sha256"))
+		}
+		return errs
+	}
+	return apis.ErrMissingOneOf("configMap", "url")
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+// Validate checks the labels of the monitors are valid
+func (m *Monitoring) Validate(ctx context.Context) *apis.FieldError {
+	if m == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(m.Labels))
+	for k := range m.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var errs *apis.FieldError
+	for _, k := range keys {
+		if msgs := validation.IsQualifiedName(k); len(msgs) > 0 {
+			errs = errs.Also(apis.ErrInvalidKeyName(k, "labels", msgs...))
+		} else if msgs := validation.IsValidLabelValue(m.Labels[k]); len(msgs) > 0 {
+			err := apis.ErrInvalidValue(m.Labels[k], "labels."+k)
+			err.Details = strings.Join(msgs, ", ")
+			errs = errs.Also(err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,35 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMonitoringValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		monitoring *Monitoring
+		wantErr    bool
+	}{{
+		name: "unset",
+	}, {
+		name:       "labels",
+		monitoring: &Monitoring{Enabled: true, Labels: map[string]string{"release": "prometheus"}},
+	}, {
+		name:       "invalid label key",
+		monitoring: &Monitoring{Enabled: true, Labels: map[string]string{"not a key": "prometheus"}},
+		wantErr:    true,
+	}, {
+		name:       "invalid label value",
+		monitoring: &Monitoring{Enabled: true, Labels: map[string]string{"release": "not a value"}},
+		wantErr:    true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.monitoring.Validate(context.Background())
+			if got := err != nil; got != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
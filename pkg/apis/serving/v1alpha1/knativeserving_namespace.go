@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+// InstallNamespace is the namespace Knative Serving is installed into:
+// spec.namespace, defaulting to the KnativeServing's own
+func (ks *KnativeServing) InstallNamespace() string {
+	if ks.Spec.Namespace != "" {
+		return ks.Spec.Namespace
+	}
+	return ks.GetNamespace()
+}
+
+// CheckImmutableFields implements apis.Immutable: once installed,
+// Knative Serving stays in its namespace, since moving it would leave
+// the old install behind
+func (ks *KnativeServing) CheckImmutableFields(ctx context.Context, og apis.Immutable) *apis.FieldError {
+	original, ok := og.(*KnativeServing)
+	if !ok {
+		return &apis.FieldError{Message: "The provided original was not a KnativeServing"}
+	}
+	if original.Status.Version == "" || ks.InstallNamespace() == original.InstallNamespace() {
+		return nil
+	}
+	return &apis.FieldError{
+		Message: "Immutable field changed",
+		Paths:   []string{"spec.namespace"},
+		Details: fmt.Sprintf("Knative Serving is installed into %s, uninstall it to move it to %s", original.InstallNamespace(), ks.InstallNamespace()),
+	}
+}
+
+func validateNamespace(namespace string) *apis.FieldError {
+	if namespace == "" {
+		return nil
+	}
+	if msgs := validation.IsDNS1123Label(namespace); len(msgs) > 0 {
+		err := apis.ErrInvalidValue(namespace, "namespace")
+		err.Details = strings.Join(msgs, ", ")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+func validateDeploymentOverrides(overrides []DeploymentOverride) *apis.FieldError {
+	var errs *apis.FieldError
+	for i := range overrides {
+		errs = errs.Also(validateEnv(overrides[i].Env).ViaFieldIndex("deploymentOverrides", i))
+		if replicas := overrides[i].Replicas; replicas != nil && *replicas < 0 {
+			errs = errs.Also(apis.ErrInvalidValue(*replicas, "replicas").ViaFieldIndex("deploymentOverrides", i))
+		}
+		errs = errs.Also(validatePriorityClassName(overrides[i].PriorityClassName, "priorityClassName").ViaFieldIndex("deploymentOverrides", i))
+	}
+	return errs
+}
+
+func validatePriorityClassName(name, field string) *apis.FieldError {
+	if name == "" {
+		return nil
+	}
+	if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+		err := apis.ErrInvalidValue(name, field)
+		err.Details = strings.Join(msgs, ", ")
+		return err
+	}
+	return nil
+}
+
+func validateEnv(env []corev1.EnvVar) *apis.FieldError {
+	var errs *apis.FieldError
+	seen := map[string]bool{}
+	for i, e := range env {
+		switch {
+		case e.Name == "":
+			errs = errs.Also(apis.ErrMissingField("name").ViaFieldIndex("env", i))
+		case seen[e.Name]:
+			errs = errs.Also((&apis.FieldError{
+				Message: fmt.Sprintf("env var %q is set more than once", e.Name),
+				Paths:   []string{"name"},
+			}).ViaFieldIndex("env", i))
+		}
+		seen[e.Name] = true
+	}
+	return errs
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"knative.dev/pkg/apis"
+)
+
+// validateOverridesFrom checks the Secret key of the overrides is named
+func validateOverridesFrom(overrides *OverridesSource) *apis.FieldError {
+	if overrides == nil {
+		return nil
+	}
+	ref := overrides.SecretRef
+	if ref == nil {
+		return apis.ErrMissingField("overridesFrom.secretRef")
+	}
+	var errs *apis.FieldError
+	if ref.Name == "" {
+		errs = errs.Also(apis.ErrMissingField("overridesFrom.secretRef.name"))
+	}
+	if ref.Key == "" {
+		errs = errs.Also(apis.ErrMissingField("overridesFrom.secretRef.key"))
+	}
+	return errs
+}
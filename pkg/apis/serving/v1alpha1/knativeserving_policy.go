@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// Update the resource whenever it differs from the manifest
+	ApplyPolicy = "Apply"
+	// Create the resource when it's missing, but leave it be otherwise
+	CreateOnlyPolicy = "CreateOnly"
+	// Neither create nor update the resource
+	NonePolicy = "None"
+)
+
+var policies = []string{ApplyPolicy, CreateOnlyPolicy, NonePolicy}
+
+// For returns the policy of a resource: its own, else its kind's, else
+// the default
+func (p *ManifestPolicy) For(kind, name string) string {
+	if p == nil {
+		return ApplyPolicy
+	}
+	result := p.Default
+	for _, r := range p.Resources {
+		if r.Kind != kind {
+			continue
+		}
+		if r.Name == name {
+			return r.Policy
+		}
+		if r.Name == "" {
+			result = r.Policy
+		}
+	}
+	if result == "" {
+		return ApplyPolicy
+	}
+	return result
+}
+
+func validateManifestPolicy(p *ManifestPolicy) *apis.FieldError {
+	if p == nil {
+		return nil
+	}
+	var errs *apis.FieldError
+	if p.Default != "" && !isPolicy(p.Default) {
+		errs = errs.Also(invalidPolicy(p.Default, "default"))
+	}
+	for i, r := range p.Resources {
+		if r.Kind == "" {
+			errs = errs.Also(apis.ErrMissingField("kind").ViaFieldIndex("resources", i))
+		}
+		if !isPolicy(r.Policy) {
+			errs = errs.Also(invalidPolicy(r.Policy, "policy").ViaFieldIndex("resources", i))
+		}
+	}
+	return errs.ViaField("manifestPolicy")
+}
+
+func invalidPolicy(policy, field string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: "invalid policy " + policy + ", must be one of " + strings.Join(policies, ", "),
+		Paths:   []string{field},
+	}
+}
+
+func isPolicy(policy string) bool {
+	for _, p := range policies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
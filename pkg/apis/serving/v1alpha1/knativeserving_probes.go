@@ -0,0 +1,57 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"knative.dev/pkg/apis"
+)
+
+func validateProbes(probes []ProbeOverride) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, p := range probes {
+		var err *apis.FieldError
+		if p.Container == "" {
+			err = err.Also(apis.ErrMissingField("container"))
+		}
+		if p.Readiness == nil && p.Liveness == nil {
+			err = err.Also(apis.ErrMissingOneOf("readiness", "liveness"))
+		}
+		err = err.Also(p.Readiness.validate().ViaField("readiness"))
+		err = err.Also(p.Liveness.validate().ViaField("liveness"))
+		errs = errs.Also(err.ViaFieldIndex("probes", i))
+	}
+	return errs
+}
+
+func (t *ProbeTuning) validate() *apis.FieldError {
+	if t == nil {
+		return nil
+	}
+	var errs *apis.FieldError
+	if v := t.InitialDelaySeconds; v != nil && *v < 0 {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*v, 0, "unbounded", "initialDelaySeconds"))
+	}
+	if v := t.TimeoutSeconds; v != nil && *v < 1 {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*v, 1, "unbounded", "timeoutSeconds"))
+	}
+	if v := t.PeriodSeconds; v != nil && *v < 1 {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*v, 1, "unbounded", "periodSeconds"))
+	}
+	if v := t.FailureThreshold; v != nil && *v < 1 {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*v, 1, "unbounded", "failureThreshold"))
+	}
+	return errs
+}
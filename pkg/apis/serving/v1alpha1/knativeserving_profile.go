@@ -0,0 +1,124 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+// The presets spec.profile selects
+const (
+	// Small clusters: a single replica of everything, small requests, a
+	// short scale-to-zero grace period and no bundled monitoring
+	DevProfile = "dev"
+	// The release as it ships
+	DefaultProfile = "default"
+	// Production clusters: highly available deployments, larger requests
+	// and a longer scale-to-zero grace period
+	ProductionProfile = "production"
+)
+
+// Profiles lists the profiles spec.profile accepts
+var Profiles = []string{DevProfile, DefaultProfile, ProductionProfile}
+
+// The requests the profiles give the control plane containers
+var profileRequests = map[string]map[string]corev1.ResourceList{
+	DevProfile: {
+		"activator":  requests("20m", "30Mi"),
+		"autoscaler": requests("10m", "30Mi"),
+		"controller": requests("10m", "40Mi"),
+		"webhook":    requests("10m", "20Mi"),
+	},
+	ProductionProfile: {
+		"activator":  requests("500m", "100Mi"),
+		"autoscaler": requests("100m", "100Mi"),
+		"controller": requests("200m", "200Mi"),
+		"webhook":    requests("100m", "100Mi"),
+	},
+}
+
+// The replicas the production profile runs the deployments with
+const productionReplicas = 3
+
+// The scale-to-zero grace periods of the profiles
+var profileScaleToZeroGracePeriods = map[string]time.Duration{
+	DevProfile:        10 * time.Second,
+	ProductionProfile: 60 * time.Second,
+}
+
+func requests(cpu, memory string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+// ApplyProfile fills the fields the spec leaves unset with the presets
+// of its profile; whatever is set, including in config, wins
+func (s *KnativeServingSpec) ApplyProfile() {
+	if s.Profile == "" || s.Profile == DefaultProfile {
+		return
+	}
+	if period, ok := profileScaleToZeroGracePeriods[s.Profile]; ok {
+		if _, set := s.Config["autoscaler"][scaleToZeroGracePeriodKey]; !set {
+			if s.Autoscaler == nil {
+				s.Autoscaler = &Autoscaler{}
+			}
+			if s.Autoscaler.ScaleToZeroGracePeriod == nil {
+				s.Autoscaler.ScaleToZeroGracePeriod = &metav1.Duration{Duration: period}
+			}
+		}
+	}
+	overridden := map[string]bool{}
+	for _, o := range s.Resources {
+		overridden[o.Container] = true
+	}
+	for _, container := range []string{"activator", "autoscaler", "controller", "webhook"} {
+		if r, ok := profileRequests[s.Profile][container]; ok && !overridden[container] {
+			s.Resources = append(s.Resources, ResourceRequirementsOverride{
+				Container:            container,
+				ResourceRequirements: corev1.ResourceRequirements{Requests: r},
+			})
+		}
+	}
+	switch s.Profile {
+	case DevProfile:
+		if !s.IsDisabled(MonitoringComponent) {
+			s.DisabledComponents = append(s.DisabledComponents, MonitoringComponent)
+		}
+	case ProductionProfile:
+		if s.HighAvailability == nil {
+			s.HighAvailability = &HighAvailability{Replicas: productionReplicas}
+		}
+	}
+}
+
+func validateProfile(profile string) *apis.FieldError {
+	if profile == "" {
+		return nil
+	}
+	for _, p := range Profiles {
+		if p == profile {
+			return nil
+		}
+	}
+	return apis.ErrInvalidValue(profile, "profile")
+}
@@ -0,0 +1,85 @@
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyProfile(t *testing.T) {
+	spec := &KnativeServingSpec{Profile: DevProfile}
+	spec.ApplyProfile()
+	if spec.HighAvailability != nil {
+		t.Errorf("highAvailability = %+v, want a single replica", spec.HighAvailability)
+	}
+	if !spec.IsDisabled(MonitoringComponent) {
+		t.Error("expected the dev profile to disable monitoring")
+	}
+	if got := spec.Autoscaler.ScaleToZeroGracePeriod.Duration; got != 10*time.Second {
+		t.Errorf("scaleToZeroGracePeriod = %v, want 10s", got)
+	}
+	if len(spec.Resources) != 4 {
+		t.Errorf("resources = %+v, want requests for each control plane container", spec.Resources)
+	}
+
+	spec = &KnativeServingSpec{Profile: ProductionProfile}
+	spec.ApplyProfile()
+	if spec.HighAvailability == nil || spec.HighAvailability.Replicas != productionReplicas {
+		t.Errorf("highAvailability = %+v, want %d replicas", spec.HighAvailability, productionReplicas)
+	}
+	if spec.IsDisabled(MonitoringComponent) {
+		t.Error("expected the production profile to keep monitoring")
+	}
+
+	spec = &KnativeServingSpec{Profile: DefaultProfile}
+	spec.ApplyProfile()
+	if !reflect.DeepEqual(spec, &KnativeServingSpec{Profile: DefaultProfile}) {
+		t.Errorf("default profile changed the spec: %+v", spec)
+	}
+}
+
+func TestApplyProfileKeepsSetFields(t *testing.T) {
+	memory := corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")}}
+	spec := &KnativeServingSpec{
+		Profile:          ProductionProfile,
+		HighAvailability: &HighAvailability{Replicas: 2},
+		Autoscaler:       &Autoscaler{ScaleToZeroGracePeriod: &metav1.Duration{Duration: 45 * time.Second}},
+		Resources:        []ResourceRequirementsOverride{{Container: "activator", ResourceRequirements: memory}},
+	}
+	spec.ApplyProfile()
+	if got := spec.HighAvailability.Replicas; got != 2 {
+		t.Errorf("highAvailability.replicas = %d, want the 2 set", got)
+	}
+	if got := spec.Autoscaler.ScaleToZeroGracePeriod.Duration; got != 45*time.Second {
+		t.Errorf("scaleToZeroGracePeriod = %v, want the 45s set", got)
+	}
+	for _, o := range spec.Resources {
+		if o.Container == "activator" && !reflect.DeepEqual(o.ResourceRequirements, memory) {
+			t.Errorf("activator resources = %+v, want those set", o.ResourceRequirements)
+		}
+	}
+
+	spec = &KnativeServingSpec{
+		Profile: DevProfile,
+		Config:  map[string]map[string]string{"autoscaler": {"scale-to-zero-grace-period": "20s"}},
+	}
+	spec.ApplyProfile()
+	if spec.Autoscaler != nil {
+		t.Errorf("autoscaler = %+v, want config-autoscaler's grace period kept", spec.Autoscaler)
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	for _, p := range append(Profiles, "") {
+		if err := validateProfile(p); err != nil {
+			t.Errorf("validateProfile(%q) = %v", p, err)
+		}
+	}
+	if err := validateProfile("staging"); err == nil {
+		t.Error("expected an unknown profile to be rejected")
+	}
+}
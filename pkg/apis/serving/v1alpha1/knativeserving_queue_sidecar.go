@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// The entry of config-deployment naming the image of the sidecar
+	queueSidecarImageKey = "queueSidecarImage"
+)
+
+// The prefixes of the entries of config-deployment for each resource
+// of the sidecar, suffixed with Request or Limit
+var queueSidecarResourceKeys = map[corev1.ResourceName]string{
+	corev1.ResourceCPU:              "queueSidecarCPU",
+	corev1.ResourceMemory:           "queueSidecarMemory",
+	corev1.ResourceEphemeralStorage: "queueSidecarEphemeralStorage",
+}
+
+// Config returns the entries of config-deployment the set fields replace
+func (q *QueueSidecar) Config() map[string]string {
+	result := map[string]string{}
+	if q == nil {
+		return result
+	}
+	if q.Image != "" {
+		result[queueSidecarImageKey] = q.Image
+	}
+	if q.Resources != nil {
+		for name, quantity := range q.Resources.Requests {
+			result[queueSidecarResourceKeys[name]+"Request"] = quantity.String()
+		}
+		for name, quantity := range q.Resources.Limits {
+			result[queueSidecarResourceKeys[name]+"Limit"] = quantity.String()
+		}
+	}
+	return result
+}
+
+// Validate checks that only the resources Knative configures are set
+// and that no request exceeds its limit
+func (q *QueueSidecar) Validate(ctx context.Context) *apis.FieldError {
+	if q == nil || q.Resources == nil {
+		return nil
+	}
+	var errs *apis.FieldError
+	for _, field := range []struct {
+		name      string
+		resources corev1.ResourceList
+	}{
+		{"requests", q.Resources.Requests},
+		{"limits", q.Resources.Limits},
+	} {
+		for name := range field.resources {
+			if _, ok := queueSidecarResourceKeys[name]; !ok {
+				errs = errs.Also(apis.ErrInvalidKeyName(string(name), fmt.Sprintf("resources.%s", field.name),
+					"only cpu, memory and ephemeral-storage may be set"))
+			}
+		}
+	}
+	for name, request := range q.Resources.Requests {
+		if limit, ok := q.Resources.Limits[name]; ok && request.Cmp(limit) > 0 {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("the %s request %s exceeds the limit %s", name, request.String(), limit.String()),
+				Paths:   []string{fmt.Sprintf("resources.requests.%s", name)},
+			})
+		}
+	}
+	return errs
+}
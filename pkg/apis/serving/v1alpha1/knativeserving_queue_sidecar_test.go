@@ -0,0 +1,47 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestQueueSidecarValidate(t *testing.T) {
+	resources := func(requests, limits corev1.ResourceList) *QueueSidecar {
+		return &QueueSidecar{Resources: &corev1.ResourceRequirements{Requests: requests, Limits: limits}}
+	}
+	cpu := func(q string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(q)}
+	}
+	tests := []struct {
+		name    string
+		sidecar *QueueSidecar
+		wantErr bool
+	}{{
+		name: "unset",
+	}, {
+		name:    "image only",
+		sidecar: &QueueSidecar{Image: "example.com/queue:v1"},
+	}, {
+		name:    "request within limit",
+		sidecar: resources(cpu("25m"), cpu("1")),
+	}, {
+		name:    "request exceeds limit",
+		sidecar: resources(cpu("2"), cpu("1")),
+		wantErr: true,
+	}, {
+		name:    "unknown resource",
+		sidecar: resources(corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}, nil),
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sidecar.Validate(context.Background())
+			if got := err != nil; got != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
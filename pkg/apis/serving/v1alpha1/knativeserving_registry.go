@@ -0,0 +1,78 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"sort"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+// Separates the architecture of an override from the container or
+// image name, e.g. arm64/activator
+const architectureSeparator = "/"
+
+// Architectures returns the architectures the overrides are keyed by, sorted
+func (r *Registry) Architectures() []string {
+	seen := map[string]bool{}
+	var result []string
+	for key := range r.Override {
+		if arch, _, ok := splitArchitecture(key); ok && !seen[arch] {
+			seen[arch] = true
+			result = append(result, arch)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ForArchitecture returns a copy of the registry whose overrides keyed
+// by the architecture replace those of the same name, leaving out the
+// overrides keyed by any other
+func (r *Registry) ForArchitecture(arch string) *Registry {
+	result := r.DeepCopy()
+	result.Override = map[string]string{}
+	for key, image := range r.Override {
+		if _, _, ok := splitArchitecture(key); !ok {
+			result.Override[key] = image
+		}
+	}
+	for key, image := range r.Override {
+		if keyArch, name, ok := splitArchitecture(key); ok && keyArch == arch {
+			result.Override[name] = image
+		}
+	}
+	return result
+}
+
+func splitArchitecture(key string) (arch, name string, ok bool) {
+	parts := strings.SplitN(key, architectureSeparator, 2)
+	if len(parts) != 2 {
+		return "", key, false
+	}
+	return parts[0], parts[1], true
+}
+
+func validateRegistry(r *Registry) *apis.FieldError {
+	var errs *apis.FieldError
+	for key := range r.Override {
+		if arch, name, ok := splitArchitecture(key); ok && (arch == "" || name == "" || strings.Contains(name, architectureSeparator)) {
+			errs = errs.Also(apis.ErrInvalidKeyName(key, "override", "must be a name, or an architecture and a name, e.g. arm64/activator"))
+		}
+	}
+	return errs.ViaField("registry")
+}
@@ -0,0 +1,46 @@
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegistryForArchitecture(t *testing.T) {
+	registry := &Registry{
+		Default: "example.com/${NAME}:v1",
+		Override: map[string]string{
+			"activator":        "example.com/activator:v1",
+			"arm64/activator":  "example.com/activator:v1-arm64",
+			"arm64/controller": "example.com/controller:v1-arm64",
+			"s390x/webhook":    "example.com/webhook:v1-s390x",
+		},
+	}
+	if got, want := registry.Architectures(), []string{"arm64", "s390x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Architectures() = %v, want %v", got, want)
+	}
+	tests := []struct {
+		arch string
+		want map[string]string
+	}{{
+		arch: "arm64",
+		want: map[string]string{
+			"activator":  "example.com/activator:v1-arm64",
+			"controller": "example.com/controller:v1-arm64",
+		},
+	}, {
+		arch: "amd64",
+		want: map[string]string{"activator": "example.com/activator:v1"},
+	}, {
+		arch: "",
+		want: map[string]string{"activator": "example.com/activator:v1"},
+	}}
+	for _, tt := range tests {
+		got := registry.ForArchitecture(tt.arch)
+		if !reflect.DeepEqual(got.Override, tt.want) {
+			t.Errorf("ForArchitecture(%q).Override = %v, want %v", tt.arch, got.Override, tt.want)
+		}
+		if got.Default != registry.Default {
+			t.Errorf("ForArchitecture(%q).Default = %q, want %q", tt.arch, got.Default, registry.Default)
+		}
+	}
+}
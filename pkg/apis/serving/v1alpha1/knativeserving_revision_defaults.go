@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"context"
+	"strconv"
+
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// The entries of config-defaults the fields of Defaults replace
+	revisionTimeoutSecondsKey    = "revision-timeout-seconds"
+	maxRevisionTimeoutSecondsKey = "max-revision-timeout-seconds"
+	containerConcurrencyKey      = "container-concurrency"
+
+	// The most concurrent requests Knative lets a revision accept
+	maxContainerConcurrency = 1000
+)
+
+// Config returns the entries of config-defaults the set fields replace
+func (d *Defaults) Config() map[string]string {
+	result := map[string]string{}
+	if d == nil {
+		return result
+	}
+	if d.RevisionTimeoutSeconds != nil {
+		result[revisionTimeoutSecondsKey] = strconv.FormatInt(*d.RevisionTimeoutSeconds, 10)
+	}
+	if d.MaxRevisionTimeoutSeconds != nil {
+		result[maxRevisionTimeoutSecondsKey] = strconv.FormatInt(*d.MaxRevisionTimeoutSeconds, 10)
+	}
+	if d.ContainerConcurrency != nil {
+		result[containerConcurrencyKey] = strconv.FormatInt(*d.ContainerConcurrency, 10)
+	}
+	return result
+}
+
+// SetDefaults sets the unset fields to Knative's defaults, unless the
+// entries they replace are set in config. The timeouts default within
+// each other's bounds, whichever of them is set.
+func (d *Defaults) SetDefaults(config map[string]string) {
+	timeout, max := d.RevisionTimeoutSeconds, d.MaxRevisionTimeoutSeconds
+	if timeout == nil {
+		timeout = parseSeconds(config[revisionTimeoutSecondsKey])
+	}
+	if max == nil {
+		max = parseSeconds(config[maxRevisionTimeoutSecondsKey])
+	}
+	if _, ok := config[revisionTimeoutSecondsKey]; !ok && d.RevisionTimeoutSeconds == nil {
+		seconds := int64(300)
+		if max != nil && *max < seconds {
+			seconds = *max
+		}
+		d.RevisionTimeoutSeconds = &seconds
+	}
+	if _, ok := config[maxRevisionTimeoutSecondsKey]; !ok && d.MaxRevisionTimeoutSeconds == nil {
+		seconds := int64(600)
+		if timeout != nil && *timeout > seconds {
+			seconds = *timeout
+		}
+		d.MaxRevisionTimeoutSeconds = &seconds
+	}
+	if _, ok := config[containerConcurrencyKey]; !ok && d.ContainerConcurrency == nil {
+		concurrency := int64(0)
+		d.ContainerConcurrency = &concurrency
+	}
+}
+
+// The seconds of an entry of config-defaults, if valid
+func parseSeconds(value string) *int64 {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &seconds
+}
+
+// Validate checks the fields are within the ranges Knative accepts
+func (d *Defaults) Validate(ctx context.Context) *apis.FieldError {
+	if d == nil {
+		return nil
+	}
+	var errs *apis.FieldError
+	if t := d.RevisionTimeoutSeconds; t != nil && *t <= 0 {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*t, 1, "unbounded", "revisionTimeoutSeconds"))
+	}
+	if m := d.MaxRevisionTimeoutSeconds; m != nil && *m <= 0 {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*m, 1, "unbounded", "maxRevisionTimeoutSeconds"))
+	}
+	if t, m := d.RevisionTimeoutSeconds, d.MaxRevisionTimeoutSeconds; t != nil && m != nil && *t > 0 && *t > *m {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*t, 1, *m, "revisionTimeoutSeconds"))
+	}
+	if c := d.ContainerConcurrency; c != nil && (*c < 0 || *c > maxContainerConcurrency) {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*c, 0, maxContainerConcurrency, "containerConcurrency"))
+	}
+	return errs
+}
@@ -0,0 +1,87 @@
+package v1alpha1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestRevisionDefaults(t *testing.T) {
+	ks := &KnativeServing{
+		Spec: KnativeServingSpec{
+			Config:   map[string]map[string]string{"defaults": {"container-concurrency": "10"}},
+			Defaults: &Defaults{},
+		},
+	}
+	ks.SetDefaults(context.Background())
+	want := map[string]string{
+		"revision-timeout-seconds":     "300",
+		"max-revision-timeout-seconds": "600",
+	}
+	if got := ks.Spec.Defaults.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %v, want %v leaving container-concurrency to spec.config", got, want)
+	}
+
+	// Within the bounds of what's set
+	max := int64(120)
+	ks.Spec.Defaults = &Defaults{MaxRevisionTimeoutSeconds: &max}
+	ks.SetDefaults(context.Background())
+	if got := *ks.Spec.Defaults.RevisionTimeoutSeconds; got != max {
+		t.Errorf("RevisionTimeoutSeconds = %d, want the max %d", got, max)
+	}
+	ks.Spec.Config = map[string]map[string]string{"defaults": {"revision-timeout-seconds": "900"}}
+	ks.Spec.Defaults = &Defaults{}
+	ks.SetDefaults(context.Background())
+	if got := *ks.Spec.Defaults.MaxRevisionTimeoutSeconds; got != 900 {
+		t.Errorf("MaxRevisionTimeoutSeconds = %d, want the timeout of spec.config", got)
+	}
+	if err := ks.Spec.Defaults.Validate(context.Background()); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+
+	ks = &KnativeServing{}
+	ks.SetDefaults(context.Background())
+	if ks.Spec.Defaults != nil {
+		t.Errorf("Defaults = %v, want it left unset", ks.Spec.Defaults)
+	}
+}
+
+func TestRevisionDefaultsValidate(t *testing.T) {
+	seconds := func(s int64) *int64 {
+		return &s
+	}
+	tests := []struct {
+		name     string
+		defaults *Defaults
+		wantErr  bool
+	}{{
+		name: "unset",
+	}, {
+		name:     "in range",
+		defaults: &Defaults{RevisionTimeoutSeconds: seconds(60), MaxRevisionTimeoutSeconds: seconds(60), ContainerConcurrency: seconds(1000)},
+	}, {
+		name:     "zero timeout",
+		defaults: &Defaults{RevisionTimeoutSeconds: seconds(0)},
+		wantErr:  true,
+	}, {
+		name:     "timeout above the max",
+		defaults: &Defaults{RevisionTimeoutSeconds: seconds(700), MaxRevisionTimeoutSeconds: seconds(600)},
+		wantErr:  true,
+	}, {
+		name:     "negative max",
+		defaults: &Defaults{MaxRevisionTimeoutSeconds: seconds(-1)},
+		wantErr:  true,
+	}, {
+		name:     "concurrency above 1000",
+		defaults: &Defaults{ContainerConcurrency: seconds(1001)},
+		wantErr:  true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.defaults.Validate(context.Background())
+			if got := err != nil; got != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// The backends of spec.tracing
+	NoTracingBackend     = "none"
+	ZipkinTracingBackend = "zipkin"
+
+	// The entries of config-tracing the fields of Tracing replace. Knative
+	// releases before the backend key only know enable.
+	tracingEnableKey         = "enable"
+	tracingBackendKey        = "backend"
+	tracingZipkinEndpointKey = "zipkin-endpoint"
+	tracingSampleRateKey     = "sample-rate"
+	tracingDebugKey          = "debug"
+)
+
+// Config returns the entries of config-tracing the fields replace
+func (t *Tracing) Config() map[string]string {
+	result := map[string]string{}
+	if t == nil {
+		return result
+	}
+	backend := t.Backend
+	if backend == "" {
+		backend = NoTracingBackend
+	}
+	result[tracingBackendKey] = backend
+	result[tracingEnableKey] = strconv.FormatBool(backend == ZipkinTracingBackend)
+	if t.ZipkinEndpoint != "" {
+		result[tracingZipkinEndpointKey] = t.ZipkinEndpoint
+	}
+	if t.SampleRate != "" {
+		result[tracingSampleRateKey] = t.SampleRate
+	}
+	result[tracingDebugKey] = strconv.FormatBool(t.Debug)
+	return result
+}
+
+// Validate checks the backend has what it needs and the sample rate is
+// a fraction
+func (t *Tracing) Validate(ctx context.Context) *apis.FieldError {
+	if t == nil {
+		return nil
+	}
+	var errs *apis.FieldError
+	switch t.Backend {
+	case "", NoTracingBackend:
+	case ZipkinTracingBackend:
+		if t.ZipkinEndpoint == "" {
+			errs = errs.Also(apis.ErrMissingField("zipkinEndpoint"))
+		}
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(t.Backend, "backend"))
+	}
+	if t.ZipkinEndpoint != "" {
+		if u, err := url.Parse(t.ZipkinEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = errs.Also(apis.ErrInvalidValue(t.ZipkinEndpoint, "zipkinEndpoint"))
+		}
+	}
+	if t.SampleRate != "" {
+		if rate, err := strconv.ParseFloat(t.SampleRate, 64); err != nil || rate < 0 || rate > 1 {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(t.SampleRate, 0, 1, "sampleRate"))
+		}
+	}
+	return errs
+}
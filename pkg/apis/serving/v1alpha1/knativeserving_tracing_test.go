@@ -0,0 +1,65 @@
+package v1alpha1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestTracingConfig(t *testing.T) {
+	tracing := &Tracing{
+		Backend:        ZipkinTracingBackend,
+		ZipkinEndpoint: "http://zipkin.istio-system.svc.cluster.local:9411/api/v2/spans",
+		SampleRate:     "0.5",
+	}
+	want := map[string]string{
+		"backend":         "zipkin",
+		"enable":          "true",
+		"zipkin-endpoint": "http://zipkin.istio-system.svc.cluster.local:9411/api/v2/spans",
+		"sample-rate":     "0.5",
+		"debug":           "false",
+	}
+	if got := tracing.Config(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Config() = %v, want %v", got, want)
+	}
+	if got := (&Tracing{}).Config(); got["enable"] != "false" || got["backend"] != "none" {
+		t.Errorf("Config() = %v, want tracing disabled", got)
+	}
+}
+
+func TestTracingValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tracing *Tracing
+		wantErr bool
+	}{{
+		name: "unset",
+	}, {
+		name:    "zipkin",
+		tracing: &Tracing{Backend: ZipkinTracingBackend, ZipkinEndpoint: "http://zipkin:9411/api/v2/spans", SampleRate: "1"},
+	}, {
+		name:    "zipkin without an endpoint",
+		tracing: &Tracing{Backend: ZipkinTracingBackend},
+		wantErr: true,
+	}, {
+		name:    "relative endpoint",
+		tracing: &Tracing{Backend: ZipkinTracingBackend, ZipkinEndpoint: "zipkin:9411"},
+		wantErr: true,
+	}, {
+		name:    "unknown backend",
+		tracing: &Tracing{Backend: "jaeger"},
+		wantErr: true,
+	}, {
+		name:    "sample rate above 1",
+		tracing: &Tracing{SampleRate: "10"},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tracing.Validate(context.Background())
+			if got := err != nil; got != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -16,7 +16,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"knative.dev/pkg/apis"
 )
 
@@ -26,6 +28,72 @@ import (
 const (
 	InstallSucceeded     apis.ConditionType = "InstallSucceeded"
 	DeploymentsAvailable apis.ConditionType = "DeploymentsAvailable"
+
+	// ProbesSucceeded reports whether the webhook and controller of an
+	// available install respond, e.g. the webhook has its certificate
+	ProbesSucceeded apis.ConditionType = "ProbesSucceeded"
+
+	// SpecValidated reports whether the spec is consistent with itself
+	// and the release it installs, checked before transforming it
+	SpecValidated apis.ConditionType = "SpecValidated"
+
+	// PreflightSucceeded reports whether the cluster has what the
+	// install needs, e.g. a recent enough Kubernetes and the APIs it
+	// uses, checked before installing anything
+	PreflightSucceeded apis.ConditionType = "PreflightSucceeded"
+
+	// Transformed and Applied report the stages of the latest install
+	Transformed apis.ConditionType = "Transformed"
+	Applied     apis.ConditionType = "Applied"
+
+	// VersionMigrationEligible is False when the installed release
+	// can't be safely upgraded to the bundled one, and Unknown while
+	// an upgrade's storage version migration Jobs run.
+	VersionMigrationEligible apis.ConditionType = "VersionMigrationEligible"
+
+	// InstallDeadlineExceeded is True when the install hasn't become
+	// Ready within spec.installTimeout. It doesn't affect readiness.
+	InstallDeadlineExceeded apis.ConditionType = "InstallDeadlineExceeded"
+
+	// UpgradeInProgress is True from when the operator starts applying
+	// a newer release over an older one until its deployments are
+	// available. It doesn't affect readiness.
+	UpgradeInProgress apis.ConditionType = "UpgradeInProgress"
+
+	// CertManagerAvailable reports whether cert-manager can provision
+	// the certificates of spec.certManager. It's only set when enabled
+	// and doesn't affect readiness.
+	CertManagerAvailable apis.ConditionType = "CertManagerAvailable"
+
+	// GatewaysAvailable reports whether the istio Gateways knative
+	// routes through exist. It's only set when spec.ingress.istio
+	// doesn't install them and doesn't affect readiness.
+	GatewaysAvailable apis.ConditionType = "GatewaysAvailable"
+
+	// ManifestVersionMatches reports whether the bundled manifest
+	// declares the operator's version of Serving, flagging a custom
+	// build that packages another release. It's only set when the
+	// manifest declares a version and doesn't affect readiness.
+	ManifestVersionMatches apis.ConditionType = "ManifestVersionMatches"
+
+	// TracingCollectorReachable reports whether the zipkin collector of
+	// spec.tracing responds. It's only set with the zipkin backend and
+	// doesn't affect readiness.
+	TracingCollectorReachable apis.ConditionType = "TracingCollectorReachable"
+
+	// FailedPermanently is True when reconciling failed in a way that
+	// retrying can't fix, e.g. an invalid spec, until the spec changes.
+	// It doesn't affect readiness.
+	FailedPermanently apis.ConditionType = "FailedPermanently"
+)
+
+// The phases of status.phase, a one-word summary of the conditions
+const (
+	PhaseInstalling = "Installing"
+	PhaseReady      = "Ready"
+	PhaseUpgrading  = "Upgrading"
+	PhaseError      = "Error"
+	PhaseDeleting   = "Deleting"
 )
 
 // Registry defines image overrides of knative images.
@@ -41,16 +109,543 @@ type Registry struct {
 	Default string `json:"default,omitempty"`
 
 	// A map of a container name or image name to the full image location of the individual knative image.
+	// A name prefixed with an architecture, e.g. arm64/activator, only applies on nodes of that architecture.
 	// +optional
 	Override map[string]string `json:"override,omitempty"`
+
+	// A list of secrets to be used when pulling the knative images. A secret in the namespace of this
+	// resource is copied into the one of the knative-serving deployments, and kept in step as it changes;
+	// otherwise it must be created there.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+}
+
+// IstioGatewayOverride binds a knative gateway to a custom istio gateway deployment
+type IstioGatewayOverride struct {
+	// Name of the Service in front of the istio gateway deployment.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the istio gateway deployment, istio-system if empty.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// A map of values to replace the "selector" values in the gateway.
+	// +optional
+	Selector map[string]string `json:"selector,omitempty"`
+}
+
+// DeploymentOverride defines the customizations applied to a single knative deployment.
+// +k8s:openapi-gen=true
+type DeploymentOverride struct {
+	// Name of the deployment in the knative manifest, e.g. controller or activator.
+	Name string `json:"name"`
+
+	// Additional volumes appended to the pod template of the deployment.
+	// +optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// Additional volume mounts appended to every container of the deployment.
+	// +optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// Labels of the nodes the pods may be scheduled on, merged into the
+	// deployment's own node selector.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Additional tolerations of the pods, e.g. of dedicated infra nodes.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Replaces the scheduling constraints of the pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Environment variables set in every container of the deployment,
+	// replacing those of the same name the manifest sets to a value.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// The number of pods, replacing the manifest's and spec.highAvailability's.
+	// Ignored for a deployment an HPA scales, and spec.hibernate still
+	// scales it to zero.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// The PriorityClass of the pods, in place of spec.controlPlanePriorityClass.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
 }
 
-// KnativeIngressGateway override the knative-ingress-gateway
-type KnativeIngressGateway struct {
-	// A map of values to replace the "selector" values in the knative-ingress-gateway.
+// DomainSelector restricts a domain to the routes with matching labels.
+type DomainSelector struct {
+	// The labels of the routes the domain applies to.
+	// +optional
 	Selector map[string]string `json:"selector,omitempty"`
 }
 
+// CertManager configures automatic TLS of routes with cert-manager, which
+// must already be installed.
+type CertManager struct {
+	// Enable autoTLS, using cert-manager to provision the certificates.
+	Enabled bool `json:"enabled"`
+
+	// The name of the ClusterIssuer of the certificates.
+	// +optional
+	ClusterIssuer string `json:"clusterIssuer,omitempty"`
+}
+
+// Proxy configures the HTTP proxy of the control plane, e.g. for
+// resolving image digests from registries outside the cluster.
+type Proxy struct {
+	// The proxy of HTTP requests.
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// The proxy of HTTPS requests.
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// Comma-separated hosts and domains that aren't proxied.
+	// +optional
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// ManifestPolicy controls how the resources of the manifest that
+// already exist are reconciled, e.g. to keep manual changes to some.
+type ManifestPolicy struct {
+	// The policy of the resources without one of their own: Apply,
+	// CreateOnly or None. Defaults to Apply.
+	// +optional
+	Default string `json:"default,omitempty"`
+
+	// The policies of particular resources.
+	// +optional
+	Resources []ResourcePolicy `json:"resources,omitempty"`
+}
+
+// ResourcePolicy sets the policy of the resources of a kind, or of one
+// of them.
+type ResourcePolicy struct {
+	// The kind of the resources, e.g. HorizontalPodAutoscaler.
+	Kind string `json:"kind"`
+
+	// The name of the resource, every resource of the kind if empty.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Apply updates the resources whenever they differ from the
+	// manifest, CreateOnly only creates them when missing, and None
+	// leaves them be altogether.
+	Policy string `json:"policy"`
+}
+
+// AdditionalManifest refers to a manifest applied along with Knative
+// Serving, either the data of a ConfigMap or the content of a URL.
+type AdditionalManifest struct {
+	// The name of a ConfigMap in the namespace of the KnativeServing,
+	// each key of which holds a manifest.
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+
+	// The HTTPS URL of a manifest.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// The SHA-256 checksum of the manifest at the URL.
+	// +optional
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// Autoscaler configures the autoscaling of revisions, replacing the
+// corresponding entries of config-autoscaler.
+type Autoscaler struct {
+	// The number of concurrent requests per pod the autoscaler aims for,
+	// unless a revision sets its own. Defaults to 100.
+	// +optional
+	ContainerConcurrencyTargetDefault *int32 `json:"containerConcurrencyTargetDefault,omitempty"`
+
+	// The window the metrics are averaged over when not panicking,
+	// between 6s and 1h. Defaults to 60s.
+	// +optional
+	StableWindow *metav1.Duration `json:"stableWindow,omitempty"`
+
+	// How long the last pod of a revision is kept after it's scaled to
+	// zero, at least 6s. Defaults to 30s.
+	// +optional
+	ScaleToZeroGracePeriod *metav1.Duration `json:"scaleToZeroGracePeriod,omitempty"`
+
+	// Whether revisions without traffic scale to zero. Defaults to true.
+	// +optional
+	EnableScaleToZero *bool `json:"enableScaleToZero,omitempty"`
+}
+
+// GC configures the garbage collection of revisions, replacing the
+// corresponding entries of config-gc.
+type GC struct {
+	// How long after its creation a revision may be collected. Defaults to 24h.
+	// +optional
+	StaleRevisionCreateDelay *metav1.Duration `json:"staleRevisionCreateDelay,omitempty"`
+
+	// How long after a route last pointed at it a revision is collected,
+	// at least 10h longer than staleRevisionLastpinnedDebounce. Defaults to 15h.
+	// +optional
+	StaleRevisionTimeout *metav1.Duration `json:"staleRevisionTimeout,omitempty"`
+
+	// The fewest revisions of a configuration kept. Defaults to 1.
+	// +optional
+	StaleRevisionMinimumGenerations *int64 `json:"staleRevisionMinimumGenerations,omitempty"`
+
+	// How stale the time a revision was last pointed at may get before
+	// it's updated. Defaults to 5h.
+	// +optional
+	StaleRevisionLastpinnedDebounce *metav1.Duration `json:"staleRevisionLastpinnedDebounce,omitempty"`
+}
+
+// Defaults configures the defaults of revisions, replacing the
+// corresponding entries of config-defaults.
+type Defaults struct {
+	// The request timeout of a revision that doesn't set its own, at
+	// most maxRevisionTimeoutSeconds. Defaults to 300.
+	// +optional
+	RevisionTimeoutSeconds *int64 `json:"revisionTimeoutSeconds,omitempty"`
+
+	// The longest request timeout a revision may set. Defaults to 600.
+	// +optional
+	MaxRevisionTimeoutSeconds *int64 `json:"maxRevisionTimeoutSeconds,omitempty"`
+
+	// The concurrent requests a revision that doesn't set its own
+	// accepts, from 0 for unlimited to 1000. Defaults to 0.
+	// +optional
+	ContainerConcurrency *int64 `json:"containerConcurrency,omitempty"`
+}
+
+// Tracing configures the tracing of requests, replacing the
+// corresponding entries of config-tracing.
+type Tracing struct {
+	// Where spans are sent: none or zipkin. Defaults to none.
+	// +optional
+	Backend string `json:"backend,omitempty"`
+
+	// The URL of the zipkin collector, required by the zipkin backend,
+	// e.g. http://zipkin.istio-system.svc.cluster.local:9411/api/v2/spans
+	// +optional
+	ZipkinEndpoint string `json:"zipkinEndpoint,omitempty"`
+
+	// The fraction of requests traced, from 0 to 1. Defaults to 0.1.
+	// +optional
+	SampleRate string `json:"sampleRate,omitempty"`
+
+	// Send every span, bypassing the sampling.
+	// +optional
+	Debug bool `json:"debug,omitempty"`
+}
+
+// Logging configures the logging of the components, replacing the
+// corresponding entries of config-logging.
+type Logging struct {
+	// The log level of each component, e.g. controller: debug. The
+	// components are those of config-logging, e.g. controller, autoscaler,
+	// activator, webhook or queueproxy.
+	// +optional
+	Levels map[string]string `json:"levels,omitempty"`
+
+	// The configuration of the zap loggers, as JSON.
+	// +optional
+	ZapLoggerConfig string `json:"zapLoggerConfig,omitempty"`
+
+	// Restart the deployments whose logging changed, so the change takes
+	// effect at once rather than whenever they pick up config-logging.
+	// +optional
+	RestartOnChange bool `json:"restartOnChange,omitempty"`
+}
+
+// QueueSidecar configures the queue-proxy sidecar of every revision.
+type QueueSidecar struct {
+	// The image of the sidecar, in place of the one of spec.registry
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// The cpu, memory and ephemeral-storage requested and limited for
+	// the sidecar
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// Security hardens the install.
+type Security struct {
+	// Restrict the traffic to the control plane with NetworkPolicies,
+	// e.g. only the activator may reach the autoscaler's metrics.
+	// +optional
+	NetworkPolicies bool `json:"networkPolicies,omitempty"`
+}
+
+// Monitoring sets up the scraping of the install by the Prometheus operator.
+type Monitoring struct {
+	// Install a ServiceMonitor for each control plane Service exposing
+	// metrics and a PodMonitor for the queue-proxy of revisions. The
+	// Prometheus operator's CRDs must be installed.
+	Enabled bool `json:"enabled"`
+
+	// Also install the bundled Grafana dashboards as ConfigMaps labeled
+	// grafana_dashboard, for Grafana's sidecar to load.
+	// +optional
+	Dashboards bool `json:"dashboards,omitempty"`
+
+	// Labels of the monitors, e.g. those the Prometheus selects them by.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Istio fits the control plane into an Istio mesh.
+type Istio struct {
+	// Make the control plane work in a mesh enforcing mutual TLS: the
+	// operator annotates its Deployments for sidecar injection, creates
+	// the PeerAuthentication and DestinationRule its traffic needs, and
+	// checks that the API server still reaches the webhook.
+	// +optional
+	MeshCompatibility bool `json:"meshCompatibility,omitempty"`
+
+	// Inject sidecars into the control plane, rather than excluding it
+	// from the mesh. Only used with meshCompatibility.
+	// +optional
+	SidecarInjection bool `json:"sidecarInjection,omitempty"`
+}
+
+// OverridesSource refers to the overrides of the spec.
+type OverridesSource struct {
+	// The key of a Secret in the namespace of the KnativeServing holding
+	// the YAML or JSON overrides, in the form of the spec.
+	SecretRef *corev1.SecretKeySelector `json:"secretRef"`
+}
+
+// DigestPinning specifies how the images of the install are pinned.
+type DigestPinning struct {
+	// The key of a Secret in the namespace of the KnativeServing holding
+	// the PEM encoded ECDSA public key every image must carry a cosign
+	// signature of.
+	// +optional
+	CosignPublicKey *corev1.SecretKeySelector `json:"cosignPublicKey,omitempty"`
+}
+
+// CustomCerts refers to a ConfigMap or Secret of CA bundles in the knative-serving namespace.
+// +k8s:openapi-gen=true
+type CustomCerts struct {
+	// One of ConfigMap or Secret
+	Type string `json:"type"`
+
+	// The name of the ConfigMap or Secret
+	Name string `json:"name"`
+}
+
+// ResourceRequirementsOverride overrides the resource requirements of a knative container.
+// +k8s:openapi-gen=true
+type ResourceRequirementsOverride struct {
+	// The name of the container, e.g. activator or autoscaler.
+	Container string `json:"container"`
+
+	// The name of the deployment, if the container name alone is ambiguous.
+	// +optional
+	Deployment string `json:"deployment,omitempty"`
+
+	corev1.ResourceRequirements `json:",inline"`
+}
+
+// ManifestExport publishes the manifest the operator renders, as
+// customized, in ConfigMaps of the namespace of the KnativeServing.
+// The resources are split among as many ConfigMaps as they need, named
+// <configMapName>-1, -2 and so on, each with a manifest.yaml key.
+type ManifestExport struct {
+	// The name the ConfigMaps are numbered after, knative-serving-manifest
+	// by default
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// Only render and export the manifest, installing nothing, e.g. on
+	// a cluster rendering the manifests of a fleet
+	// +optional
+	Only bool `json:"only,omitempty"`
+}
+
+// ExportStatus describes the last export of the manifest.
+type ExportStatus struct {
+	// The ConfigMaps holding the manifest, in order
+	// +optional
+	ConfigMaps []string `json:"configMaps,omitempty"`
+
+	// The version of Knative Serving exported
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// The SHA-256 checksum of the exported resources
+	// +optional
+	Hash string `json:"hash,omitempty"`
+
+	// When the exported resources last changed
+	// +optional
+	LastExportedTime *metav1.Time `json:"lastExportedTime,omitempty"`
+}
+
+// ProbeOverride tunes the probes of a knative container, e.g. on clusters
+// with slow disks or constrained nodes, where the manifest's timing
+// restarts the container before it becomes ready.
+// +k8s:openapi-gen=true
+type ProbeOverride struct {
+	// The name of the container, e.g. activator or autoscaler.
+	Container string `json:"container"`
+
+	// The name of the deployment, if the container name alone is ambiguous.
+	// +optional
+	Deployment string `json:"deployment,omitempty"`
+
+	// The timing of the container's readiness probe.
+	// +optional
+	Readiness *ProbeTuning `json:"readiness,omitempty"`
+
+	// The timing of the container's liveness probe.
+	// +optional
+	Liveness *ProbeTuning `json:"liveness,omitempty"`
+}
+
+// ProbeTuning replaces the given timing of a probe, keeping the check the
+// manifest defines. A container without the probe is left alone.
+// +k8s:openapi-gen=true
+type ProbeTuning struct {
+	// Seconds after the container starts before the first probe.
+	// +optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// Seconds after which a probe times out.
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// Seconds between probes.
+	// +optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// Consecutive failures after which the probe fails.
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// HighAvailability specifies options for running the control plane with multiple replicas.
+// +k8s:openapi-gen=true
+type HighAvailability struct {
+	// The number of replicas of each highly available deployment.
+	Replicas int32 `json:"replicas"`
+
+	// A HorizontalPodAutoscaler scaling the activator in place of the fixed replicas.
+	// +optional
+	Autoscaling *HighAvailabilityAutoscaling `json:"autoscaling,omitempty"`
+}
+
+// PodDisruptionBudget limits the voluntary disruption of the pods of a deployment.
+// +k8s:openapi-gen=true
+type PodDisruptionBudget struct {
+	// The name of the deployment, e.g. activator.
+	Deployment string `json:"deployment"`
+
+	// The pods, or percentage of them, that must stay available.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// The pods, or percentage of them, that may be unavailable. Only
+	// one of minAvailable and maxUnavailable may be set.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// HighAvailabilityAutoscaling specifies the HorizontalPodAutoscaler of the activator.
+// +k8s:openapi-gen=true
+type HighAvailabilityAutoscaling struct {
+	// The fewest replicas, defaulting to those of the highly available deployments.
+	// +optional
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+
+	// The most replicas.
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// The average CPU utilization, as a percentage of the requests, to scale to.
+	// +optional
+	TargetCPUUtilizationPercentage int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+}
+
+// IstioIngressConfiguration specifies options for the istio ingress.
+type IstioIngressConfiguration struct {
+	Enabled bool `json:"enabled"`
+
+	// Install the knative-ingress-gateway and cluster-local-gateway
+	// Gateways, true by default. When false the operator expects them,
+	// or those config-istio names, to exist in the install namespace
+	// and reports the GatewaysAvailable condition.
+	// +optional
+	InstallGateways *bool `json:"installGateways,omitempty"`
+}
+
+// KourierIngressConfiguration specifies options for the kourier ingress.
+type KourierIngressConfiguration struct {
+	Enabled bool `json:"enabled"`
+}
+
+// ContourIngressConfiguration specifies options for the contour ingress.
+type ContourIngressConfiguration struct {
+	Enabled bool `json:"enabled"`
+
+	// The Contour serving publicly visible routes, defaulting to the
+	// contour-external class and the contour-external/envoy Service.
+	// +optional
+	External *ContourVisibility `json:"external,omitempty"`
+
+	// The Contour serving cluster-local routes, defaulting to the
+	// contour-internal class and the contour-internal/envoy Service.
+	// +optional
+	Internal *ContourVisibility `json:"internal,omitempty"`
+}
+
+// ContourVisibility configures the Contour that routes of one visibility
+// are served by, written to the visibility of config-contour.
+type ContourVisibility struct {
+	// The ingress class of the Contour.
+	// +optional
+	Class string `json:"class,omitempty"`
+
+	// The envoy Service of the Contour, as namespace/name.
+	// +optional
+	Service string `json:"service,omitempty"`
+}
+
+// GlooIngressConfiguration specifies options for the gloo ingress, which
+// is rejected until a net-gloo release is bundled.
+type GlooIngressConfiguration struct {
+	Enabled bool `json:"enabled"`
+}
+
+// AmbassadorIngressConfiguration specifies options for the ambassador ingress.
+type AmbassadorIngressConfiguration struct {
+	Enabled bool `json:"enabled"`
+}
+
+// IngressConfigs selects the ingress implementation. At most one may be enabled,
+// and istio is used when none is.
+// +k8s:openapi-gen=true
+type IngressConfigs struct {
+	// +optional
+	Istio IstioIngressConfiguration `json:"istio,omitempty"`
+
+	// +optional
+	Kourier KourierIngressConfiguration `json:"kourier,omitempty"`
+
+	// +optional
+	Contour ContourIngressConfiguration `json:"contour,omitempty"`
+
+	// +optional
+	Gloo GlooIngressConfiguration `json:"gloo,omitempty"`
+
+	// +optional
+	Ambassador AmbassadorIngressConfiguration `json:"ambassador,omitempty"`
+}
+
 // KnativeServingSpec defines the desired state of KnativeServing
 // +k8s:openapi-gen=true
 type KnativeServingSpec struct {
@@ -68,7 +663,254 @@ type KnativeServingSpec struct {
 	Registry Registry `json:"registry,omitempty"`
 
 	// A means to override the knative-ingress-gateway
-	KnativeIngressGateway KnativeIngressGateway `json:"knative-ingress-gateway,omitempty"`
+	KnativeIngressGateway IstioGatewayOverride `json:"knative-ingress-gateway,omitempty"`
+
+	// A means to override the cluster-local-gateway
+	ClusterLocalGateway IstioGatewayOverride `json:"cluster-local-gateway,omitempty"`
+
+	// A means to customize individual deployments of the upstream manifest
+	// +optional
+	DeploymentOverrides []DeploymentOverride `json:"deploymentOverrides,omitempty"`
+
+	// How long the install of a spec may take to become ready before
+	// the InstallDeadlineExceeded condition is raised
+	// +optional
+	InstallTimeout *metav1.Duration `json:"installTimeout,omitempty"`
+
+	// The autoscaling of revisions, taking precedence over the same
+	// entries of spec.config
+	// +optional
+	Autoscaler *Autoscaler `json:"autoscaler,omitempty"`
+
+	// The garbage collection of revisions, taking precedence over the
+	// same entries of spec.config
+	// +optional
+	GC *GC `json:"gc,omitempty"`
+
+	// The tracing of requests, taking precedence over the same entries
+	// of spec.config
+	// +optional
+	Tracing *Tracing `json:"tracing,omitempty"`
+
+	// The defaults of revisions, taking precedence over the same entries
+	// of spec.config
+	// +optional
+	Defaults *Defaults `json:"defaults,omitempty"`
+
+	// The queue-proxy sidecar of revisions, taking precedence over the
+	// same entries of spec.config
+	// +optional
+	QueueSidecar *QueueSidecar `json:"queueSidecar,omitempty"`
+
+	// The logging of the components, taking precedence over the same
+	// entries of spec.config
+	// +optional
+	Logging *Logging `json:"logging,omitempty"`
+
+	// Restart each Deployment when the ConfigMaps it consumes change,
+	// whether mounted, referenced by its environment or named by one of
+	// its CONFIG_*_NAME variables, e.g. config-observability
+	// +optional
+	RestartOnConfigChange bool `json:"restartOnConfigChange,omitempty"`
+
+	// Have the Prometheus operator scrape the install
+	// +optional
+	Monitoring *Monitoring `json:"monitoring,omitempty"`
+
+	// A preset for the fields left unset: dev for small clusters,
+	// production for highly available ones, or default, the release as
+	// it ships
+	// +optional
+	Profile string `json:"profile,omitempty"`
+
+	// Run the control plane deployments with multiple replicas
+	// +optional
+	HighAvailability *HighAvailability `json:"highAvailability,omitempty"`
+
+	// PodDisruptionBudgets the operator installs for individual
+	// deployments, e.g. so that draining a node can't evict every
+	// activator at once
+	// +optional
+	PodDisruptionBudgets []PodDisruptionBudget `json:"podDisruptionBudgets,omitempty"`
+
+	// The PriorityClass of the pods of every control plane deployment,
+	// e.g. system-cluster-critical, so that node pressure evicts them last
+	// +optional
+	ControlPlanePriorityClass string `json:"controlPlanePriorityClass,omitempty"`
+
+	// What deleting the KnativeServing uninstalls: Full, KeepCRDs or
+	// KeepWorkloads. Defaults to Full, whose deletion of the CRDs
+	// deletes every Knative Service of the cluster along with them.
+	// +optional
+	UninstallPolicy string `json:"uninstallPolicy,omitempty"`
+
+	// Scale the control plane deployments to zero, keeping the CRDs
+	// and webhook configurations, until set back to false
+	// +optional
+	Hibernate bool `json:"hibernate,omitempty"`
+
+	// Only install the CustomResourceDefinitions and report Ready once
+	// they're established, e.g. for a pipeline that must create custom
+	// resources before the rest. Setting it back to false installs the
+	// rest; setting it on a complete install prunes the rest.
+	// +optional
+	CRDsOnly bool `json:"crdsOnly,omitempty"`
+
+	// CA bundles the controller trusts, e.g. for registries signed by a private CA
+	// +optional
+	ControllerCustomCerts *CustomCerts `json:"controllerCustomCerts,omitempty"`
+
+	// A means to override the resource requirements of individual containers.
+	// Only the listed requests and limits are replaced.
+	// +optional
+	Resources []ResourceRequirementsOverride `json:"resources,omitempty"`
+
+	// A means to tune the timing of the probes of individual containers.
+	// +optional
+	Probes []ProbeOverride `json:"probes,omitempty"`
+
+	// The ingress implementation to install and configure
+	// +optional
+	Ingress *IngressConfigs `json:"ingress,omitempty"`
+
+	// Optional components left out of the install, e.g. cert-manager,
+	// custom-metrics, autoscaler-hpa, monitoring or cluster-local-gateway
+	// +optional
+	DisabledComponents []string `json:"disabledComponents,omitempty"`
+
+	// The domains routes are served on, each applying to the routes its
+	// selector matches. At least one, the default, must have no selector.
+	// Replaces the domains of config-domain.
+	// +optional
+	Domain map[string]DomainSelector `json:"domain,omitempty"`
+
+	// Provision certificates for routes with cert-manager
+	// +optional
+	CertManager *CertManager `json:"certManager,omitempty"`
+
+	// The proxy the control plane reaches outside the cluster through
+	// +optional
+	Proxy *Proxy `json:"proxy,omitempty"`
+
+	// How existing resources are reconciled, by default updating them
+	// whenever they differ from the manifest
+	// +optional
+	ManifestPolicy *ManifestPolicy `json:"manifestPolicy,omitempty"`
+
+	// Take over the resources of the manifest found on the first install
+	// without the operator's labels, e.g. those of an install applied
+	// with kubectl, rather than failing the install
+	// +optional
+	AdoptExisting bool `json:"adoptExisting,omitempty"`
+
+	// Delete the resources earlier releases installed that later ones
+	// dropped, e.g. the knative-ingressgateway of istio-system, once the
+	// release being installed no longer has them. True by default; false
+	// keeps them, e.g. through a migration window.
+	// +optional
+	CleanupLegacyResources *bool `json:"cleanupLegacyResources,omitempty"`
+
+	// A Secret holding a fragment of the spec merged over it when
+	// reconciling, e.g. the registry, domain or controllerCustomCerts,
+	// to keep sensitive values out of the KnativeServing
+	// +optional
+	OverridesFrom *OverridesSource `json:"overridesFrom,omitempty"`
+
+	// Manifests transformed and applied along with Knative Serving,
+	// e.g. extra NetworkPolicies or dashboards
+	// +optional
+	AdditionalManifests []AdditionalManifest `json:"additionalManifests,omitempty"`
+
+	// Hardening of the install
+	// +optional
+	Security *Security `json:"security,omitempty"`
+
+	// How the control plane fits into an Istio service mesh
+	// +optional
+	Istio *Istio `json:"istio,omitempty"`
+
+	// Labels added to every resource the operator installs and to
+	// the pods of its workloads, unless the manifest sets them
+	// +optional
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+
+	// Annotations added to every resource the operator installs and to
+	// the pods of its workloads, unless the manifest sets them
+	// +optional
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
+
+	// Resolve the tag of every image to a digest at install time,
+	// recording them in status.images
+	// +optional
+	DigestPinning *DigestPinning `json:"digestPinning,omitempty"`
+
+	// Publish the rendered manifest in ConfigMaps, e.g. for other clusters
+	// or GitOps agents to apply
+	// +optional
+	Export *ManifestExport `json:"export,omitempty"`
+
+	// The namespace Knative Serving is installed into, created if it
+	// doesn't exist. Defaults to the namespace of the KnativeServing.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// The release of Knative Serving to install, one of those bundled
+	// with the operator. Defaults to the operator's own release. An
+	// installed release may only be upgraded one minor version at a time.
+	// +optional
+	Version string `json:"version,omitempty"`
+}
+
+// FailedResource explains why a resource of the manifest failed to apply
+type FailedResource struct {
+	// The kind of the resource, e.g. Deployment.
+	Kind string `json:"kind"`
+
+	// The namespace of the resource, unless it's cluster-scoped.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// The name of the resource.
+	Name string `json:"name"`
+
+	// The error applying the resource.
+	Message string `json:"message"`
+}
+
+// AppliedManifest identifies a manifest the operator applied.
+type AppliedManifest struct {
+	// The manifest, e.g. knative-serving/0.7.0, ingress/kourier,
+	// configmap/network-policies or the URL it was fetched from.
+	Name string `json:"name"`
+
+	// The SHA-256 checksum of the manifest's resources, before they
+	// were customized.
+	Hash string `json:"hash"`
+}
+
+// AppliedResource identifies a resource the operator applied.
+type AppliedResource struct {
+	// The group and version of the resource, e.g. apps/v1.
+	APIVersion string `json:"apiVersion"`
+
+	// The kind of the resource, e.g. Deployment.
+	Kind string `json:"kind"`
+
+	// The namespace of the resource, unless it's cluster-scoped.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// The name of the resource.
+	Name string `json:"name"`
+}
+
+// PinnedImage records the digest an image was pinned to.
+type PinnedImage struct {
+	// The image, as the manifest names it.
+	Image string `json:"image"`
+
+	// The digest of its manifest, e.g. sha256:e007c0a7....
+	Digest string `json:"digest"`
 }
 
 // KnativeServingStatus defines the observed state of KnativeServing
@@ -82,6 +924,84 @@ type KnativeServingStatus struct {
 	// The version of the installed release
 	// +optional
 	Version string `json:"version,omitempty"`
+
+	// A one-word summary of the conditions: Installing, Ready,
+	// Upgrading, Error or Deleting
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// The version of the release being installed
+	// +optional
+	TargetVersion string `json:"targetVersion,omitempty"`
+
+	// The version of the operator reconciling the KnativeServing
+	// +optional
+	OperatorVersion string `json:"operatorVersion,omitempty"`
+
+	// The Serving version the operator's bundled manifest declares
+	// +optional
+	ServingVersion string `json:"servingVersion,omitempty"`
+
+	// The generation of the spec that was last installed
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// When the operator started installing the current generation of the spec
+	// +optional
+	InstallStartTime *metav1.Time `json:"installStartTime,omitempty"`
+
+	// The generation of the spec that InstallStartTime refers to
+	// +optional
+	InstallGeneration int64 `json:"installGeneration,omitempty"`
+
+	// The deployments that are not yet available
+	// +optional
+	Deployments []DeploymentStatus `json:"deployments,omitempty"`
+
+	// The DaemonSets of the ingress that are not yet available, e.g.
+	// Contour's envoys
+	// +optional
+	DaemonSets []DaemonSetStatus `json:"daemonSets,omitempty"`
+
+	// The resources that failed to apply during the last install
+	// +optional
+	FailedResources []FailedResource `json:"failedResources,omitempty"`
+
+	// The manifests of the last successful install
+	// +optional
+	Manifests []AppliedManifest `json:"manifests,omitempty"`
+
+	// The SHA-256 checksum of the resources the last successful install
+	// applied, as customized
+	// +optional
+	ManifestHash string `json:"manifestHash,omitempty"`
+
+	// When the resources of ManifestHash were first applied
+	// +optional
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+
+	// The resources the last successful install applied, i.e. those
+	// the operator owns
+	// +optional
+	Resources []AppliedResource `json:"resources,omitempty"`
+
+	// The digests the images of the install were pinned to, with
+	// spec.digestPinning
+	// +optional
+	Images []PinnedImage `json:"images,omitempty"`
+
+	// The ConfigMaps spec.export last published the manifest in
+	// +optional
+	Export *ExportStatus `json:"export,omitempty"`
+
+	// The URL of the default domain routes are served on, e.g. http://example.com
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// The external IP address or hostname of the ingress
+	// +optional
+	Address string `json:"address,omitempty"`
+
 	// The latest available observations of a resource's current state.
 	// +optional
 	// +patchMergeKey=type
@@ -89,11 +1009,49 @@ type KnativeServingStatus struct {
 	Conditions apis.Conditions `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
+// DeploymentStatus explains why a deployment of the install is not available
+type DeploymentStatus struct {
+	// Name of the deployment, e.g. controller or activator.
+	Name string `json:"name"`
+
+	// The reason of the failing Deployment condition, or NotFound.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// The message of the failing Deployment condition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// DaemonSetStatus explains why a DaemonSet of the ingress is not available
+type DaemonSetStatus struct {
+	// Namespace of the DaemonSet, e.g. contour-external.
+	Namespace string `json:"namespace"`
+
+	// Name of the DaemonSet, e.g. envoy.
+	Name string `json:"name"`
+
+	// Why the DaemonSet is not available, e.g. NotFound or Unavailable.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable explanation of the reason.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // KnativeServing is the Schema for the knativeservings API
 // +k8s:openapi-gen=true
+// +kubebuilder:resource:path=knativeservings,shortName=ks
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.version"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].reason"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type KnativeServing struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,46 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// Delete everything the operator installed
+	UninstallFull = "Full"
+	// Delete everything but the CustomResourceDefinitions, sparing the
+	// Knative resources of the users
+	UninstallKeepCRDs = "KeepCRDs"
+	// Only delete the controllers and webhooks, leaving the rest for the
+	// existing revisions to keep serving
+	UninstallKeepWorkloads = "KeepWorkloads"
+)
+
+// The valid values of spec.uninstallPolicy
+var UninstallPolicies = []string{UninstallFull, UninstallKeepCRDs, UninstallKeepWorkloads}
+
+func validateUninstallPolicy(policy string) *apis.FieldError {
+	if policy == "" {
+		return nil
+	}
+	for _, p := range UninstallPolicies {
+		if p == policy {
+			return nil
+		}
+	}
+	return apis.ErrInvalidValue(policy, "uninstallPolicy")
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+type installationKey struct{}
+
+// installation describes the cluster the KnativeServing is validated against
+type installation struct {
+	namespaces []string
+	existing   []KnativeServing
+}
+
+// WithInstallation records the namespaces a KnativeServing may be
+// created in, any of them when empty, and the KnativeServings that
+// already exist in them, for Validate
+func WithInstallation(ctx context.Context, namespaces []string, existing []KnativeServing) context.Context {
+	return context.WithValue(ctx, installationKey{}, &installation{namespaces: namespaces, existing: existing})
+}
+
+// Validate implements apis.Validatable
+func (ks *KnativeServing) Validate(ctx context.Context) *apis.FieldError {
+	errs := ks.Spec.Ingress.Validate(ctx).ViaField("spec", "ingress")
+	errs = errs.Also(validateDisabledComponents(ks.Spec.DisabledComponents).ViaField("spec"))
+	errs = errs.Also(validateProfile(ks.Spec.Profile).ViaField("spec"))
+	errs = errs.Also(validateUninstallPolicy(ks.Spec.UninstallPolicy).ViaField("spec"))
+	errs = errs.Also(validateRegistry(&ks.Spec.Registry).ViaField("spec"))
+	errs = errs.Also(validateDomain(ks.Spec.Domain).ViaField("spec"))
+	errs = errs.Also(validateCertManager(&ks.Spec).ViaField("spec"))
+	errs = errs.Also(validateManifestPolicy(ks.Spec.ManifestPolicy).ViaField("spec"))
+	errs = errs.Also(validateAdditionalManifests(ks.Spec.AdditionalManifests).ViaField("spec"))
+	errs = errs.Also(validateOverridesFrom(ks.Spec.OverridesFrom).ViaField("spec"))
+	errs = errs.Also(ks.Spec.Autoscaler.Validate(ctx).ViaField("spec", "autoscaler"))
+	errs = errs.Also(ks.Spec.GC.Validate(ctx).ViaField("spec", "gc"))
+	errs = errs.Also(ks.Spec.Tracing.Validate(ctx).ViaField("spec", "tracing"))
+	errs = errs.Also(ks.Spec.Defaults.Validate(ctx).ViaField("spec", "defaults"))
+	errs = errs.Also(ks.Spec.QueueSidecar.Validate(ctx).ViaField("spec", "queueSidecar"))
+	errs = errs.Also(ks.Spec.Logging.Validate(ctx).ViaField("spec", "logging"))
+	errs = errs.Also(ks.Spec.Monitoring.Validate(ctx).ViaField("spec", "monitoring"))
+	errs = errs.Also(ks.Spec.HighAvailability.Validate(ctx).ViaField("spec", "highAvailability"))
+	errs = errs.Also(validateNamespace(ks.Spec.Namespace).ViaField("spec"))
+	errs = errs.Also(validateDeploymentOverrides(ks.Spec.DeploymentOverrides).ViaField("spec"))
+	errs = errs.Also(validateProbes(ks.Spec.Probes).ViaField("spec"))
+	errs = errs.Also(ks.Spec.Export.validate().ViaField("spec", "export"))
+	errs = errs.Also(validatePodDisruptionBudgets(ks.Spec.PodDisruptionBudgets).ViaField("spec"))
+	errs = errs.Also(validatePriorityClassName(ks.Spec.ControlPlanePriorityClass, "controlPlanePriorityClass").ViaField("spec"))
+	errs = errs.Also(validateCommonMetadata(&ks.Spec).ViaField("spec"))
+
+	// Only creation can violate the single instance rule
+	if apis.IsInUpdate(ctx) {
+		return errs
+	}
+	inst, ok := ctx.Value(installationKey{}).(*installation)
+	if !ok {
+		return errs
+	}
+	if !inst.allows(ks.GetNamespace()) {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("KnativeServing must be created in one of the namespaces %s", strings.Join(inst.namespaces, ", ")),
+			Paths:   []string{"metadata.namespace"},
+		})
+	}
+	for _, existing := range inst.existing {
+		if existing.GetNamespace() != ks.GetNamespace() || existing.GetName() != ks.GetName() {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("KnativeServing %s/%s already exists, only one is allowed", existing.GetNamespace(), existing.GetName()),
+				Paths:   []string{"metadata.name"},
+			})
+			break
+		}
+	}
+	return errs
+}
+
+func (inst *installation) allows(namespace string) bool {
+	if len(inst.namespaces) == 0 {
+		return true
+	}
+	for _, ns := range inst.namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
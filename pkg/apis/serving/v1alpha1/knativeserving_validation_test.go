@@ -0,0 +1,460 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/pkg/apis"
+)
+
+func TestValidate(t *testing.T) {
+	newInstance := func(namespace, name string) KnativeServing {
+		return KnativeServing{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	negative, zero, ten := int32(-1), int32(0), int32(10)
+	one, half, tooMany := intstr.FromInt(1), intstr.FromString("50%"), intstr.FromString("150%")
+	tests := []struct {
+		name     string
+		instance KnativeServing
+		existing []KnativeServing
+		anyNS    bool
+		ingress  *IngressConfigs
+		disabled []string
+		domain   map[string]DomainSelector
+		certs    *CertManager
+		policy   *ManifestPolicy
+		extra    []AdditionalManifest
+		target   string
+		env      []corev1.EnvVar
+		replicas *int32
+		ha       *HighAvailability
+		budgets  []PodDisruptionBudget
+		probes   []ProbeOverride
+		export   *ManifestExport
+		priority string
+		teardown string
+		registry Registry
+		labels   map[string]string
+		update   bool
+		wantErr  bool
+	}{{
+		name:     "first instance",
+		instance: newInstance("knative-serving", "knative-serving"),
+	}, {
+		name:     "wrong namespace",
+		instance: newInstance("default", "knative-serving"),
+		wantErr:  true,
+	}, {
+		name:     "any namespace",
+		instance: newInstance("default", "knative-serving"),
+		anyNS:    true,
+	}, {
+		name:     "conflicting instance in another namespace",
+		instance: newInstance("team-a", "knative-serving"),
+		existing: []KnativeServing{newInstance("knative-serving", "knative-serving")},
+		anyNS:    true,
+		wantErr:  true,
+	}, {
+		name:     "installing into another namespace",
+		instance: newInstance("knative-serving", "knative-serving"),
+		target:   "serving-system",
+	}, {
+		name:     "invalid install namespace",
+		instance: newInstance("knative-serving", "knative-serving"),
+		target:   "Serving_System",
+		wantErr:  true,
+	}, {
+		name:     "env overrides",
+		instance: newInstance("knative-serving", "knative-serving"),
+		env:      []corev1.EnvVar{{Name: "GOGC", Value: "50"}, {Name: "METRICS_DOMAIN", Value: "example.com/serving"}},
+	}, {
+		name:     "env var set twice",
+		instance: newInstance("knative-serving", "knative-serving"),
+		env:      []corev1.EnvVar{{Name: "GOGC", Value: "50"}, {Name: "GOGC", Value: "100"}},
+		wantErr:  true,
+	}, {
+		name:     "negative replicas",
+		instance: newInstance("knative-serving", "knative-serving"),
+		replicas: &negative,
+		wantErr:  true,
+	}, {
+		name:     "second instance",
+		instance: newInstance("knative-serving", "another"),
+		existing: []KnativeServing{newInstance("knative-serving", "knative-serving")},
+		wantErr:  true,
+	}, {
+		name:     "recreating the same instance",
+		instance: newInstance("knative-serving", "knative-serving"),
+		existing: []KnativeServing{newInstance("knative-serving", "knative-serving")},
+	}, {
+		name:     "updates are allowed",
+		instance: newInstance("default", "another"),
+		existing: []KnativeServing{newInstance("knative-serving", "knative-serving")},
+		update:   true,
+	}, {
+		name:     "a single ingress",
+		instance: newInstance("knative-serving", "knative-serving"),
+		ingress:  &IngressConfigs{Kourier: KourierIngressConfiguration{Enabled: true}},
+	}, {
+		name:     "several ingresses",
+		instance: newInstance("knative-serving", "knative-serving"),
+		ingress: &IngressConfigs{
+			Istio:   IstioIngressConfiguration{Enabled: true},
+			Contour: ContourIngressConfiguration{Enabled: true},
+		},
+		wantErr: true,
+	}, {
+		name:     "several ingresses on update",
+		instance: newInstance("knative-serving", "knative-serving"),
+		ingress: &IngressConfigs{
+			Kourier: KourierIngressConfiguration{Enabled: true},
+			Contour: ContourIngressConfiguration{Enabled: true},
+		},
+		update:  true,
+		wantErr: true,
+	}, {
+		name:     "gloo isn't supported yet",
+		instance: newInstance("knative-serving", "knative-serving"),
+		ingress:  &IngressConfigs{Gloo: GlooIngressConfiguration{Enabled: true}},
+		wantErr:  true,
+	}, {
+		name:     "contour visibility",
+		instance: newInstance("knative-serving", "knative-serving"),
+		ingress: &IngressConfigs{Contour: ContourIngressConfiguration{
+			Enabled:  true,
+			External: &ContourVisibility{Class: "contour-public", Service: "contour-public/envoy"},
+		}},
+	}, {
+		name:     "contour service without a namespace",
+		instance: newInstance("knative-serving", "knative-serving"),
+		ingress: &IngressConfigs{Contour: ContourIngressConfiguration{
+			Enabled:  true,
+			Internal: &ContourVisibility{Service: "envoy"},
+		}},
+		wantErr: true,
+	}, {
+		name:     "known disabled components",
+		instance: newInstance("knative-serving", "knative-serving"),
+		disabled: []string{"cert-manager", "cluster-local-gateway"},
+	}, {
+		name:     "unknown disabled component",
+		instance: newInstance("knative-serving", "knative-serving"),
+		disabled: []string{"monitoring", "activator"},
+		wantErr:  true,
+	}, {
+		name:     "domains with a default",
+		instance: newInstance("knative-serving", "knative-serving"),
+		domain: map[string]DomainSelector{
+			"example.com": {},
+			"example.org": {Selector: map[string]string{"app": "nonprofit"}},
+		},
+	}, {
+		name:     "domains without a default",
+		instance: newInstance("knative-serving", "knative-serving"),
+		domain: map[string]DomainSelector{
+			"example.org": {Selector: map[string]string{"app": "nonprofit"}},
+		},
+		wantErr: true,
+	}, {
+		name:     "invalid domain",
+		instance: newInstance("knative-serving", "knative-serving"),
+		domain:   map[string]DomainSelector{"Example_com": {}},
+		wantErr:  true,
+	}, {
+		name:     "cert-manager with an issuer",
+		instance: newInstance("knative-serving", "knative-serving"),
+		certs:    &CertManager{Enabled: true, ClusterIssuer: "letsencrypt"},
+	}, {
+		name:     "cert-manager without an issuer",
+		instance: newInstance("knative-serving", "knative-serving"),
+		certs:    &CertManager{Enabled: true},
+		wantErr:  true,
+	}, {
+		name:     "cert-manager enabled and disabled",
+		instance: newInstance("knative-serving", "knative-serving"),
+		certs:    &CertManager{Enabled: true, ClusterIssuer: "letsencrypt"},
+		disabled: []string{"cert-manager"},
+		wantErr:  true,
+	}, {
+		name:     "manifest policy",
+		instance: newInstance("knative-serving", "knative-serving"),
+		policy: &ManifestPolicy{
+			Default:   CreateOnlyPolicy,
+			Resources: []ResourcePolicy{{Kind: "HorizontalPodAutoscaler", Name: "activator", Policy: NonePolicy}},
+		},
+	}, {
+		name:     "unknown manifest policy",
+		instance: newInstance("knative-serving", "knative-serving"),
+		policy:   &ManifestPolicy{Resources: []ResourcePolicy{{Kind: "ConfigMap", Policy: "Ignore"}}},
+		wantErr:  true,
+	}, {
+		name:     "additional manifests",
+		instance: newInstance("knative-serving", "knative-serving"),
+		extra: []AdditionalManifest{
+			{ConfigMap: "network-policies"},
+			{URL: "https://example.com/dashboards.yaml", SHA256: "deadbeef"},
+		},
+	}, {
+		name:     "additional manifest without a checksum",
+		instance: newInstance("knative-serving", "knative-serving"),
+		extra:    []AdditionalManifest{{URL: "https://example.com/dashboards.yaml"}},
+		wantErr:  true,
+	}, {
+		name:     "additional manifest over http",
+		instance: newInstance("knative-serving", "knative-serving"),
+		extra:    []AdditionalManifest{{URL: "http://example.com/dashboards.yaml", SHA256: "deadbeef"}},
+		wantErr:  true,
+	}, {
+		name:     "additional manifest with both sources",
+		instance: newInstance("knative-serving", "knative-serving"),
+		extra:    []AdditionalManifest{{ConfigMap: "network-policies", URL: "https://example.com/dashboards.yaml"}},
+		wantErr:  true,
+	}, {
+		name:     "empty additional manifest",
+		instance: newInstance("knative-serving", "knative-serving"),
+		extra:    []AdditionalManifest{{}},
+		wantErr:  true,
+	}, {
+		name:     "activator autoscaling",
+		instance: newInstance("knative-serving", "knative-serving"),
+		ha:       &HighAvailability{Replicas: 2, Autoscaling: &HighAvailabilityAutoscaling{MinReplicas: 2, MaxReplicas: 10}},
+	}, {
+		name:     "activator autoscaling without maxReplicas",
+		instance: newInstance("knative-serving", "knative-serving"),
+		ha:       &HighAvailability{Replicas: 2, Autoscaling: &HighAvailabilityAutoscaling{MinReplicas: 2}},
+		wantErr:  true,
+	}, {
+		name:     "activator autoscaling with minReplicas above maxReplicas",
+		instance: newInstance("knative-serving", "knative-serving"),
+		ha:       &HighAvailability{Replicas: 2, Autoscaling: &HighAvailabilityAutoscaling{MinReplicas: 5, MaxReplicas: 3}},
+		wantErr:  true,
+	}, {
+		name:     "common labels",
+		instance: newInstance("knative-serving", "knative-serving"),
+		labels:   map[string]string{"example.com/cost-center": "cc-1234"},
+	}, {
+		name:     "invalid common label value",
+		instance: newInstance("knative-serving", "knative-serving"),
+		labels:   map[string]string{"example.com/owner": "team a"},
+		wantErr:  true,
+	}, {
+		name:     "common label reserved for the operator",
+		instance: newInstance("knative-serving", "knative-serving"),
+		labels:   map[string]string{"operator.knative.dev/owner-name": "other"},
+		wantErr:  true,
+	}, {
+		name:     "pod disruption budgets",
+		instance: newInstance("knative-serving", "knative-serving"),
+		budgets: []PodDisruptionBudget{
+			{Deployment: "activator", MinAvailable: &one},
+			{Deployment: "controller", MaxUnavailable: &half},
+		},
+	}, {
+		name:     "pod disruption budget with both bounds",
+		instance: newInstance("knative-serving", "knative-serving"),
+		budgets:  []PodDisruptionBudget{{Deployment: "activator", MinAvailable: &one, MaxUnavailable: &half}},
+		wantErr:  true,
+	}, {
+		name:     "pod disruption budget without a bound",
+		instance: newInstance("knative-serving", "knative-serving"),
+		budgets:  []PodDisruptionBudget{{Deployment: "activator"}},
+		wantErr:  true,
+	}, {
+		name:     "pod disruption budget above 100%",
+		instance: newInstance("knative-serving", "knative-serving"),
+		budgets:  []PodDisruptionBudget{{Deployment: "activator", MaxUnavailable: &tooMany}},
+		wantErr:  true,
+	}, {
+		name:     "two pod disruption budgets for a deployment",
+		instance: newInstance("knative-serving", "knative-serving"),
+		budgets: []PodDisruptionBudget{
+			{Deployment: "activator", MinAvailable: &one},
+			{Deployment: "activator", MaxUnavailable: &half},
+		},
+		wantErr: true,
+	}, {
+		name:     "control plane priority class",
+		instance: newInstance("knative-serving", "knative-serving"),
+		priority: "system-cluster-critical",
+	}, {
+		name:     "invalid control plane priority class",
+		instance: newInstance("knative-serving", "knative-serving"),
+		priority: "System Critical",
+		wantErr:  true,
+	}, {
+		name:     "uninstall policy",
+		instance: newInstance("knative-serving", "knative-serving"),
+		teardown: UninstallKeepCRDs,
+	}, {
+		name:     "unknown uninstall policy",
+		instance: newInstance("knative-serving", "knative-serving"),
+		teardown: "KeepEverything",
+		wantErr:  true,
+	}, {
+		name:     "overrides by architecture",
+		instance: newInstance("knative-serving", "knative-serving"),
+		registry: Registry{Override: map[string]string{"activator": "example.com/activator", "arm64/activator": "example.com/activator-arm64"}},
+	}, {
+		name:     "override without an architecture",
+		instance: newInstance("knative-serving", "knative-serving"),
+		registry: Registry{Override: map[string]string{"/activator": "example.com/activator"}},
+		wantErr:  true,
+	}, {
+		name:     "probe tuning",
+		instance: newInstance("knative-serving", "knative-serving"),
+		probes: []ProbeOverride{{
+			Container: "activator",
+			Readiness: &ProbeTuning{InitialDelaySeconds: &zero, TimeoutSeconds: &ten},
+		}},
+	}, {
+		name:     "probe tuning without a probe",
+		instance: newInstance("knative-serving", "knative-serving"),
+		probes:   []ProbeOverride{{Container: "activator"}},
+		wantErr:  true,
+	}, {
+		name:     "probe tuning without a container",
+		instance: newInstance("knative-serving", "knative-serving"),
+		probes:   []ProbeOverride{{Liveness: &ProbeTuning{InitialDelaySeconds: &ten}}},
+		wantErr:  true,
+	}, {
+		name:     "zero probe timeout",
+		instance: newInstance("knative-serving", "knative-serving"),
+		probes: []ProbeOverride{{
+			Container: "activator",
+			Liveness:  &ProbeTuning{TimeoutSeconds: &zero},
+		}},
+		wantErr: true,
+	}, {
+		name:     "negative probe delay",
+		instance: newInstance("knative-serving", "knative-serving"),
+		probes: []ProbeOverride{{
+			Container: "activator",
+			Readiness: &ProbeTuning{InitialDelaySeconds: &negative},
+		}},
+		wantErr: true,
+	}, {
+		name:     "export",
+		instance: newInstance("knative-serving", "knative-serving"),
+		export:   &ManifestExport{ConfigMapName: "fleet-manifest", Only: true},
+	}, {
+		name:     "export to an invalid ConfigMap name",
+		instance: newInstance("knative-serving", "knative-serving"),
+		export:   &ManifestExport{ConfigMapName: "Fleet_Manifest"},
+		wantErr:  true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.instance.Spec.Ingress = tt.ingress
+			tt.instance.Spec.DisabledComponents = tt.disabled
+			tt.instance.Spec.Domain = tt.domain
+			tt.instance.Spec.CertManager = tt.certs
+			tt.instance.Spec.ManifestPolicy = tt.policy
+			tt.instance.Spec.AdditionalManifests = tt.extra
+			tt.instance.Spec.Namespace = tt.target
+			tt.instance.Spec.HighAvailability = tt.ha
+			tt.instance.Spec.CommonLabels = tt.labels
+			tt.instance.Spec.PodDisruptionBudgets = tt.budgets
+			tt.instance.Spec.ControlPlanePriorityClass = tt.priority
+			tt.instance.Spec.UninstallPolicy = tt.teardown
+			tt.instance.Spec.Registry = tt.registry
+			tt.instance.Spec.Probes = tt.probes
+			tt.instance.Spec.Export = tt.export
+			if tt.env != nil || tt.replicas != nil {
+				tt.instance.Spec.DeploymentOverrides = []DeploymentOverride{{Name: "controller", Env: tt.env, Replicas: tt.replicas}}
+			}
+			namespaces := []string{"knative-serving"}
+			if tt.anyNS {
+				namespaces = nil
+			}
+			ctx := WithInstallation(context.Background(), namespaces, tt.existing)
+			if tt.update {
+				ctx = apis.WithinUpdate(ctx, &tt.instance)
+			} else {
+				ctx = apis.WithinCreate(ctx)
+			}
+			err := tt.instance.Validate(ctx)
+			if got := err != nil; got != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckImmutableFields(t *testing.T) {
+	newInstance := func(namespace, version string) *KnativeServing {
+		return &KnativeServing{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving", Name: "knative-serving"},
+			Spec:       KnativeServingSpec{Namespace: namespace},
+			Status:     KnativeServingStatus{Version: version},
+		}
+	}
+	tests := []struct {
+		name     string
+		original *KnativeServing
+		updated  *KnativeServing
+		wantErr  bool
+	}{{
+		name:     "not installed yet",
+		original: newInstance("", ""),
+		updated:  newInstance("serving-system", ""),
+	}, {
+		name:     "unchanged",
+		original: newInstance("serving-system", "0.7.0"),
+		updated:  newInstance("serving-system", "0.7.0"),
+	}, {
+		name:     "spelled out",
+		original: newInstance("", "0.7.0"),
+		updated:  newInstance("knative-serving", "0.7.0"),
+	}, {
+		name:     "moved",
+		original: newInstance("", "0.7.0"),
+		updated:  newInstance("serving-system", "0.7.0"),
+		wantErr:  true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.updated.CheckImmutableFields(context.Background(), tt.original); (err != nil) != tt.wantErr {
+				t.Errorf("CheckImmutableFields() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIngressName(t *testing.T) {
+	var unset *IngressConfigs
+	if got := unset.Name(); got != IstioIngress {
+		t.Errorf("Name() = %q, want %q", got, IstioIngress)
+	}
+	kourier := &IngressConfigs{Kourier: KourierIngressConfiguration{Enabled: true}}
+	if got := kourier.Name(); got != KourierIngress {
+		t.Errorf("Name() = %q, want %q", got, KourierIngress)
+	}
+}
+
+func TestManifestPolicyFor(t *testing.T) {
+	policy := &ManifestPolicy{
+		Resources: []ResourcePolicy{
+			{Kind: "HorizontalPodAutoscaler", Name: "activator", Policy: NonePolicy},
+			{Kind: "HorizontalPodAutoscaler", Policy: CreateOnlyPolicy},
+		},
+	}
+	tests := []struct {
+		policy     *ManifestPolicy
+		kind, name string
+		want       string
+	}{
+		{nil, "ConfigMap", "config-network", ApplyPolicy},
+		{policy, "ConfigMap", "config-network", ApplyPolicy},
+		{policy, "HorizontalPodAutoscaler", "activator", NonePolicy},
+		{policy, "HorizontalPodAutoscaler", "webhook", CreateOnlyPolicy},
+		{&ManifestPolicy{Default: CreateOnlyPolicy}, "ConfigMap", "config-network", CreateOnlyPolicy},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.For(tt.kind, tt.name); got != tt.want {
+			t.Errorf("For(%s, %s) = %s, want %s", tt.kind, tt.name, got, tt.want)
+		}
+	}
+}
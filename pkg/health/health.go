@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package health
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var (
+	port = flag.Int("health-port", 8081,
+		"The port the /healthz and /readyz probes are served on; 0 disables them")
+	log = logf.Log.WithName("health")
+
+	mu sync.RWMutex
+	// The components that have registered, and why they aren't ready
+	checks = map[string]error{}
+)
+
+// NotReady registers a component that isn't ready until SetReady
+// reports it so
+func NotReady(name string) {
+	SetReady(name, fmt.Errorf("not initialized"))
+}
+
+// SetReady records whether a component initialized, i.e. err is nil
+func SetReady(name string, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	checks[name] = err
+}
+
+// Ready returns why any registered component isn't ready
+func Ready() error {
+	mu.RLock()
+	defer mu.RUnlock()
+	var failures []string
+	for name, err := range checks {
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	sort.Strings(failures)
+	return fmt.Errorf("%s", strings.Join(failures, "; "))
+}
+
+// Handler serves /healthz, ok as long as the process responds, and
+// /readyz, ok once every registered component is ready
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if err := Ready(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// ListenAndServe serves the probes in the background, from before the
+// operator becomes the leader so it isn't restarted while it waits
+func ListenAndServe() {
+	if *port == 0 {
+		log.Info("Health probes disabled")
+		return
+	}
+	addr := fmt.Sprintf(":%d", *port)
+	go func() {
+		if err := http.ListenAndServe(addr, Handler()); err != nil {
+			log.Error(err, "Health probes stopped", "addr", addr)
+		}
+	}()
+}
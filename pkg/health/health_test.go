@@ -0,0 +1,37 @@
+package health
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func probe(t *testing.T, path string) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w.Code
+}
+
+func TestProbes(t *testing.T) {
+	defer func() { checks = map[string]error{} }()
+
+	NotReady("manifest")
+	if got := probe(t, "/healthz"); got != http.StatusOK {
+		t.Errorf("/healthz = %d, want %d", got, http.StatusOK)
+	}
+	if got := probe(t, "/readyz"); got != http.StatusServiceUnavailable {
+		t.Errorf("/readyz = %d before initializing, want %d", got, http.StatusServiceUnavailable)
+	}
+
+	SetReady("manifest", errors.New("no such directory"))
+	if err := Ready(); err == nil || err.Error() != "manifest: no such directory" {
+		t.Errorf("Ready() = %v, want the manifest failure", err)
+	}
+
+	SetReady("manifest", nil)
+	if got := probe(t, "/readyz"); got != http.StatusOK {
+		t.Errorf("/readyz = %d once initialized, want %d", got, http.StatusOK)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const activatorDeployment = "activator"
+
+// The HorizontalPodAutoscaler of spec.highAvailability.autoscaling,
+// scaling the activator on its CPU utilization. It replaces any the
+// manifest ships, so that the operator alone owns the bounds.
+func withActivatorAutoscaler(instance *servingv1alpha1.KnativeServing, resources []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	hpa, err := activatorAutoscaler(instance)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]unstructured.Unstructured, 0, len(resources)+1)
+	for _, u := range resources {
+		if u.GetKind() != "HorizontalPodAutoscaler" || u.GetName() != activatorDeployment {
+			result = append(result, u)
+		}
+	}
+	return append(result, hpa), nil
+}
+
+func activatorAutoscaler(instance *servingv1alpha1.KnativeServing) (unstructured.Unstructured, error) {
+	ha := instance.Spec.HighAvailability.DeepCopy()
+	ha.SetDefaults()
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: autoscalingv1.SchemeGroupVersion.String(),
+			Kind:       "HorizontalPodAutoscaler",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: instance.InstallNamespace(),
+			Name:      activatorDeployment,
+		},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       activatorDeployment,
+			},
+			MinReplicas:                    &ha.Autoscaling.MinReplicas,
+			MaxReplicas:                    ha.Autoscaling.MaxReplicas,
+			TargetCPUUtilizationPercentage: &ha.Autoscaling.TargetCPUUtilizationPercentage,
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(hpa)
+	if err != nil {
+		return unstructured.Unstructured{}, err
+	}
+	return unstructured.Unstructured{Object: obj}, nil
+}
+
+func activatorAutoscalingEnabled(instance *servingv1alpha1.KnativeServing) bool {
+	return instance.Spec.HighAvailability != nil && instance.Spec.HighAvailability.Autoscaling != nil
+}
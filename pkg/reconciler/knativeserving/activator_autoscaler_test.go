@@ -0,0 +1,84 @@
+package knativeserving
+
+import (
+	"context"
+	"testing"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const activatorManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: activator
+  namespace: knative-serving
+spec:
+  replicas: 1
+---
+apiVersion: autoscaling/v2beta1
+kind: HorizontalPodAutoscaler
+metadata:
+  name: activator
+  namespace: knative-serving
+spec:
+  minReplicas: 1
+  maxReplicas: 20
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: activator
+`
+
+func TestActivatorAutoscaler(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			HighAvailability: &servingv1alpha1.HighAvailability{
+				Replicas:    2,
+				Autoscaling: &servingv1alpha1.HighAvailabilityAutoscaling{MaxReplicas: 10},
+			},
+		},
+	}
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c, scheme: newTestScheme(), recorder: record.NewFakeRecorder(10), config: newTestManifest(t, activatorManifest, c)}
+
+	manifest, err := r.transform(instance, nil)
+	if err != nil {
+		t.Fatalf("transform() = %v", err)
+	}
+	var autoscalers []unstructured.Unstructured
+	for _, u := range manifest.Resources {
+		switch u.GetKind() {
+		case "HorizontalPodAutoscaler":
+			autoscalers = append(autoscalers, u)
+		case "Deployment":
+			if _, found, _ := unstructured.NestedFieldNoCopy(u.Object, "spec", "replicas"); found {
+				t.Errorf("activator replicas are set, want them left to the autoscaler")
+			}
+		}
+	}
+	if len(autoscalers) != 1 || autoscalers[0].GetAPIVersion() != "autoscaling/v1" {
+		t.Fatalf("got autoscalers %v, want only the generated one", autoscalers)
+	}
+	if _, err := applyChanged(&manifest, nil, nil); err != nil {
+		t.Fatalf("applyChanged() = %v", err)
+	}
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "activator"}, hpa); err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if *hpa.Spec.MinReplicas != 2 || hpa.Spec.MaxReplicas != 10 || *hpa.Spec.TargetCPUUtilizationPercentage != 100 {
+		t.Errorf("autoscaler spec = %+v, want 2 to 10 replicas at 100%% CPU", hpa.Spec)
+	}
+	if hpa.Spec.ScaleTargetRef.Name != "activator" {
+		t.Errorf("autoscaler targets %q, want the activator", hpa.Spec.ScaleTargetRef.Name)
+	}
+	if len(hpa.OwnerReferences) != 1 {
+		t.Errorf("autoscaler owners = %v, want the KnativeServing", hpa.OwnerReferences)
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"fmt"
+	"net/http"
+
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The resources of spec.additionalManifests, in order. A manifest at
+// a URL is pinned by its checksum, so it's only fetched once, while a
+// ConfigMap is read anew each time.
+func (r *ReconcileKnativeServing) additionalResources(instance *servingv1alpha1.KnativeServing) ([]unstructured.Unstructured, error) {
+	var result []unstructured.Unstructured
+	for _, m := range instance.Spec.AdditionalManifests {
+		resources, err := r.loadAdditional(instance.GetNamespace(), m)
+		if err != nil {
+			return nil, fmt.Errorf("loading additional manifest %s: %v", additionalName(m), err)
+		}
+		for i := range resources {
+			result = append(result, *resources[i].DeepCopy())
+		}
+	}
+	return result, nil
+}
+
+// The URL of the manifest, or else its ConfigMap
+func additionalName(m servingv1alpha1.AdditionalManifest) string {
+	if m.URL != "" {
+		return m.URL
+	}
+	return "configmap/" + m.ConfigMap
+}
+
+func (r *ReconcileKnativeServing) loadAdditional(namespace string, m servingv1alpha1.AdditionalManifest) ([]unstructured.Unstructured, error) {
+	if m.URL == "" {
+		source, err := newConfigMapSource(namespace+"/"+m.ConfigMap, r.client)
+		if err != nil {
+			return nil, err
+		}
+		return fetchResources(source, r.client)
+	}
+	key := m.URL + "@" + m.SHA256
+	if resources, ok := r.additional[key]; ok {
+		return resources, nil
+	}
+	source, err := newURLSource(m.URL, m.SHA256, http.DefaultClient)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := fetchResources(source, r.client)
+	if err != nil {
+		return nil, err
+	}
+	if r.additional == nil {
+		r.additional = map[string][]unstructured.Unstructured{}
+	}
+	r.additional[key] = resources
+	return resources, nil
+}
+
+func fetchResources(source ManifestSource, c client.Client) ([]unstructured.Unstructured, error) {
+	path, release, err := source.Fetch()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	m, err := mf.NewManifest(path, false, c)
+	if err != nil {
+		return nil, err
+	}
+	return m.Resources, nil
+}
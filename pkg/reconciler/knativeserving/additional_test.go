@@ -0,0 +1,118 @@
+package knativeserving
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/pkg/reconciler/knativeserving/common"
+)
+
+const networkPolicyManifest = `apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: allow-webhook
+spec:
+  podSelector:
+    matchLabels:
+      app: webhook
+`
+
+func TestTransformAppendsAdditionalManifests(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			AdditionalManifests: []servingv1alpha1.AdditionalManifest{{ConfigMap: "network-policies"}},
+		},
+	}
+	c := newFakeClient(newTestScheme(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "network-policies"},
+		Data:       map[string]string{"policies.yaml": networkPolicyManifest},
+	})
+	r := &ReconcileKnativeServing{client: c, scheme: newTestScheme(), config: newTestManifest(t, testManifest, c)}
+
+	manifest, err := r.transform(instance, nil)
+	if err != nil {
+		t.Fatalf("transform() = %v", err)
+	}
+	if got, want := len(manifest.Resources), len(r.config.Resources)+1; got != want {
+		t.Fatalf("got %d resources, want %d", got, want)
+	}
+	policy := manifest.Resources[len(manifest.Resources)-1]
+	if policy.GetKind() != "NetworkPolicy" || policy.GetNamespace() != operand {
+		t.Errorf("got %s %s/%s, want the NetworkPolicy in %s", policy.GetKind(), policy.GetNamespace(), policy.GetName(), operand)
+	}
+	if len(policy.GetOwnerReferences()) != 1 {
+		t.Errorf("owner references = %v, want the KnativeServing", policy.GetOwnerReferences())
+	}
+
+	instance.Spec.AdditionalManifests[0].ConfigMap = "missing"
+	if _, err := r.transform(instance, nil); err == nil {
+		t.Error("expected a missing ConfigMap to fail the transform")
+	}
+}
+
+func TestTransformOwnsPluginResources(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+	}
+	r := &ReconcileKnativeServing{scheme: newTestScheme(), config: newTestManifest(t, testManifest, nil)}
+	plugin := func(_ *servingv1alpha1.KnativeServing, resources []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+		u := unstructured.Unstructured{}
+		u.SetAPIVersion("v1")
+		u.SetKind("ConfigMap")
+		u.SetName("added-by-plugin")
+		return append(resources, u), nil
+	}
+	extensions := common.Extensions{{ManifestTransformers: []common.ManifestTransformer{plugin}}}
+
+	manifest, err := r.transform(instance, extensions)
+	if err != nil {
+		t.Fatalf("transform() = %v", err)
+	}
+	added := manifest.Resources[len(manifest.Resources)-1]
+	if added.GetName() != "added-by-plugin" || added.GetNamespace() != operand {
+		t.Errorf("got %s %s/%s, want the ConfigMap in %s", added.GetKind(), added.GetNamespace(), added.GetName(), operand)
+	}
+	if len(added.GetOwnerReferences()) != 1 {
+		t.Errorf("owner references = %v, want the KnativeServing", added.GetOwnerReferences())
+	}
+}
+
+func TestAdditionalManifestURLIsFetchedOnce(t *testing.T) {
+	fetches := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches++
+		w.Write([]byte(networkPolicyManifest))
+	}))
+	defer server.Close()
+	defer func(c *http.Client) { http.DefaultClient = c }(http.DefaultClient)
+	http.DefaultClient = server.Client()
+	sum := sha256.Sum256([]byte(networkPolicyManifest))
+
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			AdditionalManifests: []servingv1alpha1.AdditionalManifest{{URL: server.URL, SHA256: hex.EncodeToString(sum[:])}},
+		},
+	}
+	r := &ReconcileKnativeServing{client: newFakeClient(newTestScheme())}
+	for i := 0; i < 2; i++ {
+		resources, err := r.additionalResources(instance)
+		if err != nil {
+			t.Fatalf("additionalResources() = %v", err)
+		}
+		if len(resources) != 1 {
+			t.Errorf("got %d resources, want 1", len(resources))
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetched the manifest %d times, want once", fetches)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"fmt"
+	"strings"
+
+	mf "github.com/jcrossley3/manifestival"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+// The most foreign resources an error names
+const maxForeignNames = 5
+
+// Refuse to take over the resources of an install the operator didn't
+// make, e.g. one applied with kubectl, unless spec.adoptExisting says
+// so: applying the manifest then labels them as the operator's. Only
+// the first install is checked, since the resources of any later one
+// are the operator's own. Namespaces are commonly created ahead of the
+// install, and the resources the policy doesn't update aren't taken over.
+func (r *ReconcileKnativeServing) adopt(instance *servingv1alpha1.KnativeServing, manifest *mf.Manifest) error {
+	if instance.Status.Version != "" {
+		return nil
+	}
+	var foreign []string
+	for i := range manifest.Resources {
+		u := &manifest.Resources[i]
+		if u.GetKind() == "Namespace" || instance.Spec.ManifestPolicy.For(u.GetKind(), u.GetName()) != servingv1alpha1.ApplyPolicy {
+			continue
+		}
+		current, err := manifest.Get(u)
+		if err != nil {
+			return err
+		}
+		if current != nil && !isOperators(current) {
+			foreign = append(foreign, resourceName(u))
+		}
+	}
+	if len(foreign) == 0 {
+		return nil
+	}
+	if !instance.Spec.AdoptExisting {
+		names := foreign
+		if len(names) > maxForeignNames {
+			names = append(names[:maxForeignNames:maxForeignNames], "...")
+		}
+		return fmt.Errorf("%d resources of the manifest exist without the operator's labels: %s; set spec.adoptExisting to take them over",
+			len(foreign), strings.Join(names, ", "))
+	}
+	log.Info("Adopting an existing install", "resources", len(foreign))
+	r.recorder.Eventf(instance, v1.EventTypeNormal, "Adopted", "Adopting %d resources of an existing install", len(foreign))
+	return nil
+}
+
+// Whether the operator applied the resource, by its labels
+func isOperators(u *unstructured.Unstructured) bool {
+	labels := u.GetLabels()
+	return labels[releaseLabel] != "" || labels[ownerNameLabel] != ""
+}
+
+func resourceName(u *unstructured.Unstructured) string {
+	if u.GetNamespace() == "" {
+		return u.GetKind() + " " + u.GetName()
+	}
+	return u.GetKind() + " " + u.GetNamespace() + "/" + u.GetName()
+}
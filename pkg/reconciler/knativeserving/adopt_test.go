@@ -0,0 +1,58 @@
+package knativeserving
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func newAdoptionReconciler(t *testing.T, objs ...runtime.Object) (*ReconcileKnativeServing, *record.FakeRecorder) {
+	c := newFakeClient(newTestScheme(), objs...)
+	recorder := record.NewFakeRecorder(10)
+	return &ReconcileKnativeServing{client: c, recorder: recorder, config: newTestManifest(t, testManifest, c)}, recorder
+}
+
+func TestAdoptRefusesForeignResources(t *testing.T) {
+	r, recorder := newAdoptionReconciler(t, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "config-network"}})
+	instance := &servingv1alpha1.KnativeServing{}
+
+	err := r.adopt(instance, &r.config)
+	if err == nil || !strings.Contains(err.Error(), "ConfigMap knative-serving/config-network") {
+		t.Errorf("adopt() = %v, want the foreign ConfigMap named", err)
+	}
+	expectNoEvent(t, recorder)
+
+	// Once installed, the resources are the operator's
+	instance.Status.Version = "0.7.0"
+	if err := r.adopt(instance, &r.config); err != nil {
+		t.Errorf("adopt() = %v after an install", err)
+	}
+}
+
+func TestAdoptExisting(t *testing.T) {
+	r, recorder := newAdoptionReconciler(t, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "config-network"}})
+	instance := &servingv1alpha1.KnativeServing{Spec: servingv1alpha1.KnativeServingSpec{AdoptExisting: true}}
+
+	if err := r.adopt(instance, &r.config); err != nil {
+		t.Fatalf("adopt() = %v", err)
+	}
+	expectEvent(t, recorder, "Normal Adopted")
+}
+
+func TestAdoptOperatorsResources(t *testing.T) {
+	r, recorder := newAdoptionReconciler(t, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace: operand,
+		Name:      "config-network",
+		Labels:    map[string]string{releaseLabel: "0.7.0"},
+	}})
+
+	if err := r.adopt(&servingv1alpha1.KnativeServing{}, &r.config); err != nil {
+		t.Errorf("adopt() = %v, want the labeled ConfigMap left to apply", err)
+	}
+	expectNoEvent(t, recorder)
+}
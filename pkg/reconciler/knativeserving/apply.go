@@ -0,0 +1,178 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+// The resources that failed to apply
+type applyError []servingv1alpha1.FailedResource
+
+func (e applyError) Error() string {
+	msgs := make([]string, len(e))
+	for i, f := range e {
+		name := f.Name
+		if f.Namespace != "" {
+			name = f.Namespace + "/" + f.Name
+		}
+		msgs[i] = fmt.Sprintf("%s %s: %s", f.Kind, name, f.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Apply the manifest in phases, only creating the missing resources
+// and updating those that differ from it. The fields the API server
+// defaults, which are left out of the manifest, don't count, and
+// neither do the resources the policy leaves be. A custom resource
+// waits for its CRD of the manifest to be established. A resource
+// failing to apply doesn't stop the others, the failures are returned
+// together as an applyError, which is permanent if the API server
+// rejected every one of them as invalid. Unless given another apply,
+// manifestival's updates the resources. The resources found out of
+// sync, missing or differing, are counted by kind.
+func applyChanged(manifest *mf.Manifest, policy *servingv1alpha1.ManifestPolicy, apply applyFunc) (map[string]int, error) {
+	if apply == nil {
+		apply = (*mf.Manifest).Apply
+	}
+	var failed applyError
+	outOfSync := map[string]int{}
+	rejected := true
+	resources := phased(manifest.Resources)
+	var crds []*unstructured.Unstructured
+	for _, u := range resources {
+		if applyPhase(u) == crdsPhase {
+			crds = append(crds, u)
+		}
+	}
+	established := map[string]error{}
+	for _, u := range resources {
+		var err error
+		if crd := definingCRD(crds, u); crd != nil {
+			if _, ok := established[crd.GetName()]; !ok {
+				established[crd.GetName()] = waitEstablished(manifest, crd)
+			}
+			err = established[crd.GetName()]
+		}
+		if err == nil {
+			err = applyResource(manifest, u, policy.For(u.GetKind(), u.GetName()), apply, outOfSync)
+		}
+		if err != nil {
+			log.Error(err, "Failed to apply", "kind", u.GetKind(), "namespace", u.GetNamespace(), "name", u.GetName())
+			rejected = rejected && isRejected(err)
+			failed = append(failed, servingv1alpha1.FailedResource{
+				Kind:      u.GetKind(),
+				Namespace: u.GetNamespace(),
+				Name:      u.GetName(),
+				Message:   err.Error(),
+			})
+		}
+	}
+	log.V(1).Info("Applied manifest", "resources", len(manifest.Resources), "outOfSync", outOfSync, "failed", len(failed))
+	if len(failed) > 0 && rejected {
+		// Applying the same resources again would fail the same way
+		return outOfSync, permanent(failed)
+	}
+	if len(failed) > 0 {
+		return outOfSync, failed
+	}
+	return outOfSync, nil
+}
+
+func applyResource(manifest *mf.Manifest, u *unstructured.Unstructured, policy string, apply applyFunc, outOfSync map[string]int) error {
+	if policy == servingv1alpha1.NonePolicy {
+		return nil
+	}
+	current, err := manifest.Get(u)
+	if err != nil {
+		return err
+	}
+	if current != nil && (policy == servingv1alpha1.CreateOnlyPolicy || len(changedFields(u.Object, current.Object, "")) == 0) {
+		return nil
+	}
+	outOfSync[u.GetKind()]++
+	return apply(manifest, u)
+}
+
+// The paths of the fields set in src that differ in tgt, in the same
+// terms as the update made by manifestival's Apply. Empty fields of
+// src are left to the API server's defaults, as are the fields of its
+// list items that tgt adds.
+func changedFields(src, tgt map[string]interface{}, prefix string) []string {
+	var result []string
+	for k, v := range src {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		result = append(result, changedValue(v, tgt[k], path)...)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func changedValue(src, tgt interface{}, path string) []string {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		if t, ok := tgt.(map[string]interface{}); ok {
+			return changedFields(v, t, path)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		if t, ok := tgt.([]interface{}); ok && len(t) == len(v) {
+			var result []string
+			for i := range v {
+				result = append(result, changedValue(v[i], t[i], fmt.Sprintf("%s[%d]", path, i))...)
+			}
+			return result
+		}
+	case int64, int32, int, float64:
+		if number(v) == number(tgt) {
+			return nil
+		}
+	}
+	if !equality.Semantic.DeepEqual(src, tgt) {
+		return []string{path}
+	}
+	return nil
+}
+
+// JSON numbers decode as either int64 or float64
+func number(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int64:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int:
+		return float64(n)
+	}
+	return v
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// The phases the resources are applied in, each depending on those
+// before: the webhooks only once what they call is up, the Gateways
+// last since istio's webhook may reject them while Knative's resources
+// are missing
+const (
+	namespacesPhase = iota
+	crdsPhase
+	rbacPhase
+	configPhase
+	workloadsPhase
+	webhooksPhase
+	gatewaysPhase
+)
+
+var (
+	// How long a custom resource waits for its CRD to be established
+	crdEstablishTimeout  = 30 * time.Second
+	crdEstablishInterval = time.Second
+)
+
+func applyPhase(u *unstructured.Unstructured) int {
+	switch u.GetKind() {
+	case "Namespace":
+		return namespacesPhase
+	case "CustomResourceDefinition":
+		return crdsPhase
+	case "ServiceAccount", "ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding":
+		return rbacPhase
+	case "ConfigMap", "Secret":
+		return configPhase
+	case "MutatingWebhookConfiguration", "ValidatingWebhookConfiguration", "APIService":
+		return webhooksPhase
+	}
+	if u.GroupVersionKind().Group == "networking.istio.io" {
+		return gatewaysPhase
+	}
+	return workloadsPhase
+}
+
+// The resources in the order of their phases, and of the manifest
+// within each
+func phased(resources []unstructured.Unstructured) []*unstructured.Unstructured {
+	result := make([]*unstructured.Unstructured, len(resources))
+	for i := range resources {
+		result[i] = &resources[i]
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return applyPhase(result[i]) < applyPhase(result[j])
+	})
+	return result
+}
+
+// The CRD of the manifest defining the kind of the resource, if any
+func definingCRD(crds []*unstructured.Unstructured, u *unstructured.Unstructured) *unstructured.Unstructured {
+	gvk := u.GroupVersionKind()
+	for _, crd := range crds {
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+		if group == gvk.Group && kind == gvk.Kind {
+			return crd
+		}
+	}
+	return nil
+}
+
+// Wait for the CRD to be established, so that its resources can be
+// created, for up to crdEstablishTimeout
+func waitEstablished(manifest *mf.Manifest, crd *unstructured.Unstructured) error {
+	err := wait.PollImmediate(crdEstablishInterval, crdEstablishTimeout, func() (bool, error) {
+		current, err := manifest.Get(crd)
+		if err != nil {
+			return false, err
+		}
+		return current != nil && crdEstablished(current), nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("CRD %s not established within %v", crd.GetName(), crdEstablishTimeout)
+	}
+	return err
+}
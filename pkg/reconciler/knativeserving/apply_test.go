@@ -0,0 +1,319 @@
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	mf "github.com/jcrossley3/manifestival"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// countingClient counts the updates made through it
+type countingClient struct {
+	*fakeClient
+	updates int
+}
+
+func (c *countingClient) Update(ctx context.Context, obj runtime.Object) error {
+	c.updates++
+	return c.fakeClient.Update(ctx, obj)
+}
+
+func TestChangedFields(t *testing.T) {
+	src := map[string]interface{}{
+		"data": map[string]interface{}{"a": "1", "b": "2"},
+		"kind": "ConfigMap",
+	}
+	tgt := map[string]interface{}{
+		"data":     map[string]interface{}{"a": "1", "b": "3", "c": "4"},
+		"kind":     "ConfigMap",
+		"metadata": map[string]interface{}{"resourceVersion": "42"},
+	}
+	if got, want := changedFields(src, tgt, ""), []string{"data.b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("changedFields() = %v, want %v", got, want)
+	}
+}
+
+func TestChangedFieldsIgnoresDefaults(t *testing.T) {
+	src := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "controller", "creationTimestamp": nil},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "controller", "image": "new", "resources": map[string]interface{}{}},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{},
+	}
+	tgt := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "controller", "creationTimestamp": "2019-07-01T00:00:00Z"},
+		"spec": map[string]interface{}{
+			"replicas": float64(1),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "controller", "image": "old", "imagePullPolicy": "IfNotPresent"},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{"replicas": int64(1)},
+	}
+	want := []string{"spec.template.spec.containers[0].image"}
+	if got := changedFields(src, tgt, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("changedFields() = %v, want %v", got, want)
+	}
+
+	unstructured.SetNestedField(src, "old", "spec", "template", "spec", "containers")
+	if got := changedFields(src, tgt, ""); !reflect.DeepEqual(got, []string{"spec.template.spec.containers"}) {
+		t.Errorf("changedFields() = %v, want the replaced list", got)
+	}
+}
+
+func TestApplyChanged(t *testing.T) {
+	c := &countingClient{fakeClient: newFakeClient(newTestScheme())}
+	manifest := newTestManifest(t, testManifest, c)
+	if _, err := applyChanged(&manifest, nil, nil); err != nil {
+		t.Fatalf("applyChanged() = %v", err)
+	}
+	if c.updates != 0 {
+		t.Errorf("created resources were updated %d times", c.updates)
+	}
+
+	// Reapplying the same manifest changes nothing
+	manifest = newTestManifest(t, testManifest, c)
+	if _, err := applyChanged(&manifest, nil, nil); err != nil {
+		t.Fatalf("applyChanged() = %v", err)
+	}
+	if c.updates != 0 {
+		t.Errorf("unchanged resources were updated %d times", c.updates)
+	}
+
+	manifest = newTestManifest(t, testManifest, c)
+	unstructured.SetNestedField(manifest.Resources[1].Object, "true", "data", "autoTLS")
+	if _, err := applyChanged(&manifest, nil, nil); err != nil {
+		t.Fatalf("applyChanged() = %v", err)
+	}
+	if c.updates != 1 {
+		t.Errorf("updates = %d, want only the changed ConfigMap", c.updates)
+	}
+}
+
+func TestApplyChangedCountsOutOfSync(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	manifest := newTestManifest(t, activatorManifest, c)
+	if outOfSync, err := applyChanged(&manifest, nil, nil); err != nil || len(outOfSync) != 2 {
+		t.Fatalf("applyChanged() = %v, %v, want both resources created", outOfSync, err)
+	}
+	manifest = newTestManifest(t, activatorManifest, c)
+	if outOfSync, err := applyChanged(&manifest, nil, nil); err != nil || len(outOfSync) != 0 {
+		t.Fatalf("applyChanged() = %v, %v, want nothing out of sync", outOfSync, err)
+	}
+
+	// A hand-edited Deployment is repaired and counted
+	deployment := &appsv1.Deployment{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "activator"}, deployment); err != nil {
+		t.Fatal(err)
+	}
+	replicas := int32(3)
+	deployment.Spec.Replicas = &replicas
+	if err := c.Update(context.TODO(), deployment); err != nil {
+		t.Fatal(err)
+	}
+	manifest = newTestManifest(t, activatorManifest, c)
+	outOfSync, err := applyChanged(&manifest, nil, nil)
+	if err != nil {
+		t.Fatalf("applyChanged() = %v", err)
+	}
+	if want := map[string]int{"Deployment": 1}; !reflect.DeepEqual(outOfSync, want) {
+		t.Errorf("applyChanged() = %v, want %v", outOfSync, want)
+	}
+}
+
+func TestApplyChangedHonorsPolicy(t *testing.T) {
+	c := &countingClient{fakeClient: newFakeClient(newTestScheme())}
+	policy := &servingv1alpha1.ManifestPolicy{
+		Resources: []servingv1alpha1.ResourcePolicy{
+			{Kind: "ConfigMap", Name: "config-network", Policy: servingv1alpha1.CreateOnlyPolicy},
+			{Kind: "Deployment", Name: "webhook", Policy: servingv1alpha1.NonePolicy},
+		},
+	}
+	manifest := newTestManifest(t, testManifest, c)
+	if _, err := applyChanged(&manifest, policy, nil); err != nil {
+		t.Fatalf("applyChanged() = %v", err)
+	}
+	if current, _ := manifest.Get(&manifest.Resources[2]); current != nil {
+		t.Error("a resource with the None policy was created")
+	}
+
+	manifest = newTestManifest(t, testManifest, c)
+	unstructured.SetNestedField(manifest.Resources[1].Object, "true", "data", "autoTLS")
+	if _, err := applyChanged(&manifest, policy, nil); err != nil {
+		t.Fatalf("applyChanged() = %v", err)
+	}
+	if c.updates != 0 {
+		t.Errorf("updates = %d, want the CreateOnly ConfigMap left be", c.updates)
+	}
+}
+
+// failingClient fails to create the resources of the given names,
+// with err if set
+type failingClient struct {
+	*fakeClient
+	names map[string]bool
+	err   error
+}
+
+func (c *failingClient) Create(ctx context.Context, obj runtime.Object) error {
+	if accessor, err := meta.Accessor(obj); err == nil && c.names[accessor.GetName()] {
+		if c.err != nil {
+			return c.err
+		}
+		return fmt.Errorf("denied")
+	}
+	return c.fakeClient.Create(ctx, obj)
+}
+
+func TestApplyChangedContinuesPastFailures(t *testing.T) {
+	c := &failingClient{fakeClient: newFakeClient(newTestScheme()), names: map[string]bool{"config-network": true, "webhook": true}}
+	manifest := newTestManifest(t, testManifest, c)
+	_, err := applyChanged(&manifest, nil, nil)
+	failed, ok := err.(applyError)
+	if !ok {
+		t.Fatalf("applyChanged() = %v, want an applyError", err)
+	}
+	want := applyError{
+		{Kind: "ConfigMap", Namespace: operand, Name: "config-network", Message: "denied"},
+		{Kind: "Deployment", Namespace: operand, Name: "webhook", Message: "denied"},
+	}
+	if !reflect.DeepEqual(failed, want) {
+		t.Errorf("applyChanged() = %v, want %v", failed, want)
+	}
+	if got := err.Error(); got != "ConfigMap knative-serving/config-network: denied; Deployment knative-serving/webhook: denied" {
+		t.Errorf("Error() = %q", got)
+	}
+	// The resources after the failures are still applied
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "controller"}, &appsv1.Deployment{}); err != nil {
+		t.Errorf("controller not applied: %v", err)
+	}
+}
+
+func TestApplyChangedRejectedIsPermanent(t *testing.T) {
+	invalid := errors.NewInvalid(schema.GroupKind{Kind: "ConfigMap"}, "config-network", nil)
+	c := &failingClient{fakeClient: newFakeClient(newTestScheme()), names: map[string]bool{"config-network": true}, err: invalid}
+	manifest := newTestManifest(t, testManifest, c)
+	_, err := applyChanged(&manifest, nil, nil)
+	if !isPermanent(err) {
+		t.Errorf("applyChanged() = %v, want a permanent error", err)
+	}
+	if _, ok := cause(err).(applyError); !ok {
+		t.Errorf("applyChanged() = %v, want an applyError", err)
+	}
+
+	c.names["webhook"] = true
+	c.err = nil
+	manifest = newTestManifest(t, testManifest, c)
+	if _, err := applyChanged(&manifest, nil, nil); err == nil || isPermanent(err) {
+		t.Errorf("applyChanged() = %v, want a failure worth retrying", err)
+	}
+}
+
+const crdManifest = `apiVersion: networking.istio.io/v1alpha3
+kind: Gateway
+metadata:
+  name: knative-ingress-gateway
+  namespace: knative-serving
+---
+apiVersion: caching.internal.knative.dev/v1alpha1
+kind: Image
+metadata:
+  name: queue-proxy
+  namespace: knative-serving
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-network
+  namespace: knative-serving
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: images.caching.internal.knative.dev
+spec:
+  group: caching.internal.knative.dev
+  names:
+    kind: Image
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: knative-serving
+`
+
+func TestApplyChangedInPhases(t *testing.T) {
+	defer func(timeout, interval time.Duration) {
+		crdEstablishTimeout, crdEstablishInterval = timeout, interval
+	}(crdEstablishTimeout, crdEstablishInterval)
+	crdEstablishTimeout, crdEstablishInterval = 10*time.Millisecond, time.Millisecond
+	crd := &unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1beta1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName("images.caching.internal.knative.dev")
+	unstructured.SetNestedSlice(crd.Object, []interface{}{
+		map[string]interface{}{"type": "Established", "status": "True"},
+	}, "status", "conditions")
+	c := newFakeClient(newTestScheme(), crd)
+	manifest := newTestManifest(t, crdManifest, c)
+	var applied []string
+	apply := func(manifest *mf.Manifest, u *unstructured.Unstructured) error {
+		applied = append(applied, u.GetKind())
+		return manifest.Apply(u)
+	}
+
+	if _, err := applyChanged(&manifest, nil, apply); err != nil {
+		t.Fatalf("applyChanged() = %v", err)
+	}
+	want := []string{"Namespace", "CustomResourceDefinition", "ConfigMap", "Image", "Gateway"}
+	if !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied %v, want %v", applied, want)
+	}
+}
+
+func TestApplyChangedWaitsForCRDs(t *testing.T) {
+	defer func(timeout, interval time.Duration) {
+		crdEstablishTimeout, crdEstablishInterval = timeout, interval
+	}(crdEstablishTimeout, crdEstablishInterval)
+	crdEstablishTimeout, crdEstablishInterval = 10*time.Millisecond, time.Millisecond
+	c := newFakeClient(newTestScheme())
+	manifest := newTestManifest(t, crdManifest, c)
+
+	// The fake API server never establishes the CRD
+	_, err := applyChanged(&manifest, nil, nil)
+	failed, ok := err.(applyError)
+	if !ok || isPermanent(err) {
+		t.Fatalf("applyChanged() = %v, want an applyError worth retrying", err)
+	}
+	if len(failed) != 1 || failed[0].Kind != "Image" || !strings.Contains(failed[0].Message, "not established") {
+		t.Errorf("applyChanged() = %v, want only the Image waiting on its CRD", failed)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "config-network"}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("config-network not applied: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// The architecture label of nodes, and that of clusters before 1.14
+	archLabel     = "kubernetes.io/arch"
+	betaArchLabel = "beta.kubernetes.io/arch"
+)
+
+// The architecture the control plane runs on when the registry has
+// overrides keyed by architecture: that of the most schedulable nodes
+// among those the overrides cover. The node selector pinning the
+// control plane to it is only needed on a cluster mixing architectures.
+func (r *ReconcileKnativeServing) architecture(instance *servingv1alpha1.KnativeServing) (string, map[string]string, error) {
+	covered := instance.Spec.Registry.Architectures()
+	if len(covered) == 0 {
+		return "", nil, nil
+	}
+	nodes := &v1.NodeList{}
+	if err := r.client.List(context.TODO(), &client.ListOptions{}, nodes); err != nil {
+		return "", nil, err
+	}
+	counts := map[string]int{}
+	// Whether every counted node has the label
+	labeled := map[string]bool{archLabel: true, betaArchLabel: true}
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		arch, ok := node.Labels[archLabel]
+		if !ok {
+			arch = node.Labels[betaArchLabel]
+		}
+		if arch == "" {
+			continue
+		}
+		counts[arch]++
+		for l := range labeled {
+			if _, ok := node.Labels[l]; !ok {
+				labeled[l] = false
+			}
+		}
+	}
+	// Architectures() is sorted, so ties go to the first by name
+	sort.SliceStable(covered, func(i, j int) bool { return counts[covered[i]] > counts[covered[j]] })
+	arch := covered[0]
+	if counts[arch] == 0 {
+		// No node could run the images
+		return "", nil, nil
+	}
+	if len(counts) == 1 {
+		return arch, nil, nil
+	}
+	// The label of the whole cluster, not of some node. Should no label
+	// be on every node, the selector still only admits the architecture.
+	label := archLabel
+	if !labeled[archLabel] && labeled[betaArchLabel] {
+		label = betaArchLabel
+	}
+	return arch, map[string]string{label: arch}, nil
+}
+
+// A copy of the instance whose registry overrides are those of the
+// architecture
+func withArchitecture(instance *servingv1alpha1.KnativeServing, arch string) *servingv1alpha1.KnativeServing {
+	if len(instance.Spec.Registry.Architectures()) == 0 {
+		return instance
+	}
+	result := instance.DeepCopy()
+	result.Spec.Registry = *instance.Spec.Registry.ForArchitecture(arch)
+	return result
+}
+
+// Schedule the control plane deployments on the nodes of the selector
+func pinArchitecture(instance *servingv1alpha1.KnativeServing, resources []unstructured.Unstructured, selector map[string]string) {
+	for i := range resources {
+		u := &resources[i]
+		if u.GetKind() != "Deployment" || u.GetNamespace() != instance.InstallNamespace() {
+			continue
+		}
+		nodeSelector, _, _ := unstructured.NestedStringMap(u.Object, "spec", "template", "spec", "nodeSelector")
+		if nodeSelector == nil {
+			nodeSelector = map[string]string{}
+		}
+		for k, v := range selector {
+			nodeSelector[k] = v
+		}
+		unstructured.SetNestedStringMap(u.Object, nodeSelector, "spec", "template", "spec", "nodeSelector")
+	}
+}
@@ -0,0 +1,104 @@
+package knativeserving
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const archManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: activator
+  namespace: knative-serving
+spec:
+  template:
+    spec:
+      containers:
+      - name: activator
+        image: gcr.io/knative-releases/activator
+`
+
+func newNode(name, arch string) *v1.Node {
+	return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{archLabel: arch}}}
+}
+
+func newLabeledNode(name string, labels map[string]string) *v1.Node {
+	return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestArchitectureOverrides(t *testing.T) {
+	tests := []struct {
+		name         string
+		nodes        []runtime.Object
+		wantImage    string
+		wantSelector map[string]string
+	}{{
+		name:      "single architecture",
+		nodes:     []runtime.Object{newNode("a", "arm64"), newNode("b", "arm64")},
+		wantImage: "example.com/activator:arm64",
+	}, {
+		name:         "mixed architectures",
+		nodes:        []runtime.Object{newNode("a", "arm64"), newNode("b", "arm64"), newNode("c", "amd64")},
+		wantImage:    "example.com/activator:arm64",
+		wantSelector: map[string]string{archLabel: "arm64"},
+	}, {
+		name: "a node without the GA label",
+		nodes: []runtime.Object{
+			newLabeledNode("a", map[string]string{betaArchLabel: "arm64"}),
+			newLabeledNode("b", map[string]string{archLabel: "arm64", betaArchLabel: "arm64"}),
+			newLabeledNode("c", map[string]string{archLabel: "amd64", betaArchLabel: "amd64"}),
+		},
+		wantImage:    "example.com/activator:arm64",
+		wantSelector: map[string]string{betaArchLabel: "arm64"},
+	}, {
+		name: "the last node without the beta label",
+		nodes: []runtime.Object{
+			newLabeledNode("a", map[string]string{archLabel: "arm64", betaArchLabel: "arm64"}),
+			newLabeledNode("b", map[string]string{archLabel: "arm64"}),
+			newLabeledNode("c", map[string]string{archLabel: "amd64", betaArchLabel: "amd64"}),
+			newLabeledNode("d", map[string]string{}),
+		},
+		wantImage:    "example.com/activator:arm64",
+		wantSelector: map[string]string{archLabel: "arm64"},
+	}, {
+		name:      "no node of the architecture",
+		nodes:     []runtime.Object{newNode("a", "amd64")},
+		wantImage: "example.com/activator",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &servingv1alpha1.KnativeServing{
+				ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+				Spec: servingv1alpha1.KnativeServingSpec{
+					Registry: servingv1alpha1.Registry{Override: map[string]string{
+						"activator":       "example.com/activator",
+						"arm64/activator": "example.com/activator:arm64",
+					}},
+				},
+			}
+			c := newFakeClient(newTestScheme(), tt.nodes...)
+			r := &ReconcileKnativeServing{client: c, scheme: newTestScheme(), recorder: record.NewFakeRecorder(10), config: newTestManifest(t, archManifest, c)}
+
+			manifest, err := r.transform(instance, nil)
+			if err != nil {
+				t.Fatalf("transform() = %v", err)
+			}
+			u := manifest.Resources[0]
+			containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+			if got := containers[0].(map[string]interface{})["image"]; got != tt.wantImage {
+				t.Errorf("image = %v, want %v", got, tt.wantImage)
+			}
+			selector, _, _ := unstructured.NestedStringMap(u.Object, "spec", "template", "spec", "nodeSelector")
+			if !reflect.DeepEqual(selector, tt.wantSelector) {
+				t.Errorf("nodeSelector = %v, want %v", selector, tt.wantSelector)
+			}
+		})
+	}
+}
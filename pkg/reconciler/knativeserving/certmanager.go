@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// The cert-manager API the bundled networking-certmanager controller uses
+	certManagerAPIVersion = "certmanager.k8s.io/v1alpha1"
+)
+
+// Report whether cert-manager can issue the certificates of
+// spec.certManager: it must be installed and have the ClusterIssuer
+func (r *ReconcileKnativeServing) checkCertManager(instance *servingv1alpha1.KnativeServing) error {
+	cm := instance.Spec.CertManager
+	if cm == nil || !cm.Enabled {
+		if instance.Status.GetCondition(servingv1alpha1.CertManagerAvailable) == nil {
+			return nil
+		}
+		instance.Status.ClearCertManagerAvailable()
+		return r.updateStatus(instance)
+	}
+	issuer := &unstructured.Unstructured{}
+	issuer.SetAPIVersion(certManagerAPIVersion)
+	issuer.SetKind("ClusterIssuer")
+	err := r.client.Get(context.TODO(), client.ObjectKey{Name: cm.ClusterIssuer}, issuer)
+	switch {
+	case meta.IsNoMatchError(err):
+		instance.Status.MarkCertManagerUnavailable("NotInstalled",
+			fmt.Sprintf("cert-manager's %s API is not installed", certManagerAPIVersion))
+	case errors.IsNotFound(err):
+		instance.Status.MarkCertManagerUnavailable("IssuerNotFound",
+			fmt.Sprintf("ClusterIssuer %s not found", cm.ClusterIssuer))
+	case err != nil:
+		return err
+	default:
+		instance.Status.MarkCertManagerAvailable()
+	}
+	return r.updateStatus(instance)
+}
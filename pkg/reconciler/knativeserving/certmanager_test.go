@@ -0,0 +1,46 @@
+package knativeserving
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func TestCheckCertManagerAvailable(t *testing.T) {
+	instance := newInstance(withCertManager("letsencrypt"))
+	issuer := &unstructured.Unstructured{}
+	issuer.SetAPIVersion(certManagerAPIVersion)
+	issuer.SetKind("ClusterIssuer")
+	issuer.SetName("letsencrypt")
+	r := &ReconcileKnativeServing{client: newFakeClient(newTestScheme(), instance.DeepCopy(), issuer)}
+
+	if err := r.checkCertManager(instance); err != nil {
+		t.Fatalf("checkCertManager() = %v", err)
+	}
+	if !instance.Status.GetCondition(servingv1alpha1.CertManagerAvailable).IsTrue() {
+		t.Errorf("CertManagerAvailable = %v, want True", instance.Status.GetCondition(servingv1alpha1.CertManagerAvailable))
+	}
+}
+
+func TestCheckCertManagerMissingIssuer(t *testing.T) {
+	instance := newInstance(withCertManager("letsencrypt"))
+	r := &ReconcileKnativeServing{client: newFakeClient(newTestScheme(), instance.DeepCopy())}
+
+	if err := r.checkCertManager(instance); err != nil {
+		t.Fatalf("checkCertManager() = %v", err)
+	}
+	condition := instance.Status.GetCondition(servingv1alpha1.CertManagerAvailable)
+	if !condition.IsFalse() || condition.Reason != "IssuerNotFound" {
+		t.Errorf("CertManagerAvailable = %v, want False with reason IssuerNotFound", condition)
+	}
+
+	// Disabling cert-manager clears the condition
+	instance.Spec.CertManager = nil
+	if err := r.checkCertManager(instance); err != nil {
+		t.Fatalf("checkCertManager() = %v", err)
+	}
+	if instance.Status.GetCondition(servingv1alpha1.CertManagerAvailable) != nil {
+		t.Error("expected the condition to be cleared")
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// Ambassador's manifests are meant to be applied to this namespace
+	AmbassadorNamespace  = "ambassador"
+	ambassadorDeployment = "ambassador"
+	// Makes Ambassador serve Knative's Ingresses
+	ambassadorKnativeSupportEnv = "AMBASSADOR_KNATIVE_SUPPORT"
+)
+
+// AmbassadorTransform completes Ambassador's bundled manifests, which
+// leave their namespace to kubectl: it places the namespaced resources
+// and the service account subjects in the ambassador namespace, and
+// enables Knative support on the ambassador deployment.
+func AmbassadorTransform(log logr.Logger) mf.Transformer {
+	inject := mf.InjectNamespace(AmbassadorNamespace)
+	return func(u *unstructured.Unstructured) error {
+		switch u.GetKind() {
+		case "Namespace":
+			return nil
+		case "ClusterRoleBinding":
+			subjects, _, _ := unstructured.NestedSlice(u.Object, "subjects")
+			for _, s := range subjects {
+				if subject, ok := s.(map[string]interface{}); ok && subject["kind"] == "ServiceAccount" && subject["namespace"] == nil {
+					subject["namespace"] = AmbassadorNamespace
+				}
+			}
+			return unstructured.SetNestedSlice(u.Object, subjects, "subjects")
+		}
+		if u.GetNamespace() == "" {
+			if err := inject(u); err != nil {
+				return err
+			}
+		}
+		if u.GetKind() != "Deployment" || u.GetName() != ambassadorDeployment {
+			return nil
+		}
+		containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok || container["name"] != ambassadorDeployment {
+				continue
+			}
+			env, _ := container["env"].([]interface{})
+			found := false
+			for _, e := range env {
+				if v, ok := e.(map[string]interface{}); ok && v["name"] == ambassadorKnativeSupportEnv {
+					v["value"] = "true"
+					delete(v, "valueFrom")
+					found = true
+				}
+			}
+			if !found {
+				env = append(env, map[string]interface{}{"name": ambassadorKnativeSupportEnv, "value": "true"})
+			}
+			container["env"] = env
+			log.V(1).Info("Enabling Knative support", "deployment", u.GetName())
+		}
+		return unstructured.SetNestedSlice(u.Object, containers, "spec", "template", "spec", "containers")
+	}
+}
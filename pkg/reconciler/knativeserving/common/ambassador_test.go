@@ -0,0 +1,72 @@
+package common
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestAmbassadorTransform(t *testing.T) {
+	log := logf.Log.WithName("TestAmbassadorTransform")
+	resources := []unstructured.Unstructured{{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]interface{}{"name": "ambassador"},
+		},
+	}, {
+		Object: map[string]interface{}{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "ClusterRoleBinding",
+			"metadata":   map[string]interface{}{"name": "ambassador"},
+			"subjects": []interface{}{
+				map[string]interface{}{"kind": "ServiceAccount", "name": "ambassador"},
+				map[string]interface{}{"kind": "ServiceAccount", "name": "other", "namespace": "elsewhere"},
+			},
+		},
+	}, {
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "ambassador"},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name": "ambassador",
+								"env": []interface{}{
+									map[string]interface{}{"name": "AMBASSADOR_ID", "value": "default"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+	transform := AmbassadorTransform(log)
+	for i := range resources {
+		assertEqual(t, transform(&resources[i]), nil)
+	}
+
+	assertEqual(t, resources[0].GetNamespace(), "")
+
+	subjects, _, _ := unstructured.NestedSlice(resources[1].Object, "subjects")
+	assertEqual(t, subjects[0].(map[string]interface{})["namespace"], AmbassadorNamespace)
+	assertEqual(t, subjects[1].(map[string]interface{})["namespace"], "elsewhere")
+
+	deployment := resources[2]
+	assertEqual(t, deployment.GetNamespace(), AmbassadorNamespace)
+	containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	env := containers[0].(map[string]interface{})["env"].([]interface{})
+	assertEqual(t, len(env), 2)
+	assertEqual(t, env[1].(map[string]interface{})["name"], "AMBASSADOR_KNATIVE_SUPPORT")
+	assertEqual(t, env[1].(map[string]interface{})["value"], "true")
+
+	// Applying it again is a no-op
+	assertEqual(t, transform(&deployment), nil)
+	containers, _, _ = unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	assertEqual(t, len(containers[0].(map[string]interface{})["env"].([]interface{})), 2)
+}
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestAutoscalerTransform(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	disabled := false
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Config: map[string]map[string]string{
+				"autoscaler": {"stable-window": "120s", "panic-window": "6s"},
+			},
+			Autoscaler: &servingv1alpha1.Autoscaler{
+				StableWindow:      &metav1.Duration{Duration: 90 * time.Second},
+				EnableScaleToZero: &disabled,
+			},
+		},
+	}
+	u := makeUnstructuredConfigMap("config-autoscaler", map[string]interface{}{
+		"container-concurrency-target-default": "100",
+		"enable-scale-to-zero":                 "true",
+	})
+	log := logf.Log.WithName("autoscaler")
+	assertEqual(t, ConfigMapTransform(instance, log)(&u), nil)
+	assertEqual(t, AutoscalerTransform(instance, log)(&u), nil)
+
+	data, _, _ := unstructured.NestedStringMap(u.Object, "data")
+	want := map[string]string{
+		"container-concurrency-target-default": "100",
+		"enable-scale-to-zero":                 "false",
+		"panic-window":                         "6s",
+		"stable-window":                        "1m30s",
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("data = %v, want %v", data, want)
+	}
+}
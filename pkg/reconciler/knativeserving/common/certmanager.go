@@ -0,0 +1,44 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+// CertManagerTransform has the routes' certificates issued by the
+// ClusterIssuer of spec.certManager, when it's enabled
+func CertManagerTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		cm := instance.Spec.CertManager
+		if cm == nil || !cm.Enabled || u.GetKind() != "ConfigMap" {
+			return nil
+		}
+		switch u.GetName() {
+		case "config-certmanager":
+			issuerRef := fmt.Sprintf("kind: ClusterIssuer\nname: %s\n", cm.ClusterIssuer)
+			UpdateConfigMap(u, map[string]string{"issuerRef": issuerRef}, log)
+		case "config-network":
+			UpdateConfigMap(u, map[string]string{"autoTLS": "Enabled"}, log)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,36 @@
+package common
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestCertManagerTransform(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			CertManager: &servingv1alpha1.CertManager{Enabled: true, ClusterIssuer: "letsencrypt"},
+		},
+	}
+	certs := makeUnstructuredConfigMap("config-certmanager", map[string]interface{}{"_example": "docs"})
+	network := makeUnstructuredConfigMap("config-network", map[string]interface{}{"autoTLS": "Disabled"})
+	transform := CertManagerTransform(instance, logf.Log.WithName("certmanager"))
+	assertEqual(t, transform(&certs), nil)
+	assertEqual(t, transform(&network), nil)
+
+	issuerRef, _, _ := unstructured.NestedString(certs.Object, "data", "issuerRef")
+	assertEqual(t, issuerRef, "kind: ClusterIssuer\nname: letsencrypt\n")
+	autoTLS, _, _ := unstructured.NestedString(network.Object, "data", "autoTLS")
+	assertEqual(t, autoTLS, "Enabled")
+}
+
+func TestCertManagerTransformDisabled(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{}
+	network := makeUnstructuredConfigMap("config-network", map[string]interface{}{"autoTLS": "Disabled"})
+	assertEqual(t, CertManagerTransform(instance, logf.Log.WithName("certmanager"))(&network), nil)
+	autoTLS, _, _ := unstructured.NestedString(network.Object, "data", "autoTLS")
+	assertEqual(t, autoTLS, "Disabled")
+}
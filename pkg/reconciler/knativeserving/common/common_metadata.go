@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+// CommonMetadataTransform stamps the labels and annotations of
+// spec.commonLabels and spec.commonAnnotations on every resource, and
+// on the pods of the workloads, so that admission policies requiring
+// them admit both. Those the manifest already sets are left alone,
+// since Knative selects its pods by them.
+func CommonMetadataTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		labels, annotations := instance.Spec.CommonLabels, instance.Spec.CommonAnnotations
+		if len(labels) == 0 && len(annotations) == 0 {
+			return nil
+		}
+		paths := [][]string{{"metadata"}}
+		switch u.GetKind() {
+		case "Deployment", "DaemonSet", "StatefulSet", "Job":
+			paths = append(paths, []string{"spec", "template", "metadata"})
+		}
+		for _, path := range paths {
+			for field, values := range map[string]map[string]string{"labels": labels, "annotations": annotations} {
+				if err := addMissing(u, values, append(path, field)...); err != nil {
+					return err
+				}
+			}
+		}
+		log.V(1).Info("Stamped common metadata", "kind", u.GetKind(), "name", u.GetName())
+		return nil
+	}
+}
+
+func addMissing(u *unstructured.Unstructured, values map[string]string, fields ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	existing, _, err := unstructured.NestedStringMap(u.Object, fields...)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		existing = map[string]string{}
+	}
+	for k, v := range values {
+		if _, ok := existing[k]; !ok {
+			existing[k] = v
+		}
+	}
+	return unstructured.SetNestedStringMap(u.Object, existing, fields...)
+}
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestCommonMetadataTransform(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			CommonLabels:      map[string]string{"example.com/cost-center": "cc-1234", "app": "knative"},
+			CommonAnnotations: map[string]string{"example.com/owner": "platform-team"},
+		},
+	}
+	transform := CommonMetadataTransform(instance, logf.Log.WithName("common-metadata"))
+
+	u := makeUnstructuredDeploymentWithVolumes(t, &deploymentOverridesTest{deploymentName: "controller"})
+	u.SetLabels(map[string]string{"app": "controller"})
+	unstructured.SetNestedStringMap(u.Object, map[string]string{"app": "controller"}, "spec", "template", "metadata", "labels")
+	assertEqual(t, transform(&u), nil)
+	for _, path := range [][]string{{"metadata"}, {"spec", "template", "metadata"}} {
+		labels, _, _ := unstructured.NestedStringMap(u.Object, append(path, "labels")...)
+		assertEqual(t, labels["example.com/cost-center"], "cc-1234")
+		// The manifest's own labels select the pods
+		assertEqual(t, labels["app"], "controller")
+		annotations, _, _ := unstructured.NestedStringMap(u.Object, append(path, "annotations")...)
+		assertEqual(t, annotations["example.com/owner"], "platform-team")
+	}
+
+	cm := makeUnstructuredConfigMap("config-network", nil)
+	assertEqual(t, transform(&cm), nil)
+	assertEqual(t, cm.GetLabels()["example.com/cost-center"], "cc-1234")
+	assertEqual(t, cm.GetAnnotations()["example.com/owner"], "platform-team")
+	if _, found, _ := unstructured.NestedFieldNoCopy(cm.Object, "spec"); found {
+		t.Error("ConfigMap has no pod template to stamp")
+	}
+}
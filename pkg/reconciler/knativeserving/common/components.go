@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	certificateProviderLabel = "networking.knative.dev/certificate-provider"
+	metricProviderLabel      = "autoscaling.knative.dev/metric-provider"
+	monitoringNamespace      = "knative-monitoring"
+)
+
+// Recognize the resources of each optional component
+var components = map[string]func(u *unstructured.Unstructured) bool{
+	servingv1alpha1.CertManagerComponent: func(u *unstructured.Unstructured) bool {
+		return u.GetLabels()[certificateProviderLabel] == "cert-manager"
+	},
+	servingv1alpha1.CustomMetricsComponent: func(u *unstructured.Unstructured) bool {
+		return u.GetLabels()[metricProviderLabel] == "custom-metrics"
+	},
+	servingv1alpha1.AutoscalerHPAComponent: func(u *unstructured.Unstructured) bool {
+		return u.GetKind() == "Deployment" && u.GetName() == "autoscaler-hpa"
+	},
+	servingv1alpha1.MonitoringComponent: func(u *unstructured.Unstructured) bool {
+		return u.GetNamespace() == monitoringNamespace ||
+			(u.GetKind() == "Namespace" && u.GetName() == monitoringNamespace)
+	},
+	servingv1alpha1.ClusterLocalGatewayComponent: func(u *unstructured.Unstructured) bool {
+		return u.GetKind() == "Gateway" && u.GetName() == ClusterLocalGateway
+	},
+}
+
+// FilterComponents removes the resources of the disabled components
+func FilterComponents(resources []unstructured.Unstructured, instance *servingv1alpha1.KnativeServing) []unstructured.Unstructured {
+	if len(instance.Spec.DisabledComponents) == 0 {
+		return resources
+	}
+	result := make([]unstructured.Unstructured, 0, len(resources))
+	for i := range resources {
+		if disabled(&resources[i], instance) {
+			log.V(1).Info("Skipping disabled component", "kind", resources[i].GetKind(), "name", resources[i].GetName())
+			continue
+		}
+		result = append(result, resources[i])
+	}
+	return result
+}
+
+func disabled(u *unstructured.Unstructured, instance *servingv1alpha1.KnativeServing) bool {
+	for _, component := range instance.Spec.DisabledComponents {
+		if matches, ok := components[component]; ok && matches(u) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestFilterComponents(t *testing.T) {
+	resource := func(kind, namespace, name string, labels map[string]string) unstructured.Unstructured {
+		u := unstructured.Unstructured{}
+		u.SetKind(kind)
+		u.SetNamespace(namespace)
+		u.SetName(name)
+		u.SetLabels(labels)
+		return u
+	}
+	resources := []unstructured.Unstructured{
+		resource("Deployment", "knative-serving", "controller", nil),
+		resource("Deployment", "knative-serving", "networking-certmanager", map[string]string{certificateProviderLabel: "cert-manager"}),
+		resource("APIService", "", "v1beta1.custom.metrics.k8s.io", map[string]string{metricProviderLabel: "custom-metrics"}),
+		resource("Deployment", "knative-serving", "autoscaler-hpa", nil),
+		resource("Namespace", "", "knative-monitoring", nil),
+		resource("Deployment", "knative-monitoring", "grafana", nil),
+		resource("Gateway", "knative-serving", "cluster-local-gateway", nil),
+	}
+	names := func(resources []unstructured.Unstructured) []string {
+		var result []string
+		for _, u := range resources {
+			result = append(result, u.GetName())
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		disabled []string
+		expected []string
+	}{{
+		name:     "NothingDisabled",
+		expected: names(resources),
+	}, {
+		name:     "CertManager",
+		disabled: []string{"cert-manager"},
+		expected: []string{"controller", "v1beta1.custom.metrics.k8s.io", "autoscaler-hpa", "knative-monitoring", "grafana", "cluster-local-gateway"},
+	}, {
+		name:     "Several",
+		disabled: []string{"custom-metrics", "autoscaler-hpa", "monitoring", "cluster-local-gateway"},
+		expected: []string{"controller", "networking-certmanager"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &servingv1alpha1.KnativeServing{
+				Spec: servingv1alpha1.KnativeServingSpec{DisabledComponents: tt.disabled},
+			}
+			got := names(FilterComponents(resources, instance))
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("FilterComponents() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGatewayTransformDisabledClusterLocalGateway(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	u := makeUnstructuredConfigMap("config-istio", map[string]interface{}{
+		"local-gateway.cluster-local-gateway": "cluster-local-gateway.istio-system.svc.cluster.local",
+	})
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{DisabledComponents: []string{"cluster-local-gateway"}},
+	}
+	err := GatewayTransform(runtime.NewScheme(), instance, logf.Log.WithName("disabled"))(&u)
+	assertEqual(t, err, nil)
+	_, found, _ := unstructured.NestedString(u.Object, "data", "local-gateway.cluster-local-gateway")
+	assertEqual(t, found, false)
+	mesh, _, _ := unstructured.NestedString(u.Object, "data", "local-gateway.mesh")
+	assertEqual(t, mesh, "mesh")
+}
@@ -16,17 +16,22 @@ limitations under the License.
 package common
 
 import (
+	"strings"
+
 	"github.com/go-logr/logr"
 	mf "github.com/jcrossley3/manifestival"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
 )
 
+// The spec.config keys are the names of the upstream ConfigMaps without this prefix
+const configMapPrefix = "config-"
+
 func ConfigMapTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
 	return func(u *unstructured.Unstructured) error {
 		// Let any config in instance override everything else
-		if u.GetKind() == "ConfigMap" {
-			if data, ok := instance.Spec.Config[u.GetName()[len(`config-`):]]; ok {
+		if u.GetKind() == "ConfigMap" && strings.HasPrefix(u.GetName(), configMapPrefix) {
+			if data, ok := instance.Spec.Config[strings.TrimPrefix(u.GetName(), configMapPrefix)]; ok {
 				UpdateConfigMap(u, data, log)
 			}
 		}
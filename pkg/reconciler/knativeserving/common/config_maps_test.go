@@ -0,0 +1,103 @@
+package common
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+type configMapTransformTest struct {
+	name     string
+	cmName   string
+	in       map[string]interface{}
+	config   map[string]map[string]string
+	expected map[string]string
+}
+
+var configMapTransformTests = []configMapTransformTest{
+	{
+		name:   "OverridesMatchingConfigMap",
+		cmName: "config-autoscaler",
+		in: map[string]interface{}{
+			"stable-window": "60s",
+			"tick-interval": "2s",
+		},
+		config: map[string]map[string]string{
+			"autoscaler": {
+				"stable-window":        "120s",
+				"enable-scale-to-zero": "false",
+			},
+		},
+		expected: map[string]string{
+			"stable-window":        "120s",
+			"tick-interval":        "2s",
+			"enable-scale-to-zero": "false",
+		},
+	},
+	{
+		name:   "IgnoresOtherConfigMaps",
+		cmName: "config-network",
+		in: map[string]interface{}{
+			"istio.sidecar.includeOutboundIPRanges": "*",
+		},
+		config: map[string]map[string]string{
+			"autoscaler": {
+				"stable-window": "120s",
+			},
+		},
+		expected: map[string]string{
+			"istio.sidecar.includeOutboundIPRanges": "*",
+		},
+	},
+	{
+		name:   "IgnoresUnprefixedConfigMaps",
+		cmName: "istio",
+		in: map[string]interface{}{
+			"mesh": "x",
+		},
+		config: map[string]map[string]string{
+			"istio": {
+				"mesh": "y",
+			},
+		},
+		expected: map[string]string{
+			"mesh": "x",
+		},
+	},
+}
+
+func TestConfigMapTransform(t *testing.T) {
+	for _, tt := range configMapTransformTests {
+		t.Run(tt.name, func(t *testing.T) {
+			runConfigMapTransformTest(t, &tt)
+		})
+	}
+}
+
+func runConfigMapTransformTest(t *testing.T, tt *configMapTransformTest) {
+	log := logf.Log.WithName(tt.name)
+	logf.SetLogger(logf.ZapLogger(true))
+	u := makeUnstructuredConfigMap(tt.cmName, tt.in)
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Config: tt.config,
+		},
+	}
+	err := ConfigMapTransform(instance, log)(&u)
+	assertEqual(t, err, nil)
+	data, _, _ := unstructured.NestedStringMap(u.Object, "data")
+	assertEqual(t, len(data), len(tt.expected))
+	for k, v := range tt.expected {
+		assertEqual(t, data[k], v)
+	}
+}
+
+func makeUnstructuredConfigMap(name string, data map[string]interface{}) unstructured.Unstructured {
+	u := unstructured.Unstructured{Object: map[string]interface{}{"data": data}}
+	u.SetAPIVersion("v1")
+	u.SetKind("ConfigMap")
+	u.SetName(name)
+	return u
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"strings"
+
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	contourConfigMap = "config-contour"
+	visibilityKey    = "visibility"
+
+	// The visibilities of routes, as config-contour names them
+	externalVisibility = "ExternalIP"
+	internalVisibility = "ClusterLocal"
+)
+
+var (
+	// The Contours net-contour's release installs
+	defaultContours = map[string]servingv1alpha1.ContourVisibility{
+		externalVisibility: {Class: "contour-external", Service: "contour-external/envoy"},
+		internalVisibility: {Class: "contour-internal", Service: "contour-internal/envoy"},
+	}
+)
+
+// ContourTransform writes the Contours configured by
+// spec.ingress.contour to the visibility of config-contour, leaving
+// the upstream one when neither is
+func ContourTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if u.GetKind() != "ConfigMap" || u.GetName() != contourConfigMap {
+			return nil
+		}
+		ingress := instance.Spec.Ingress
+		if ingress == nil || ingress.Contour.External == nil && ingress.Contour.Internal == nil {
+			return nil
+		}
+		visibility, err := yaml.Marshal(map[string]servingv1alpha1.ContourVisibility{
+			externalVisibility: contourVisibility(instance, externalVisibility),
+			internalVisibility: contourVisibility(instance, internalVisibility),
+		})
+		if err != nil {
+			return err
+		}
+		UpdateConfigMap(u, map[string]string{visibilityKey: string(visibility)}, log)
+		return nil
+	}
+}
+
+// ContourService returns the name and namespace of the envoy Service
+// serving publicly visible routes
+func ContourService(instance *servingv1alpha1.KnativeServing) (string, string) {
+	namespace, name := splitKey(contourVisibility(instance, externalVisibility).Service)
+	return name, namespace
+}
+
+// The configured Contour of the visibility, completed by the default
+func contourVisibility(instance *servingv1alpha1.KnativeServing, visibility string) servingv1alpha1.ContourVisibility {
+	result := defaultContours[visibility]
+	var override *servingv1alpha1.ContourVisibility
+	if ingress := instance.Spec.Ingress; ingress != nil {
+		override = ingress.Contour.External
+		if visibility == internalVisibility {
+			override = ingress.Contour.Internal
+		}
+	}
+	if override != nil {
+		if override.Class != "" {
+			result.Class = override.Class
+		}
+		if override.Service != "" {
+			result.Service = override.Service
+		}
+	}
+	return result
+}
+
+func splitKey(key string) (string, string) {
+	parts := strings.SplitN(key, "/", 2)
+	if len(parts) < 2 {
+		return "", key
+	}
+	return parts[0], parts[1]
+}
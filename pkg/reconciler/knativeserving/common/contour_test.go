@@ -0,0 +1,59 @@
+package common
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestContourTransformKeepsUpstreamVisibility(t *testing.T) {
+	log := logf.Log.WithName("TestContourTransformKeepsUpstreamVisibility")
+	u := makeUnstructuredConfigMap("config-contour", map[string]interface{}{"visibility": "upstream"})
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Ingress: &servingv1alpha1.IngressConfigs{Contour: servingv1alpha1.ContourIngressConfiguration{Enabled: true}},
+		},
+	}
+	assertEqual(t, ContourTransform(instance, log)(&u), nil)
+	visibility, _, _ := unstructured.NestedString(u.Object, "data", "visibility")
+	assertEqual(t, visibility, "upstream")
+}
+
+func TestContourService(t *testing.T) {
+	tests := []struct {
+		name      string
+		ingress   *servingv1alpha1.IngressConfigs
+		service   string
+		namespace string
+	}{{
+		name:      "Default",
+		service:   "envoy",
+		namespace: "contour-external",
+	}, {
+		name: "ClassOnly",
+		ingress: &servingv1alpha1.IngressConfigs{Contour: servingv1alpha1.ContourIngressConfiguration{
+			External: &servingv1alpha1.ContourVisibility{Class: "contour-public"},
+		}},
+		service:   "envoy",
+		namespace: "contour-external",
+	}, {
+		name: "Service",
+		ingress: &servingv1alpha1.IngressConfigs{Contour: servingv1alpha1.ContourIngressConfiguration{
+			External: &servingv1alpha1.ContourVisibility{Service: "projectcontour/envoy-public"},
+		}},
+		service:   "envoy-public",
+		namespace: "projectcontour",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &servingv1alpha1.KnativeServing{
+				Spec: servingv1alpha1.KnativeServingSpec{Ingress: tt.ingress},
+			}
+			name, namespace := ContourService(instance)
+			assertEqual(t, name, tt.service)
+			assertEqual(t, namespace, tt.namespace)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	customCertsDeployment = "controller"
+	customCertsVolume     = "custom-certs"
+	customCertsMountPath  = "/custom-certs"
+	// Go's crypto/x509 reads the trusted CAs from this directory
+	customCertsEnvName = "SSL_CERT_DIR"
+
+	customCertsConfigMap = "ConfigMap"
+	customCertsSecret    = "Secret"
+)
+
+func CustomCertsTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		certs := instance.Spec.ControllerCustomCerts
+		if certs == nil || u.GetKind() != "Deployment" || u.GetName() != customCertsDeployment {
+			return nil
+		}
+		volume := corev1.Volume{Name: customCertsVolume}
+		switch certs.Type {
+		case customCertsConfigMap:
+			volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: certs.Name},
+			}
+		case customCertsSecret:
+			volume.Secret = &corev1.SecretVolumeSource{SecretName: certs.Name}
+		default:
+			return fmt.Errorf("unknown controllerCustomCerts type %q, must be %s or %s", certs.Type, customCertsConfigMap, customCertsSecret)
+		}
+
+		deployment := &appsv1.Deployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment); err != nil {
+			log.Error(err, "Error converting Unstructured to Deployment", "unstructured", u, "deployment", deployment)
+			return err
+		}
+		log.V(1).Info("Mounting custom certs", "deployment", u.GetName(), "certs", certs)
+		override := &servingv1alpha1.DeploymentOverride{
+			Volumes:      []corev1.Volume{volume},
+			VolumeMounts: []corev1.VolumeMount{{Name: customCertsVolume, MountPath: customCertsMountPath, ReadOnly: true}},
+		}
+		if err := addVolumes(deployment, override); err != nil {
+			return err
+		}
+		containers := deployment.Spec.Template.Spec.Containers
+		for i := range containers {
+			setEnv(&containers[i], customCertsEnvName, customCertsMountPath)
+		}
+		return updateUnstructured(u, deployment, log)
+	}
+}
+
+// setEnv sets the variable in the container, replacing any previous value
+func setEnv(container *corev1.Container, name, value string) {
+	for i := range container.Env {
+		if container.Env[i].Name == name {
+			container.Env[i].Value = value
+			container.Env[i].ValueFrom = nil
+			return
+		}
+	}
+	container.Env = append(container.Env, corev1.EnvVar{Name: name, Value: value})
+}
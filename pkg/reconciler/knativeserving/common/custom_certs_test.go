@@ -0,0 +1,96 @@
+package common
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+type customCertsTest struct {
+	name            string
+	deploymentName  string
+	certs           *servingv1alpha1.CustomCerts
+	expectError     bool
+	expectConfigMap string
+	expectSecret    string
+}
+
+var customCertsTests = []customCertsTest{
+	{
+		name:            "MountsConfigMap",
+		deploymentName:  "controller",
+		certs:           &servingv1alpha1.CustomCerts{Type: "ConfigMap", Name: "my-cas"},
+		expectConfigMap: "my-cas",
+	},
+	{
+		name:           "MountsSecret",
+		deploymentName: "controller",
+		certs:          &servingv1alpha1.CustomCerts{Type: "Secret", Name: "my-cas"},
+		expectSecret:   "my-cas",
+	},
+	{
+		name:           "IgnoresOtherDeployments",
+		deploymentName: "activator",
+		certs:          &servingv1alpha1.CustomCerts{Type: "Secret", Name: "my-cas"},
+	},
+	{
+		name:           "IgnoresUnsetCerts",
+		deploymentName: "controller",
+	},
+	{
+		name:           "RejectsUnknownType",
+		deploymentName: "controller",
+		certs:          &servingv1alpha1.CustomCerts{Type: "Volume", Name: "my-cas"},
+		expectError:    true,
+	},
+}
+
+func TestCustomCertsTransform(t *testing.T) {
+	for _, tt := range customCertsTests {
+		t.Run(tt.name, func(t *testing.T) {
+			runCustomCertsTransformTest(t, &tt)
+		})
+	}
+}
+
+func runCustomCertsTransformTest(t *testing.T, tt *customCertsTest) {
+	log := logf.Log.WithName(tt.name)
+	logf.SetLogger(logf.ZapLogger(true))
+	u := makeUnstructuredDeploymentWithVolumes(t, &deploymentOverridesTest{deploymentName: tt.deploymentName})
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			ControllerCustomCerts: tt.certs,
+		},
+	}
+	err := CustomCertsTransform(instance, log)(&u)
+	if tt.expectError {
+		if err == nil {
+			t.Fatalf("expected an error for an unknown type")
+		}
+		return
+	}
+	assertEqual(t, err, nil)
+
+	deployment := &appsv1.Deployment{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment)
+	assertEqual(t, err, nil)
+	podSpec := deployment.Spec.Template.Spec
+	if tt.expectConfigMap == "" && tt.expectSecret == "" {
+		assertEqual(t, len(podSpec.Volumes), 0)
+		assertEqual(t, len(podSpec.Containers[0].Env), 0)
+		return
+	}
+	assertEqual(t, len(podSpec.Volumes), 1)
+	if tt.expectConfigMap != "" {
+		assertEqual(t, podSpec.Volumes[0].ConfigMap.Name, tt.expectConfigMap)
+	} else {
+		assertEqual(t, podSpec.Volumes[0].Secret.SecretName, tt.expectSecret)
+	}
+	container := podSpec.Containers[0]
+	assertEqual(t, container.VolumeMounts[0].MountPath, "/custom-certs")
+	assertEqual(t, container.Env[0].Name, "SSL_CERT_DIR")
+	assertEqual(t, container.Env[0].Value, "/custom-certs")
+}
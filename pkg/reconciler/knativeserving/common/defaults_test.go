@@ -0,0 +1,39 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestDefaultsTransform(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	timeout := int64(60)
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Config: map[string]map[string]string{
+				"defaults": {"revision-timeout-seconds": "30", "container-concurrency": "10"},
+			},
+			Defaults: &servingv1alpha1.Defaults{RevisionTimeoutSeconds: &timeout},
+		},
+	}
+	u := makeUnstructuredConfigMap("config-defaults", map[string]interface{}{
+		"max-revision-timeout-seconds": "600",
+	})
+	log := logf.Log.WithName("defaults")
+	assertEqual(t, ConfigMapTransform(instance, log)(&u), nil)
+	assertEqual(t, DefaultsTransform(instance, log)(&u), nil)
+
+	data, _, _ := unstructured.NestedStringMap(u.Object, "data")
+	want := map[string]string{
+		"revision-timeout-seconds":     "60",
+		"max-revision-timeout-seconds": "600",
+		"container-concurrency":        "10",
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("data = %v, want %v", data, want)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func DeploymentOverridesTransform(scheme *runtime.Scheme, instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if u.GetKind() == "Deployment" {
+			for i := range instance.Spec.DeploymentOverrides {
+				override := &instance.Spec.DeploymentOverrides[i]
+				if override.Name == u.GetName() {
+					return overrideDeployment(u, override, autoscaled(instance, u.GetName()), log)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func overrideDeployment(u *unstructured.Unstructured, override *servingv1alpha1.DeploymentOverride, autoscaled bool, log logr.Logger) error {
+	deployment := &appsv1.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment); err != nil {
+		log.Error(err, "Error converting Unstructured to Deployment", "unstructured", u, "deployment", deployment)
+		return err
+	}
+	log.V(1).Info("Overriding Deployment", "name", u.GetName(), "override", override)
+
+	if err := addVolumes(deployment, override); err != nil {
+		return err
+	}
+	placePods(deployment, override)
+	if err := overrideEnv(deployment, override); err != nil {
+		return err
+	}
+	if override.Replicas != nil && !autoscaled {
+		replicas := *override.Replicas
+		deployment.Spec.Replicas = &replicas
+	}
+	if err := updateUnstructured(u, deployment, log); err != nil {
+		return err
+	}
+
+	log.V(1).Info("Finished conversion", "name", u.GetName(), "unstructured", u.Object)
+	return nil
+}
+
+// The replicas a deployment override sets, if any
+func replicasOverride(instance *servingv1alpha1.KnativeServing, name string) *int32 {
+	for i := range instance.Spec.DeploymentOverrides {
+		if override := &instance.Spec.DeploymentOverrides[i]; override.Name == name {
+			return override.Replicas
+		}
+	}
+	return nil
+}
+
+// addVolumes appends the override's volumes to the pod template and its volume
+// mounts to every container, refusing to shadow anything the manifest defines
+func addVolumes(deployment *appsv1.Deployment, override *servingv1alpha1.DeploymentOverride) error {
+	podSpec := &deployment.Spec.Template.Spec
+	for _, volume := range override.Volumes {
+		for _, existing := range podSpec.Volumes {
+			if existing.Name == volume.Name {
+				return fmt.Errorf("volume %q already exists in deployment %q", volume.Name, deployment.GetName())
+			}
+		}
+		podSpec.Volumes = append(podSpec.Volumes, volume)
+	}
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		for _, mount := range override.VolumeMounts {
+			for _, existing := range container.VolumeMounts {
+				if existing.Name == mount.Name || existing.MountPath == mount.MountPath {
+					return fmt.Errorf("volume mount %q at %q collides with an existing mount in container %q of deployment %q",
+						mount.Name, mount.MountPath, container.Name, deployment.GetName())
+				}
+			}
+			container.VolumeMounts = append(container.VolumeMounts, mount)
+		}
+	}
+	return nil
+}
+
+// overrideEnv sets the override's environment variables in every container,
+// refusing to replace those the manifest wires up from elsewhere, e.g.
+// the namespace of the pod
+func overrideEnv(deployment *appsv1.Deployment, override *servingv1alpha1.DeploymentOverride) error {
+	podSpec := &deployment.Spec.Template.Spec
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		for _, env := range override.Env {
+			existing := findEnv(container, env.Name)
+			switch {
+			case existing == nil:
+				container.Env = append(container.Env, env)
+			case existing.ValueFrom != nil:
+				return fmt.Errorf("env var %q of container %q of deployment %q is set from another source and can't be overridden",
+					env.Name, container.Name, deployment.GetName())
+			default:
+				*existing = env
+			}
+		}
+	}
+	return nil
+}
+
+func findEnv(container *corev1.Container, name string) *corev1.EnvVar {
+	for i := range container.Env {
+		if container.Env[i].Name == name {
+			return &container.Env[i]
+		}
+	}
+	return nil
+}
+
+// placePods applies the override's node placement to the pod template
+func placePods(deployment *appsv1.Deployment, override *servingv1alpha1.DeploymentOverride) {
+	podSpec := &deployment.Spec.Template.Spec
+	if len(override.NodeSelector) > 0 {
+		if podSpec.NodeSelector == nil {
+			podSpec.NodeSelector = map[string]string{}
+		}
+		for key, value := range override.NodeSelector {
+			podSpec.NodeSelector[key] = value
+		}
+	}
+	podSpec.Tolerations = append(podSpec.Tolerations, override.Tolerations...)
+	if override.Affinity != nil {
+		podSpec.Affinity = override.Affinity.DeepCopy()
+	}
+	if override.PriorityClassName != "" {
+		podSpec.PriorityClassName = override.PriorityClassName
+	}
+}
@@ -0,0 +1,319 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+type deploymentOverridesTest struct {
+	name           string
+	deploymentName string
+	volumes        []corev1.Volume
+	volumeMounts   []corev1.VolumeMount
+	overrides      []servingv1alpha1.DeploymentOverride
+	expectError    bool
+	expectedVolume string
+	expectedMount  string
+}
+
+var customVolume = corev1.Volume{
+	Name: "custom-certs",
+	VolumeSource: corev1.VolumeSource{
+		Secret: &corev1.SecretVolumeSource{SecretName: "custom-certs"},
+	},
+}
+
+var customVolumeMount = corev1.VolumeMount{
+	Name:      "custom-certs",
+	MountPath: "/etc/custom-certs",
+}
+
+var deploymentOverridesTests = []deploymentOverridesTest{
+	{
+		name:           "AddsVolumeAndMountToController",
+		deploymentName: "controller",
+		overrides: []servingv1alpha1.DeploymentOverride{{
+			Name:         "controller",
+			Volumes:      []corev1.Volume{customVolume},
+			VolumeMounts: []corev1.VolumeMount{customVolumeMount},
+		}},
+		expectedVolume: "custom-certs",
+		expectedMount:  "/etc/custom-certs",
+	},
+	{
+		name:           "IgnoresOtherDeployments",
+		deploymentName: "activator",
+		overrides: []servingv1alpha1.DeploymentOverride{{
+			Name:         "controller",
+			Volumes:      []corev1.Volume{customVolume},
+			VolumeMounts: []corev1.VolumeMount{customVolumeMount},
+		}},
+	},
+	{
+		name:           "RejectsCollidingVolume",
+		deploymentName: "controller",
+		volumes: []corev1.Volume{{
+			Name: "custom-certs",
+		}},
+		overrides: []servingv1alpha1.DeploymentOverride{{
+			Name:    "controller",
+			Volumes: []corev1.Volume{customVolume},
+		}},
+		expectError: true,
+	},
+	{
+		name:           "RejectsCollidingMountPath",
+		deploymentName: "controller",
+		volumeMounts: []corev1.VolumeMount{{
+			Name:      "existing",
+			MountPath: "/etc/custom-certs",
+		}},
+		overrides: []servingv1alpha1.DeploymentOverride{{
+			Name:         "controller",
+			VolumeMounts: []corev1.VolumeMount{customVolumeMount},
+		}},
+		expectError: true,
+	},
+}
+
+func TestDeploymentOverridesTransform(t *testing.T) {
+	for _, tt := range deploymentOverridesTests {
+		t.Run(tt.name, func(t *testing.T) {
+			runDeploymentOverridesTransformTest(t, &tt)
+		})
+	}
+}
+
+func runDeploymentOverridesTransformTest(t *testing.T, tt *deploymentOverridesTest) {
+	log := logf.Log.WithName(tt.name)
+	logf.SetLogger(logf.ZapLogger(true))
+	testScheme := runtime.NewScheme()
+	u := makeUnstructuredDeploymentWithVolumes(t, tt)
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			DeploymentOverrides: tt.overrides,
+		},
+	}
+	err := DeploymentOverridesTransform(testScheme, instance, log)(&u)
+	if tt.expectError {
+		if err == nil {
+			t.Fatalf("expected an error for colliding volumes")
+		}
+		return
+	}
+	assertEqual(t, err, nil)
+
+	deployment := &appsv1.Deployment{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment)
+	assertEqual(t, err, nil)
+	podSpec := deployment.Spec.Template.Spec
+	if tt.expectedVolume == "" {
+		assertEqual(t, len(podSpec.Volumes), 0)
+		assertEqual(t, len(podSpec.Containers[0].VolumeMounts), 0)
+		return
+	}
+	assertEqual(t, len(podSpec.Volumes), 1)
+	assertEqual(t, podSpec.Volumes[0].Name, tt.expectedVolume)
+	assertEqual(t, podSpec.Volumes[0].Secret.SecretName, tt.expectedVolume)
+	assertEqual(t, len(podSpec.Containers[0].VolumeMounts), 1)
+	assertEqual(t, podSpec.Containers[0].VolumeMounts[0].MountPath, tt.expectedMount)
+}
+
+func makeUnstructuredDeploymentWithVolumes(t *testing.T, tt *deploymentOverridesTest) unstructured.Unstructured {
+	deployment := appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: tt.deploymentName,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Volumes: tt.volumes,
+					Containers: []corev1.Container{{
+						Name:         tt.deploymentName,
+						VolumeMounts: tt.volumeMounts,
+					}},
+				},
+			},
+		},
+	}
+	result, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&deployment)
+	if err != nil {
+		t.Fatalf("Could not create unstructured deployment object: %v, err: %v", result, err)
+	}
+	return unstructured.Unstructured{
+		Object: result,
+	}
+}
+
+func TestDeploymentOverridesPlacement(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	u := makeUnstructuredDeploymentWithVolumes(t, &deploymentOverridesTest{deploymentName: "controller"})
+	unstructured.SetNestedStringMap(u.Object, map[string]string{"kubernetes.io/os": "linux"},
+		"spec", "template", "spec", "nodeSelector")
+	infra := corev1.Toleration{Key: "node-role.kubernetes.io/infra", Effect: corev1.TaintEffectNoSchedule}
+	affinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key:      "node-role.kubernetes.io/infra",
+						Operator: corev1.NodeSelectorOpExists,
+					}},
+				}},
+			},
+		},
+	}
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			DeploymentOverrides: []servingv1alpha1.DeploymentOverride{{
+				Name:         "controller",
+				NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+				Tolerations:  []corev1.Toleration{infra},
+				Affinity:     affinity,
+			}},
+		},
+	}
+	err := DeploymentOverridesTransform(runtime.NewScheme(), instance, logf.Log.WithName("placement"))(&u)
+	assertEqual(t, err, nil)
+
+	deployment := &appsv1.Deployment{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment)
+	assertEqual(t, err, nil)
+	podSpec := deployment.Spec.Template.Spec
+	wantSelector := map[string]string{
+		"kubernetes.io/os":              "linux",
+		"node-role.kubernetes.io/infra": "",
+	}
+	if !reflect.DeepEqual(podSpec.NodeSelector, wantSelector) {
+		t.Errorf("nodeSelector = %v, want %v", podSpec.NodeSelector, wantSelector)
+	}
+	if !reflect.DeepEqual(podSpec.Tolerations, []corev1.Toleration{infra}) {
+		t.Errorf("tolerations = %v, want %v", podSpec.Tolerations, infra)
+	}
+	if !reflect.DeepEqual(podSpec.Affinity, affinity) {
+		t.Errorf("affinity = %v, want %v", podSpec.Affinity, affinity)
+	}
+}
+
+func TestDeploymentOverridesEnv(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	transform := func(env ...corev1.EnvVar) (*appsv1.Deployment, error) {
+		deployment := &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "controller"},
+		}
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{
+			Name: "controller",
+			Env: []corev1.EnvVar{{Name: "GOGC", Value: "100"}, {
+				Name:      "SYSTEM_NAMESPACE",
+				ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"}},
+			}},
+		}}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deployment)
+		assertEqual(t, err, nil)
+		u := &unstructured.Unstructured{Object: obj}
+		instance := &servingv1alpha1.KnativeServing{
+			Spec: servingv1alpha1.KnativeServingSpec{
+				DeploymentOverrides: []servingv1alpha1.DeploymentOverride{{Name: "controller", Env: env}},
+			},
+		}
+		if err := DeploymentOverridesTransform(runtime.NewScheme(), instance, logf.Log.WithName("env"))(u); err != nil {
+			return nil, err
+		}
+		result := &appsv1.Deployment{}
+		assertEqual(t, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, result), nil)
+		return result, nil
+	}
+
+	deployment, err := transform(corev1.EnvVar{Name: "GOGC", Value: "50"}, corev1.EnvVar{Name: "METRICS_DOMAIN", Value: "example.com/serving"})
+	assertEqual(t, err, nil)
+	env := deployment.Spec.Template.Spec.Containers[0].Env
+	assertEqual(t, len(env), 3)
+	assertEqual(t, env[0].Value, "50")
+	assertEqual(t, env[2].Name, "METRICS_DOMAIN")
+
+	if _, err := transform(corev1.EnvVar{Name: "SYSTEM_NAMESPACE", Value: "default"}); err == nil {
+		t.Error("expected an error overriding a var set from the pod's metadata")
+	}
+}
+
+func TestDeploymentOverridesReplicas(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	three, one := int32(3), int32(1)
+	overrides := []servingv1alpha1.DeploymentOverride{
+		{Name: "activator", Replicas: &three},
+		{Name: "autoscaler-hpa", Replicas: &one},
+	}
+	tests := []struct {
+		name       string
+		deployment string
+		ha         *servingv1alpha1.HighAvailability
+		hibernate  bool
+		expected   int64
+		found      bool
+	}{{
+		name:       "overrides the manifest",
+		deployment: "activator",
+		expected:   3,
+		found:      true,
+	}, {
+		name:       "overrides high availability",
+		deployment: "autoscaler-hpa",
+		ha:         &servingv1alpha1.HighAvailability{Replicas: 2},
+		expected:   1,
+		found:      true,
+	}, {
+		name:       "high availability without an override",
+		deployment: "controller",
+		ha:         &servingv1alpha1.HighAvailability{Replicas: 2},
+		expected:   2,
+		found:      true,
+	}, {
+		name:       "left to the HPA",
+		deployment: "activator",
+		ha: &servingv1alpha1.HighAvailability{
+			Replicas:    2,
+			Autoscaling: &servingv1alpha1.HighAvailabilityAutoscaling{MaxReplicas: 10},
+		},
+	}, {
+		name:       "hibernating",
+		deployment: "activator",
+		hibernate:  true,
+		expected:   0,
+		found:      true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := makeUnstructuredDeploymentWithVolumes(t, &deploymentOverridesTest{deploymentName: tt.deployment})
+			instance := &servingv1alpha1.KnativeServing{
+				Spec: servingv1alpha1.KnativeServingSpec{
+					DeploymentOverrides: overrides,
+					HighAvailability:    tt.ha,
+					Hibernate:           tt.hibernate,
+				},
+			}
+			log := logf.Log.WithName("replicas")
+			for _, transform := range []func(*unstructured.Unstructured) error{
+				DeploymentOverridesTransform(runtime.NewScheme(), instance, log),
+				HighAvailabilityTransform(instance, true, log),
+				HibernateTransform(instance, log),
+			} {
+				assertEqual(t, transform(&u), nil)
+			}
+			replicas, found, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+			assertEqual(t, found, tt.found)
+			assertEqual(t, replicas, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+// DomainTransform replaces the domains of config-domain with those of
+// spec.domain, keeping its examples
+func DomainTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if u.GetKind() != "ConfigMap" || u.GetName() != "config-domain" || len(instance.Spec.Domain) == 0 {
+			return nil
+		}
+		data, _, _ := unstructured.NestedStringMap(u.Object, "data")
+		result := map[string]string{}
+		for k, v := range data {
+			if strings.HasPrefix(k, "_") {
+				result[k] = v
+			}
+		}
+		for domain, d := range instance.Spec.Domain {
+			result[domain] = domainSelector(d)
+		}
+		log.Info("Setting domains", "domain", instance.Spec.Domain)
+		return unstructured.SetNestedStringMap(u.Object, result, "data")
+	}
+}
+
+// HasDefaultDomain returns whether the config-domain ConfigMap names a
+// domain for the routes no selector matches
+func HasDefaultDomain(cm *unstructured.Unstructured) bool {
+	data, _, _ := unstructured.NestedStringMap(cm.Object, "data")
+	for k, v := range data {
+		if !strings.HasPrefix(k, "_") && strings.TrimSpace(v) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// The config-domain value of the selector, quoting the labels so
+// they're read as strings
+func domainSelector(d servingv1alpha1.DomainSelector) string {
+	if len(d.Selector) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(d.Selector))
+	for k := range d.Selector {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString("selector:\n")
+	for _, k := range keys {
+		b.WriteString("  " + strconv.Quote(k) + ": " + strconv.Quote(d.Selector[k]) + "\n")
+	}
+	return b.String()
+}
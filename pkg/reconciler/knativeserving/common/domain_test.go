@@ -0,0 +1,48 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestDomainTransform(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	u := makeUnstructuredConfigMap("config-domain", map[string]interface{}{
+		"_example":    "docs",
+		"example.com": "",
+	})
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Domain: map[string]servingv1alpha1.DomainSelector{
+				"serving.io":  {},
+				"example.org": {Selector: map[string]string{"app": "nonprofit", "tier": "1"}},
+			},
+		},
+	}
+	err := DomainTransform(instance, logf.Log.WithName("domain"))(&u)
+	assertEqual(t, err, nil)
+	data, _, _ := unstructured.NestedStringMap(u.Object, "data")
+	expected := map[string]string{
+		"_example":    "docs",
+		"serving.io":  "",
+		"example.org": "selector:\n  \"app\": \"nonprofit\"\n  \"tier\": \"1\"\n",
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("data = %v, want %v", data, expected)
+	}
+	assertEqual(t, HasDefaultDomain(&u), true)
+}
+
+func TestDomainTransformUnset(t *testing.T) {
+	u := makeUnstructuredConfigMap("config-domain", map[string]interface{}{"_example": "docs"})
+	instance := &servingv1alpha1.KnativeServing{}
+	err := DomainTransform(instance, logf.Log.WithName("domain"))(&u)
+	assertEqual(t, err, nil)
+	data, _, _ := unstructured.NestedStringMap(u.Object, "data")
+	assertEqual(t, len(data), 1)
+	assertEqual(t, HasDefaultDomain(&u), false)
+}
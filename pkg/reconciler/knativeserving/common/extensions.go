@@ -17,7 +17,9 @@ package common
 
 import (
 	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
 	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
@@ -25,18 +27,23 @@ import (
 
 var log = logf.Log.WithName("common")
 
-type Platforms []func(client.Client, *runtime.Scheme) (*Extension, error)
+type Platforms []func(client.Client, discovery.DiscoveryInterface, *runtime.Scheme) (*Extension, error)
 type Extender func(*servingv1alpha1.KnativeServing) error
+
+// ManifestTransformer transforms the manifest as a whole, after the
+// Transformers of every extension, free to add or drop resources
+type ManifestTransformer func(*servingv1alpha1.KnativeServing, []unstructured.Unstructured) ([]unstructured.Unstructured, error)
 type Extensions []Extension
 type Extension struct {
-	Transformers []mf.Transformer
-	PreInstalls  []Extender
-	PostInstalls []Extender
+	Transformers         []mf.Transformer
+	ManifestTransformers []ManifestTransformer
+	PreInstalls          []Extender
+	PostInstalls         []Extender
 }
 
-func (platforms Platforms) Extend(c client.Client, scheme *runtime.Scheme) (result Extensions, err error) {
+func (platforms Platforms) Extend(c client.Client, dc discovery.DiscoveryInterface, scheme *runtime.Scheme) (result Extensions, err error) {
 	for _, fn := range platforms {
-		ext, err := fn(c, scheme)
+		ext, err := fn(c, dc, scheme)
 		if err != nil {
 			return result, err
 		}
@@ -47,15 +54,38 @@ func (platforms Platforms) Extend(c client.Client, scheme *runtime.Scheme) (resu
 	return
 }
 
-func (exts Extensions) Transform(scheme *runtime.Scheme, instance *servingv1alpha1.KnativeServing) []mf.Transformer {
+func (exts Extensions) Transform(scheme *runtime.Scheme, instance *servingv1alpha1.KnativeServing, resources []unstructured.Unstructured) []mf.Transformer {
 	log.V(1).Info("Transforming", "instance", instance)
 	result := []mf.Transformer{
-		mf.InjectOwner(instance),
-		mf.InjectNamespace(instance.GetNamespace()),
+		InjectOwner(instance),
+		mf.InjectNamespace(instance.InstallNamespace()),
+		QueueSidecarTransform(instance, log),
 		ConfigMapTransform(instance, log),
+		DomainTransform(instance, log),
+		CertManagerTransform(instance, log),
+		AutoscalerTransform(instance, log),
+		GCTransform(instance, log),
+		TracingTransform(instance, log),
+		DefaultsTransform(instance, log),
+		LoggingTransform(instance, log),
+		QueueSidecarConfigTransform(instance, log),
 		DeploymentTransform(scheme, instance, log),
 		ImageTransform(scheme, instance, log),
-		GatewayTransform(scheme, instance, log),
+		PriorityClassTransform(instance, log),
+		DeploymentOverridesTransform(scheme, instance, log),
+		ResourcesTransform(instance, log),
+		ProbesTransform(instance, log),
+		CustomCertsTransform(instance, log),
+		ProxyTransform(instance, log),
+		HighAvailabilityTransform(instance, LeaderElected(resources), log),
+		MeshTransform(instance, log),
+		NamespaceInjectionTransform(instance, log),
+		IngressTransform(instance, log),
+		HibernateTransform(instance, log),
+		CommonMetadataTransform(instance, log),
+	}
+	if instance.Spec.Ingress.Name() == servingv1alpha1.IstioIngress {
+		result = append(result, GatewayTransform(scheme, instance, log))
 	}
 	for _, extension := range exts {
 		result = append(result, extension.Transformers...)
@@ -63,6 +93,30 @@ func (exts Extensions) Transform(scheme *runtime.Scheme, instance *servingv1alph
 	return result
 }
 
+// TransformManifest passes the resources through the ManifestTransformers
+// of every extension in turn, stopping at the first to fail
+func (exts Extensions) TransformManifest(instance *servingv1alpha1.KnativeServing, resources []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	for _, extension := range exts {
+		for _, transform := range extension.ManifestTransformers {
+			var err error
+			if resources, err = transform(instance, resources); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return resources, nil
+}
+
+// InjectOwner makes the KnativeServing own the resources, unless they're
+// installed into another namespace, where the garbage collector would
+// take the reference for a missing owner and delete them
+func InjectOwner(instance *servingv1alpha1.KnativeServing) mf.Transformer {
+	if instance.InstallNamespace() != instance.GetNamespace() {
+		return func(*unstructured.Unstructured) error { return nil }
+	}
+	return mf.InjectOwner(instance)
+}
+
 func (exts Extensions) PreInstall(instance *servingv1alpha1.KnativeServing) error {
 	for _, extension := range exts {
 		for _, f := range extension.PreInstalls {
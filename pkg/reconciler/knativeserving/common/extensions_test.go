@@ -0,0 +1,22 @@
+package common
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func TestInjectOwnerSkipsOtherNamespaces(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving", Name: "knative-serving"},
+	}
+	u := makeUnstructuredConfigMap("config-network", nil)
+	assertEqual(t, InjectOwner(instance)(&u), nil)
+	assertEqual(t, len(u.GetOwnerReferences()), 1)
+
+	instance.Spec.Namespace = "serving-system"
+	u = makeUnstructuredConfigMap("config-network", nil)
+	assertEqual(t, InjectOwner(instance)(&u), nil)
+	assertEqual(t, len(u.GetOwnerReferences()), 0)
+}
@@ -1,6 +1,10 @@
 package common
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/go-logr/logr"
 	mf "github.com/jcrossley3/manifestival"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -8,22 +12,151 @@ import (
 	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
 )
 
+const (
+	// The istio Gateways in the upstream manifest
+	KnativeIngressGateway = "knative-ingress-gateway"
+	ClusterLocalGateway   = "cluster-local-gateway"
+
+	istioGatewayNamespace = "istio-system"
+
+	// The config-istio key prefixes naming the Gateways knative routes
+	// through; local-gateway.mesh means no cluster-local Gateway
+	ingressGatewayKeyPrefix = "gateway."
+	localGatewayKeyPrefix   = "local-gateway."
+	meshLocalGateway        = "mesh"
+)
+
+var (
+	// The istio Services each Gateway is bound to by default
+	defaultGatewayServices = map[string]string{
+		KnativeIngressGateway: "istio-ingressgateway",
+		ClusterLocalGateway:   "cluster-local-gateway",
+	}
+	// The config-istio keys naming the Service of each Gateway
+	gatewayConfigKeys = map[string]string{
+		KnativeIngressGateway: "gateway." + KnativeIngressGateway,
+		ClusterLocalGateway:   "local-gateway." + ClusterLocalGateway,
+	}
+)
+
 func GatewayTransform(scheme *runtime.Scheme, instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
 	return func(u *unstructured.Unstructured) error {
-		// Update the deployment with the new registry and tag
-		if u.GetAPIVersion() == "networking.istio.io/v1alpha3" && u.GetKind() == "Gateway" && u.GetName() == "knative-ingress-gateway" {
-			return updateKnativeIngressGateway(scheme, instance, u)
+		if isGateway(u) {
+			if override, ok := gatewayOverrides(instance)[u.GetName()]; ok {
+				return updateGateway(override, u, log)
+			}
+		}
+		if u.GetKind() == "ConfigMap" && u.GetName() == "config-istio" {
+			return updateConfigIstio(instance, u, log)
 		}
 		return nil
 	}
 }
 
-func updateKnativeIngressGateway(scheme *runtime.Scheme, instance *servingv1alpha1.KnativeServing, u *unstructured.Unstructured) error {
-	gatewayOverrides := instance.Spec.KnativeIngressGateway
-	if len(gatewayOverrides.Selector) > 0 {
-		log.V(1).Info("Updating Gateway", "name", u.GetName(), "gatewayOverrides", gatewayOverrides)
-		unstructured.SetNestedStringMap(u.Object, gatewayOverrides.Selector, "spec", "selector")
+// FilterGateways drops the istio Gateways unless spec.ingress.istio
+// installs them
+func FilterGateways(resources []unstructured.Unstructured, instance *servingv1alpha1.KnativeServing) []unstructured.Unstructured {
+	if instance.Spec.Ingress.GatewaysInstalled() {
+		return resources
+	}
+	result := make([]unstructured.Unstructured, 0, len(resources))
+	for i := range resources {
+		if isGateway(&resources[i]) {
+			log.V(1).Info("Skipping Gateway", "name", resources[i].GetName())
+			continue
+		}
+		result = append(result, resources[i])
+	}
+	return result
+}
+
+// GatewayNames returns the Gateways knative routes through: those
+// spec.config.istio names, else the upstream ones, leaving out the
+// cluster-local-gateway when it's disabled
+func GatewayNames(instance *servingv1alpha1.KnativeServing) []string {
+	var ingress, local []string
+	routesLocal := false
+	for key := range instance.Spec.Config["istio"] {
+		switch {
+		case strings.HasPrefix(key, ingressGatewayKeyPrefix):
+			ingress = append(ingress, strings.TrimPrefix(key, ingressGatewayKeyPrefix))
+		case key == localGatewayKeyPrefix+meshLocalGateway:
+			routesLocal = true
+		case strings.HasPrefix(key, localGatewayKeyPrefix):
+			local = append(local, strings.TrimPrefix(key, localGatewayKeyPrefix))
+		}
+	}
+	if len(ingress) == 0 {
+		ingress = []string{KnativeIngressGateway}
+	}
+	if len(local) == 0 && !routesLocal && !instance.Spec.IsDisabled(servingv1alpha1.ClusterLocalGatewayComponent) {
+		local = []string{ClusterLocalGateway}
+	}
+	result := append(ingress, local...)
+	sort.Strings(result)
+	return result
+}
+
+func isGateway(u *unstructured.Unstructured) bool {
+	return u.GetAPIVersion() == "networking.istio.io/v1alpha3" && u.GetKind() == "Gateway"
+}
+
+// GatewayService returns the name and namespace of the istio Service
+// the named knative gateway is bound to
+func GatewayService(instance *servingv1alpha1.KnativeServing, gateway string) (string, string) {
+	name, namespace := defaultGatewayServices[gateway], istioGatewayNamespace
+	if override, ok := gatewayOverrides(instance)[gateway]; ok {
+		if override.Name != "" {
+			name = override.Name
+		}
+		if override.Namespace != "" {
+			namespace = override.Namespace
+		}
+	}
+	return name, namespace
+}
+
+func gatewayOverrides(instance *servingv1alpha1.KnativeServing) map[string]*servingv1alpha1.IstioGatewayOverride {
+	return map[string]*servingv1alpha1.IstioGatewayOverride{
+		KnativeIngressGateway: &instance.Spec.KnativeIngressGateway,
+		ClusterLocalGateway:   &instance.Spec.ClusterLocalGateway,
+	}
+}
+
+func updateGateway(override *servingv1alpha1.IstioGatewayOverride, u *unstructured.Unstructured, log logr.Logger) error {
+	if len(override.Selector) > 0 {
+		log.V(1).Info("Updating Gateway", "name", u.GetName(), "gatewayOverrides", override)
+		if err := unstructured.SetNestedStringMap(u.Object, override.Selector, "spec", "selector"); err != nil {
+			return err
+		}
 		log.V(1).Info("Finished conversion", "name", u.GetName(), "unstructured", u.Object)
 	}
 	return nil
 }
+
+// Point config-istio at the Services of the overridden gateways, or
+// at the mesh when the cluster-local-gateway is disabled
+func updateConfigIstio(instance *servingv1alpha1.KnativeServing, u *unstructured.Unstructured, log logr.Logger) error {
+	if instance.Spec.IsDisabled(servingv1alpha1.ClusterLocalGatewayComponent) {
+		log.V(1).Info("Routing cluster-local traffic through the mesh")
+		unstructured.RemoveNestedField(u.Object, "data", gatewayConfigKeys[ClusterLocalGateway])
+		if err := unstructured.SetNestedField(u.Object, "mesh", "data", "local-gateway.mesh"); err != nil {
+			return err
+		}
+	}
+	for gateway, override := range gatewayOverrides(instance) {
+		if override.Name == "" && override.Namespace == "" {
+			continue
+		}
+		if gateway == ClusterLocalGateway && instance.Spec.IsDisabled(servingv1alpha1.ClusterLocalGatewayComponent) {
+			continue
+		}
+		name, namespace := GatewayService(instance, gateway)
+		value := fmt.Sprintf("%s.%s.svc.cluster.local", name, namespace)
+		log.V(1).Info("Updating config-istio", "key", gatewayConfigKeys[gateway], "value", value)
+		if err := unstructured.SetNestedField(u.Object, value, "data", gatewayConfigKeys[gateway]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"strings"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -15,7 +16,8 @@ type updateGatewayTest struct {
 	name           string
 	gatewayName    string
 	in             map[string]string
-	ingressGateway servingv1alpha1.KnativeIngressGateway
+	ingressGateway servingv1alpha1.IstioGatewayOverride
+	localGateway   servingv1alpha1.IstioGatewayOverride
 	expected       map[string]string
 }
 
@@ -26,7 +28,7 @@ var updateGatewayTests = []updateGatewayTest{
 		in: map[string]string{
 			"istio": "old-istio",
 		},
-		ingressGateway: servingv1alpha1.KnativeIngressGateway{
+		ingressGateway: servingv1alpha1.IstioGatewayOverride{
 			Selector: map[string]string{
 				"istio": "new-istio",
 			},
@@ -35,13 +37,28 @@ var updateGatewayTests = []updateGatewayTest{
 			"istio": "new-istio",
 		},
 	},
+	{
+		name:        "UpdatesClusterLocalGateway",
+		gatewayName: "cluster-local-gateway",
+		in: map[string]string{
+			"istio": "cluster-local-gateway",
+		},
+		localGateway: servingv1alpha1.IstioGatewayOverride{
+			Selector: map[string]string{
+				"istio": "custom-local-gateway",
+			},
+		},
+		expected: map[string]string{
+			"istio": "custom-local-gateway",
+		},
+	},
 	{
 		name:        "DoesNothingToOtherGateway",
 		gatewayName: "not-knative-ingress-gateway",
 		in: map[string]string{
 			"istio": "old-istio",
 		},
-		ingressGateway: servingv1alpha1.KnativeIngressGateway{
+		ingressGateway: servingv1alpha1.IstioGatewayOverride{
 			Selector: map[string]string{
 				"istio": "new-istio",
 			},
@@ -68,6 +85,7 @@ func runGatewayTransformTest(t *testing.T, tt *updateGatewayTest) {
 	instance := &servingv1alpha1.KnativeServing{
 		Spec: servingv1alpha1.KnativeServingSpec{
 			KnativeIngressGateway: tt.ingressGateway,
+			ClusterLocalGateway:   tt.localGateway,
 		},
 	}
 	gatewayTransform := GatewayTransform(testScheme, instance, log)
@@ -75,6 +93,85 @@ func runGatewayTransformTest(t *testing.T, tt *updateGatewayTest) {
 	validateUnstructedGatewayChanged(t, tt, &unstructedGateway)
 }
 
+func TestGatewayTransformConfigIstio(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	u := makeUnstructuredConfigMap("config-istio", map[string]interface{}{
+		"gateway.knative-ingress-gateway":     "istio-ingressgateway.istio-system.svc.cluster.local",
+		"local-gateway.cluster-local-gateway": "cluster-local-gateway.istio-system.svc.cluster.local",
+	})
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			KnativeIngressGateway: servingv1alpha1.IstioGatewayOverride{
+				Name:      "custom-ingressgateway",
+				Namespace: "custom-istio",
+			},
+			ClusterLocalGateway: servingv1alpha1.IstioGatewayOverride{
+				Selector: map[string]string{"istio": "custom-local-gateway"},
+			},
+		},
+	}
+	err := GatewayTransform(runtime.NewScheme(), instance, logf.Log.WithName("config-istio"))(&u)
+	assertEqual(t, err, nil)
+	ingress, _, _ := unstructured.NestedString(u.Object, "data", "gateway.knative-ingress-gateway")
+	assertEqual(t, ingress, "custom-ingressgateway.custom-istio.svc.cluster.local")
+	local, _, _ := unstructured.NestedString(u.Object, "data", "local-gateway.cluster-local-gateway")
+	assertEqual(t, local, "cluster-local-gateway.istio-system.svc.cluster.local")
+}
+
+func TestFilterGateways(t *testing.T) {
+	gateway := makeUnstructuredGateway(t, &updateGatewayTest{gatewayName: "knative-ingress-gateway"}, runtime.NewScheme())
+	configMap := makeUnstructuredConfigMap("config-istio", nil)
+	resources := []unstructured.Unstructured{gateway, configMap}
+	instance := &servingv1alpha1.KnativeServing{}
+
+	assertEqual(t, len(FilterGateways(resources, instance)), 2)
+	install := false
+	instance.Spec.Ingress = &servingv1alpha1.IngressConfigs{
+		Istio: servingv1alpha1.IstioIngressConfiguration{Enabled: true, InstallGateways: &install},
+	}
+	result := FilterGateways(resources, instance)
+	assertEqual(t, len(result), 1)
+	assertEqual(t, result[0].GetKind(), "ConfigMap")
+}
+
+func TestGatewayNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     servingv1alpha1.KnativeServingSpec
+		expected []string
+	}{{
+		name:     "upstream gateways",
+		expected: []string{"cluster-local-gateway", "knative-ingress-gateway"},
+	}, {
+		name: "cluster-local-gateway disabled",
+		spec: servingv1alpha1.KnativeServingSpec{
+			DisabledComponents: []string{servingv1alpha1.ClusterLocalGatewayComponent},
+		},
+		expected: []string{"knative-ingress-gateway"},
+	}, {
+		name: "configured gateways",
+		spec: servingv1alpha1.KnativeServingSpec{
+			Config: map[string]map[string]string{"istio": {
+				"gateway.public":      "istio-ingressgateway.istio-system.svc.cluster.local",
+				"local-gateway.local": "cluster-local-gateway.istio-system.svc.cluster.local",
+			}},
+		},
+		expected: []string{"local", "public"},
+	}, {
+		name: "cluster-local traffic through the mesh",
+		spec: servingv1alpha1.KnativeServingSpec{
+			Config: map[string]map[string]string{"istio": {"local-gateway.mesh": "mesh"}},
+		},
+		expected: []string{"knative-ingress-gateway"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GatewayNames(&servingv1alpha1.KnativeServing{Spec: tt.spec})
+			assertEqual(t, strings.Join(got, ","), strings.Join(tt.expected, ","))
+		})
+	}
+}
+
 func validateUnstructedGatewayChanged(t *testing.T, tt *updateGatewayTest, u *unstructured.Unstructured) {
 	var gateway = &v1alpha3.Gateway{}
 	err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, gateway)
@@ -0,0 +1,44 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestGCTransform(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	generations := int64(3)
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Config: map[string]map[string]string{
+				"gc": {"stale-revision-minimum-generations": "2", "stale-revision-create-delay": "1h"},
+			},
+			GC: &servingv1alpha1.GC{
+				StaleRevisionMinimumGenerations: &generations,
+				StaleRevisionTimeout:            &metav1.Duration{Duration: 20 * time.Hour},
+			},
+		},
+	}
+	u := makeUnstructuredConfigMap("config-gc", map[string]interface{}{
+		"stale-revision-timeout": "15h",
+	})
+	log := logf.Log.WithName("gc")
+	assertEqual(t, ConfigMapTransform(instance, log)(&u), nil)
+	assertEqual(t, GCTransform(instance, log)(&u), nil)
+
+	data, _, _ := unstructured.NestedStringMap(u.Object, "data")
+	want := map[string]string{
+		"stale-revision-create-delay":        "1h",
+		"stale-revision-minimum-generations": "3",
+		"stale-revision-timeout":             "20h0m0s",
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("data = %v, want %v", data, want)
+	}
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+// HibernateTransform scales the control plane deployments of
+// spec.hibernate to zero. It comes after every transformer setting
+// replicas, so nothing scales them back up. The activator's HPA
+// leaves a deployment scaled to zero alone.
+func HibernateTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if !instance.Spec.Hibernate || u.GetKind() != "Deployment" || u.GetNamespace() != instance.InstallNamespace() {
+			return nil
+		}
+		log.V(1).Info("Hibernating", "deployment", u.GetName())
+		return unstructured.SetNestedField(u.Object, int64(0), "spec", "replicas")
+	}
+}
@@ -0,0 +1,48 @@
+package common
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestHibernateTransform(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving"},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			HighAvailability: &servingv1alpha1.HighAvailability{Replicas: 3},
+			Hibernate:        true,
+		},
+	}
+	tests := []struct {
+		name      string
+		namespace string
+		want      int64
+	}{
+		{name: "controller", namespace: "knative-serving", want: 0},
+		{name: "3scale-kourier-gateway", namespace: "kourier-system", want: 3},
+	}
+	for _, tt := range tests {
+		u := makeUnstructuredDeploymentWithVolumes(t, &deploymentOverridesTest{deploymentName: tt.name})
+		u.SetNamespace(tt.namespace)
+		unstructured.SetNestedField(u.Object, int64(3), "spec", "replicas")
+		err := HibernateTransform(instance, logf.Log.WithName("hibernate"))(&u)
+		assertEqual(t, err, nil)
+		replicas, _, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+		assertEqual(t, replicas, tt.want)
+	}
+
+	// Waking up leaves the replicas as the other transformers set them
+	instance.Spec.Hibernate = false
+	u := makeUnstructuredDeploymentWithVolumes(t, &deploymentOverridesTest{deploymentName: "controller"})
+	u.SetNamespace("knative-serving")
+	unstructured.SetNestedField(u.Object, int64(3), "spec", "replicas")
+	err := HibernateTransform(instance, logf.Log.WithName("hibernate"))(&u)
+	assertEqual(t, err, nil)
+	replicas, _, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+	assertEqual(t, replicas, int64(3))
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	// Releases that support leader election ship this ConfigMap
+	leaderElectionConfigMap = "config-leader-election"
+	enabledComponentsKey    = "enabledComponents"
+	activatorDeployment     = "activator"
+)
+
+var (
+	// The deployments that may safely run more than one replica, those
+	// mapped to true only once they elect a leader
+	haDeployments = map[string]bool{
+		"controller":     true,
+		"webhook":        false,
+		"activator":      false,
+		"autoscaler-hpa": true,
+	}
+	// The components that must elect a leader once replicated
+	haComponents = "controller,hpaautoscaler,certcontroller,istiocontroller,nscontroller"
+)
+
+// LeaderElected tells whether the release ships config-leader-election,
+// without which its controllers can't be replicated
+func LeaderElected(resources []unstructured.Unstructured) bool {
+	for _, u := range resources {
+		if u.GetKind() == "ConfigMap" && u.GetName() == leaderElectionConfigMap {
+			return true
+		}
+	}
+	return false
+}
+
+func HighAvailabilityTransform(instance *servingv1alpha1.KnativeServing, leaderElected bool, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		ha := instance.Spec.HighAvailability
+		if ha == nil {
+			return nil
+		}
+		if u.GetKind() == "Deployment" && autoscaled(instance, u.GetName()) {
+			// Leave the replicas to the HorizontalPodAutoscaler
+			unstructured.RemoveNestedField(u.Object, "spec", "replicas")
+			return nil
+		}
+		if ha.Replicas < 2 {
+			return nil
+		}
+		elects, ok := haDeployments[u.GetName()]
+		switch {
+		case u.GetKind() == "Deployment" && ok:
+			if elects && !leaderElected {
+				// Replicas would fight over the same resources
+				log.V(1).Info("Not scaling without leader election", "deployment", u.GetName())
+				return nil
+			}
+			if replicasOverride(instance, u.GetName()) != nil {
+				// The deployment override comes first
+				return nil
+			}
+			log.V(1).Info("Scaling", "deployment", u.GetName(), "replicas", ha.Replicas)
+			return unstructured.SetNestedField(u.Object, int64(ha.Replicas), "spec", "replicas")
+		case u.GetKind() == "ConfigMap" && u.GetName() == leaderElectionConfigMap:
+			UpdateConfigMap(u, map[string]string{enabledComponentsKey: haComponents}, log)
+		}
+		return nil
+	}
+}
+
+// Whether the HorizontalPodAutoscaler of spec.highAvailability scales
+// the deployment, leaving its replicas to it
+func autoscaled(instance *servingv1alpha1.KnativeServing, name string) bool {
+	ha := instance.Spec.HighAvailability
+	return ha != nil && ha.Autoscaling != nil && name == activatorDeployment
+}
@@ -0,0 +1,125 @@
+package common
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+type highAvailabilityTest struct {
+	name             string
+	kind             string
+	resourceName     string
+	highAvailability *servingv1alpha1.HighAvailability
+	manifestReplicas int64
+	expectedReplicas int64
+	expectedData     string
+	// The release doesn't ship config-leader-election
+	noLeaderElection bool
+}
+
+var highAvailabilityTests = []highAvailabilityTest{
+	{
+		name:             "ScalesController",
+		kind:             "Deployment",
+		resourceName:     "controller",
+		highAvailability: &servingv1alpha1.HighAvailability{Replicas: 3},
+		expectedReplicas: 3,
+	},
+	{
+		name:             "ScalesActivator",
+		kind:             "Deployment",
+		resourceName:     "activator",
+		highAvailability: &servingv1alpha1.HighAvailability{Replicas: 2},
+		expectedReplicas: 2,
+	},
+	{
+		name:             "LeavesControllerWithoutLeaderElection",
+		kind:             "Deployment",
+		resourceName:     "controller",
+		highAvailability: &servingv1alpha1.HighAvailability{Replicas: 3},
+		noLeaderElection: true,
+	},
+	{
+		name:             "LeavesHPAAutoscalerWithoutLeaderElection",
+		kind:             "Deployment",
+		resourceName:     "autoscaler-hpa",
+		highAvailability: &servingv1alpha1.HighAvailability{Replicas: 3},
+		noLeaderElection: true,
+	},
+	{
+		name:             "ScalesActivatorWithoutLeaderElection",
+		kind:             "Deployment",
+		resourceName:     "activator",
+		highAvailability: &servingv1alpha1.HighAvailability{Replicas: 2},
+		noLeaderElection: true,
+		expectedReplicas: 2,
+	},
+	{
+		name:         "LeavesAutoscaledActivatorToHPA",
+		kind:         "Deployment",
+		resourceName: "activator",
+		highAvailability: &servingv1alpha1.HighAvailability{
+			Replicas:    2,
+			Autoscaling: &servingv1alpha1.HighAvailabilityAutoscaling{MaxReplicas: 10},
+		},
+		manifestReplicas: 1,
+	},
+	{
+		name:             "IgnoresAutoscaler",
+		kind:             "Deployment",
+		resourceName:     "autoscaler",
+		highAvailability: &servingv1alpha1.HighAvailability{Replicas: 3},
+	},
+	{
+		name:         "IgnoresUnsetHighAvailability",
+		kind:         "Deployment",
+		resourceName: "controller",
+	},
+	{
+		name:             "IgnoresSingleReplica",
+		kind:             "Deployment",
+		resourceName:     "controller",
+		highAvailability: &servingv1alpha1.HighAvailability{Replicas: 1},
+	},
+	{
+		name:             "EnablesLeaderElection",
+		kind:             "ConfigMap",
+		resourceName:     "config-leader-election",
+		highAvailability: &servingv1alpha1.HighAvailability{Replicas: 2},
+		expectedData:     haComponents,
+	},
+}
+
+func TestHighAvailabilityTransform(t *testing.T) {
+	for _, tt := range highAvailabilityTests {
+		t.Run(tt.name, func(t *testing.T) {
+			runHighAvailabilityTransformTest(t, &tt)
+		})
+	}
+}
+
+func runHighAvailabilityTransformTest(t *testing.T, tt *highAvailabilityTest) {
+	log := logf.Log.WithName(tt.name)
+	logf.SetLogger(logf.ZapLogger(true))
+	u := unstructured.Unstructured{}
+	u.SetKind(tt.kind)
+	u.SetName(tt.resourceName)
+	if tt.manifestReplicas != 0 {
+		unstructured.SetNestedField(u.Object, tt.manifestReplicas, "spec", "replicas")
+	}
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			HighAvailability: tt.highAvailability,
+		},
+	}
+	err := HighAvailabilityTransform(instance, !tt.noLeaderElection, log)(&u)
+	assertEqual(t, err, nil)
+
+	replicas, _, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+	assertEqual(t, replicas, tt.expectedReplicas)
+	data, _, _ := unstructured.NestedString(u.Object, "data", enabledComponentsKey)
+	assertEqual(t, data, tt.expectedData)
+}
@@ -35,6 +35,12 @@ var (
 	containerNameVariable = "${NAME}"
 )
 
+const (
+	// The name of the queue sidecar image, which is configured in config-deployment
+	queueSidecarName     = "queue-proxy"
+	queueSidecarImageKey = "queueSidecarImage"
+)
+
 func DeploymentTransform(scheme *runtime.Scheme, instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
 	return func(u *unstructured.Unstructured) error {
 		// Update the deployment with the new registry and tag
@@ -55,6 +61,18 @@ func ImageTransform(scheme *runtime.Scheme, instance *servingv1alpha1.KnativeSer
 	}
 }
 
+func QueueSidecarTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		// Point the revisions' queue sidecar at the new registry and tag
+		if u.GetKind() == "ConfigMap" && u.GetName() == "config-deployment" {
+			if newImage := getNewImage(&instance.Spec.Registry, queueSidecarName); newImage != "" {
+				UpdateConfigMap(u, map[string]string{queueSidecarImageKey: newImage}, log)
+			}
+		}
+		return nil
+	}
+}
+
 func updateDeployment(scheme *runtime.Scheme, instance *servingv1alpha1.KnativeServing, u *unstructured.Unstructured, log logr.Logger) error {
 	var deployment = &appsv1.Deployment{}
 	err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment)
@@ -67,6 +85,7 @@ func updateDeployment(scheme *runtime.Scheme, instance *servingv1alpha1.KnativeS
 	log.V(1).Info("Updating Deployment", "name", u.GetName(), "registry", registry)
 
 	updateDeploymentImage(deployment, &registry, log)
+	updateImagePullSecrets(deployment, &registry, log)
 	err = updateUnstructured(u, deployment, log)
 	if err != nil {
 		return err
@@ -99,6 +118,24 @@ func updateDeploymentImage(deployment *appsv1.Deployment, registry *servingv1alp
 	log.V(1).Info("Finished updating images", "name", deployment.GetName(), "containers", deployment.Spec.Template.Spec.Containers)
 }
 
+// updateImagePullSecrets adds the registry's pull secrets to the deployment, unless already present
+func updateImagePullSecrets(deployment *appsv1.Deployment, registry *servingv1alpha1.Registry, log logr.Logger) {
+	podSpec := &deployment.Spec.Template.Spec
+	for _, secret := range registry.ImagePullSecrets {
+		found := false
+		for _, existing := range podSpec.ImagePullSecrets {
+			if existing.Name == secret.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, secret)
+		}
+	}
+	log.V(1).Info("Finished updating image pull secrets", "name", deployment.GetName(), "imagePullSecrets", podSpec.ImagePullSecrets)
+}
+
 func updateCachingImage(scheme *runtime.Scheme, instance *servingv1alpha1.KnativeServing, u *unstructured.Unstructured) error {
 	var image = &caching.Image{}
 	err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, image)
@@ -227,3 +227,88 @@ func assertEqual(t *testing.T, actual, expected interface{}) {
 	}
 	t.Fatalf("expected does not equal actual. \nExpected: %v\nActual: %v", expected, actual)
 }
+
+func TestImagePullSecrets(t *testing.T) {
+	log := logf.Log.WithName("TestImagePullSecrets")
+	logf.SetLogger(logf.ZapLogger(true))
+	deployment := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "existing"}},
+				},
+			},
+		},
+	}
+	registry := &servingv1alpha1.Registry{
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "existing"}, {Name: "new"}},
+	}
+	updateImagePullSecrets(deployment, registry, log)
+	secrets := deployment.Spec.Template.Spec.ImagePullSecrets
+	assertEqual(t, len(secrets), 2)
+	assertEqual(t, secrets[0].Name, "existing")
+	assertEqual(t, secrets[1].Name, "new")
+}
+
+type queueSidecarTest struct {
+	name     string
+	cmName   string
+	registry servingv1alpha1.Registry
+	expected string
+}
+
+var queueSidecarTests = []queueSidecarTest{
+	{
+		name:   "UsesNameFromDefault",
+		cmName: "config-deployment",
+		registry: servingv1alpha1.Registry{
+			Default: "new-registry.io/test/path/${NAME}:new-tag",
+		},
+		expected: "new-registry.io/test/path/queue-proxy:new-tag",
+	},
+	{
+		name:   "UsesOverride",
+		cmName: "config-deployment",
+		registry: servingv1alpha1.Registry{
+			Default: "new-registry.io/test/path/${NAME}:new-tag",
+			Override: map[string]string{
+				"queue-proxy": "new-registry.io/test/path/new-value:new-override-tag",
+			},
+		},
+		expected: "new-registry.io/test/path/new-value:new-override-tag",
+	},
+	{
+		name:     "NoChange",
+		cmName:   "config-deployment",
+		expected: "gcr.io/queue:tag",
+	},
+	{
+		name:   "IgnoresOtherConfigMaps",
+		cmName: "config-network",
+		registry: servingv1alpha1.Registry{
+			Default: "new-registry.io/test/path/${NAME}:new-tag",
+		},
+		expected: "gcr.io/queue:tag",
+	},
+}
+
+func TestQueueSidecarTransform(t *testing.T) {
+	for _, tt := range queueSidecarTests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logf.Log.WithName(tt.name)
+			logf.SetLogger(logf.ZapLogger(true))
+			u := makeUnstructuredConfigMap(tt.cmName, map[string]interface{}{
+				"queueSidecarImage": "gcr.io/queue:tag",
+			})
+			instance := &servingv1alpha1.KnativeServing{
+				Spec: servingv1alpha1.KnativeServingSpec{
+					Registry: tt.registry,
+				},
+			}
+			err := QueueSidecarTransform(instance, log)(&u)
+			assertEqual(t, err, nil)
+			image, _, _ := unstructured.NestedString(u.Object, "data", "queueSidecarImage")
+			assertEqual(t, image, tt.expected)
+		})
+	}
+}
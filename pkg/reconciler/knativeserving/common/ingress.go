@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	// Labels the resources specific to one ingress implementation
+	IngressProviderLabel = "networking.knative.dev/ingress-provider"
+
+	networkConfigMap = "config-network"
+	// Newer releases read ingress.class, older ones clusteringress.class
+	ingressClassKey        = "ingress.class"
+	clusterIngressClassKey = "clusteringress.class"
+
+	istioNetworkingGroup = "networking.istio.io"
+)
+
+func IngressTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		// Point the networking config at the selected ingress
+		if u.GetKind() == "ConfigMap" && u.GetName() == networkConfigMap {
+			class := instance.Spec.Ingress.Class()
+			UpdateConfigMap(u, map[string]string{
+				ingressClassKey:        class,
+				clusterIngressClassKey: class,
+			}, log)
+		}
+		return nil
+	}
+}
+
+// FilterIngress drops the resources of every ingress but the given one,
+// including the istio networking resources, e.g. Gateways, whether
+// labeled or not
+func FilterIngress(resources []unstructured.Unstructured, ingress string) []unstructured.Unstructured {
+	result := make([]unstructured.Unstructured, 0, len(resources))
+	for _, u := range resources {
+		if provider, ok := u.GetLabels()[IngressProviderLabel]; ok && provider != ingress {
+			continue
+		}
+		if ingress != servingv1alpha1.IstioIngress && u.GroupVersionKind().Group == istioNetworkingGroup {
+			continue
+		}
+		result = append(result, u)
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package common
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestIngressTransform(t *testing.T) {
+	tests := []struct {
+		name     string
+		ingress  *servingv1alpha1.IngressConfigs
+		expected string
+	}{{
+		name:     "DefaultsToIstio",
+		expected: "istio.ingress.networking.knative.dev",
+	}, {
+		name:     "Kourier",
+		ingress:  &servingv1alpha1.IngressConfigs{Kourier: servingv1alpha1.KourierIngressConfiguration{Enabled: true}},
+		expected: "kourier.ingress.networking.knative.dev",
+	}, {
+		name:     "Gloo",
+		ingress:  &servingv1alpha1.IngressConfigs{Gloo: servingv1alpha1.GlooIngressConfiguration{Enabled: true}},
+		expected: "gloo.ingress.networking.knative.dev",
+	}, {
+		name:     "Ambassador",
+		ingress:  &servingv1alpha1.IngressConfigs{Ambassador: servingv1alpha1.AmbassadorIngressConfiguration{Enabled: true}},
+		expected: "ambassador.ingress.networking.knative.dev",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logf.Log.WithName(tt.name)
+			logf.SetLogger(logf.ZapLogger(true))
+			u := makeUnstructuredConfigMap("config-network", map[string]interface{}{
+				"clusteringress.class": "istio.ingress.networking.knative.dev",
+			})
+			instance := &servingv1alpha1.KnativeServing{
+				Spec: servingv1alpha1.KnativeServingSpec{Ingress: tt.ingress},
+			}
+			err := IngressTransform(instance, log)(&u)
+			assertEqual(t, err, nil)
+			for _, key := range []string{"ingress.class", "clusteringress.class"} {
+				class, _, _ := unstructured.NestedString(u.Object, "data", key)
+				assertEqual(t, class, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterIngress(t *testing.T) {
+	resource := func(name, provider string) unstructured.Unstructured {
+		u := unstructured.Unstructured{}
+		u.SetName(name)
+		if provider != "" {
+			u.SetLabels(map[string]string{IngressProviderLabel: provider})
+		}
+		return u
+	}
+	resources := []unstructured.Unstructured{
+		resource("controller", ""),
+		resource("networking-istio", "istio"),
+		resource("config-istio", "istio"),
+		resource("kourier-control", "kourier"),
+	}
+
+	istio := FilterIngress(resources, "istio")
+	assertEqual(t, len(istio), 3)
+	kourier := FilterIngress(resources, "kourier")
+	assertEqual(t, len(kourier), 2)
+	assertEqual(t, kourier[0].GetName(), "controller")
+	assertEqual(t, kourier[1].GetName(), "kourier-control")
+
+	// The istio Gateways are unlabeled but only belong with istio
+	gateway := resource("knative-ingress-gateway", "")
+	gateway.SetAPIVersion("networking.istio.io/v1alpha3")
+	gateway.SetKind("Gateway")
+	resources = append(resources, gateway)
+	assertEqual(t, len(FilterIngress(resources, "istio")), 4)
+	gloo := FilterIngress(resources, "gloo")
+	assertEqual(t, len(gloo), 1)
+	assertEqual(t, gloo[0].GetName(), "controller")
+}
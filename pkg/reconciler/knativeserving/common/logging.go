@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	loggingConfigMap = "config-logging"
+	// Changes with the logging of a deployment, restarting it
+	LoggingHashAnnotation = "operator.knative.dev/logging-hash"
+
+	zapLoggerConfigKey = "zap-logger-config"
+	logLevelKeyPrefix  = "loglevel."
+)
+
+// LoggingTransform projects the fields of spec.logging into
+// config-logging and, with restartOnChange, annotates the pod template
+// of each deployment with a hash of its logging, so changing it rolls
+// the deployment
+func LoggingTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		logging := instance.Spec.Logging
+		if logging == nil {
+			return nil
+		}
+		switch u.GetKind() {
+		case "ConfigMap":
+			if u.GetName() == loggingConfigMap {
+				UpdateConfigMap(u, logging.Config(), log)
+			}
+		case "Deployment":
+			if !logging.RestartOnChange {
+				return nil
+			}
+			hash := loggingHash(instance, u.GetName())
+			if hash == "" {
+				return nil
+			}
+			annotations, _, _ := unstructured.NestedStringMap(u.Object, "spec", "template", "metadata", "annotations")
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[LoggingHashAnnotation] = hash
+			return unstructured.SetNestedStringMap(u.Object, annotations, "spec", "template", "metadata", "annotations")
+		}
+		return nil
+	}
+}
+
+// Hash the entries of config-logging the operator sets that the
+// deployment reads, its log level and the zap config, empty without any
+func loggingHash(instance *servingv1alpha1.KnativeServing, deployment string) string {
+	config := map[string]string{}
+	for k, v := range instance.Spec.Config["logging"] {
+		config[k] = v
+	}
+	for k, v := range instance.Spec.Logging.Config() {
+		config[k] = v
+	}
+	var keys []string
+	for _, key := range []string{zapLoggerConfigKey, logLevelKeyPrefix + deployment} {
+		if _, ok := config[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	for _, key := range keys {
+		h.Write([]byte(key + "=" + config[key] + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,54 @@
+package common
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestLoggingTransformConfigMap(t *testing.T) {
+	log := logf.Log.WithName("TestLoggingTransformConfigMap")
+	u := makeUnstructuredConfigMap("config-logging", map[string]interface{}{
+		"loglevel.controller": "info",
+		"loglevel.webhook":    "info",
+	})
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Logging: &servingv1alpha1.Logging{
+				Levels:          map[string]string{"controller": "debug"},
+				ZapLoggerConfig: `{"level": "info", "encoding": "console"}`,
+			},
+		},
+	}
+	assertEqual(t, LoggingTransform(instance, log)(&u), nil)
+	data, _, _ := unstructured.NestedStringMap(u.Object, "data")
+	assertEqual(t, data["loglevel.controller"], "debug")
+	assertEqual(t, data["loglevel.webhook"], "info")
+	assertEqual(t, data["zap-logger-config"], `{"level": "info", "encoding": "console"}`)
+}
+
+func TestLoggingTransformRestartsOnChange(t *testing.T) {
+	log := logf.Log.WithName("TestLoggingTransformRestartsOnChange")
+	hash := func(logging *servingv1alpha1.Logging, deployment string) string {
+		u := makeUnstructuredDeploymentWithResources(t, deployment)
+		instance := &servingv1alpha1.KnativeServing{
+			Spec: servingv1alpha1.KnativeServingSpec{Logging: logging},
+		}
+		assertEqual(t, LoggingTransform(instance, log)(&u), nil)
+		annotation, _, _ := unstructured.NestedString(u.Object, "spec", "template", "metadata", "annotations", LoggingHashAnnotation)
+		return annotation
+	}
+	debug := &servingv1alpha1.Logging{Levels: map[string]string{"controller": "debug"}, RestartOnChange: true}
+	info := &servingv1alpha1.Logging{Levels: map[string]string{"controller": "info"}, RestartOnChange: true}
+
+	if hash(debug, "controller") == "" {
+		t.Error("the controller wasn't annotated with its logging")
+	}
+	if hash(debug, "controller") == hash(info, "controller") {
+		t.Error("changing the controller's level doesn't restart it")
+	}
+	assertEqual(t, hash(debug, "webhook"), "")
+	assertEqual(t, hash(&servingv1alpha1.Logging{Levels: debug.Levels}, "controller"), "")
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"strconv"
+
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	sidecarInjectAnnotation       = "sidecar.istio.io/inject"
+	excludeInboundPortsAnnotation = "traffic.sidecar.istio.io/excludeInboundPorts"
+	webhookDeployment             = "webhook"
+	webhookPort                   = "8443"
+
+	// The namespace label Istio's sidecar injector selects by
+	InjectionLabel = "istio-injection"
+)
+
+// SidecarInjection returns the istio-injection label of the namespace
+// Knative Serving is installed into: enabled only for the sidecars of
+// spec.istio, disabled otherwise, so that no injection policy gives the
+// webhook a sidecar the API server can't call through
+func SidecarInjection(instance *servingv1alpha1.KnativeServing) string {
+	if istio := instance.Spec.Istio; istio != nil && istio.MeshCompatibility && istio.SidecarInjection {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+// NamespaceInjectionTransform labels the namespace Knative Serving is
+// installed into for the sidecar injection of spec.istio
+func NamespaceInjectionTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if u.GetKind() != "Namespace" || u.GetName() != instance.InstallNamespace() {
+			return nil
+		}
+		labels := u.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[InjectionLabel] = SidecarInjection(instance)
+		log.V(1).Info("Labeling namespace", "name", u.GetName(), InjectionLabel, labels[InjectionLabel])
+		u.SetLabels(labels)
+		return nil
+	}
+}
+
+// MeshTransform annotates the control plane Deployments for the
+// sidecar injection of spec.istio. With sidecars, the webhook port is
+// left outside the mesh, since the API server calling it has none.
+func MeshTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		istio := instance.Spec.Istio
+		if istio == nil || !istio.MeshCompatibility || u.GetKind() != "Deployment" || u.GetNamespace() != instance.InstallNamespace() {
+			return nil
+		}
+		deployment := &appsv1.Deployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment); err != nil {
+			log.Error(err, "Error converting Unstructured to Deployment", "unstructured", u, "deployment", deployment)
+			return err
+		}
+		log.V(1).Info("Setting sidecar injection", "deployment", u.GetName(), "inject", istio.SidecarInjection)
+		annotations := deployment.Spec.Template.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[sidecarInjectAnnotation] = strconv.FormatBool(istio.SidecarInjection)
+		if istio.SidecarInjection && u.GetName() == webhookDeployment {
+			annotations[excludeInboundPortsAnnotation] = webhookPort
+		}
+		deployment.Spec.Template.SetAnnotations(annotations)
+		return updateUnstructured(u, deployment, log)
+	}
+}
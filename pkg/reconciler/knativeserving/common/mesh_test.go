@@ -0,0 +1,115 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestMeshTransform(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	tests := []struct {
+		name       string
+		istio      *servingv1alpha1.Istio
+		deployment string
+		namespace  string
+		want       map[string]string
+	}{{
+		name:       "disabled",
+		deployment: "controller",
+		namespace:  "knative-serving",
+	}, {
+		name:       "excluded from the mesh",
+		istio:      &servingv1alpha1.Istio{MeshCompatibility: true},
+		deployment: "webhook",
+		namespace:  "knative-serving",
+		want:       map[string]string{"sidecar.istio.io/inject": "false"},
+	}, {
+		name:       "injected",
+		istio:      &servingv1alpha1.Istio{MeshCompatibility: true, SidecarInjection: true},
+		deployment: "controller",
+		namespace:  "knative-serving",
+		want:       map[string]string{"sidecar.istio.io/inject": "true"},
+	}, {
+		name:       "webhook port excluded",
+		istio:      &servingv1alpha1.Istio{MeshCompatibility: true, SidecarInjection: true},
+		deployment: "webhook",
+		namespace:  "knative-serving",
+		want: map[string]string{
+			"sidecar.istio.io/inject":                      "true",
+			"traffic.sidecar.istio.io/excludeInboundPorts": "8443",
+		},
+	}, {
+		name:       "other namespace",
+		istio:      &servingv1alpha1.Istio{MeshCompatibility: true, SidecarInjection: true},
+		deployment: "3scale-kourier-gateway",
+		namespace:  "kourier-system",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &servingv1alpha1.KnativeServing{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving"},
+				Spec:       servingv1alpha1.KnativeServingSpec{Istio: tt.istio},
+			}
+			u := makeUnstructuredDeploymentWithVolumes(t, &deploymentOverridesTest{deploymentName: tt.deployment})
+			u.SetNamespace(tt.namespace)
+			err := MeshTransform(instance, logf.Log.WithName("mesh"))(&u)
+			assertEqual(t, err, nil)
+			deployment := &appsv1.Deployment{}
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment)
+			assertEqual(t, err, nil)
+			if got := deployment.Spec.Template.Annotations; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("annotations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamespaceInjectionTransform(t *testing.T) {
+	tests := []struct {
+		name      string
+		istio     *servingv1alpha1.Istio
+		namespace string
+		want      string
+	}{{
+		name:      "not in the mesh",
+		namespace: "knative-serving",
+		want:      "disabled",
+	}, {
+		name:      "excluded from the mesh",
+		istio:     &servingv1alpha1.Istio{MeshCompatibility: true},
+		namespace: "knative-serving",
+		want:      "disabled",
+	}, {
+		name:      "injected",
+		istio:     &servingv1alpha1.Istio{MeshCompatibility: true, SidecarInjection: true},
+		namespace: "knative-serving",
+		want:      "enabled",
+	}, {
+		name:      "other namespace",
+		namespace: "kourier-system",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &servingv1alpha1.KnativeServing{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving"},
+				Spec:       servingv1alpha1.KnativeServingSpec{Istio: tt.istio},
+			}
+			u := unstructured.Unstructured{}
+			u.SetAPIVersion("v1")
+			u.SetKind("Namespace")
+			u.SetName(tt.namespace)
+			u.SetLabels(map[string]string{"serving.knative.dev/release": "v0.7.0"})
+			err := NamespaceInjectionTransform(instance, logf.Log.WithName("mesh"))(&u)
+			assertEqual(t, err, nil)
+			assertEqual(t, u.GetLabels()["istio-injection"], tt.want)
+			assertEqual(t, u.GetLabels()["serving.knative.dev/release"], "v0.7.0")
+		})
+	}
+}
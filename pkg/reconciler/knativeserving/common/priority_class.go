@@ -0,0 +1,38 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+// PriorityClassTransform gives the pods of the control plane
+// deployments the PriorityClass of spec.controlPlanePriorityClass. It
+// comes before the deployment overrides, whose own priorityClassName
+// takes precedence.
+func PriorityClassTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		class := instance.Spec.ControlPlanePriorityClass
+		if class == "" || u.GetKind() != "Deployment" || u.GetNamespace() != instance.InstallNamespace() {
+			return nil
+		}
+		log.V(1).Info("Setting priority class", "deployment", u.GetName(), "priorityClassName", class)
+		return unstructured.SetNestedField(u.Object, class, "spec", "template", "spec", "priorityClassName")
+	}
+}
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestPriorityClassTransform(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving"},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			ControlPlanePriorityClass: "system-cluster-critical",
+			DeploymentOverrides: []servingv1alpha1.DeploymentOverride{
+				{Name: "activator", PriorityClassName: "serving-data-plane"},
+			},
+		},
+	}
+	tests := []struct {
+		name      string
+		namespace string
+		want      string
+	}{
+		{name: "controller", namespace: "knative-serving", want: "system-cluster-critical"},
+		{name: "activator", namespace: "knative-serving", want: "serving-data-plane"},
+		{name: "3scale-kourier-gateway", namespace: "kourier-system", want: ""},
+	}
+	log := logf.Log.WithName("priority")
+	for _, tt := range tests {
+		u := makeUnstructuredDeploymentWithVolumes(t, &deploymentOverridesTest{deploymentName: tt.name})
+		u.SetNamespace(tt.namespace)
+		for _, transform := range []func(*unstructured.Unstructured) error{
+			PriorityClassTransform(instance, log),
+			DeploymentOverridesTransform(runtime.NewScheme(), instance, log),
+		} {
+			assertEqual(t, transform(&u), nil)
+		}
+		class, _, _ := unstructured.NestedString(u.Object, "spec", "template", "spec", "priorityClassName")
+		assertEqual(t, class, tt.want)
+	}
+}
@@ -0,0 +1,122 @@
+package common
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestProbesTransform(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	ten, thirty, five := int32(10), int32(30), int32(5)
+	tests := []struct {
+		name          string
+		deployment    string
+		liveness      bool
+		overrides     []servingv1alpha1.ProbeOverride
+		wantReadiness corev1.Probe
+		wantLiveness  *corev1.Probe
+	}{{
+		name:       "tunes the given timing only",
+		deployment: "activator",
+		liveness:   true,
+		overrides: []servingv1alpha1.ProbeOverride{{
+			Container: "activator",
+			Readiness: &servingv1alpha1.ProbeTuning{InitialDelaySeconds: &ten, TimeoutSeconds: &five},
+			Liveness:  &servingv1alpha1.ProbeTuning{InitialDelaySeconds: &thirty},
+		}},
+		wantReadiness: corev1.Probe{InitialDelaySeconds: 10, TimeoutSeconds: 5, PeriodSeconds: 10, FailureThreshold: 3},
+		wantLiveness:  &corev1.Probe{InitialDelaySeconds: 30, TimeoutSeconds: 1, PeriodSeconds: 10, FailureThreshold: 3},
+	}, {
+		name:       "leaves a missing probe alone",
+		deployment: "autoscaler",
+		overrides: []servingv1alpha1.ProbeOverride{{
+			Container: "autoscaler",
+			Liveness:  &servingv1alpha1.ProbeTuning{InitialDelaySeconds: &thirty},
+		}},
+		wantReadiness: corev1.Probe{TimeoutSeconds: 1, PeriodSeconds: 10, FailureThreshold: 3},
+	}, {
+		name:       "ignores other deployments",
+		deployment: "activator",
+		liveness:   true,
+		overrides: []servingv1alpha1.ProbeOverride{{
+			Container:  "activator",
+			Deployment: "activator-canary",
+			Readiness:  &servingv1alpha1.ProbeTuning{InitialDelaySeconds: &ten},
+		}},
+		wantReadiness: corev1.Probe{TimeoutSeconds: 1, PeriodSeconds: 10, FailureThreshold: 3},
+		wantLiveness:  &corev1.Probe{TimeoutSeconds: 1, PeriodSeconds: 10, FailureThreshold: 3},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := makeUnstructuredDeploymentWithProbes(t, tt.deployment, tt.liveness)
+			instance := &servingv1alpha1.KnativeServing{
+				Spec: servingv1alpha1.KnativeServingSpec{
+					Probes: tt.overrides,
+				},
+			}
+			err := ProbesTransform(instance, logf.Log.WithName(tt.name))(&u)
+			assertEqual(t, err, nil)
+
+			deployment := &appsv1.Deployment{}
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment)
+			assertEqual(t, err, nil)
+			container := deployment.Spec.Template.Spec.Containers[0]
+			assertProbeTiming(t, "readiness", container.ReadinessProbe, &tt.wantReadiness)
+			assertProbeTiming(t, "liveness", container.LivenessProbe, tt.wantLiveness)
+		})
+	}
+}
+
+func assertProbeTiming(t *testing.T, name string, got, want *corev1.Probe) {
+	t.Helper()
+	if (got == nil) != (want == nil) {
+		t.Fatalf("%s probe = %v, want %v", name, got, want)
+	}
+	if got == nil {
+		return
+	}
+	assertEqual(t, got.InitialDelaySeconds, want.InitialDelaySeconds)
+	assertEqual(t, got.TimeoutSeconds, want.TimeoutSeconds)
+	assertEqual(t, got.PeriodSeconds, want.PeriodSeconds)
+	assertEqual(t, got.FailureThreshold, want.FailureThreshold)
+	if got.HTTPGet == nil || got.HTTPGet.Path != "/healthz" {
+		t.Errorf("%s probe check = %v, want the manifest's", name, got.Handler)
+	}
+}
+
+func makeUnstructuredDeploymentWithProbes(t *testing.T, name string, liveness bool) unstructured.Unstructured {
+	probe := func() *corev1.Probe {
+		return &corev1.Probe{
+			Handler: corev1.Handler{
+				HTTPGet: &corev1.HTTPGetAction{Path: "/healthz"},
+			},
+			TimeoutSeconds:   1,
+			PeriodSeconds:    10,
+			FailureThreshold: 3,
+		}
+	}
+	container := corev1.Container{
+		Name:           name,
+		ReadinessProbe: probe(),
+	}
+	if liveness {
+		container.LivenessProbe = probe()
+	}
+	deployment := appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{container}
+	result, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&deployment)
+	if err != nil {
+		t.Fatalf("Could not create unstructured deployment object: %v, err: %v", result, err)
+	}
+	return unstructured.Unstructured{Object: result}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+var (
+	// The deployments that make requests outside the cluster
+	proxiedDeployments = map[string]bool{
+		"controller": true,
+		"webhook":    true,
+		"autoscaler": true,
+	}
+)
+
+// ProxyTransform has the control plane reach outside the cluster
+// through the proxy of spec.proxy
+func ProxyTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		proxy := instance.Spec.Proxy
+		if proxy == nil || u.GetKind() != "Deployment" || !proxiedDeployments[u.GetName()] {
+			return nil
+		}
+		env := []corev1.EnvVar{
+			{Name: "HTTP_PROXY", Value: proxy.HTTPProxy},
+			{Name: "HTTPS_PROXY", Value: proxy.HTTPSProxy},
+			{Name: "NO_PROXY", Value: proxy.NoProxy},
+		}
+		deployment := &appsv1.Deployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment); err != nil {
+			log.Error(err, "Error converting Unstructured to Deployment", "unstructured", u, "deployment", deployment)
+			return err
+		}
+		log.V(1).Info("Setting proxy", "deployment", u.GetName(), "proxy", proxy)
+		containers := deployment.Spec.Template.Spec.Containers
+		for i := range containers {
+			for _, e := range env {
+				if e.Value != "" {
+					setEnv(&containers[i], e.Name, e.Value)
+				}
+			}
+		}
+		return updateUnstructured(u, deployment, log)
+	}
+}
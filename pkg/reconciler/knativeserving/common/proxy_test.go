@@ -0,0 +1,43 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestProxyTransform(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Proxy: &servingv1alpha1.Proxy{
+				HTTPSProxy: "http://proxy.corp:3128",
+				NoProxy:    ".cluster.local,10.0.0.0/8",
+			},
+		},
+	}
+	for _, name := range []string{"controller", "activator"} {
+		u := makeUnstructuredDeploymentWithVolumes(t, &deploymentOverridesTest{deploymentName: name})
+		err := ProxyTransform(instance, logf.Log.WithName("proxy"))(&u)
+		assertEqual(t, err, nil)
+		deployment := &appsv1.Deployment{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment)
+		assertEqual(t, err, nil)
+
+		var want []corev1.EnvVar
+		if name == "controller" {
+			want = []corev1.EnvVar{
+				{Name: "HTTPS_PROXY", Value: "http://proxy.corp:3128"},
+				{Name: "NO_PROXY", Value: ".cluster.local,10.0.0.0/8"},
+			}
+		}
+		if got := deployment.Spec.Template.Spec.Containers[0].Env; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s env = %v, want %v", name, got, want)
+		}
+	}
+}
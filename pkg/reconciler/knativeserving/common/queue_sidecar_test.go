@@ -0,0 +1,48 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestQueueSidecarConfigTransform(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Config: map[string]map[string]string{
+				"deployment": {"queueSidecarImage": "example.com/queue:config"},
+			},
+			QueueSidecar: &servingv1alpha1.QueueSidecar{
+				Image: "example.com/queue:v1",
+				Resources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("25m")},
+					Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("200Mi")},
+				},
+			},
+		},
+	}
+	u := makeUnstructuredConfigMap("config-deployment", map[string]interface{}{
+		"queueSidecarImage":              "gcr.io/knative-releases/queue",
+		"registriesSkippingTagResolving": "ko.local",
+	})
+	log := logf.Log.WithName("queue-sidecar")
+	assertEqual(t, ConfigMapTransform(instance, log)(&u), nil)
+	assertEqual(t, QueueSidecarConfigTransform(instance, log)(&u), nil)
+
+	data, _, _ := unstructured.NestedStringMap(u.Object, "data")
+	want := map[string]string{
+		"queueSidecarImage":              "example.com/queue:v1",
+		"queueSidecarCPURequest":         "25m",
+		"queueSidecarMemoryLimit":        "200Mi",
+		"registriesSkippingTagResolving": "ko.local",
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("data = %v, want %v", data, want)
+	}
+}
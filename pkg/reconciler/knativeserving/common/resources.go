@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"github.com/go-logr/logr"
+	mf "github.com/jcrossley3/manifestival"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func ResourcesTransform(instance *servingv1alpha1.KnativeServing, log logr.Logger) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if u.GetKind() == "Deployment" && len(instance.Spec.Resources) > 0 {
+			return updateResources(u, instance.Spec.Resources, log)
+		}
+		return nil
+	}
+}
+
+func updateResources(u *unstructured.Unstructured, overrides []servingv1alpha1.ResourceRequirementsOverride, log logr.Logger) error {
+	deployment := &appsv1.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment); err != nil {
+		log.Error(err, "Error converting Unstructured to Deployment", "unstructured", u, "deployment", deployment)
+		return err
+	}
+	changed := false
+	containers := deployment.Spec.Template.Spec.Containers
+	for i := range containers {
+		container := &containers[i]
+		for _, override := range overrides {
+			if override.Container != container.Name || (override.Deployment != "" && override.Deployment != deployment.GetName()) {
+				continue
+			}
+			log.V(1).Info("Overriding resources", "deployment", deployment.GetName(), "container", container.Name, "resources", override.ResourceRequirements)
+			container.Resources.Requests = mergeResources(container.Resources.Requests, override.Requests)
+			container.Resources.Limits = mergeResources(container.Resources.Limits, override.Limits)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return updateUnstructured(u, deployment, log)
+}
+
+// mergeResources replaces only the quantities given in the override
+func mergeResources(current, override corev1.ResourceList) corev1.ResourceList {
+	if len(override) == 0 {
+		return current
+	}
+	if current == nil {
+		current = corev1.ResourceList{}
+	}
+	for name, quantity := range override {
+		current[name] = quantity
+	}
+	return current
+}
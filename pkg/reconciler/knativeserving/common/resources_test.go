@@ -0,0 +1,144 @@
+package common
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+type resourcesTest struct {
+	name           string
+	deploymentName string
+	overrides      []servingv1alpha1.ResourceRequirementsOverride
+	expectedCPU    string
+	expectedMemory string
+	expectedLimit  string
+}
+
+var resourcesTests = []resourcesTest{
+	{
+		name:           "OverridesCPURequestOnly",
+		deploymentName: "activator",
+		overrides: []servingv1alpha1.ResourceRequirementsOverride{{
+			Container: "activator",
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+			},
+		}},
+		expectedCPU:    "500m",
+		expectedMemory: "60Mi",
+		expectedLimit:  "1",
+	},
+	{
+		name:           "OverridesLimits",
+		deploymentName: "activator",
+		overrides: []servingv1alpha1.ResourceRequirementsOverride{{
+			Container: "activator",
+			ResourceRequirements: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+			},
+		}},
+		expectedCPU:    "300m",
+		expectedMemory: "60Mi",
+		expectedLimit:  "2",
+	},
+	{
+		name:           "IgnoresOtherContainers",
+		deploymentName: "activator",
+		overrides: []servingv1alpha1.ResourceRequirementsOverride{{
+			Container: "autoscaler",
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+			},
+		}},
+		expectedCPU:    "300m",
+		expectedMemory: "60Mi",
+		expectedLimit:  "1",
+	},
+	{
+		name:           "IgnoresOtherDeployments",
+		deploymentName: "activator",
+		overrides: []servingv1alpha1.ResourceRequirementsOverride{{
+			Container:  "activator",
+			Deployment: "other",
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+			},
+		}},
+		expectedCPU:    "300m",
+		expectedMemory: "60Mi",
+		expectedLimit:  "1",
+	},
+}
+
+func TestResourcesTransform(t *testing.T) {
+	for _, tt := range resourcesTests {
+		t.Run(tt.name, func(t *testing.T) {
+			runResourcesTransformTest(t, &tt)
+		})
+	}
+}
+
+func runResourcesTransformTest(t *testing.T, tt *resourcesTest) {
+	log := logf.Log.WithName(tt.name)
+	logf.SetLogger(logf.ZapLogger(true))
+	u := makeUnstructuredDeploymentWithResources(t, tt.deploymentName)
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Resources: tt.overrides,
+		},
+	}
+	err := ResourcesTransform(instance, log)(&u)
+	assertEqual(t, err, nil)
+
+	deployment := &appsv1.Deployment{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment)
+	assertEqual(t, err, nil)
+	resources := deployment.Spec.Template.Spec.Containers[0].Resources
+	assertEqual(t, resources.Requests.Cpu().String(), tt.expectedCPU)
+	assertEqual(t, resources.Requests.Memory().String(), tt.expectedMemory)
+	assertEqual(t, resources.Limits.Cpu().String(), tt.expectedLimit)
+}
+
+func makeUnstructuredDeploymentWithResources(t *testing.T, name string) unstructured.Unstructured {
+	deployment := appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: name,
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("300m"),
+								corev1.ResourceMemory: resource.MustParse("60Mi"),
+							},
+							Limits: corev1.ResourceList{
+								corev1.ResourceCPU: resource.MustParse("1"),
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+	result, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&deployment)
+	if err != nil {
+		t.Fatalf("Could not create unstructured deployment object: %v, err: %v", result, err)
+	}
+	return unstructured.Unstructured{
+		Object: result,
+	}
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestTracingTransform(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Config: map[string]map[string]string{
+				"tracing": {"enable": "false", "sample-rate": "0.5"},
+			},
+			Tracing: &servingv1alpha1.Tracing{
+				Backend:        servingv1alpha1.ZipkinTracingBackend,
+				ZipkinEndpoint: "http://zipkin.istio-system:9411/api/v2/spans",
+			},
+		},
+	}
+	u := makeUnstructuredConfigMap("config-tracing", map[string]interface{}{
+		"debug": "true",
+	})
+	log := logf.Log.WithName("tracing")
+	assertEqual(t, ConfigMapTransform(instance, log)(&u), nil)
+	assertEqual(t, TracingTransform(instance, log)(&u), nil)
+
+	data, _, _ := unstructured.NestedStringMap(u.Object, "data")
+	want := map[string]string{
+		"backend":         "zipkin",
+		"enable":          "true",
+		"zipkin-endpoint": "http://zipkin.istio-system:9411/api/v2/spans",
+		"sample-rate":     "0.5",
+		"debug":           "false",
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("data = %v, want %v", data, want)
+	}
+}
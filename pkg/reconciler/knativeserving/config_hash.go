@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	// Changes with the ConfigMaps a Deployment consumes, restarting it
+	configHashAnnotation = "operator.knative.dev/config-hash"
+)
+
+var (
+	// Knative components read the ConfigMaps these variables name
+	// through the API rather than mounting them
+	configNameEnv = regexp.MustCompile(`^CONFIG_[A-Z0-9_]+_NAME$`)
+)
+
+// With spec.restartOnConfigChange, annotate the pod template of each
+// Deployment with a hash of the manifest's ConfigMaps it consumes, so
+// changing them, e.g. through spec.config, rolls it
+func withConfigHashes(instance *servingv1alpha1.KnativeServing, resources []unstructured.Unstructured) error {
+	if !instance.Spec.RestartOnConfigChange {
+		return nil
+	}
+	configMaps := map[string]*unstructured.Unstructured{}
+	for i := range resources {
+		if u := &resources[i]; u.GetKind() == "ConfigMap" {
+			configMaps[u.GetNamespace()+"/"+u.GetName()] = u
+		}
+	}
+	for i := range resources {
+		u := &resources[i]
+		if u.GetKind() != "Deployment" {
+			continue
+		}
+		deployment := &appsv1.Deployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, deployment); err != nil {
+			return err
+		}
+		var consumed []*unstructured.Unstructured
+		for _, name := range consumedConfigMaps(&deployment.Spec.Template.Spec) {
+			if cm, ok := configMaps[u.GetNamespace()+"/"+name]; ok {
+				consumed = append(consumed, cm)
+			}
+		}
+		if len(consumed) == 0 {
+			continue
+		}
+		annotations, _, _ := unstructured.NestedStringMap(u.Object, "spec", "template", "metadata", "annotations")
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[configHashAnnotation] = hashConfigMaps(consumed)
+		if err := unstructured.SetNestedStringMap(u.Object, annotations, "spec", "template", "metadata", "annotations"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The names of the ConfigMaps the pods mount, reference from their
+// environment or name in a CONFIG_*_NAME variable, sorted
+func consumedConfigMaps(spec *corev1.PodSpec) []string {
+	seen := map[string]bool{}
+	for _, v := range spec.Volumes {
+		if v.ConfigMap != nil {
+			seen[v.ConfigMap.Name] = true
+		}
+		if v.Projected != nil {
+			for _, s := range v.Projected.Sources {
+				if s.ConfigMap != nil {
+					seen[s.ConfigMap.Name] = true
+				}
+			}
+		}
+	}
+	for _, c := range append(spec.InitContainers, spec.Containers...) {
+		for _, from := range c.EnvFrom {
+			if from.ConfigMapRef != nil {
+				seen[from.ConfigMapRef.Name] = true
+			}
+		}
+		for _, env := range c.Env {
+			switch {
+			case env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil:
+				seen[env.ValueFrom.ConfigMapKeyRef.Name] = true
+			case configNameEnv.MatchString(env.Name) && env.Value != "":
+				seen[env.Value] = true
+			}
+		}
+	}
+	result := make([]string, 0, len(seen))
+	for name := range seen {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Hash the data of the ConfigMaps, in the given order
+func hashConfigMaps(configMaps []*unstructured.Unstructured) string {
+	h := sha256.New()
+	for _, cm := range configMaps {
+		h.Write([]byte(cm.GetName() + "\n"))
+		for _, field := range []string{"data", "binaryData"} {
+			data, _, _ := unstructured.NestedMap(cm.Object, field)
+			keys := make([]string, 0, len(data))
+			for k := range data {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				v, _ := data[k].(string)
+				h.Write([]byte(field + "." + k + "=" + v + "\n"))
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
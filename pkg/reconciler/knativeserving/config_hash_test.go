@@ -0,0 +1,109 @@
+package knativeserving
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const configHashManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-observability
+  namespace: knative-serving
+data:
+  metrics.backend-destination: prometheus
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-logging
+  namespace: knative-serving
+data:
+  loglevel.webhook: info
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller
+  namespace: knative-serving
+spec:
+  template:
+    spec:
+      containers:
+      - name: controller
+        env:
+        - name: CONFIG_OBSERVABILITY_NAME
+          value: config-observability
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: webhook
+  namespace: knative-serving
+spec:
+  template:
+    spec:
+      containers:
+      - name: webhook
+      volumes:
+      - name: logging
+        configMap:
+          name: config-logging
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: activator
+  namespace: knative-serving
+spec:
+  template:
+    spec:
+      containers:
+      - name: activator
+`
+
+func TestWithConfigHashes(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{RestartOnConfigChange: true},
+	}
+	hashes := func(observability string) map[string]string {
+		resources := newTestManifest(t, configHashManifest, c).Resources
+		unstructured.SetNestedField(resources[0].Object, observability, "data", "metrics.backend-destination")
+		if err := withConfigHashes(instance, resources); err != nil {
+			t.Fatalf("withConfigHashes() = %v", err)
+		}
+		result := map[string]string{}
+		for _, u := range resources[2:] {
+			hash, _, _ := unstructured.NestedString(u.Object, "spec", "template", "metadata", "annotations", configHashAnnotation)
+			result[u.GetName()] = hash
+		}
+		return result
+	}
+	prometheus, opencensus := hashes("prometheus"), hashes("opencensus")
+
+	if prometheus["controller"] == "" || prometheus["controller"] == opencensus["controller"] {
+		t.Errorf("controller hashes = %q and %q, want them to differ", prometheus["controller"], opencensus["controller"])
+	}
+	if prometheus["webhook"] == "" || prometheus["webhook"] != opencensus["webhook"] {
+		t.Errorf("webhook hashes = %q and %q, want them equal", prometheus["webhook"], opencensus["webhook"])
+	}
+	if prometheus["activator"] != "" {
+		t.Errorf("activator hash = %q, want none", prometheus["activator"])
+	}
+}
+
+func TestWithConfigHashesIsOptIn(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	resources := newTestManifest(t, configHashManifest, c).Resources
+	if err := withConfigHashes(&servingv1alpha1.KnativeServing{}, resources); err != nil {
+		t.Fatalf("withConfigHashes() = %v", err)
+	}
+	for _, u := range resources {
+		if _, found, _ := unstructured.NestedMap(u.Object, "spec", "template", "metadata", "annotations"); found {
+			t.Errorf("%s %s was annotated", u.GetKind(), u.GetName())
+		}
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func crdsOnly(instance *servingv1alpha1.KnativeServing) bool {
+	return instance.Spec.CRDsOnly
+}
+
+// Keep only the CustomResourceDefinitions of the resources
+func onlyCRDs(resources []unstructured.Unstructured) []unstructured.Unstructured {
+	var result []unstructured.Unstructured
+	for _, u := range resources {
+		if u.GetKind() == "CustomResourceDefinition" {
+			result = append(result, u)
+		}
+	}
+	return result
+}
+
+// In place of the probes of a complete install, report whether every
+// CRD of the release is established, i.e. serves its custom resources
+func (r *ReconcileKnativeServing) probeCRDs(instance *servingv1alpha1.KnativeServing) error {
+	core, ingress, err := r.selectIngress(instance)
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, u := range onlyCRDs(append(core[:len(core):len(core)], ingress...)) {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(apiextensionsv1beta1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+		err := r.client.Get(context.TODO(), client.ObjectKey{Name: u.GetName()}, crd)
+		switch {
+		case errors.IsNotFound(err):
+			pending = append(pending, u.GetName())
+		case err != nil:
+			return err
+		case !crdEstablished(crd):
+			pending = append(pending, u.GetName())
+		}
+	}
+	if len(pending) > 0 {
+		sort.Strings(pending)
+		msg := fmt.Sprintf("Waiting on CRDs to be established: %s", strings.Join(pending, ", "))
+		log.Info(msg)
+		instance.Status.MarkProbesFailed(msg)
+	} else {
+		instance.Status.MarkProbesSucceeded()
+	}
+	return r.updateStatus(instance)
+}
+
+// Report whether the CRD's Established condition is True
+func crdEstablished(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == string(apiextensionsv1beta1.Established) {
+			return condition["status"] == string(apiextensionsv1beta1.ConditionTrue)
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package knativeserving
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func TestTransformOnlyCRDs(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c, scheme: newTestScheme(), config: newTestManifest(t, upgradeManifest, c)}
+	manifest, err := r.transform(newInstance(withSpec(servingv1alpha1.KnativeServingSpec{CRDsOnly: true})), nil)
+	if err != nil {
+		t.Fatalf("transform() = %v", err)
+	}
+	if len(manifest.Resources) != 1 || manifest.Resources[0].GetName() != "services.serving.knative.dev" {
+		var names []string
+		for _, u := range manifest.Resources {
+			names = append(names, u.GetKind()+"/"+u.GetName())
+		}
+		t.Errorf("transform() = %v, want only the CRD", names)
+	}
+}
+
+func TestCRDsOnlyReadyOnceEstablished(t *testing.T) {
+	instance := newInstance(withSpec(servingv1alpha1.KnativeServingSpec{CRDsOnly: true}))
+	instance.Status.InitializeConditions()
+	c := newFakeClient(newTestScheme(), instance.DeepCopy())
+	r := &ReconcileKnativeServing{
+		client:   c,
+		recorder: record.NewFakeRecorder(10),
+		config:   newTestManifest(t, upgradeManifest, c),
+		probe: func(string, []byte) error {
+			t.Error("the webhook and controller aren't probed")
+			return nil
+		},
+	}
+
+	// The webhook and controller deployments aren't waited on
+	if err := r.checkDeployments(instance); err != nil {
+		t.Fatalf("checkDeployments() = %v", err)
+	}
+	if !instance.Status.IsAvailable() {
+		t.Fatalf("expected no deployments to wait on, got %v", instance.Status.Deployments)
+	}
+
+	if err := r.probeServing(instance); err != nil {
+		t.Fatalf("probeServing() = %v", err)
+	}
+	if !instance.Status.GetCondition(servingv1alpha1.ProbesSucceeded).IsFalse() {
+		t.Error("expected the probes to fail without the CRD")
+	}
+
+	crd := newStoredCRD("v1alpha1")
+	if err := c.Create(context.TODO(), crd); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	if err := r.probeServing(instance); err != nil {
+		t.Fatalf("probeServing() = %v", err)
+	}
+	if !instance.Status.GetCondition(servingv1alpha1.ProbesSucceeded).IsFalse() {
+		t.Error("expected the probes to fail until the CRD is established")
+	}
+
+	unstructured.SetNestedSlice(crd.Object, []interface{}{
+		map[string]interface{}{"type": "Established", "status": "True"},
+	}, "status", "conditions")
+	if err := c.Update(context.TODO(), crd); err != nil {
+		t.Fatalf("Update() = %v", err)
+	}
+	if err := r.probeServing(instance); err != nil {
+		t.Fatalf("probeServing() = %v", err)
+	}
+	if !instance.Status.GetCondition(servingv1alpha1.ProbesSucceeded).IsTrue() {
+		t.Errorf("expected the probes to succeed once established: %+v", instance.Status.GetCondition(servingv1alpha1.ProbesSucceeded))
+	}
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"sort"
+
+	mf "github.com/jcrossley3/manifestival"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Pin the images of spec.digestPinning to the digests their tags
+// resolve to, verifying their cosign signatures if a key is given.
+// The digests recorded in the status are reused, so that reapplying
+// the same images doesn't pick up a tag that moved since, and so are
+// the signatures verified before, so that it doesn't reach the registry.
+func (r *ReconcileKnativeServing) pinDigests(instance *servingv1alpha1.KnativeServing, manifest *mf.Manifest) error {
+	pinning := instance.Spec.DigestPinning
+	if pinning == nil {
+		instance.Status.Images = nil
+		return nil
+	}
+	var key *ecdsa.PublicKey
+	if pinning.CosignPublicKey != nil {
+		var err error
+		if key, err = r.cosignPublicKey(instance.GetNamespace(), pinning.CosignPublicKey); err != nil {
+			return err
+		}
+	}
+	credentials, err := r.registryCredentials(instance)
+	if err != nil {
+		return err
+	}
+	registry := newRegistryClient(credentials)
+	recorded := map[string]string{}
+	for _, image := range instance.Status.Images {
+		recorded[image.Image] = image.Digest
+	}
+	pinned := map[string]string{}
+	var images []servingv1alpha1.PinnedImage
+	pin := func(image string) (string, error) {
+		if result, ok := pinned[image]; ok {
+			return result, nil
+		}
+		ref, err := parseImageReference(image)
+		if err != nil {
+			return "", err
+		}
+		digest := ref.digest
+		if digest == "" {
+			digest = recorded[image]
+		}
+		if digest == "" {
+			if digest, err = registry.digest(ref); err != nil {
+				return "", fmt.Errorf("unable to resolve image %s: %v", image, err)
+			}
+		}
+		if key != nil {
+			if err := r.verifyImage(registry, ref, digest, key); err != nil {
+				return "", err
+			}
+		}
+		result := image
+		if ref.digest == "" {
+			result = image + "@" + digest
+		}
+		pinned[image] = result
+		images = append(images, servingv1alpha1.PinnedImage{Image: image, Digest: digest})
+		return result, nil
+	}
+	for i := range manifest.Resources {
+		if err := pinImages(&manifest.Resources[i], pin); err != nil {
+			return err
+		}
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].Image < images[j].Image })
+	instance.Status.Images = images
+	return nil
+}
+
+// Replace the images of the containers of a workload, the image of a
+// caching Image and the queue sidecar image of config-deployment
+func pinImages(u *unstructured.Unstructured, pin func(string) (string, error)) error {
+	switch u.GetKind() {
+	case "Deployment", "DaemonSet", "StatefulSet", "Job":
+		for _, field := range []string{"initContainers", "containers"} {
+			containers, found, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", field)
+			if !found {
+				continue
+			}
+			for _, c := range containers {
+				container, ok := c.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				image, ok := container["image"].(string)
+				if !ok || image == "" {
+					continue
+				}
+				result, err := pin(image)
+				if err != nil {
+					return err
+				}
+				container["image"] = result
+			}
+			if err := unstructured.SetNestedSlice(u.Object, containers, "spec", "template", "spec", field); err != nil {
+				return err
+			}
+		}
+	case "Image":
+		return pinField(u, pin, "spec", "image")
+	case "ConfigMap":
+		if u.GetName() == "config-deployment" {
+			return pinField(u, pin, "data", "queueSidecarImage")
+		}
+	}
+	return nil
+}
+
+func pinField(u *unstructured.Unstructured, pin func(string) (string, error), fields ...string) error {
+	image, found, _ := unstructured.NestedString(u.Object, fields...)
+	if !found || image == "" {
+		return nil
+	}
+	result, err := pin(image)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(u.Object, result, fields...)
+}
+
+// The credentials of the image pull secrets of spec.registry, which
+// live in the namespace Knative Serving is installed into
+func (r *ReconcileKnativeServing) registryCredentials(instance *servingv1alpha1.KnativeServing) (map[string]registryCredentials, error) {
+	result := map[string]registryCredentials{}
+	for _, ref := range instance.Spec.Registry.ImagePullSecrets {
+		secret := &corev1.Secret{}
+		key := client.ObjectKey{Namespace: instance.InstallNamespace(), Name: ref.Name}
+		if err := r.client.Get(context.TODO(), key, secret); err != nil {
+			if errors.IsNotFound(err) {
+				// Not created yet, anonymous access may do
+				continue
+			}
+			return nil, err
+		}
+		var credentials map[string]registryCredentials
+		var err error
+		switch secret.Type {
+		case corev1.SecretTypeDockerConfigJson:
+			credentials, err = parseDockerConfig(secret.Data[corev1.DockerConfigJsonKey], false)
+		case corev1.SecretTypeDockercfg:
+			credentials, err = parseDockerConfig(secret.Data[corev1.DockerConfigKey], true)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid image pull secret %s: %v", ref.Name, err)
+		}
+		for host, c := range credentials {
+			result[host] = c
+		}
+	}
+	return result, nil
+}
+
+// The public key of spec.digestPinning.cosignPublicKey, in the
+// namespace of the KnativeServing
+func (r *ReconcileKnativeServing) cosignPublicKey(namespace string, selector *corev1.SecretKeySelector) (*ecdsa.PublicKey, error) {
+	secret := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: selector.Name}, secret); err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(secret.Data[selector.Key])
+	if block == nil {
+		return nil, permanent(fmt.Errorf("no PEM encoded public key in key %s of Secret %s", selector.Key, selector.Name))
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, permanent(fmt.Errorf("invalid public key in Secret %s: %v", selector.Name, err))
+	}
+	ecdsaKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, permanent(fmt.Errorf("the public key in Secret %s isn't an ECDSA key", selector.Name))
+	}
+	return ecdsaKey, nil
+}
+
+// Fail unless a cosign signature of the digest verifies against the key
+func (r *ReconcileKnativeServing) verifyImage(registry *registryClient, ref imageReference, digest string, key *ecdsa.PublicKey) error {
+	name := ref.name() + "@" + digest
+	if verified, ok := r.verified[name]; ok && verified.Equal(key) {
+		return nil
+	}
+	signatures, err := registry.signatures(ref, digest)
+	if err != nil {
+		return fmt.Errorf("unable to fetch the signatures of %s@%s: %v", ref.name(), digest, err)
+	}
+	for _, signature := range signatures {
+		if verifyCosignSignature(signature, digest, key) {
+			if r.verified == nil {
+				r.verified = map[string]*ecdsa.PublicKey{}
+			}
+			r.verified[name] = key
+			return nil
+		}
+	}
+	return fmt.Errorf("no signature of %s@%s verifies against the cosign public key", ref.name(), digest)
+}
+
+// A signature is valid if the key signed its payload and the payload
+// names the digest
+func verifyCosignSignature(signature cosignSignature, digest string, key *ecdsa.PublicKey) bool {
+	hash := sha256.Sum256(signature.payload)
+	if !ecdsa.VerifyASN1(key, hash[:], signature.signature) {
+		return false
+	}
+	payload := struct {
+		Critical struct {
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}{}
+	if err := json.Unmarshal(signature.payload, &payload); err != nil {
+		return false
+	}
+	return payload.Critical.Image.DockerManifestDigest == digest
+}
@@ -0,0 +1,248 @@
+package knativeserving
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const fakeRegistryToken = "Bearer pull-token"
+
+// fakeRegistry serves manifests and blobs by repository, only to the
+// bearer of its token
+type fakeRegistry struct {
+	*httptest.Server
+	manifests map[string][]byte
+	blobs     map[string][]byte
+	// How many requests it served
+	requests int32
+}
+
+func newFakeRegistry() *fakeRegistry {
+	r := &fakeRegistry{manifests: map[string][]byte{}, blobs: map[string][]byte{}}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serve))
+	return r
+}
+
+func (r *fakeRegistry) host() string {
+	return strings.TrimPrefix(r.URL, "http://")
+}
+
+func (r *fakeRegistry) push(repository, tag string, manifest []byte) string {
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(manifest))
+	r.manifests[repository+":"+tag] = manifest
+	return digest
+}
+
+// Push a cosign signature of the digest, signed by the key
+func (r *fakeRegistry) sign(t *testing.T, repository, digest string, key *ecdsa.PrivateKey) {
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"%s/%s"},"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"}}`,
+		r.host(), repository, digest))
+	hash := sha256.Sum256(payload)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	payloadDigest := fmt.Sprintf("sha256:%x", hash)
+	r.blobs[payloadDigest] = payload
+	manifest, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"layers": []map[string]interface{}{{
+			"digest":      payloadDigest,
+			"annotations": map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signature)},
+		}},
+	})
+	r.push(repository, strings.Replace(digest, ":", "-", 1)+".sig", manifest)
+}
+
+func (r *fakeRegistry) serve(w http.ResponseWriter, req *http.Request) {
+	atomic.AddInt32(&r.requests, 1)
+	if req.URL.Path == "/token" {
+		fmt.Fprint(w, `{"token":"pull-token"}`)
+		return
+	}
+	if req.Header.Get("Authorization") != fakeRegistryToken {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake"`, r.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	var content []byte
+	if i := strings.LastIndex(path, "/manifests/"); i >= 0 {
+		content = r.manifests[path[:i]+":"+path[i+len("/manifests/"):]]
+		if content != nil {
+			w.Header().Set("Docker-Content-Digest", fmt.Sprintf("sha256:%x", sha256.Sum256(content)))
+		}
+	} else if i := strings.LastIndex(path, "/blobs/"); i >= 0 {
+		content = r.blobs[path[i+len("/blobs/"):]]
+	}
+	if content == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if req.Method == http.MethodGet {
+		w.Write(content)
+	}
+}
+
+func newCosignKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func pinnedManifest(host string) string {
+	return fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller
+  namespace: knative-serving
+spec:
+  template:
+    spec:
+      containers:
+      - name: controller
+        image: %[1]s/serving/controller:v1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-deployment
+  namespace: knative-serving
+data:
+  queueSidecarImage: %[1]s/serving/queue:v1
+`, host)
+}
+
+func containerImage(t *testing.T, u *unstructured.Unstructured) string {
+	containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+	if len(containers) != 1 {
+		t.Fatalf("got containers %v, want 1", containers)
+	}
+	return containers[0].(map[string]interface{})["image"].(string)
+}
+
+func TestPinDigests(t *testing.T) {
+	registry := newFakeRegistry()
+	defer registry.Close()
+	key, publicKey := newCosignKey(t)
+	controller := registry.push("serving/controller", "v1", []byte(`{"schemaVersion":2,"config":{"digest":"controller"}}`))
+	queue := registry.push("serving/queue", "v1", []byte(`{"schemaVersion":2,"config":{"digest":"queue"}}`))
+	registry.sign(t, "serving/controller", controller, key)
+	registry.sign(t, "serving/queue", queue, key)
+
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			DigestPinning: &servingv1alpha1.DigestPinning{
+				CosignPublicKey: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "cosign"},
+					Key:                  "cosign.pub",
+				},
+			},
+		},
+	}
+	c := newFakeClient(newTestScheme(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "cosign"},
+		Data:       map[string][]byte{"cosign.pub": publicKey},
+	})
+	r := &ReconcileKnativeServing{client: c}
+	host := registry.host()
+
+	manifest := newTestManifest(t, pinnedManifest(host), c)
+	if err := r.pinDigests(instance, &manifest); err != nil {
+		t.Fatalf("pinDigests() = %v", err)
+	}
+	if got, want := containerImage(t, &manifest.Resources[0]), host+"/serving/controller:v1@"+controller; got != want {
+		t.Errorf("controller image = %s, want %s", got, want)
+	}
+	if got, want := manifest.Resources[1].Object["data"].(map[string]interface{})["queueSidecarImage"], host+"/serving/queue:v1@"+queue; got != want {
+		t.Errorf("queueSidecarImage = %s, want %s", got, want)
+	}
+	want := []servingv1alpha1.PinnedImage{
+		{Image: host + "/serving/controller:v1", Digest: controller},
+		{Image: host + "/serving/queue:v1", Digest: queue},
+	}
+	if got := instance.Status.Images; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("status.images = %v, want %v", got, want)
+	}
+
+	// Moving the tag doesn't change the pinned image, nor does pinning
+	// it again reach the registry
+	moved := registry.push("serving/controller", "v1", []byte(`{"schemaVersion":2,"config":{"digest":"moved"}}`))
+	registry.sign(t, "serving/controller", moved, key)
+	requests := atomic.LoadInt32(&registry.requests)
+	manifest = newTestManifest(t, pinnedManifest(host), c)
+	if err := r.pinDigests(instance, &manifest); err != nil {
+		t.Fatalf("pinDigests() = %v", err)
+	}
+	if got, want := containerImage(t, &manifest.Resources[0]), host+"/serving/controller:v1@"+controller; got != want {
+		t.Errorf("controller image = %s, want %s", got, want)
+	}
+	if got := atomic.LoadInt32(&registry.requests) - requests; got != 0 {
+		t.Errorf("got %d registry requests, want the verified digests reused", got)
+	}
+
+	// Another key didn't sign the images
+	_, otherKey := newCosignKey(t)
+	c = newFakeClient(newTestScheme(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "cosign"},
+		Data:       map[string][]byte{"cosign.pub": otherKey},
+	})
+	r = &ReconcileKnativeServing{client: c}
+	manifest = newTestManifest(t, pinnedManifest(host), c)
+	if err := r.pinDigests(instance, &manifest); err == nil || !strings.Contains(err.Error(), "verifies against the cosign public key") {
+		t.Errorf("pinDigests() = %v, want a verification failure", err)
+	}
+
+	// Unsigned images can still be pinned without a key
+	instance.Spec.DigestPinning.CosignPublicKey = nil
+	instance.Status.Images = nil
+	registry.push("serving/queue", "v1", []byte(`{"schemaVersion":2,"config":{"digest":"unsigned"}}`))
+	manifest = newTestManifest(t, pinnedManifest(host), c)
+	if err := r.pinDigests(instance, &manifest); err != nil {
+		t.Fatalf("pinDigests() = %v", err)
+	}
+	if got := instance.Status.Images[0].Digest; got != moved {
+		t.Errorf("controller digest = %s, want the moved tag's %s", got, moved)
+	}
+}
+
+func TestPinDigestsDisabled(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{}
+	instance.Status.Images = []servingv1alpha1.PinnedImage{{Image: "gcr.io/knative/controller:v1", Digest: "sha256:0123"}}
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c}
+	manifest := newTestManifest(t, pinnedManifest("gcr.io"), c)
+	if err := r.pinDigests(instance, &manifest); err != nil {
+		t.Fatalf("pinDigests() = %v", err)
+	}
+	if got := containerImage(t, &manifest.Resources[0]); got != "gcr.io/serving/controller:v1" {
+		t.Errorf("controller image = %s, want it unpinned", got)
+	}
+	if instance.Status.Images != nil {
+		t.Errorf("status.images = %v, want none", instance.Status.Images)
+	}
+}
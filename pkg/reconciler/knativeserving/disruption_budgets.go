@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"fmt"
+
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+// The PodDisruptionBudgets of spec.podDisruptionBudgets, each named
+// after its deployment and selecting the deployment's pods. A budget
+// for a deployment the resources don't contain, e.g. a disabled
+// component's, fails along with those that could be built.
+func podDisruptionBudgets(instance *servingv1alpha1.KnativeServing, resources []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	selectors := map[string]map[string]interface{}{}
+	for i := range resources {
+		u := &resources[i]
+		if u.GetKind() != "Deployment" {
+			continue
+		}
+		if selector, found, _ := unstructured.NestedMap(u.Object, "spec", "selector"); found {
+			selectors[u.GetName()] = selector
+		}
+	}
+	var result []unstructured.Unstructured
+	var missing []string
+	for _, b := range instance.Spec.PodDisruptionBudgets {
+		selector, ok := selectors[b.Deployment]
+		if !ok {
+			missing = append(missing, b.Deployment)
+			continue
+		}
+		result = append(result, podDisruptionBudget(instance.InstallNamespace(), b, selector))
+	}
+	if len(missing) > 0 {
+		return result, fmt.Errorf("no deployment to budget the disruption of: %v", missing)
+	}
+	return result, nil
+}
+
+func podDisruptionBudget(namespace string, budget servingv1alpha1.PodDisruptionBudget, selector map[string]interface{}) unstructured.Unstructured {
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: policyv1beta1.SchemeGroupVersion.String(),
+			Kind:       "PodDisruptionBudget",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      budget.Deployment,
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MinAvailable:   budget.MinAvailable,
+			MaxUnavailable: budget.MaxUnavailable,
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pdb)
+	if err != nil {
+		// Only a bug in the budget above could fail to convert
+		panic(err)
+	}
+	// The deployment's selector, as the manifest spells it
+	unstructured.SetNestedMap(obj, selector, "spec", "selector")
+	return unstructured.Unstructured{Object: obj}
+}
@@ -0,0 +1,66 @@
+package knativeserving
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const budgetedManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: activator
+  namespace: knative-serving
+spec:
+  selector:
+    matchLabels:
+      app: activator
+      role: activator
+`
+
+func TestPodDisruptionBudgets(t *testing.T) {
+	one := intstr.FromInt(1)
+	instance := newInstance(withSpec(servingv1alpha1.KnativeServingSpec{
+		PodDisruptionBudgets: []servingv1alpha1.PodDisruptionBudget{{Deployment: "activator", MinAvailable: &one}},
+	}))
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c, scheme: newTestScheme(), recorder: record.NewFakeRecorder(10), config: newTestManifest(t, budgetedManifest, c)}
+
+	manifest, err := r.transform(instance, nil)
+	if err != nil {
+		t.Fatalf("transform() = %v", err)
+	}
+	if _, err := applyChanged(&manifest, nil, nil); err != nil {
+		t.Fatalf("applyChanged() = %v", err)
+	}
+	pdb := &policyv1beta1.PodDisruptionBudget{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "activator"}, pdb); err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if pdb.Spec.MinAvailable == nil || pdb.Spec.MinAvailable.IntValue() != 1 || pdb.Spec.MaxUnavailable != nil {
+		t.Errorf("budget = %+v, want minAvailable 1", pdb.Spec)
+	}
+	want := map[string]string{"app": "activator", "role": "activator"}
+	if pdb.Spec.Selector == nil || !reflect.DeepEqual(pdb.Spec.Selector.MatchLabels, want) {
+		t.Errorf("selector = %v, want the activator's %v", pdb.Spec.Selector, want)
+	}
+}
+
+func TestPodDisruptionBudgetsUnknownDeployment(t *testing.T) {
+	half := intstr.FromString("50%")
+	instance := newInstance(withSpec(servingv1alpha1.KnativeServingSpec{
+		PodDisruptionBudgets: []servingv1alpha1.PodDisruptionBudget{{Deployment: "autoscaler-hpa", MaxUnavailable: &half}},
+	}))
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c, scheme: newTestScheme(), recorder: record.NewFakeRecorder(10), config: newTestManifest(t, budgetedManifest, c)}
+
+	if _, err := r.transform(instance, nil); err == nil || !isPermanent(err) {
+		t.Errorf("transform() = %v, want a permanent error", err)
+	}
+}
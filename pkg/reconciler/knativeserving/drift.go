@@ -0,0 +1,137 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	mf "github.com/jcrossley3/manifestival"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	backupSuffix         = "-backup"
+	backupOfAnnotation   = "operator.knative.dev/backup-of"
+	backupTimeAnnotation = "operator.knative.dev/backup-time"
+)
+
+// Whether the manifest is the one the last install applied, so that
+// any resource out of sync with it drifted in the cluster rather than
+// the spec or release changing
+func isLastApplied(instance *servingv1alpha1.KnativeServing, manifest *mf.Manifest) bool {
+	return instance.Status.IsInstalled() && instance.Status.ManifestHash == hashResources(manifest.Resources)
+}
+
+// The resources the apply found out of sync, by kind, if they drifted
+func driftedResources(lastApplied bool, outOfSync map[string]int) map[string]int {
+	if !lastApplied {
+		return map[string]int{}
+	}
+	return outOfSync
+}
+
+// Emit an event for each ConfigMap or Service the apply is about to
+// restore. Only ConfigMap data can be compared reliably, because the
+// apiserver defaults fields within a Service's lists, so only deleted
+// Services are reported. Neither are the resources the manifest policy
+// keeps from being updated. The drift of every kind is counted by the
+// apply itself.
+func (r *ReconcileKnativeServing) reportDrift(instance *servingv1alpha1.KnativeServing, manifest *mf.Manifest) {
+	if !isLastApplied(instance, manifest) {
+		// Nothing to drift from yet, or the spec or release changed
+		return
+	}
+	for i := range manifest.Resources {
+		u := &manifest.Resources[i]
+		kind := u.GetKind()
+		if kind != "ConfigMap" && kind != "Service" {
+			continue
+		}
+		if instance.Spec.ManifestPolicy.For(kind, u.GetName()) != servingv1alpha1.ApplyPolicy {
+			continue
+		}
+		current, err := manifest.Get(u)
+		if err != nil {
+			log.Error(err, "Unable to check for drift", "kind", kind, "name", u.GetName())
+			continue
+		}
+		if current == nil {
+			log.Info("Restoring deleted resource", "kind", kind, "name", u.GetName())
+			r.recorder.Eventf(instance, v1.EventTypeWarning, "DriftRepaired",
+				"Restoring deleted %s %s/%s", kind, u.GetNamespace(), u.GetName())
+		} else if kind == "ConfigMap" {
+			// UpdateChanged overwrites the fields it compares
+			previous := current.DeepCopy()
+			if !mf.UpdateChanged(u.UnstructuredContent(), current.UnstructuredContent()) {
+				continue
+			}
+			log.Info("Reverting modified resource", "kind", kind, "name", u.GetName())
+			r.recorder.Eventf(instance, v1.EventTypeWarning, "DriftRepaired",
+				"Reverting manual changes to %s %s/%s", kind, u.GetNamespace(), u.GetName())
+			if strings.HasPrefix(u.GetName(), "config-") {
+				r.backupConfigMap(instance, previous)
+			}
+		}
+	}
+}
+
+// Save the data of a hand-edited ConfigMap about to be reverted to a
+// ConfigMap of the same name suffixed with -backup in the namespace of
+// the KnativeServing, so the changes can be recovered. Only the latest
+// modification is kept.
+func (r *ReconcileKnativeServing) backupConfigMap(instance *servingv1alpha1.KnativeServing, current *unstructured.Unstructured) {
+	data, _, err := unstructured.NestedStringMap(current.Object, "data")
+	if err != nil {
+		log.Error(err, "Unable to read ConfigMap data", "name", current.GetName())
+		return
+	}
+	cm := &v1.ConfigMap{}
+	key := client.ObjectKey{Namespace: instance.GetNamespace(), Name: current.GetName() + backupSuffix}
+	err = r.client.Get(context.TODO(), key, cm)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Unable to back up ConfigMap", "name", current.GetName())
+		return
+	}
+	create := errors.IsNotFound(err)
+	cm.Namespace = key.Namespace
+	cm.Name = key.Name
+	cm.OwnerReferences = []metav1.OwnerReference{
+		*metav1.NewControllerRef(instance, servingv1alpha1.SchemeGroupVersion.WithKind("KnativeServing")),
+	}
+	cm.Annotations = map[string]string{
+		backupOfAnnotation:   current.GetNamespace() + "/" + current.GetName(),
+		backupTimeAnnotation: time.Now().UTC().Format(time.RFC3339),
+	}
+	cm.Data = data
+	if create {
+		err = r.client.Create(context.TODO(), cm)
+	} else {
+		err = r.client.Update(context.TODO(), cm)
+	}
+	if err != nil {
+		log.Error(err, "Unable to back up ConfigMap", "name", current.GetName())
+		return
+	}
+	r.recorder.Eventf(instance, v1.EventTypeNormal, "ConfigMapBackedUp",
+		"Saved manual changes to ConfigMap %s/%s in %s/%s", current.GetNamespace(), current.GetName(), cm.Namespace, cm.Name)
+}
@@ -0,0 +1,164 @@
+package knativeserving
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const driftManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-network
+  namespace: knative-serving
+data:
+  istio.sidecar.includeOutboundIPRanges: "*"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-autoscaler
+  namespace: knative-serving
+data:
+  stable-window: 60s
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-gc
+  namespace: knative-serving
+data:
+  stale-revision-timeout: 15h
+`
+
+func TestReportDrift(t *testing.T) {
+	c := newFakeClient(newTestScheme(),
+		// Hand-edited
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "config-network"},
+			Data:       map[string]string{"istio.sidecar.includeOutboundIPRanges": "10.0.0.1/24"},
+		},
+		// Untouched, config-autoscaler was deleted
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "config-gc"},
+			Data:       map[string]string{"stale-revision-timeout": "15h"},
+		},
+	)
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, recorder: recorder}
+	manifest := newTestManifest(t, driftManifest, c)
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "operator", Name: "knative-serving"},
+	}
+	instance.Status.InitializeConditions()
+	instance.Status.MarkInstallSucceeded()
+	recordManifestHash(&instance.Status, &manifest)
+
+	r.reportDrift(instance, &manifest)
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got events %v, want 3", events)
+	}
+	if !strings.Contains(events[0], "Reverting manual changes to ConfigMap knative-serving/config-network") {
+		t.Errorf("unexpected event: %s", events[0])
+	}
+	if !strings.Contains(events[1], "Saved manual changes to ConfigMap knative-serving/config-network in operator/config-network-backup") {
+		t.Errorf("unexpected event: %s", events[1])
+	}
+	if !strings.Contains(events[2], "Restoring deleted ConfigMap knative-serving/config-autoscaler") {
+		t.Errorf("unexpected event: %s", events[2])
+	}
+
+	backup := &v1.ConfigMap{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "operator", Name: "config-network-backup"}, backup); err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if got := backup.Data["istio.sidecar.includeOutboundIPRanges"]; got != "10.0.0.1/24" {
+		t.Errorf("backup data = %q, want the manual change", got)
+	}
+	if got := backup.Annotations[backupOfAnnotation]; got != "knative-serving/config-network" {
+		t.Errorf("backup-of = %q, want knative-serving/config-network", got)
+	}
+	if backup.Annotations[backupTimeAnnotation] == "" {
+		t.Error("backup has no timestamp")
+	}
+}
+
+func TestReportDriftBeforeInstall(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, recorder: recorder}
+	manifest := newTestManifest(t, driftManifest, c)
+	instance := &servingv1alpha1.KnativeServing{}
+	instance.Status.InitializeConditions()
+
+	r.reportDrift(instance, &manifest)
+	if len(recorder.Events) != 0 {
+		t.Errorf("unexpected event: %s", <-recorder.Events)
+	}
+}
+
+func TestReportDriftOfChangedSpec(t *testing.T) {
+	c := newFakeClient(newTestScheme(),
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "config-network"},
+			Data:       map[string]string{"istio.sidecar.includeOutboundIPRanges": "10.0.0.1/24"},
+		},
+	)
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, recorder: recorder}
+	manifest := newTestManifest(t, driftManifest, c)
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "operator", Name: "knative-serving"},
+	}
+	instance.Status.InitializeConditions()
+	instance.Status.MarkInstallSucceeded()
+	recordManifestHash(&instance.Status, &manifest)
+
+	// spec.config changed config-network since the last install
+	unstructured.SetNestedField(manifest.Resources[0].Object, "changed", "data", "spec")
+	r.reportDrift(instance, &manifest)
+	if len(recorder.Events) != 0 {
+		t.Errorf("unexpected event: %s", <-recorder.Events)
+	}
+	err := c.Get(context.TODO(), client.ObjectKey{Namespace: "operator", Name: "config-network-backup"}, &v1.ConfigMap{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("Get() = %v, want no backup of a spec change", err)
+	}
+}
+
+func TestDriftedResources(t *testing.T) {
+	manifest := newTestManifest(t, driftManifest, nil)
+	instance := &servingv1alpha1.KnativeServing{}
+	instance.Status.InitializeConditions()
+	outOfSync := map[string]int{"Deployment": 1, "ClusterRole": 2}
+
+	// Everything is out of sync on the first install
+	if got := driftedResources(isLastApplied(instance, &manifest), outOfSync); len(got) != 0 {
+		t.Errorf("driftedResources() = %v, want none before the install", got)
+	}
+	instance.Status.MarkInstallSucceeded()
+	recordManifestHash(&instance.Status, &manifest)
+	if got := driftedResources(isLastApplied(instance, &manifest), outOfSync); !reflect.DeepEqual(got, outOfSync) {
+		t.Errorf("driftedResources() = %v, want %v", got, outOfSync)
+	}
+
+	// A changed spec or release updates the resources, it doesn't drift
+	unstructured.SetNestedField(manifest.Resources[0].Object, "changed", "data", "spec")
+	if got := driftedResources(isLastApplied(instance, &manifest), outOfSync); len(got) != 0 {
+		t.Errorf("driftedResources() = %v, want none for a changed manifest", got)
+	}
+}
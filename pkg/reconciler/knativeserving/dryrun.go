@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/operator-sdk/pkg/predicate"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const (
+	// Set to "true" on the KnativeServing to preview rather than apply
+	dryRunAnnotation = "operator.knative.dev/dry-run"
+	// The ConfigMap in the KnativeServing's namespace holding the preview
+	dryRunConfigMap = "knative-serving-dry-run"
+	dryRunDiffKey   = "diff"
+)
+
+// Reconcile when the spec changes or the dry-run annotation is toggled,
+// which doesn't bump the generation
+type dryRunChangedPredicate struct {
+	predicate.GenerationChangedPredicate
+}
+
+func (p dryRunChangedPredicate) Update(e event.UpdateEvent) bool {
+	if p.GenerationChangedPredicate.Update(e) {
+		return true
+	}
+	if e.MetaOld == nil || e.MetaNew == nil {
+		return false
+	}
+	return e.MetaOld.GetAnnotations()[dryRunAnnotation] != e.MetaNew.GetAnnotations()[dryRunAnnotation]
+}
+
+func isDryRun(instance *servingv1alpha1.KnativeServing) bool {
+	return instance.GetAnnotations()[dryRunAnnotation] == "true"
+}
+
+// Publish what applying the manifest would change without changing it
+func (r *ReconcileKnativeServing) dryRun(instance *servingv1alpha1.KnativeServing) error {
+	log.Info("Dry run", "version", targetVersion(instance))
+	extensions, err := platforms.Extend(r.client, r.discovery, r.scheme)
+	if err != nil {
+		return err
+	}
+	manifest, err := r.transform(instance, extensions)
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	if isUpgrade(instance) {
+		lines = append(lines, fmt.Sprintf("upgrade %s -> %s", instance.Status.Version, targetVersion(instance)))
+	}
+	changes := 0
+	for i := range manifest.Resources {
+		u := &manifest.Resources[i]
+		policy := instance.Spec.ManifestPolicy.For(u.GetKind(), u.GetName())
+		if policy == servingv1alpha1.NonePolicy {
+			continue
+		}
+		current, err := manifest.Get(u)
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("%s %s", u.GetKind(), u.GetName())
+		if ns := u.GetNamespace(); ns != "" {
+			name = fmt.Sprintf("%s %s/%s", u.GetKind(), ns, u.GetName())
+		}
+		if current == nil {
+			lines = append(lines, "create "+name)
+			changes++
+		} else if policy == servingv1alpha1.CreateOnlyPolicy {
+			continue
+		} else if fields := changedFields(u.Object, current.Object, ""); len(fields) > 0 {
+			lines = append(lines, fmt.Sprintf("update %s: %s", name, strings.Join(fields, ", ")))
+			changes++
+		}
+	}
+	if err := r.publishDryRun(instance, strings.Join(lines, "\n")); err != nil {
+		return err
+	}
+	r.recorder.Eventf(instance, v1.EventTypeNormal, "DryRun",
+		"Applying Knative Serving %s would change %d resources, see ConfigMap %s", targetVersion(instance), changes, dryRunConfigMap)
+	return nil
+}
+
+// Create or update the ConfigMap holding the preview
+func (r *ReconcileKnativeServing) publishDryRun(instance *servingv1alpha1.KnativeServing, diff string) error {
+	cm := &v1.ConfigMap{}
+	key := client.ObjectKey{Namespace: instance.GetNamespace(), Name: dryRunConfigMap}
+	err := r.client.Get(context.TODO(), key, cm)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	create := errors.IsNotFound(err)
+	cm.Namespace = key.Namespace
+	cm.Name = key.Name
+	cm.OwnerReferences = []metav1.OwnerReference{
+		*metav1.NewControllerRef(instance, servingv1alpha1.SchemeGroupVersion.WithKind("KnativeServing")),
+	}
+	cm.Data = map[string]string{dryRunDiffKey: diff}
+	if create {
+		return r.client.Create(context.TODO(), cm)
+	}
+	return r.client.Update(context.TODO(), cm)
+}
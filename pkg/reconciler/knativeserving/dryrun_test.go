@@ -0,0 +1,82 @@
+package knativeserving
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/pkg/reconciler/knativeserving/common"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestDryRun(t *testing.T) {
+	// The platforms probe for APIs the fake client doesn't know
+	defer func(p common.Platforms) { platforms = p }(platforms)
+	platforms = nil
+
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   operand,
+			Name:        operand,
+			Annotations: map[string]string{dryRunAnnotation: "true"},
+		},
+	}
+	c := newFakeClient(newTestScheme(),
+		instance.DeepCopy(),
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "config-network"}},
+	)
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, scheme: newTestScheme(), recorder: recorder, config: newTestManifest(t, testManifest, c)}
+
+	if !isDryRun(instance) {
+		t.Fatal("expected a dry run")
+	}
+	if err := r.dryRun(instance); err != nil {
+		t.Fatalf("dryRun() = %v", err)
+	}
+
+	cm := &v1.ConfigMap{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: dryRunConfigMap}, cm); err != nil {
+		t.Fatalf("dry run ConfigMap not found: %v", err)
+	}
+	diff := cm.Data[dryRunDiffKey]
+	for _, want := range []string{
+		"create Deployment knative-serving/webhook",
+		"create Deployment knative-serving/controller",
+		"update ConfigMap knative-serving/config-network: data, metadata.labels, metadata.ownerReferences",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff %q doesn't contain %q", diff, want)
+		}
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "controller"}, &appsv1.Deployment{}); err == nil {
+		t.Error("a dry run must not create resources")
+	}
+	expectEvent(t, recorder, "Normal DryRun")
+}
+
+func TestDryRunChangedPredicate(t *testing.T) {
+	meta := func(generation int64, dryRun string) *metav1.ObjectMeta {
+		m := &metav1.ObjectMeta{Generation: generation}
+		if dryRun != "" {
+			m.Annotations = map[string]string{dryRunAnnotation: dryRun}
+		}
+		return m
+	}
+	p := dryRunChangedPredicate{}
+	if !p.Update(event.UpdateEvent{MetaOld: meta(1, ""), MetaNew: meta(1, "true"), ObjectOld: &servingv1alpha1.KnativeServing{}, ObjectNew: &servingv1alpha1.KnativeServing{}}) {
+		t.Error("expected toggling the dry-run annotation to reconcile")
+	}
+	if p.Update(event.UpdateEvent{MetaOld: meta(1, "true"), MetaNew: meta(1, "true"), ObjectOld: &servingv1alpha1.KnativeServing{}, ObjectNew: &servingv1alpha1.KnativeServing{}}) {
+		t.Error("expected no reconcile without a change")
+	}
+	if !p.Update(event.UpdateEvent{MetaOld: meta(1, ""), MetaNew: meta(2, ""), ObjectOld: &servingv1alpha1.KnativeServing{}, ObjectNew: &servingv1alpha1.KnativeServing{}}) {
+		t.Error("expected a spec change to reconcile")
+	}
+}
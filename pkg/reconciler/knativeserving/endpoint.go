@@ -0,0 +1,116 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/pkg/reconciler/knativeserving/common"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Knative's default domain, when config-domain names none
+	defaultDomain = "example.com"
+)
+
+var (
+	// The Services exposing the ingresses other than istio and
+	// Contour, whose is configurable, as namespace/name
+	ingressServices = map[string]client.ObjectKey{
+		servingv1alpha1.KourierIngress:    {Namespace: "kourier-system", Name: "kourier"},
+		servingv1alpha1.GlooIngress:       {Namespace: "gloo-system", Name: "knative-external-proxy"},
+		servingv1alpha1.AmbassadorIngress: {Namespace: common.AmbassadorNamespace, Name: "ambassador"},
+	}
+)
+
+// Publish where the install serves routes: the default domain and the
+// load balancer address of the ingress, once it's been assigned
+func (r *ReconcileKnativeServing) publishEndpoint(instance *servingv1alpha1.KnativeServing) error {
+	domain, err := r.defaultDomain(instance.InstallNamespace())
+	if err != nil {
+		return err
+	}
+	address, err := r.ingressAddress(instance)
+	if err != nil {
+		return err
+	}
+	url := "http://" + domain
+	if instance.Status.URL == url && instance.Status.Address == address {
+		return nil
+	}
+	log.Info("Ingress endpoint", "url", url, "address", address)
+	instance.Status.URL = url
+	instance.Status.Address = address
+	return r.updateStatus(instance)
+}
+
+// The domain config-domain applies to routes without a matching
+// selector, i.e. the first of its keys without one
+func (r *ReconcileKnativeServing) defaultDomain(namespace string) (string, error) {
+	cm := &v1.ConfigMap{}
+	key := client.ObjectKey{Namespace: namespace, Name: "config-domain"}
+	if err := r.client.Get(context.TODO(), key, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return defaultDomain, nil
+		}
+		return "", err
+	}
+	var domains []string
+	for domain, selector := range cm.Data {
+		if strings.TrimSpace(selector) == "" && !strings.HasPrefix(domain, "_") {
+			domains = append(domains, domain)
+		}
+	}
+	if len(domains) == 0 {
+		return defaultDomain, nil
+	}
+	sort.Strings(domains)
+	return domains[0], nil
+}
+
+// The external IP or hostname of the selected ingress's Service, if
+// its load balancer has one
+func (r *ReconcileKnativeServing) ingressAddress(instance *servingv1alpha1.KnativeServing) (string, error) {
+	key, ok := ingressServices[instance.Spec.Ingress.Name()]
+	switch {
+	case instance.Spec.Ingress.Name() == servingv1alpha1.ContourIngress:
+		key.Name, key.Namespace = common.ContourService(instance)
+	case !ok:
+		key.Name, key.Namespace = common.GatewayService(instance, common.KnativeIngressGateway)
+	}
+	svc := &v1.Service{}
+	if err := r.client.Get(context.TODO(), key, svc); err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			return ingress.IP, nil
+		}
+		if ingress.Hostname != "" {
+			return ingress.Hostname, nil
+		}
+	}
+	return "", nil
+}
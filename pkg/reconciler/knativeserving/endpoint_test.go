@@ -0,0 +1,77 @@
+package knativeserving
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func newIngressService(namespace, name string, ingress ...v1.LoadBalancerIngress) *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Status: v1.ServiceStatus{
+			LoadBalancer: v1.LoadBalancerStatus{Ingress: ingress},
+		},
+	}
+}
+
+func TestPublishEndpoint(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+	}
+	domain := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "config-domain"},
+		Data: map[string]string{
+			"_example":       "ignored",
+			"internal.local": "selector:\n  app: secret\n",
+			"serving.io":     "",
+		},
+	}
+	svc := newIngressService("istio-system", "istio-ingressgateway", v1.LoadBalancerIngress{IP: "1.2.3.4"})
+	r := &ReconcileKnativeServing{client: newFakeClient(newTestScheme(), instance.DeepCopy(), domain, svc)}
+
+	if err := r.publishEndpoint(instance); err != nil {
+		t.Fatalf("publishEndpoint() = %v", err)
+	}
+	if instance.Status.URL != "http://serving.io" {
+		t.Errorf("URL = %q, want %q", instance.Status.URL, "http://serving.io")
+	}
+	if instance.Status.Address != "1.2.3.4" {
+		t.Errorf("Address = %q, want %q", instance.Status.Address, "1.2.3.4")
+	}
+}
+
+func TestPublishEndpointPending(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Ingress: &servingv1alpha1.IngressConfigs{
+				Kourier: servingv1alpha1.KourierIngressConfiguration{Enabled: true},
+			},
+		},
+	}
+	svc := newIngressService("kourier-system", "kourier")
+	r := &ReconcileKnativeServing{client: newFakeClient(newTestScheme(), instance.DeepCopy(), svc)}
+
+	if err := r.publishEndpoint(instance); err != nil {
+		t.Fatalf("publishEndpoint() = %v", err)
+	}
+	if instance.Status.URL != "http://"+defaultDomain {
+		t.Errorf("URL = %q, want the default domain", instance.Status.URL)
+	}
+	if instance.Status.Address != "" {
+		t.Errorf("Address = %q, want none before the load balancer is assigned", instance.Status.Address)
+	}
+
+	svc.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{Hostname: "lb.example.com"}}
+	r.client.Update(context.TODO(), svc)
+	if err := r.publishEndpoint(instance); err != nil {
+		t.Fatalf("publishEndpoint() = %v", err)
+	}
+	if instance.Status.Address != "lb.example.com" {
+		t.Errorf("Address = %q, want %q", instance.Status.Address, "lb.example.com")
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The Services that must have a ready endpoint for the install to be
+// ready. A selector broken by a transformer leaves the Deployment
+// available but the Service without backends.
+var endpointServices = []string{"webhook", "autoscaler"}
+
+// The keys of the manifest's Services of endpointServices
+func endpointKeys(instance *servingv1alpha1.KnativeServing, core []unstructured.Unstructured) []client.ObjectKey {
+	if crdsOnly(instance) || instance.Spec.Hibernate {
+		// Nothing serves them
+		return nil
+	}
+	var result []client.ObjectKey
+	for _, name := range endpointServices {
+		for _, u := range core {
+			if u.GetKind() == "Service" && u.GetAPIVersion() == "v1" && u.GetName() == name {
+				result = append(result, client.ObjectKey{Namespace: instance.InstallNamespace(), Name: name})
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Report whether the Service has a ready endpoint and, if not, why
+func (r *ReconcileKnativeServing) endpointsStatus(key client.ObjectKey) (servingv1alpha1.DeploymentStatus, bool, error) {
+	status := servingv1alpha1.DeploymentStatus{
+		Name:    key.Name,
+		Reason:  "NoEndpoints",
+		Message: fmt.Sprintf("Service %s has no ready endpoints", key.Name),
+	}
+	// Read unstructured, i.e. from the apiserver, sparing the operator a
+	// cluster-wide informer of the ever changing Endpoints
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("Endpoints")
+	if err := r.client.Get(context.TODO(), key, u); err != nil {
+		if errors.IsNotFound(err) {
+			return status, false, nil
+		}
+		return status, false, err
+	}
+	endpoints := &v1.Endpoints{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, endpoints); err != nil {
+		return status, false, err
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return servingv1alpha1.DeploymentStatus{Name: key.Name}, true, nil
+		}
+	}
+	return status, false, nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// permanentError is an error retrying can't fix, e.g. an invalid spec
+// or a version that isn't bundled. The request isn't retried until the
+// KnativeServing changes, or its next resync.
+type permanentError struct {
+	error
+}
+
+func permanent(err error) error {
+	if err == nil || isPermanent(err) {
+		return err
+	}
+	return permanentError{err}
+}
+
+func isPermanent(err error) bool {
+	_, ok := err.(permanentError)
+	return ok
+}
+
+// The error a permanentError wraps
+func cause(err error) error {
+	if p, ok := err.(permanentError); ok {
+		return p.error
+	}
+	return err
+}
+
+// Whether the API server rejected a request that would be rejected
+// again, as opposed to e.g. a conflict or timeout
+func isRejected(err error) bool {
+	return errors.IsInvalid(err) || errors.IsBadRequest(err)
+}
@@ -0,0 +1,55 @@
+package knativeserving
+
+import (
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func TestPermanent(t *testing.T) {
+	err := fmt.Errorf("invalid")
+	if isPermanent(err) {
+		t.Error("errors aren't permanent unless wrapped")
+	}
+	p := permanent(err)
+	if !isPermanent(p) || cause(p) != err || p.Error() != "invalid" {
+		t.Errorf("permanent() = %#v, want a permanent wrapper of %v", p, err)
+	}
+	if cause(permanent(p)) != err {
+		t.Error("wrapping twice should be a no-op")
+	}
+	if permanent(nil) != nil {
+		t.Error("permanent(nil) should be nil")
+	}
+}
+
+func TestRecordPermanent(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec:       servingv1alpha1.KnativeServingSpec{Version: "0.1.0"},
+	}
+	instance.Status.InitializeConditions()
+	r, _ := newTestReconciler(instance)
+	r.releases = nil
+
+	_, _, err := r.selectIngress(instance)
+	if !isPermanent(err) {
+		t.Fatalf("selectIngress() = %v, want a permanent error for a version that isn't bundled", err)
+	}
+	if err := r.recordPermanent(instance, err); err != nil {
+		t.Fatalf("recordPermanent() = %v", err)
+	}
+	if !instance.Status.IsFailedPermanently() {
+		t.Error("expected FailedPermanently to be raised")
+	}
+
+	// A transient failure says nothing about the spec
+	if err := r.recordPermanent(instance, fmt.Errorf("timeout")); err != nil {
+		t.Fatalf("recordPermanent() = %v", err)
+	}
+	if instance.Status.IsFailedPermanently() {
+		t.Error("expected FailedPermanently to be cleared")
+	}
+}
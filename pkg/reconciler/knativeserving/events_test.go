@@ -0,0 +1,182 @@
+package knativeserving
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func expectEvent(t *testing.T, recorder *record.FakeRecorder, prefix string) {
+	t.Helper()
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, prefix) {
+			t.Errorf("event = %q, want prefix %q", event, prefix)
+		}
+	default:
+		t.Errorf("expected a %q event", prefix)
+	}
+}
+
+func expectNoEvent(t *testing.T, recorder *record.FakeRecorder) {
+	t.Helper()
+	if len(recorder.Events) != 0 {
+		t.Errorf("unexpected event: %s", <-recorder.Events)
+	}
+}
+
+func TestCheckDeploymentsReportsTransitions(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+	}
+	instance.Status.InitializeConditions()
+	instance.Status.MarkDeploymentsAvailable()
+	c := newFakeClient(newTestScheme(), instance.DeepCopy())
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, recorder: recorder, config: newTestManifest(t, testManifest, c)}
+
+	// A missing deployment regresses an available install
+	if err := r.checkDeployments(instance); err != nil {
+		t.Fatalf("checkDeployments() = %v", err)
+	}
+	expectEvent(t, recorder, "Warning DeploymentsNotReady Waiting on deployments webhook, controller")
+
+	// Still waiting is not news
+	if err := r.checkDeployments(instance); err != nil {
+		t.Fatalf("checkDeployments() = %v", err)
+	}
+	expectNoEvent(t, recorder)
+}
+
+func TestCheckDeploymentsRecordsUnreadyDeployments(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+	}
+	instance.Status.InitializeConditions()
+	webhook := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "webhook"},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{
+				Type:   appsv1.DeploymentAvailable,
+				Status: corev1.ConditionTrue,
+			}},
+		},
+	}
+	controller := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "controller"},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{
+				Type:    appsv1.DeploymentAvailable,
+				Status:  corev1.ConditionFalse,
+				Reason:  "MinimumReplicasUnavailable",
+				Message: "Deployment does not have minimum availability.",
+			}, {
+				Type:    appsv1.DeploymentReplicaFailure,
+				Status:  corev1.ConditionTrue,
+				Reason:  "FailedCreate",
+				Message: "exceeded quota",
+			}},
+		},
+	}
+	c := newFakeClient(newTestScheme(), instance.DeepCopy(), webhook, controller)
+	r := &ReconcileKnativeServing{client: c, recorder: record.NewFakeRecorder(10), config: newTestManifest(t, testManifest, c)}
+
+	if err := r.checkDeployments(instance); err != nil {
+		t.Fatalf("checkDeployments() = %v", err)
+	}
+	want := []servingv1alpha1.DeploymentStatus{{
+		Name:    "controller",
+		Reason:  "FailedCreate",
+		Message: "exceeded quota",
+	}}
+	if !reflect.DeepEqual(instance.Status.Deployments, want) {
+		t.Errorf("Deployments = %+v, want %+v", instance.Status.Deployments, want)
+	}
+
+	// The list is cleared once everything is available
+	controller.Status.Conditions = webhook.Status.Conditions
+	if err := c.Update(context.TODO(), controller); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.checkDeployments(instance); err != nil {
+		t.Fatalf("checkDeployments() = %v", err)
+	}
+	if instance.Status.Deployments != nil {
+		t.Errorf("Deployments = %+v, want none", instance.Status.Deployments)
+	}
+	if !instance.Status.GetCondition(servingv1alpha1.DeploymentsAvailable).IsTrue() {
+		t.Error("DeploymentsAvailable should be True")
+	}
+}
+
+func TestCheckDeploymentsRequiresEndpoints(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+	}
+	instance.Status.InitializeConditions()
+	available := appsv1.DeploymentStatus{
+		Conditions: []appsv1.DeploymentCondition{{
+			Type:   appsv1.DeploymentAvailable,
+			Status: corev1.ConditionTrue,
+		}},
+	}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "webhook"},
+		Subsets:    []corev1.EndpointSubset{{NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+	}
+	c := newFakeClient(newTestScheme(), instance.DeepCopy(), endpoints,
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "webhook"}, Status: available},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "controller"}, Status: available})
+	manifest := testManifest + `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: webhook
+  namespace: knative-serving
+`
+	r := &ReconcileKnativeServing{client: c, recorder: record.NewFakeRecorder(10), config: newTestManifest(t, manifest, c)}
+
+	// Without a ready address, e.g. of a broken selector
+	if err := r.checkDeployments(instance); err != nil {
+		t.Fatalf("checkDeployments() = %v", err)
+	}
+	want := []servingv1alpha1.DeploymentStatus{{
+		Name:    "webhook",
+		Reason:  "NoEndpoints",
+		Message: "Service webhook has no ready endpoints",
+	}}
+	if !reflect.DeepEqual(instance.Status.Deployments, want) {
+		t.Errorf("Deployments = %+v, want %+v", instance.Status.Deployments, want)
+	}
+	if instance.Status.GetCondition(servingv1alpha1.DeploymentsAvailable).IsTrue() {
+		t.Error("DeploymentsAvailable shouldn't be True without endpoints")
+	}
+
+	// Hibernating, nothing is expected to serve
+	instance.Spec.Hibernate = true
+	if err := r.checkDeployments(instance); err != nil {
+		t.Fatalf("checkDeployments() = %v", err)
+	}
+	if instance.Status.Deployments != nil || !instance.Status.GetCondition(servingv1alpha1.DeploymentsAvailable).IsTrue() {
+		t.Errorf("Deployments = %+v, want no endpoints required while hibernating", instance.Status.Deployments)
+	}
+	instance.Spec.Hibernate = false
+
+	endpoints.Subsets[0].Addresses = endpoints.Subsets[0].NotReadyAddresses
+	if err := c.Update(context.TODO(), endpoints); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.checkDeployments(instance); err != nil {
+		t.Fatalf("checkDeployments() = %v", err)
+	}
+	if instance.Status.Deployments != nil || !instance.Status.GetCondition(servingv1alpha1.DeploymentsAvailable).IsTrue() {
+		t.Errorf("Deployments = %+v, want none available", instance.Status.Deployments)
+	}
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// The label of the ConfigMaps of an export, naming the KnativeServing
+	exportLabel       = "operator.knative.dev/export"
+	exportManifestKey = "manifest.yaml"
+)
+
+// The most YAML a ConfigMap of an export holds, leaving room under the
+// 1MiB an object may take
+var exportChunkSize = 900 * 1024
+
+// Render and export the manifest without installing it
+func (r *ReconcileKnativeServing) exportOnly(instance *servingv1alpha1.KnativeServing) error {
+	log.V(1).Info("exportOnly", "status", instance.Status)
+	defer r.updateStatus(instance)
+
+	extensions, err := platforms.Extend(r.client, r.discovery, r.scheme)
+	if err != nil {
+		return err
+	}
+	instance.Status.TargetVersion = targetVersion(instance)
+	if err := r.validateSpec(instance); err != nil {
+		return err
+	}
+	manifest, err := r.transform(instance, extensions)
+	if err != nil {
+		instance.Status.MarkTransformFailed(err.Error())
+		return err
+	}
+	instance.Status.MarkTransformed()
+	if err := r.pinDigests(instance, &manifest); err != nil {
+		return err
+	}
+	return r.exportManifest(instance, manifest.Resources)
+}
+
+// Publish the resources in the ConfigMaps of spec.export, deleting those
+// of an earlier export they no longer fill
+func (r *ReconcileKnativeServing) exportManifest(instance *servingv1alpha1.KnativeServing, resources []unstructured.Unstructured) error {
+	export := instance.Spec.Export
+	if export == nil {
+		if instance.Status.Export == nil {
+			return nil
+		}
+		if err := r.deleteExported(instance, nil); err != nil {
+			return err
+		}
+		instance.Status.Export = nil
+		return nil
+	}
+	chunks, err := chunkManifest(resources)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for i, chunk := range chunks {
+		name := fmt.Sprintf("%s-%d", export.Name(), i+1)
+		if err := r.publishExported(instance, name, chunk); err != nil {
+			return err
+		}
+		names = append(names, name)
+	}
+	if err := r.deleteExported(instance, names); err != nil {
+		return err
+	}
+
+	hash := hashResources(resources)
+	status := &servingv1alpha1.ExportStatus{
+		ConfigMaps: names,
+		Version:    targetVersion(instance),
+		Hash:       hash,
+	}
+	if previous := instance.Status.Export; previous != nil && previous.Hash == hash {
+		status.LastExportedTime = previous.LastExportedTime
+	} else {
+		now := metav1.Now()
+		status.LastExportedTime = &now
+		r.recorder.Eventf(instance, v1.EventTypeNormal, "ManifestExported",
+			"Exported Knative Serving %s to ConfigMaps %s", status.Version, strings.Join(names, ", "))
+	}
+	instance.Status.Export = status
+	return nil
+}
+
+// Split the resources into streams of YAML documents of at most
+// exportChunkSize, unless a single resource is larger
+func chunkManifest(resources []unstructured.Unstructured) ([]string, error) {
+	var chunks []string
+	var chunk strings.Builder
+	for i := range resources {
+		doc, err := yaml.Marshal(resources[i].Object)
+		if err != nil {
+			return nil, err
+		}
+		if chunk.Len() > 0 && chunk.Len()+len(doc)+len("---\n") > exportChunkSize {
+			chunks = append(chunks, chunk.String())
+			chunk.Reset()
+		}
+		chunk.WriteString("---\n")
+		chunk.Write(doc)
+	}
+	if chunk.Len() > 0 {
+		chunks = append(chunks, chunk.String())
+	}
+	return chunks, nil
+}
+
+// Create or update a ConfigMap of the export
+func (r *ReconcileKnativeServing) publishExported(instance *servingv1alpha1.KnativeServing, name, manifest string) error {
+	cm := &v1.ConfigMap{}
+	key := client.ObjectKey{Namespace: instance.GetNamespace(), Name: name}
+	err := r.client.Get(context.TODO(), key, cm)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	create := errors.IsNotFound(err)
+	data := map[string]string{exportManifestKey: manifest}
+	if !create && cm.Labels[exportLabel] == instance.GetName() && reflect.DeepEqual(cm.Data, data) {
+		return nil
+	}
+	cm.Namespace = key.Namespace
+	cm.Name = key.Name
+	if cm.Labels == nil {
+		cm.Labels = map[string]string{}
+	}
+	cm.Labels[exportLabel] = instance.GetName()
+	cm.OwnerReferences = []metav1.OwnerReference{
+		*metav1.NewControllerRef(instance, servingv1alpha1.SchemeGroupVersion.WithKind("KnativeServing")),
+	}
+	cm.Data = data
+	if create {
+		return r.client.Create(context.TODO(), cm)
+	}
+	return r.client.Update(context.TODO(), cm)
+}
+
+// Delete the ConfigMaps exported for the KnativeServing but those named
+func (r *ReconcileKnativeServing) deleteExported(instance *servingv1alpha1.KnativeServing, keep []string) error {
+	list := &v1.ConfigMapList{}
+	opts := &client.ListOptions{
+		Namespace:     instance.GetNamespace(),
+		LabelSelector: labels.SelectorFromSet(labels.Set{exportLabel: instance.GetName()}),
+	}
+	if err := r.client.List(context.TODO(), opts, list); err != nil {
+		return err
+	}
+	kept := map[string]bool{}
+	for _, name := range keep {
+		kept[name] = true
+	}
+	for i := range list.Items {
+		if cm := &list.Items[i]; !kept[cm.Name] {
+			if err := r.client.Delete(context.TODO(), cm); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/pkg/reconciler/knativeserving/common"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The exported ConfigMaps of the namespace, by name
+func exportedConfigMaps(t *testing.T, c client.Client) map[string]*v1.ConfigMap {
+	t.Helper()
+	list := &v1.ConfigMapList{}
+	if err := c.List(context.TODO(), &client.ListOptions{Namespace: operand}, list); err != nil {
+		t.Fatalf("List() = %v", err)
+	}
+	result := map[string]*v1.ConfigMap{}
+	for i := range list.Items {
+		if cm := &list.Items[i]; cm.Labels[exportLabel] == operand {
+			result[cm.Name] = cm
+		}
+	}
+	return result
+}
+
+func TestExportOnly(t *testing.T) {
+	// The platforms probe for APIs the fake client doesn't know
+	defer func(p common.Platforms) { platforms = p }(platforms)
+	platforms = nil
+	defer func(size int) { exportChunkSize = size }(exportChunkSize)
+	exportChunkSize = 1
+
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Export: &servingv1alpha1.ManifestExport{Only: true},
+		},
+	}
+	c := newFakeClient(newTestScheme(), instance.DeepCopy())
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, scheme: newTestScheme(), recorder: recorder, config: newTestManifest(t, testManifest, c)}
+
+	if !instance.ExportOnly() {
+		t.Fatal("expected an export only")
+	}
+	if err := r.exportOnly(instance); err != nil {
+		t.Fatalf("exportOnly() = %v", err)
+	}
+	expectEvent(t, recorder, "Normal ManifestExported")
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "controller"}, &appsv1.Deployment{}); err == nil {
+		t.Error("an export only must not create resources")
+	}
+
+	// One ConfigMap per resource
+	exported := exportedConfigMaps(t, c)
+	status := instance.Status.Export
+	if status == nil || len(status.ConfigMaps) < 2 || len(exported) != len(status.ConfigMaps) {
+		t.Fatalf("status.export = %v, want a ConfigMap per resource of %v", status, exported)
+	}
+	var docs []string
+	for i, name := range status.ConfigMaps {
+		if want := fmt.Sprintf("%s-%d", servingv1alpha1.DefaultExportConfigMapName, i+1); name != want {
+			t.Errorf("ConfigMap %d = %s, want %s", i, name, want)
+		}
+		cm, ok := exported[name]
+		if !ok {
+			t.Fatalf("ConfigMap %s wasn't exported", name)
+		}
+		if len(cm.OwnerReferences) != 1 || cm.OwnerReferences[0].Name != operand {
+			t.Errorf("ConfigMap %s owners = %v, want the KnativeServing", name, cm.OwnerReferences)
+		}
+		docs = append(docs, cm.Data[exportManifestKey])
+	}
+	if !strings.Contains(strings.Join(docs, ""), "name: controller") {
+		t.Errorf("exported manifest lacks the controller: %s", strings.Join(docs, ""))
+	}
+	if status.Hash == "" || status.LastExportedTime == nil {
+		t.Errorf("status.export = %v, want a hash and time", status)
+	}
+
+	// Larger chunks take fewer ConfigMaps, and the rest are deleted
+	exportChunkSize = 900 * 1024
+	exportedAt := status.LastExportedTime
+	if err := r.exportOnly(instance); err != nil {
+		t.Fatalf("exportOnly() = %v", err)
+	}
+	expectNoEvent(t, recorder)
+	if got := instance.Status.Export.ConfigMaps; len(got) != 1 {
+		t.Errorf("status.export.configMaps = %v, want one", got)
+	}
+	if got := exportedConfigMaps(t, c); len(got) != 1 {
+		t.Errorf("exported ConfigMaps = %v, want one", got)
+	}
+	if instance.Status.Export.LastExportedTime != exportedAt {
+		t.Error("expected the time of an unchanged export to be kept")
+	}
+
+	// No longer exporting deletes the ConfigMaps
+	instance.Spec.Export = nil
+	if err := r.exportManifest(instance, nil); err != nil {
+		t.Fatalf("exportManifest() = %v", err)
+	}
+	if instance.Status.Export != nil {
+		t.Errorf("status.export = %v, want none", instance.Status.Export)
+	}
+	if got := exportedConfigMaps(t, c); len(got) != 0 {
+		t.Errorf("exported ConfigMaps = %v, want none", got)
+	}
+}
+
+func TestChunkManifest(t *testing.T) {
+	defer func(size int) { exportChunkSize = size }(exportChunkSize)
+	resources := newTestManifest(t, testManifest, newFakeClient(newTestScheme())).Resources
+	for _, tt := range []struct {
+		size   int
+		chunks int
+	}{{
+		size:   900 * 1024,
+		chunks: 1,
+	}, {
+		size:   1,
+		chunks: len(resources),
+	}} {
+		exportChunkSize = tt.size
+		chunks, err := chunkManifest(resources)
+		if err != nil {
+			t.Fatalf("chunkManifest() = %v", err)
+		}
+		if len(chunks) != tt.chunks {
+			t.Errorf("chunkManifest() with size %d = %d chunks, want %d", tt.size, len(chunks), tt.chunks)
+		}
+		if got := strings.Count(strings.Join(chunks, ""), "---\n"); got != len(resources) {
+			t.Errorf("chunks hold %d documents, want %d", got, len(resources))
+		}
+	}
+}
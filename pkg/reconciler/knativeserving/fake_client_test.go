@@ -0,0 +1,206 @@
+package knativeserving
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// fakeClient is a minimal in-memory client.Client for reconciler tests
+type fakeClient struct {
+	sync.Mutex
+	scheme  *runtime.Scheme
+	objects map[string]map[string]interface{}
+}
+
+var _ client.Client = &fakeClient{}
+
+func newTestScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	scheme.AddToScheme(s)
+	servingv1alpha1.SchemeBuilder.AddToScheme(s)
+	return s
+}
+
+func newFakeClient(s *runtime.Scheme, objs ...runtime.Object) *fakeClient {
+	c := &fakeClient{scheme: s, objects: map[string]map[string]interface{}{}}
+	for _, obj := range objs {
+		if err := c.Create(context.TODO(), obj); err != nil {
+			panic(err)
+		}
+	}
+	return c
+}
+
+func (c *fakeClient) gvk(obj runtime.Object) (schema.GroupVersionKind, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.GroupVersionKind(), nil
+	}
+	return apiutil.GVKForObject(obj, c.scheme)
+}
+
+func objectKey(gvk schema.GroupVersionKind, namespace, name string) string {
+	return strings.Join([]string{gvk.Group, gvk.Kind, namespace, name}, "/")
+}
+
+func (c *fakeClient) key(obj runtime.Object) (string, error) {
+	gvk, err := c.gvk(obj)
+	if err != nil {
+		return "", err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+	return objectKey(gvk, accessor.GetNamespace(), accessor.GetName()), nil
+}
+
+func (c *fakeClient) store(obj runtime.Object) (string, map[string]interface{}, error) {
+	key, err := c.key(obj)
+	if err != nil {
+		return "", nil, err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return "", nil, err
+	}
+	gvk, _ := c.gvk(obj)
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvk)
+	return key, u.Object, nil
+}
+
+func (c *fakeClient) load(content map[string]interface{}, obj runtime.Object) error {
+	content = runtime.DeepCopyJSON(content)
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		u.SetUnstructuredContent(content)
+		return nil
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(content, obj)
+}
+
+func (c *fakeClient) Get(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+	c.Lock()
+	defer c.Unlock()
+	gvk, err := c.gvk(obj)
+	if err != nil {
+		return err
+	}
+	content, ok := c.objects[objectKey(gvk, key.Namespace, key.Name)]
+	if !ok {
+		return errors.NewNotFound(schema.GroupResource{Group: gvk.Group, Resource: gvk.Kind}, key.Name)
+	}
+	return c.load(content, obj)
+}
+
+func (c *fakeClient) List(_ context.Context, opts *client.ListOptions, list runtime.Object) error {
+	c.Lock()
+	defer c.Unlock()
+	gvk, err := c.gvk(list)
+	if err != nil {
+		return err
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	var items []runtime.Object
+	for key, content := range c.objects {
+		if !strings.HasPrefix(key, gvk.Group+"/"+gvk.Kind+"/") {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: runtime.DeepCopyJSON(content)}
+		if opts != nil && opts.Namespace != "" && u.GetNamespace() != opts.Namespace {
+			continue
+		}
+		if opts != nil && opts.LabelSelector != nil && !opts.LabelSelector.Matches(labelSet(u.GetLabels())) {
+			continue
+		}
+		items = append(items, u)
+	}
+	if ul, ok := list.(*unstructured.UnstructuredList); ok {
+		for _, item := range items {
+			ul.Items = append(ul.Items, *item.(*unstructured.Unstructured))
+		}
+		return nil
+	}
+	typed := make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		obj, err := c.scheme.New(gvk)
+		if err != nil {
+			return err
+		}
+		if err := c.load(item.(*unstructured.Unstructured).Object, obj); err != nil {
+			return err
+		}
+		typed = append(typed, obj)
+	}
+	return meta.SetList(list, typed)
+}
+
+func (c *fakeClient) Create(_ context.Context, obj runtime.Object) error {
+	c.Lock()
+	defer c.Unlock()
+	key, content, err := c.store(obj)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.objects[key]; ok {
+		accessor, _ := meta.Accessor(obj)
+		return errors.NewAlreadyExists(schema.GroupResource{}, accessor.GetName())
+	}
+	c.objects[key] = content
+	return nil
+}
+
+func (c *fakeClient) Delete(_ context.Context, obj runtime.Object, _ ...client.DeleteOptionFunc) error {
+	c.Lock()
+	defer c.Unlock()
+	key, err := c.key(obj)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.objects[key]; !ok {
+		accessor, _ := meta.Accessor(obj)
+		return errors.NewNotFound(schema.GroupResource{}, accessor.GetName())
+	}
+	delete(c.objects, key)
+	return nil
+}
+
+func (c *fakeClient) Update(_ context.Context, obj runtime.Object) error {
+	c.Lock()
+	defer c.Unlock()
+	key, content, err := c.store(obj)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.objects[key]; !ok {
+		accessor, _ := meta.Accessor(obj)
+		return errors.NewNotFound(schema.GroupResource{}, accessor.GetName())
+	}
+	c.objects[key] = content
+	return nil
+}
+
+func (c *fakeClient) Status() client.StatusWriter {
+	return c
+}
+
+type labelSet map[string]string
+
+func (ls labelSet) Has(label string) bool {
+	_, ok := ls[label]
+	return ok
+}
+
+func (ls labelSet) Get(label string) string {
+	return ls[label]
+}
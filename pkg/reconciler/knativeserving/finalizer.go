@@ -0,0 +1,243 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	// Guarantees the installed resources are deleted before the KnativeServing
+	finalizerName = "delete-knative-serving-manifest"
+)
+
+// Add our finalizer so that uninstall isn't left to chance
+func (r *ReconcileKnativeServing) ensureFinalizer(instance *servingv1alpha1.KnativeServing) error {
+	if hasFinalizer(instance) {
+		return nil
+	}
+	log.V(1).Info("ensureFinalizer", "finalizer", finalizerName)
+	instance.SetFinalizers(append(instance.GetFinalizers(), finalizerName))
+	return r.update(instance)
+}
+
+// Uninstall everything, then release the KnativeServing
+func (r *ReconcileKnativeServing) delete(instance *servingv1alpha1.KnativeServing) error {
+	if !hasFinalizer(instance) {
+		return nil
+	}
+	log.Info("Uninstalling", "version", instance.Status.Version)
+	instance.Status.MarkUninstalling()
+	if err := r.updateStatus(instance); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	resources := r.allResources(instance.InstallNamespace())
+	if meshCompatibilityEnabled(instance) {
+		// Only then may the Istio APIs be assumed installed
+		resources = append(resources, meshResources(instance)...)
+	}
+	if monitoringEnabled(instance) {
+		// Likewise the Prometheus operator's
+		resources = append(resources, monitoringResources(instance, resources, r.dashboards)...)
+	}
+	if activatorAutoscalingEnabled(instance) {
+		hpa, err := activatorAutoscaler(instance)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, hpa)
+	}
+	// Those of deployments missing from the manifest were never applied
+	budgets, _ := podDisruptionBudgets(instance, resources)
+	resources = append(resources, budgets...)
+	recorded, err := r.recordedResources(instance, resources)
+	if err != nil {
+		return err
+	}
+	resources, err = r.withoutForeignNamespace(instance, append(resources, recorded...))
+	if err != nil {
+		return err
+	}
+	manifest := r.config
+	manifest.Resources = uninstallOrder(uninstalled(instance, resources))
+	if err := manifest.DeleteAll(); err != nil {
+		return err
+	}
+	if err := r.disown(instance, resources); err != nil {
+		return err
+	}
+	if err := r.deleteSecretCopies(instance, nil); err != nil {
+		return err
+	}
+	log.Info("Uninstall succeeded", "policy", instance.Spec.UninstallPolicy)
+
+	var finalizers []string
+	for _, f := range instance.GetFinalizers() {
+		if f != finalizerName {
+			finalizers = append(finalizers, f)
+		}
+	}
+	instance.SetFinalizers(finalizers)
+	return r.update(instance)
+}
+
+// The resources the last install recorded in the status but the others
+// lack, e.g. those added by the transformer plugins, unless their API
+// is gone
+func (r *ReconcileKnativeServing) recordedResources(instance *servingv1alpha1.KnativeServing, known []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	seen := map[string]bool{}
+	for i := range known {
+		seen[resourceKey(&known[i])] = true
+	}
+	var result []unstructured.Unstructured
+	for _, applied := range instance.Status.Resources {
+		u := unstructured.Unstructured{}
+		u.SetAPIVersion(applied.APIVersion)
+		u.SetKind(applied.Kind)
+		u.SetNamespace(applied.Namespace)
+		u.SetName(applied.Name)
+		if seen[resourceKey(&u)] {
+			continue
+		}
+		seen[resourceKey(&u)] = true
+		if _, err := r.config.Get(&u); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		result = append(result, u)
+	}
+	return result, nil
+}
+
+// Drop the KnativeServing's owner references from the resources the
+// uninstall policy keeps, lest the garbage collector delete them along
+// with the KnativeServing
+func (r *ReconcileKnativeServing) disown(instance *servingv1alpha1.KnativeServing, resources []unstructured.Unstructured) error {
+	deleted := map[string]bool{}
+	for _, u := range uninstalled(instance, resources) {
+		deleted[resourceKey(&u)] = true
+	}
+	for i := range resources {
+		if deleted[resourceKey(&resources[i])] {
+			continue
+		}
+		current, err := r.config.Get(&resources[i])
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return err
+		}
+		if current == nil {
+			continue
+		}
+		refs := current.GetOwnerReferences()
+		var kept []metav1.OwnerReference
+		for _, ref := range refs {
+			if ref.UID != instance.GetUID() {
+				kept = append(kept, ref)
+			}
+		}
+		if len(kept) == len(refs) {
+			continue
+		}
+		log.V(1).Info("Disowning", "kind", current.GetKind(), "namespace", current.GetNamespace(), "name", current.GetName())
+		current.SetOwnerReferences(kept)
+		if err := r.client.Update(context.TODO(), current); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update the KnativeServing itself, e.g. its finalizers
+func (r *ReconcileKnativeServing) update(instance *servingv1alpha1.KnativeServing) error {
+	// Account for https://github.com/kubernetes-sigs/controller-runtime/issues/406
+	gvk := instance.GroupVersionKind()
+	defer instance.SetGroupVersionKind(gvk)
+
+	return r.client.Update(context.TODO(), instance)
+}
+
+func hasFinalizer(instance *servingv1alpha1.KnativeServing) bool {
+	for _, f := range instance.GetFinalizers() {
+		if f == finalizerName {
+			return true
+		}
+	}
+	return false
+}
+
+// The resources spec.uninstallPolicy deletes. KeepCRDs spares the
+// CRDs, and with them the Knative resources of the users. KeepWorkloads
+// only deletes the webhooks and the deployments reconciling Knative
+// resources, so the activator, autoscaler and ingress keep serving the
+// existing revisions.
+func uninstalled(instance *servingv1alpha1.KnativeServing, resources []unstructured.Unstructured) []unstructured.Unstructured {
+	policy := instance.Spec.UninstallPolicy
+	if policy == "" || policy == servingv1alpha1.UninstallFull {
+		return resources
+	}
+	var result []unstructured.Unstructured
+	for _, u := range resources {
+		switch {
+		case u.GetKind() == "CustomResourceDefinition":
+		case policy == servingv1alpha1.UninstallKeepWorkloads && !isWebhook(&u) && !isReconciler(instance, &u):
+		default:
+			result = append(result, u)
+		}
+	}
+	return result
+}
+
+// Whether it's a deployment reconciling Knative resources
+func isReconciler(instance *servingv1alpha1.KnativeServing, u *unstructured.Unstructured) bool {
+	return u.GetKind() == "Deployment" && u.GetNamespace() == instance.InstallNamespace() &&
+		(u.GetName() == "controller" || strings.HasPrefix(u.GetName(), "networking-"))
+}
+
+// Order the resources such that DeleteAll, which deletes in reverse,
+// removes the webhooks after everything they might validate, and the
+// namespaces after that
+func uninstallOrder(resources []unstructured.Unstructured) []unstructured.Unstructured {
+	var namespaces, webhooks, others []unstructured.Unstructured
+	for _, u := range resources {
+		switch {
+		case u.GetKind() == "Namespace":
+			namespaces = append(namespaces, u)
+		case isWebhook(&u):
+			webhooks = append(webhooks, u)
+		default:
+			others = append(others, u)
+		}
+	}
+	result := append(namespaces, webhooks...)
+	return append(result, others...)
+}
+
+func isWebhook(u *unstructured.Unstructured) bool {
+	return strings.HasSuffix(u.GetKind(), "WebhookConfiguration") || u.GetName() == "webhook"
+}
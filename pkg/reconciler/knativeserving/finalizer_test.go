@@ -0,0 +1,310 @@
+package knativeserving
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	mf "github.com/jcrossley3/manifestival"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const testManifest = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: knative-serving
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-network
+  namespace: knative-serving
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: webhook
+  namespace: knative-serving
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller
+  namespace: knative-serving
+`
+
+// newTestManifest parses the yaml into a Manifest bound to the client
+func newTestManifest(t *testing.T, yaml string, c client.Client) mf.Manifest {
+	f, err := ioutil.TempFile("", "manifest-*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(yaml); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	m, err := mf.NewManifest(f.Name(), false, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestUninstallOrder(t *testing.T) {
+	m := newTestManifest(t, testManifest, nil)
+	ordered := uninstallOrder(m.Resources)
+	// DeleteAll deletes in reverse order
+	want := []string{"knative-serving", "webhook", "config-network", "controller"}
+	if len(ordered) != len(want) {
+		t.Fatalf("got %d resources, want %d", len(ordered), len(want))
+	}
+	for i, name := range want {
+		if got := ordered[i].GetName(); got != name {
+			t.Errorf("resource %d = %q, want %q", i, got, name)
+		}
+	}
+}
+
+func TestEnsureFinalizer(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+	}
+	c := newFakeClient(newTestScheme(), instance.DeepCopy())
+	r := &ReconcileKnativeServing{client: c}
+
+	if err := r.ensureFinalizer(instance); err != nil {
+		t.Fatalf("ensureFinalizer() = %v", err)
+	}
+	stored := &servingv1alpha1.KnativeServing{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: operand}, stored); err != nil {
+		t.Fatal(err)
+	}
+	if !hasFinalizer(stored) {
+		t.Errorf("finalizer not added: %v", stored.GetFinalizers())
+	}
+}
+
+func TestDeleteUninstallsAndReleases(t *testing.T) {
+	now := metav1.Now()
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         operand,
+			Name:              operand,
+			DeletionTimestamp: &now,
+			Finalizers:        []string{"other", finalizerName},
+		},
+	}
+	c := newFakeClient(newTestScheme(),
+		instance.DeepCopy(),
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "config-network"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "webhook"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "controller"}},
+	)
+	r := &ReconcileKnativeServing{client: c, config: newTestManifest(t, testManifest, c)}
+
+	if err := r.delete(instance); err != nil {
+		t.Fatalf("delete() = %v", err)
+	}
+	for _, u := range r.config.Resources {
+		if u.GetKind() == "Namespace" {
+			continue
+		}
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(u.GroupVersionKind())
+		if err := c.Get(context.TODO(), client.ObjectKey{Namespace: u.GetNamespace(), Name: u.GetName()}, current); err == nil {
+			t.Errorf("%s %s not deleted", u.GetKind(), u.GetName())
+		}
+	}
+	if hasFinalizer(instance) {
+		t.Error("finalizer not removed")
+	}
+	if got := instance.GetFinalizers(); len(got) != 1 || got[0] != "other" {
+		t.Errorf("finalizers = %v, want [other]", got)
+	}
+	if cond := instance.Status.GetCondition(servingv1alpha1.InstallSucceeded); cond == nil || cond.Reason != "Uninstalling" {
+		t.Errorf("InstallSucceeded = %v, want reason Uninstalling", cond)
+	}
+}
+
+func TestDeleteUninstallsFromInstallNamespace(t *testing.T) {
+	now := metav1.Now()
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         operand,
+			Name:              operand,
+			DeletionTimestamp: &now,
+			Finalizers:        []string{finalizerName},
+		},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Namespace: "serving-system",
+			HighAvailability: &servingv1alpha1.HighAvailability{
+				Autoscaling: &servingv1alpha1.HighAvailabilityAutoscaling{MaxReplicas: 10},
+			},
+		},
+	}
+	instance.Spec.PodDisruptionBudgets = []servingv1alpha1.PodDisruptionBudget{{Deployment: "webhook"}}
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Namespace: "serving-system", Name: activatorDeployment}}
+	pdb := &policyv1beta1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Namespace: "serving-system", Name: "webhook"}}
+	c := newFakeClient(newTestScheme(), instance.DeepCopy(), hpa, pdb)
+	// Budgets are only built for deployments with a selector
+	manifest := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: webhook
+  namespace: knative-serving
+spec:
+  selector:
+    matchLabels:
+      app: webhook
+`
+	r := &ReconcileKnativeServing{client: c, config: newTestManifest(t, manifest, c)}
+
+	if err := r.delete(instance); err != nil {
+		t.Fatalf("delete() = %v", err)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "serving-system", Name: activatorDeployment}, &autoscalingv1.HorizontalPodAutoscaler{}); err == nil {
+		t.Error("the activator's HorizontalPodAutoscaler in spec.namespace wasn't deleted")
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "serving-system", Name: "webhook"}, &policyv1beta1.PodDisruptionBudget{}); err == nil {
+		t.Error("the webhook's PodDisruptionBudget in spec.namespace wasn't deleted")
+	}
+}
+
+func TestDeleteRemovesRecordedResources(t *testing.T) {
+	now := metav1.Now()
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         operand,
+			Name:              operand,
+			DeletionTimestamp: &now,
+			Finalizers:        []string{finalizerName},
+		},
+		Status: servingv1alpha1.KnativeServingStatus{
+			Resources: []servingv1alpha1.AppliedResource{{
+				APIVersion: "v1", Kind: "ConfigMap", Namespace: operand, Name: "added-by-plugin",
+			}},
+		},
+	}
+	added := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "added-by-plugin"}}
+	c := newFakeClient(newTestScheme(), instance.DeepCopy(), added)
+	r := &ReconcileKnativeServing{client: c, config: newTestManifest(t, testManifest, c)}
+
+	if err := r.delete(instance); err != nil {
+		t.Fatalf("delete() = %v", err)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "added-by-plugin"}, &v1.ConfigMap{}); err == nil {
+		t.Error("the ConfigMap recorded in status.resources wasn't deleted")
+	}
+}
+
+func TestReconcileOfMissingInstanceLeavesInstall(t *testing.T) {
+	network := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "config-network"}}
+	c := newFakeClient(newTestScheme(), network)
+	r := &ReconcileKnativeServing{client: c, config: newTestManifest(t, testManifest, c)}
+
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: operand, Name: operand}}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	// Only the finalizer uninstalls, as the uninstall policy says
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "config-network"}, &v1.ConfigMap{}); err != nil {
+		t.Errorf("config-network was deleted: %v", err)
+	}
+}
+
+func TestDeleteDisownsKeptWorkloads(t *testing.T) {
+	now := metav1.Now()
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         operand,
+			Name:              operand,
+			UID:               "ks-uid",
+			DeletionTimestamp: &now,
+			Finalizers:        []string{finalizerName},
+		},
+		Spec: servingv1alpha1.KnativeServingSpec{UninstallPolicy: servingv1alpha1.UninstallKeepWorkloads},
+	}
+	owned := func(name string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Namespace:       operand,
+			Name:            name,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(instance, instance.GroupVersionKind())},
+		}}
+	}
+	c := newFakeClient(newTestScheme(), instance.DeepCopy(), owned("controller"), owned("activator"))
+	manifest := testManifest + `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: activator
+  namespace: knative-serving
+`
+	r := &ReconcileKnativeServing{client: c, config: newTestManifest(t, manifest, c)}
+
+	if err := r.delete(instance); err != nil {
+		t.Fatalf("delete() = %v", err)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "controller"}, &appsv1.Deployment{}); err == nil {
+		t.Error("the controller should be deleted")
+	}
+	activator := &appsv1.Deployment{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "activator"}, activator); err != nil {
+		t.Fatalf("the activator should be kept: %v", err)
+	}
+	if refs := activator.GetOwnerReferences(); len(refs) != 0 {
+		t.Errorf("owner references = %v, want none for the garbage collector to follow", refs)
+	}
+}
+
+func TestUninstalled(t *testing.T) {
+	m := newTestManifest(t, `apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: services.serving.knative.dev
+---
+`+testManifest+`---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: activator
+  namespace: knative-serving
+`, nil)
+	tests := []struct {
+		policy string
+		want   []string
+	}{{
+		policy: "",
+		want:   []string{"services.serving.knative.dev", "knative-serving", "config-network", "webhook", "controller", "activator"},
+	}, {
+		policy: servingv1alpha1.UninstallKeepCRDs,
+		want:   []string{"knative-serving", "config-network", "webhook", "controller", "activator"},
+	}, {
+		policy: servingv1alpha1.UninstallKeepWorkloads,
+		want:   []string{"webhook", "controller"},
+	}}
+	for _, tt := range tests {
+		instance := &servingv1alpha1.KnativeServing{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+			Spec:       servingv1alpha1.KnativeServingSpec{UninstallPolicy: tt.policy},
+		}
+		var got []string
+		for _, u := range uninstalled(instance, m.Resources) {
+			got = append(got, u.GetName())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("uninstalled(%q) = %v, want %v", tt.policy, got, tt.want)
+		}
+	}
+}
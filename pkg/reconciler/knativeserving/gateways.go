@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/pkg/reconciler/knativeserving/common"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// The istio API of the Gateways knative routes through
+	istioGatewayAPIVersion = "networking.istio.io/v1alpha3"
+)
+
+// Report whether the istio Gateways the user brings, rather than
+// spec.ingress.istio installing them, exist in the install namespace
+func (r *ReconcileKnativeServing) checkGateways(instance *servingv1alpha1.KnativeServing) error {
+	ingress := instance.Spec.Ingress
+	if ingress.Name() != servingv1alpha1.IstioIngress || ingress.GatewaysInstalled() {
+		if instance.Status.GetCondition(servingv1alpha1.GatewaysAvailable) == nil {
+			return nil
+		}
+		instance.Status.ClearGatewaysAvailable()
+		return r.updateStatus(instance)
+	}
+	var missing []string
+	for _, name := range common.GatewayNames(withProfile(instance)) {
+		gateway := &unstructured.Unstructured{}
+		gateway.SetAPIVersion(istioGatewayAPIVersion)
+		gateway.SetKind("Gateway")
+		err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: instance.InstallNamespace(), Name: name}, gateway)
+		switch {
+		case meta.IsNoMatchError(err):
+			instance.Status.MarkGatewaysUnavailable("NotInstalled",
+				fmt.Sprintf("istio's %s API is not installed", istioGatewayAPIVersion))
+			return r.updateStatus(instance)
+		case errors.IsNotFound(err):
+			missing = append(missing, name)
+		case err != nil:
+			return err
+		}
+	}
+	if len(missing) > 0 {
+		instance.Status.MarkGatewaysUnavailable("GatewaysNotFound",
+			fmt.Sprintf("Gateways not found in namespace %s: %s", instance.InstallNamespace(), strings.Join(missing, ", ")))
+	} else {
+		instance.Status.MarkGatewaysAvailable()
+	}
+	return r.updateStatus(instance)
+}
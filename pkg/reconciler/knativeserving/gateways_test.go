@@ -0,0 +1,57 @@
+package knativeserving
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func newGateway(name string) *unstructured.Unstructured {
+	gateway := &unstructured.Unstructured{}
+	gateway.SetAPIVersion(istioGatewayAPIVersion)
+	gateway.SetKind("Gateway")
+	gateway.SetNamespace(operand)
+	gateway.SetName(name)
+	return gateway
+}
+
+func TestCheckGatewaysAvailable(t *testing.T) {
+	instance := newInstance(withOwnGateways())
+	r := &ReconcileKnativeServing{client: newFakeClient(newTestScheme(), instance.DeepCopy(),
+		newGateway("knative-ingress-gateway"), newGateway("cluster-local-gateway"))}
+
+	if err := r.checkGateways(instance); err != nil {
+		t.Fatalf("checkGateways() = %v", err)
+	}
+	if !instance.Status.GetCondition(servingv1alpha1.GatewaysAvailable).IsTrue() {
+		t.Errorf("GatewaysAvailable = %v, want True", instance.Status.GetCondition(servingv1alpha1.GatewaysAvailable))
+	}
+}
+
+func TestCheckGatewaysMissing(t *testing.T) {
+	instance := newInstance(withOwnGateways())
+	r := &ReconcileKnativeServing{client: newFakeClient(newTestScheme(), instance.DeepCopy(),
+		newGateway("knative-ingress-gateway"))}
+
+	if err := r.checkGateways(instance); err != nil {
+		t.Fatalf("checkGateways() = %v", err)
+	}
+	condition := instance.Status.GetCondition(servingv1alpha1.GatewaysAvailable)
+	if !condition.IsFalse() || condition.Reason != "GatewaysNotFound" {
+		t.Fatalf("GatewaysAvailable = %v, want False with reason GatewaysNotFound", condition)
+	}
+	want := "Gateways not found in namespace " + operand + ": cluster-local-gateway"
+	if condition.Message != want {
+		t.Errorf("Message = %q, want %q", condition.Message, want)
+	}
+
+	// Installing the gateways again clears the condition
+	instance.Spec.Ingress.Istio.InstallGateways = nil
+	if err := r.checkGateways(instance); err != nil {
+		t.Fatalf("checkGateways() = %v", err)
+	}
+	if instance.Status.GetCondition(servingv1alpha1.GatewaysAvailable) != nil {
+		t.Error("expected the condition to be cleared")
+	}
+}
@@ -0,0 +1,93 @@
+package knativeserving
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+// instanceOption shapes the KnativeServing of a test
+type instanceOption func(*servingv1alpha1.KnativeServing)
+
+// newInstance is the KnativeServing knative-serving/knative-serving,
+// its conditions initialized, as the options leave it
+func newInstance(opts ...instanceOption) *servingv1alpha1.KnativeServing {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+	}
+	instance.Status.InitializeConditions()
+	for _, opt := range opts {
+		opt(instance)
+	}
+	return instance
+}
+
+func withSpec(spec servingv1alpha1.KnativeServingSpec) instanceOption {
+	return func(instance *servingv1alpha1.KnativeServing) {
+		instance.Spec = spec
+	}
+}
+
+// Issuing the certificates of the routes with cert-manager's issuer
+func withCertManager(issuer string) instanceOption {
+	return func(instance *servingv1alpha1.KnativeServing) {
+		instance.Spec.CertManager = &servingv1alpha1.CertManager{Enabled: true, ClusterIssuer: issuer}
+	}
+}
+
+// Routing through Istio gateways the cluster brings itself
+func withOwnGateways() instanceOption {
+	return func(instance *servingv1alpha1.KnativeServing) {
+		install := false
+		instance.Spec.Ingress = &servingv1alpha1.IngressConfigs{
+			Istio: servingv1alpha1.IstioIngressConfiguration{Enabled: true, InstallGateways: &install},
+		}
+	}
+}
+
+// Overriding spec.config and spec.registry from the Secret
+// serving-overrides, with defaults of its own
+func withOverridesFrom() instanceOption {
+	return func(instance *servingv1alpha1.KnativeServing) {
+		instance.Spec.Config = map[string]map[string]string{"network": {"istio.sidecar.includeOutboundIPRanges": "*"}}
+		instance.Spec.Registry = servingv1alpha1.Registry{Default: "gcr.io/public/${NAME}:latest"}
+		instance.Spec.OverridesFrom = &servingv1alpha1.OverridesSource{
+			SecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "serving-overrides"},
+				Key:                  "overrides.yaml",
+			},
+		}
+	}
+}
+
+// Installed the version before, if any
+func withInstalled(version string) instanceOption {
+	return func(instance *servingv1alpha1.KnativeServing) {
+		instance.Status.Version = version
+	}
+}
+
+// Installing the first generation for the time started, within the
+// timeout
+func withInstallDeadline(timeout, started time.Duration) instanceOption {
+	return func(instance *servingv1alpha1.KnativeServing) {
+		start := metav1.NewTime(time.Now().Add(-started))
+		instance.Generation = 1
+		instance.Spec.InstallTimeout = &metav1.Duration{Duration: timeout}
+		instance.Status.InstallStartTime = &start
+		instance.Status.InstallGeneration = 1
+	}
+}
+
+// newTestReconciler reconciles the instance in a fake client, recording
+// its events
+func newTestReconciler(instance *servingv1alpha1.KnativeServing) (*ReconcileKnativeServing, *record.FakeRecorder) {
+	recorder := record.NewFakeRecorder(10)
+	return &ReconcileKnativeServing{
+		client:   newFakeClient(newTestScheme(), instance.DeepCopy()),
+		recorder: recorder,
+	}, recorder
+}
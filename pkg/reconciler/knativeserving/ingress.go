@@ -0,0 +1,193 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/pkg/reconciler/knativeserving/common"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Holds a directory of manifests per ingress, e.g. ingress/kourier
+	ingressDir = "ingress"
+)
+
+var (
+	// The deployments an ingress can't serve routes without, whether
+	// its bundled manifest includes them or it was installed separately
+	ingressDeployments = map[string][]client.ObjectKey{
+		servingv1alpha1.GlooIngress: {
+			{Namespace: "gloo-system", Name: "knative-external-proxy"},
+			{Namespace: "gloo-system", Name: "knative-internal-proxy"},
+		},
+		servingv1alpha1.KourierIngress: {
+			{Namespace: "kourier-system", Name: "3scale-kourier-gateway"},
+		},
+		servingv1alpha1.AmbassadorIngress: {
+			{Namespace: common.AmbassadorNamespace, Name: "ambassador"},
+		},
+	}
+
+	// The DaemonSets an ingress can't serve routes without, likewise
+	ingressDaemonSets = map[string][]client.ObjectKey{
+		servingv1alpha1.ContourIngress: {
+			{Namespace: "contour-external", Name: "envoy"},
+			{Namespace: "contour-internal", Name: "envoy"},
+		},
+	}
+)
+
+// Parse the bundled manifests of the ingresses that don't ship with
+// the core manifest, keyed by ingress name
+func loadIngresses(koDataDir string) (map[string][]unstructured.Unstructured, error) {
+	result := map[string][]unstructured.Unstructured{}
+	dirs, err := ioutil.ReadDir(filepath.Join(koDataDir, ingressDir))
+	if os.IsNotExist(err) {
+		return result, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		resources, err := mf.Parse(filepath.Join(koDataDir, ingressDir, dir.Name()), false)
+		if err != nil {
+			return nil, err
+		}
+		result[dir.Name()] = resources
+	}
+	return result, nil
+}
+
+// Split the resources to install into the core resources for the
+// selected ingress and the manifest bundled for it, if any, leaving
+// out the disabled components and any istio Gateways the user brings
+func (r *ReconcileKnativeServing) selectIngress(instance *servingv1alpha1.KnativeServing) (core, ingress []unstructured.Unstructured, err error) {
+	if err := instance.Spec.Ingress.Validate(context.TODO()); err != nil {
+		return nil, nil, permanent(err)
+	}
+	release, err := r.manifestFor(instance)
+	if err != nil {
+		return nil, nil, err
+	}
+	name := instance.Spec.Ingress.Name()
+	// The profile may disable components too
+	profiled := withProfile(instance)
+	core = common.FilterGateways(common.FilterComponents(common.FilterIngress(release.Resources, name), profiled), profiled)
+	if name == servingv1alpha1.IstioIngress {
+		// Ships with the core manifest
+		return core, nil, nil
+	}
+	ingress, ok := r.ingresses[name]
+	if !ok {
+		return nil, nil, permanent(fmt.Errorf("no manifest is bundled for the %s ingress, see ./hack/update-%s.sh", name, name))
+	}
+	return core, common.FilterComponents(ingress, profiled), nil
+}
+
+// The deployments that must be available: those of the core
+// resources, in the namespace they're installed into, and of the
+// selected ingress
+func deploymentKeys(instance *servingv1alpha1.KnativeServing, core, ingress []unstructured.Unstructured) []client.ObjectKey {
+	if crdsOnly(instance) {
+		return nil
+	}
+	var result []client.ObjectKey
+	seen := map[client.ObjectKey]bool{}
+	add := func(key client.ObjectKey) {
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, key)
+		}
+	}
+	for _, u := range core {
+		if u.GetKind() == "Deployment" {
+			add(client.ObjectKey{Namespace: instance.InstallNamespace(), Name: u.GetName()})
+		}
+	}
+	for _, u := range ingress {
+		if u.GetKind() == "Deployment" {
+			add(client.ObjectKey{Namespace: u.GetNamespace(), Name: u.GetName()})
+		}
+	}
+	for _, key := range ingressDeployments[instance.Spec.Ingress.Name()] {
+		add(key)
+	}
+	return result
+}
+
+// The DaemonSets of the selected ingress that must be available
+func daemonSetKeys(instance *servingv1alpha1.KnativeServing, ingress []unstructured.Unstructured) []client.ObjectKey {
+	if crdsOnly(instance) {
+		return nil
+	}
+	var result []client.ObjectKey
+	seen := map[client.ObjectKey]bool{}
+	add := func(key client.ObjectKey) {
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, key)
+		}
+	}
+	for _, u := range ingress {
+		if u.GetKind() == "DaemonSet" {
+			add(client.ObjectKey{Namespace: u.GetNamespace(), Name: u.GetName()})
+		}
+	}
+	for _, key := range ingressDaemonSets[instance.Spec.Ingress.Name()] {
+		add(key)
+	}
+	return result
+}
+
+// Every resource the operator may have installed into the namespace,
+// whichever release and ingress was selected and whether
+// NetworkPolicies were enabled
+func (r *ReconcileKnativeServing) allResources(namespace string) []unstructured.Unstructured {
+	result := append([]unstructured.Unstructured{}, r.config.Resources...)
+	seen := map[string]bool{}
+	for i := range result {
+		seen[resourceKey(&result[i])] = true
+	}
+	for _, release := range r.releases {
+		for i := range release.Resources {
+			if key := resourceKey(&release.Resources[i]); !seen[key] {
+				seen[key] = true
+				result = append(result, release.Resources[i])
+			}
+		}
+	}
+	result = append(result, networkPolicies()...)
+	relocate := mf.InjectNamespace(namespace)
+	for i := range result {
+		result[i] = *result[i].DeepCopy()
+		relocate(&result[i])
+	}
+	for _, resources := range r.ingresses {
+		result = append(result, resources...)
+	}
+	return result
+}
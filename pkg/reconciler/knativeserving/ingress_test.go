@@ -0,0 +1,364 @@
+package knativeserving
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const istioManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller
+  namespace: knative-serving
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: networking-istio
+  namespace: knative-serving
+  labels:
+    networking.knative.dev/ingress-provider: istio
+`
+
+const kourierManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: 3scale-kourier-gateway
+  namespace: kourier-system
+`
+
+const glooManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: knative-external-proxy
+  namespace: gloo-system
+`
+
+func TestLoadIngresses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kodata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ingresses, err := loadIngresses(dir)
+	if err != nil {
+		t.Fatalf("loadIngresses() = %v", err)
+	}
+	if len(ingresses) != 0 {
+		t.Errorf("got %d ingresses without an ingress directory, want 0", len(ingresses))
+	}
+
+	kourierDir := filepath.Join(dir, ingressDir, "kourier")
+	if err := os.MkdirAll(kourierDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(kourierDir, "kourier.yaml"), []byte(kourierManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ingresses, err = loadIngresses(dir)
+	if err != nil {
+		t.Fatalf("loadIngresses() = %v", err)
+	}
+	if got := len(ingresses["kourier"]); got != 1 {
+		t.Errorf("got %d kourier resources, want 1", got)
+	}
+}
+
+const contourManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-contour
+  namespace: knative-serving
+data:
+  _example: "..."
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: contour
+  namespace: contour-external
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: envoy
+  namespace: contour-external
+`
+
+func TestBundledIngresses(t *testing.T) {
+	ingresses, err := loadIngresses("../../../cmd/manager/kodata")
+	if err != nil {
+		t.Fatalf("loadIngresses() = %v", err)
+	}
+	// The releases committed under kodata/ingress
+	for _, name := range []string{servingv1alpha1.KourierIngress, servingv1alpha1.AmbassadorIngress, servingv1alpha1.ContourIngress} {
+		if _, ok := ingresses[name]; !ok {
+			t.Errorf("the %s ingress isn't bundled, see ./hack/update-%s.sh", name, name)
+		}
+	}
+	known := map[string]bool{}
+	for _, name := range servingv1alpha1.IngressNames() {
+		known[name] = name != servingv1alpha1.IstioIngress
+	}
+	for name, resources := range ingresses {
+		if !known[name] {
+			t.Errorf("bundled ingress %s isn't one that's installed separately from Knative Serving", name)
+			continue
+		}
+		// The bundle holds what readiness waits on
+		names := map[string]bool{}
+		for _, u := range resources {
+			names[u.GetKind()+"/"+u.GetName()] = true
+		}
+		for _, key := range ingressDeployments[name] {
+			if !names["Deployment/"+key.Name] {
+				t.Errorf("bundled ingress %s lacks Deployment %s", name, key.Name)
+			}
+		}
+		for _, key := range ingressDaemonSets[name] {
+			if !names["DaemonSet/"+key.Name] {
+				t.Errorf("bundled ingress %s lacks DaemonSet %s", name, key.Name)
+			}
+		}
+	}
+}
+
+func TestSelectIngress(t *testing.T) {
+	r := &ReconcileKnativeServing{
+		config: newTestManifest(t, istioManifest, nil),
+	}
+	r.ingresses = map[string][]unstructured.Unstructured{
+		"kourier": newTestManifest(t, kourierManifest, nil).Resources,
+	}
+
+	tests := []struct {
+		name    string
+		ingress *servingv1alpha1.IngressConfigs
+		core    int
+		bundled int
+		wantErr bool
+	}{{
+		name: "istio by default",
+		core: 2,
+	}, {
+		name:    "kourier",
+		ingress: &servingv1alpha1.IngressConfigs{Kourier: servingv1alpha1.KourierIngressConfiguration{Enabled: true}},
+		core:    1,
+		bundled: 1,
+	}, {
+		name:    "gloo isn't supported yet",
+		ingress: &servingv1alpha1.IngressConfigs{Gloo: servingv1alpha1.GlooIngressConfiguration{Enabled: true}},
+		wantErr: true,
+	}, {
+		name: "several ingresses",
+		ingress: &servingv1alpha1.IngressConfigs{
+			Istio:   servingv1alpha1.IstioIngressConfiguration{Enabled: true},
+			Kourier: servingv1alpha1.KourierIngressConfiguration{Enabled: true},
+		},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &servingv1alpha1.KnativeServing{
+				Spec: servingv1alpha1.KnativeServingSpec{Ingress: tt.ingress},
+			}
+			core, bundled, err := r.selectIngress(instance)
+			if got := err != nil; got != tt.wantErr {
+				t.Fatalf("selectIngress() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(core) != tt.core || len(bundled) != tt.bundled {
+				t.Errorf("got %d core and %d bundled resources, want %d and %d", len(core), len(bundled), tt.core, tt.bundled)
+			}
+		})
+	}
+
+	if got, want := len(r.allResources(operand)), 3+len(networkPolicies()); got != want {
+		t.Errorf("got %d resources to uninstall, want %d", got, want)
+	}
+}
+
+func TestDeploymentKeysOfGlooProxies(t *testing.T) {
+	// Kept for when net-gloo is bundled, which validation waits on
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Ingress: &servingv1alpha1.IngressConfigs{Gloo: servingv1alpha1.GlooIngressConfiguration{Enabled: true}},
+		},
+	}
+	keys := deploymentKeys(instance, nil, newTestManifest(t, glooManifest, nil).Resources)
+	want := map[client.ObjectKey]bool{
+		{Namespace: "gloo-system", Name: "knative-external-proxy"}: true,
+		{Namespace: "gloo-system", Name: "knative-internal-proxy"}: true,
+	}
+	for _, key := range keys {
+		delete(want, key)
+	}
+	if len(want) != 0 {
+		t.Errorf("deploymentKeys() = %v, missing the gloo proxies %v", keys, want)
+	}
+
+	r := &ReconcileKnativeServing{client: newFakeClient(newTestScheme()), recorder: record.NewFakeRecorder(10), config: newTestManifest(t, testManifest, nil)}
+	if err := r.checkDeployments(instance); err == nil {
+		t.Error("checkDeployments() succeeded, want gloo rejected")
+	}
+}
+
+func TestCheckDeploymentsWaitsOnContourEnvoys(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Ingress: &servingv1alpha1.IngressConfigs{Contour: servingv1alpha1.ContourIngressConfiguration{Enabled: true}},
+		},
+	}
+	instance.Status.InitializeConditions()
+	available := func(namespace, name string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Status: appsv1.DeploymentStatus{
+				Conditions: []appsv1.DeploymentCondition{{
+					Type:   appsv1.DeploymentAvailable,
+					Status: corev1.ConditionTrue,
+				}},
+			},
+		}
+	}
+	envoy := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "contour-external", Name: "envoy", Generation: 2},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration:     2,
+			DesiredNumberScheduled: 3,
+			UpdatedNumberScheduled: 3,
+			NumberAvailable:        1,
+		},
+	}
+	c := newFakeClient(newTestScheme(), instance.DeepCopy(),
+		available(operand, "webhook"), available(operand, "controller"),
+		available("contour-external", "contour"), envoy)
+	r := &ReconcileKnativeServing{client: c, recorder: record.NewFakeRecorder(10), config: newTestManifest(t, testManifest, c)}
+	r.ingresses = map[string][]unstructured.Unstructured{
+		"contour": newTestManifest(t, contourManifest, c).Resources,
+	}
+
+	if err := r.checkDeployments(instance); err != nil {
+		t.Fatalf("checkDeployments() = %v", err)
+	}
+	if instance.Status.IsAvailable() {
+		t.Error("deployments shouldn't be available without the envoys")
+	}
+	if got := instance.Status.Deployments; len(got) != 0 {
+		t.Errorf("unready deployments = %v, want none", got)
+	}
+	want := []servingv1alpha1.DaemonSetStatus{{
+		Namespace: "contour-external",
+		Name:      "envoy",
+		Reason:    "Unavailable",
+		Message:   "1 of 3 pods are available",
+	}, {
+		Namespace: "contour-internal",
+		Name:      "envoy",
+		Reason:    "NotFound",
+		Message:   "The DaemonSet does not exist",
+	}}
+	if got := instance.Status.DaemonSets; !reflect.DeepEqual(got, want) {
+		t.Errorf("unready DaemonSets = %v, want %v", got, want)
+	}
+}
+
+func TestTransformConfiguresContour(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c, scheme: newTestScheme(), config: newTestManifest(t, istioManifest, c)}
+	r.ingresses = map[string][]unstructured.Unstructured{
+		"contour": newTestManifest(t, contourManifest, c).Resources,
+	}
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Config: map[string]map[string]string{"contour": {"default-tls-secret": "certs/wildcard"}},
+			Ingress: &servingv1alpha1.IngressConfigs{Contour: servingv1alpha1.ContourIngressConfiguration{
+				Enabled:  true,
+				External: &servingv1alpha1.ContourVisibility{Class: "contour-public"},
+			}},
+		},
+	}
+	manifest, err := r.transform(instance, nil)
+	if err != nil {
+		t.Fatalf("transform() = %v", err)
+	}
+	var cm *unstructured.Unstructured
+	for i := range manifest.Resources {
+		if u := &manifest.Resources[i]; u.GetKind() == "ConfigMap" && u.GetName() == "config-contour" {
+			cm = u
+		}
+	}
+	if cm == nil {
+		t.Fatal("config-contour wasn't installed")
+	}
+	data, _, _ := unstructured.NestedStringMap(cm.Object, "data")
+	want := map[string]string{
+		"_example":           "...",
+		"default-tls-secret": "certs/wildcard",
+		"visibility": `ClusterLocal:
+  class: contour-internal
+  service: contour-internal/envoy
+ExternalIP:
+  class: contour-public
+  service: contour-external/envoy
+`,
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("config-contour data = %v, want %v", data, want)
+	}
+}
+
+func TestDeploymentKeysTrackKourierGateway(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Ingress: &servingv1alpha1.IngressConfigs{Kourier: servingv1alpha1.KourierIngressConfiguration{Enabled: true}},
+		},
+	}
+	c := newFakeClient(newTestScheme())
+	keys := deploymentKeys(instance, newTestManifest(t, testManifest, c).Resources, nil)
+	if got := keys[len(keys)-1]; got.Namespace != "kourier-system" || got.Name != "3scale-kourier-gateway" {
+		t.Errorf("deploymentKeys() = %v, want the kourier gateway last", keys)
+	}
+}
+
+func TestTransformStampsIngress(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c, scheme: newTestScheme(), config: newTestManifest(t, istioManifest, c)}
+	r.ingresses = map[string][]unstructured.Unstructured{
+		"kourier": newTestManifest(t, kourierManifest, c).Resources,
+	}
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Ingress:      &servingv1alpha1.IngressConfigs{Kourier: servingv1alpha1.KourierIngressConfiguration{Enabled: true}},
+			CommonLabels: map[string]string{"example.com/cost-center": "cc-1234"},
+		},
+	}
+	manifest, err := r.transform(instance, nil)
+	if err != nil {
+		t.Fatalf("transform() = %v", err)
+	}
+	for _, u := range manifest.Resources {
+		if got := u.GetLabels()["example.com/cost-center"]; got != "cc-1234" {
+			t.Errorf("%s %s/%s cost-center = %q, want cc-1234", u.GetKind(), u.GetNamespace(), u.GetName(), got)
+		}
+	}
+	// The bundle is shared by every reconcile
+	if labels := r.ingresses["kourier"][0].GetLabels(); len(labels) != 0 {
+		t.Errorf("bundled kourier labels = %v, want none", labels)
+	}
+}
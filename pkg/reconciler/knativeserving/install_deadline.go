@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Raise InstallDeadlineExceeded if the current generation of the spec
+// hasn't become Ready within spec.installTimeout, measured from the
+// first reconcile of that generation. Until the deadline passes, the
+// request is requeued for when it's due.
+func (r *ReconcileKnativeServing) checkInstallDeadline(instance *servingv1alpha1.KnativeServing) (reconcile.Result, error) {
+	status := &instance.Status
+	changed := false
+	if status.InstallStartTime == nil || status.InstallGeneration != instance.Generation {
+		// A new spec resets the clock
+		now := metav1.Now()
+		status.InstallStartTime = &now
+		status.InstallGeneration = instance.Generation
+		status.ClearInstallDeadlineExceeded()
+		changed = true
+	}
+
+	result := reconcile.Result{}
+	if timeout := instance.Spec.InstallTimeout; timeout == nil || status.IsReady() {
+		if status.IsInstallDeadlineExceeded() {
+			status.ClearInstallDeadlineExceeded()
+			changed = true
+		}
+	} else if remaining := timeout.Duration - time.Since(status.InstallStartTime.Time); remaining > 0 {
+		result.RequeueAfter = remaining
+	} else {
+		if !status.IsInstallDeadlineExceeded() {
+			log.Info("Install deadline exceeded", "timeout", timeout.Duration)
+			status.MarkInstallDeadlineExceeded(timeout.Duration)
+			r.recorder.Eventf(instance, v1.EventTypeWarning, "InstallDeadlineExceeded",
+				"Install did not become ready within %v", timeout.Duration)
+			changed = true
+		}
+		// Keep retrying
+		result.Requeue = true
+	}
+
+	if changed {
+		return result, r.updateStatus(instance)
+	}
+	return result, nil
+}
@@ -0,0 +1,107 @@
+package knativeserving
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInstallDeadlineConvergesInTime(t *testing.T) {
+	instance := newInstance(withInstallDeadline(10*time.Minute, time.Minute))
+	instance.Status.MarkTransformed()
+	instance.Status.MarkApplied()
+	instance.Status.MarkInstallSucceeded()
+	instance.Status.MarkDeploymentsAvailable()
+	instance.Status.MarkSpecValidated()
+	instance.Status.MarkPreflightSucceeded()
+	instance.Status.MarkVersionMigrationEligible()
+	instance.Status.MarkProbesSucceeded()
+	r, recorder := newTestReconciler(instance)
+
+	result, err := r.checkInstallDeadline(instance)
+	if err != nil {
+		t.Fatalf("checkInstallDeadline() = %v", err)
+	}
+	if result.Requeue || result.RequeueAfter != 0 {
+		t.Errorf("unexpected requeue for a ready install: %+v", result)
+	}
+	if instance.Status.IsInstallDeadlineExceeded() {
+		t.Error("InstallDeadlineExceeded should not be set for a ready install")
+	}
+	if len(recorder.Events) != 0 {
+		t.Errorf("unexpected event: %s", <-recorder.Events)
+	}
+}
+
+func TestInstallDeadlinePending(t *testing.T) {
+	instance := newInstance(withInstallDeadline(10*time.Minute, time.Minute))
+	r, _ := newTestReconciler(instance)
+
+	result, err := r.checkInstallDeadline(instance)
+	if err != nil {
+		t.Fatalf("checkInstallDeadline() = %v", err)
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > 9*time.Minute {
+		t.Errorf("RequeueAfter = %v, want the remaining time until the deadline", result.RequeueAfter)
+	}
+	if instance.Status.IsInstallDeadlineExceeded() {
+		t.Error("InstallDeadlineExceeded should not be set before the deadline")
+	}
+}
+
+func TestInstallDeadlineExceeded(t *testing.T) {
+	instance := newInstance(withInstallDeadline(time.Minute, 10*time.Minute))
+	instance.Status.MarkDeploymentsNotReady()
+	r, recorder := newTestReconciler(instance)
+
+	result, err := r.checkInstallDeadline(instance)
+	if err != nil {
+		t.Fatalf("checkInstallDeadline() = %v", err)
+	}
+	if !result.Requeue {
+		t.Error("expected the request to be retried after the deadline")
+	}
+	if !instance.Status.IsInstallDeadlineExceeded() {
+		t.Fatal("InstallDeadlineExceeded should be set after the deadline")
+	}
+	if instance.Status.IsReady() {
+		t.Error("Ready should be unaffected by the deadline")
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, "Warning InstallDeadlineExceeded") {
+			t.Errorf("unexpected event: %s", event)
+		}
+	default:
+		t.Error("expected a Warning event")
+	}
+
+	// The event is only emitted once
+	if _, err := r.checkInstallDeadline(instance); err != nil {
+		t.Fatalf("checkInstallDeadline() = %v", err)
+	}
+	if len(recorder.Events) != 0 {
+		t.Errorf("unexpected event: %s", <-recorder.Events)
+	}
+}
+
+func TestInstallDeadlineResetOnSpecChange(t *testing.T) {
+	instance := newInstance(withInstallDeadline(time.Minute, 10*time.Minute))
+	instance.Status.MarkInstallDeadlineExceeded(time.Minute)
+	instance.Generation = 2
+	r, _ := newTestReconciler(instance)
+
+	result, err := r.checkInstallDeadline(instance)
+	if err != nil {
+		t.Fatalf("checkInstallDeadline() = %v", err)
+	}
+	if instance.Status.IsInstallDeadlineExceeded() {
+		t.Error("InstallDeadlineExceeded should be cleared for a new spec")
+	}
+	if instance.Status.InstallGeneration != 2 {
+		t.Errorf("InstallGeneration = %d, want 2", instance.Status.InstallGeneration)
+	}
+	if result.RequeueAfter <= 0 {
+		t.Errorf("RequeueAfter = %v, want the full timeout", result.RequeueAfter)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	mf "github.com/jcrossley3/manifestival"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+// The manifests the install applies, identified by the checksums of
+// their resources as loaded
+func (r *ReconcileKnativeServing) appliedManifests(instance *servingv1alpha1.KnativeServing) ([]servingv1alpha1.AppliedManifest, error) {
+	release, err := r.manifestFor(instance)
+	if err != nil {
+		return nil, err
+	}
+	result := []servingv1alpha1.AppliedManifest{{
+		Name: operand + "/" + targetVersion(instance),
+		Hash: hashResources(release.Resources),
+	}}
+	if ingress := instance.Spec.Ingress.Name(); ingress != servingv1alpha1.IstioIngress {
+		result = append(result, servingv1alpha1.AppliedManifest{
+			Name: ingressDir + "/" + ingress,
+			Hash: hashResources(r.ingresses[ingress]),
+		})
+	}
+	for _, m := range instance.Spec.AdditionalManifests {
+		resources, err := r.loadAdditional(instance.GetNamespace(), m)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, servingv1alpha1.AppliedManifest{
+			Name: additionalName(m),
+			Hash: hashResources(resources),
+		})
+	}
+	return result, nil
+}
+
+// Record the checksum of the applied resources, and when they changed
+func recordManifestHash(status *servingv1alpha1.KnativeServingStatus, manifest *mf.Manifest) {
+	hash := hashResources(manifest.Resources)
+	if hash == status.ManifestHash && status.LastAppliedTime != nil {
+		return
+	}
+	now := metav1.Now()
+	status.ManifestHash, status.LastAppliedTime = hash, &now
+}
+
+// The resources of the manifest the policy had applied
+func appliedResources(manifest *mf.Manifest, policy *servingv1alpha1.ManifestPolicy) []servingv1alpha1.AppliedResource {
+	var result []servingv1alpha1.AppliedResource
+	for _, u := range manifest.Resources {
+		if policy.For(u.GetKind(), u.GetName()) == servingv1alpha1.NonePolicy {
+			continue
+		}
+		result = append(result, servingv1alpha1.AppliedResource{
+			APIVersion: u.GetAPIVersion(),
+			Kind:       u.GetKind(),
+			Namespace:  u.GetNamespace(),
+			Name:       u.GetName(),
+		})
+	}
+	return result
+}
+
+func hashResources(resources []unstructured.Unstructured) string {
+	h := sha256.New()
+	for i := range resources {
+		// Maps marshal with sorted keys, so the same resources always
+		// hash the same
+		b, err := json.Marshal(resources[i].Object)
+		if err != nil {
+			continue
+		}
+		h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
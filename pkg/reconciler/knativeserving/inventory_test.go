@@ -0,0 +1,66 @@
+package knativeserving
+
+import (
+	"reflect"
+	"testing"
+
+	mf "github.com/jcrossley3/manifestival"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/version"
+)
+
+func TestAppliedResources(t *testing.T) {
+	manifest := newTestManifest(t, testManifest, nil)
+	policy := &servingv1alpha1.ManifestPolicy{
+		Resources: []servingv1alpha1.ResourcePolicy{{Kind: "Deployment", Policy: servingv1alpha1.NonePolicy}},
+	}
+	want := []servingv1alpha1.AppliedResource{
+		{APIVersion: "v1", Kind: "Namespace", Name: operand},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: operand, Name: "config-network"},
+	}
+	if got := appliedResources(&manifest, policy); !reflect.DeepEqual(got, want) {
+		t.Errorf("appliedResources() = %v, want %v", got, want)
+	}
+}
+
+func TestAppliedManifests(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c, config: newTestManifest(t, testManifest, c)}
+	r.releases = map[string]mf.Manifest{version.Version: r.config}
+	instance := &servingv1alpha1.KnativeServing{}
+
+	manifests, err := r.appliedManifests(instance)
+	if err != nil {
+		t.Fatalf("appliedManifests() = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Name != operand+"/"+version.Version {
+		t.Fatalf("appliedManifests() = %v, want the release alone", manifests)
+	}
+	if manifests[0].Hash != hashResources(newTestManifest(t, testManifest, c).Resources) {
+		t.Error("the same resources should hash the same")
+	}
+	if manifests[0].Hash == hashResources(newTestManifest(t, upgradeManifest, c).Resources) {
+		t.Error("different resources should hash differently")
+	}
+}
+
+func TestRecordManifestHash(t *testing.T) {
+	manifest := newTestManifest(t, testManifest, nil)
+	status := &servingv1alpha1.KnativeServingStatus{}
+	recordManifestHash(status, &manifest)
+	if status.ManifestHash != hashResources(manifest.Resources) || status.LastAppliedTime == nil {
+		t.Fatalf("status = %+v, want the manifest's hash and when it was applied", status)
+	}
+
+	applied := status.LastAppliedTime.DeepCopy()
+	recordManifestHash(status, &manifest)
+	if !status.LastAppliedTime.Equal(applied) {
+		t.Error("reapplying the same resources should keep the time they were applied")
+	}
+
+	manifest.Resources[0].SetLabels(map[string]string{"changed": "true"})
+	recordManifestHash(status, &manifest)
+	if status.ManifestHash == hashResources(newTestManifest(t, testManifest, nil).Resources) {
+		t.Error("expected the changed resources to hash differently")
+	}
+}
@@ -17,22 +17,30 @@ package knativeserving
 
 import (
 	"context"
-	"flag"
+	"crypto/ecdsa"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/go-logr/logr"
 	mf "github.com/jcrossley3/manifestival"
+	"go.opencensus.io/trace"
 	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/pkg/health"
 	"knative.dev/serving-operator/pkg/reconciler/knativeserving/common"
+	"knative.dev/serving-operator/pkg/scope"
 	"knative.dev/serving-operator/version"
 
-	"github.com/operator-framework/operator-sdk/pkg/predicate"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -44,11 +52,11 @@ import (
 
 const (
 	operand = "knative-serving"
+	// The readiness check of the loaded manifests
+	healthCheck = "knativeserving-manifest"
 )
 
 var (
-	recursive = flag.Bool("recursive", false,
-		"If filename is a directory, process all manifests recursively")
 	log = logf.Log.WithName("controller_knativeserving")
 	// Platform-specific behavior to affect the installation
 	platforms common.Platforms
@@ -57,12 +65,36 @@ var (
 // Add creates a new KnativeServing Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	health.NotReady(healthCheck)
+	source, err := newManifestSource(mgr)
+	if err != nil {
+		return err
+	}
+	if err := setupTracing(); err != nil {
+		return err
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	return add(mgr, newReconciler(mgr, source, dc))
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileKnativeServing{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+func newReconciler(mgr manager.Manager, source ManifestSource, dc discovery.DiscoveryInterface) reconcile.Reconciler {
+	return &ReconcileKnativeServing{
+		client:           mgr.GetClient(),
+		scheme:           mgr.GetScheme(),
+		discovery:        dc,
+		recorder:         mgr.GetRecorder("knativeserving-controller"),
+		source:           source,
+		leaseName:        *reconcileLease,
+		retries:          newBackoff(),
+		resyncPeriod:     *resyncPeriod,
+		progressDeadline: *progressDeadline,
+		probe:            newProber(),
+		apply:            newApplier(dc.RESTClient(), mgr.GetRESTMapper()),
+	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -74,21 +106,32 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	}
 
 	// Watch for changes to primary resource KnativeServing
-	err = c.Watch(&source.Kind{Type: &servingv1alpha1.KnativeServing{}}, &handler.EnqueueRequestForObject{}, predicate.GenerationChangedPredicate{})
+	err = c.Watch(&source.Kind{Type: &servingv1alpha1.KnativeServing{}}, &handler.EnqueueRequestForObject{}, dryRunChangedPredicate{})
 	if err != nil {
 		return err
 	}
 
-	// Watch child deployments for availability
-	err = c.Watch(&source.Kind{Type: &appsv1.Deployment{}}, &handler.EnqueueRequestForOwner{
-		IsController: true,
-		OwnerType:    &servingv1alpha1.KnativeServing{},
-	})
+	// Once the active KnativeServing is deleted, the next one takes over
+	err = c.Watch(&source.Kind{Type: &servingv1alpha1.KnativeServing{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: enqueueKnativeServings(mgr.GetClient()),
+	}, deletedPredicate)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// Watch the installed resources for availability and to repair
+	// manual drift, e.g. a deleted ConfigMap
+	if err := watchNamespaced(c); err != nil {
+		return err
+	}
+
+	// Re-reconcile when the Secrets the spec refers to change, e.g.
+	// rotated registry credentials
+	if err := watchSecrets(mgr, c); err != nil {
+		return err
+	}
+
+	return watchClusterScoped(mgr, c)
 }
 
 var _ reconcile.Reconciler = &ReconcileKnativeServing{}
@@ -99,19 +142,83 @@ type ReconcileKnativeServing struct {
 	// that reads objects from the cache and writes to the apiserver
 	client client.Client
 	scheme *runtime.Scheme
-	config mf.Manifest
+	// Lets the platforms detect themselves
+	discovery discovery.DiscoveryInterface
+	recorder  record.EventRecorder
+	source    ManifestSource
+	config    mf.Manifest
+	// Bundled releases, by version
+	releases map[string]mf.Manifest
+	// Bundled manifests of the ingresses other than istio, by name
+	ingresses map[string][]unstructured.Unstructured
+	// Bundled Grafana dashboards, by name
+	dashboards map[string]string
+	// The additional manifests fetched from URLs, by URL and checksum
+	additional map[string][]unstructured.Unstructured
+	// The cosign public key each pinned image verified against, by
+	// image reference and digest
+	verified map[string]*ecdsa.PublicKey
+	// Name of the Lease annotated with reconcile outcomes, if any
+	leaseName string
+	// Backoff of failed requests
+	retries workqueue.RateLimiter
+	// How often an install is reconciled without any event, if at all
+	resyncPeriod time.Duration
+	// How long deployments may stay unavailable, if limited
+	progressDeadline time.Duration
+	// The Serving version the bundled manifest declares, if any
+	servingVersion string
+	// Why the manifests couldn't be loaded, if they couldn't
+	loadErr error
+	// Probes the services of the install, unless disabled
+	probe prober
+	// Applies the resources, unless manifestival does
+	apply applyFunc
 }
 
 // Create manifestival resources and KnativeServing, if necessary
 func (r *ReconcileKnativeServing) InjectClient(c client.Client) error {
+	r.loadErr = r.loadManifests(c)
+	health.SetReady(healthCheck, r.loadErr)
+	if r.loadErr != nil {
+		// Stay up to fail the readiness probe rather than crash-loop
+		return nil
+	}
+	return r.ensureKnativeServing()
+}
+
+func (r *ReconcileKnativeServing) loadManifests(c client.Client) error {
 	koDataDir := os.Getenv("KO_DATA_PATH")
-	m, err := mf.NewManifest(filepath.Join(koDataDir, "knative-serving/"), *recursive, c)
+	path, release, err := r.source.Fetch()
 	if err != nil {
+		log.Error(err, "Failed to fetch manifest")
+		return err
+	}
+	defer release()
+	if r.releases, err = loadReleases(path, c); err != nil {
 		log.Error(err, "Failed to load manifest")
 		return err
 	}
-	r.config = m
-	return r.ensureKnativeServing()
+	// The operator's own release, or else the latest bundled
+	var ok bool
+	if r.config, ok = r.releases[version.Version]; !ok {
+		versions := r.versions()
+		r.config = r.releases[versions[len(versions)-1]]
+	}
+	// A custom build may package another release
+	if r.servingVersion = declaredVersion(r.config.Resources); r.servingVersion != "" && r.servingVersion != version.Version {
+		log.Info("The bundled manifest doesn't match the operator's version",
+			"operator", version.Version, "serving", r.servingVersion)
+	}
+	if r.ingresses, err = loadIngresses(koDataDir); err != nil {
+		log.Error(err, "Failed to load ingress manifests")
+		return err
+	}
+	if r.dashboards, err = loadDashboards(koDataDir); err != nil {
+		log.Error(err, "Failed to load dashboards")
+		return err
+	}
+	return nil
 }
 
 // Reconcile reads that state of the cluster for a KnativeServing object and makes changes based on the state read
@@ -123,13 +230,34 @@ func (r *ReconcileKnativeServing) Reconcile(request reconcile.Request) (reconcil
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	reqLogger.Info("Reconciling KnativeServing")
 
+	start := time.Now()
+	result, err := r.reconcile(request, reqLogger)
+	recordReconcileMetrics(start, err)
+	if leaseErr := r.recordReconcile(err); leaseErr != nil {
+		reqLogger.Error(leaseErr, "Failed to record reconcile outcome", "lease", r.leaseName)
+	}
+	return r.backoff(request, result, err, reqLogger)
+}
+
+// Run the reconcile stages for a single request
+func (r *ReconcileKnativeServing) reconcile(request reconcile.Request, reqLogger logr.Logger) (reconcile.Result, error) {
+	ctx, span := trace.StartSpan(context.Background(), reconcileSpan)
+	defer span.End()
+	span.AddAttributes(trace.StringAttribute("namespace", request.Namespace), trace.StringAttribute("name", request.Name))
+	if r.loadErr != nil {
+		return reconcile.Result{}, permanent(fmt.Errorf("manifests not loaded: %v", r.loadErr))
+	}
+	if !scope.InShard(request.Namespace) {
+		// Another shard's
+		reqLogger.V(1).Info("KnativeServing of another shard", "shard", scope.ShardOf(request.Namespace))
+		return reconcile.Result{}, nil
+	}
+
 	// Fetch the KnativeServing instance
 	instance := &servingv1alpha1.KnativeServing{}
 	if err := r.client.Get(context.TODO(), request.NamespacedName, instance); err != nil {
 		if errors.IsNotFound(err) {
-			if isInteresting(request) {
-				r.config.DeleteAll()
-			}
+			// The finalizer uninstalled it
 			reqLogger.V(1).Info("No KnativeServing")
 			return reconcile.Result{}, nil
 		}
@@ -137,24 +265,108 @@ func (r *ReconcileKnativeServing) Reconcile(request reconcile.Request) (reconcil
 		return reconcile.Result{}, err
 	}
 
-	if !isInteresting(request) {
-		reqLogger.V(1).Info("Not interesting KnativeServing", "request", request.String)
-		return reconcile.Result{}, r.ignore(instance)
+	active, err := r.active()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if reason := ignoredReason(instance, active); reason != "" {
+		reqLogger.V(1).Info("Not interesting KnativeServing", "request", request.String, "reason", reason)
+		return reconcile.Result{}, r.ignore(instance, reason)
+	}
+
+	if instance.GetDeletionTimestamp() != nil {
+		return reconcile.Result{}, r.delete(instance)
 	}
 
 	stages := []func(*servingv1alpha1.KnativeServing) error{
+		r.ensureFinalizer,
 		r.initStatus,
+		r.mergeOverrides,
+		r.checkManifestVersion,
+		r.preflight,
+		r.ensureNamespace,
+		r.syncSecrets,
 		r.install,
+		r.deleteLegacyResources,
 		r.checkDeployments,
-		r.deleteObsoleteResources,
+		r.checkMigrations,
+		r.checkWebhookCerts,
+		r.probeServing,
+		r.checkMesh,
+		r.checkSidecarInjection,
+		r.publishEndpoint,
+		r.checkCertManager,
+		r.checkGateways,
+		r.checkTracingCollector,
+		r.completeUpgrade,
+	}
+
+	if crdsOnly(instance) {
+		// Ready once the CRDs are established
+		stages = []func(*servingv1alpha1.KnativeServing) error{
+			r.ensureFinalizer,
+			r.initStatus,
+			r.mergeOverrides,
+			r.checkManifestVersion,
+			r.preflight,
+			r.ensureNamespace,
+			r.install,
+			r.checkDeployments,
+			r.probeServing,
+			r.completeUpgrade,
+		}
+	}
+
+	if instance.ExportOnly() {
+		// Only render the manifest for others to apply
+		stages = []func(*servingv1alpha1.KnativeServing) error{
+			r.ensureFinalizer,
+			r.initStatus,
+			r.mergeOverrides,
+			r.checkManifestVersion,
+			r.exportOnly,
+		}
+	}
+
+	if isDryRun(instance) {
+		// Only preview the install
+		stages = []func(*servingv1alpha1.KnativeServing) error{
+			r.ensureFinalizer,
+			r.initStatus,
+			r.mergeOverrides,
+			r.checkManifestVersion,
+			r.dryRun,
+		}
 	}
 
 	for _, stage := range stages {
-		if err := stage(instance); err != nil {
-			return reconcile.Result{}, err
+		if err = runStage(ctx, stage, instance); err != nil {
+			break
 		}
 	}
-	return reconcile.Result{}, nil
+	// The deadline is enforced whether or not the stages succeeded
+	result, deadlineErr := r.checkInstallDeadline(instance)
+	if err == nil {
+		err = deadlineErr
+	}
+	if statusErr := r.recordPermanent(instance, err); statusErr != nil && err == nil {
+		err = statusErr
+	}
+	return r.resync(requeueRollout(instance, requeueProbe(instance, result))), err
+}
+
+// Raise FailedPermanently for a permanent error, until a reconcile
+// gets past it
+func (r *ReconcileKnativeServing) recordPermanent(instance *servingv1alpha1.KnativeServing, err error) error {
+	switch {
+	case isPermanent(err):
+		instance.Status.MarkFailedPermanently(err.Error())
+	case instance.Status.IsFailedPermanently():
+		instance.Status.ClearFailedPermanently()
+	default:
+		return nil
+	}
+	return r.updateStatus(instance)
 }
 
 // Initialize status conditions
@@ -177,6 +389,7 @@ func (r *ReconcileKnativeServing) updateStatus(instance *servingv1alpha1.Knative
 	gvk := instance.GroupVersionKind()
 	defer instance.SetGroupVersionKind(gvk)
 
+	instance.Status.UpdatePhase()
 	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
 		return err
 	}
@@ -191,123 +404,414 @@ func (r *ReconcileKnativeServing) install(instance *servingv1alpha1.KnativeServi
 	}
 	defer r.updateStatus(instance)
 
-	extensions, err := platforms.Extend(r.client, r.scheme)
+	extensions, err := platforms.Extend(r.client, r.discovery, r.scheme)
 	if err != nil {
 		return err
 	}
 
-	err = r.config.Transform(extensions.Transform(r.scheme, instance)...)
+	// Tell the story of a fresh install or upgrade, not every reapply
+	target := targetVersion(instance)
+	instance.Status.TargetVersion = target
+	upToDate := instance.Status.IsInstalled() && instance.Status.Version == target
+	if !upToDate {
+		r.recorder.Eventf(instance, v1.EventTypeNormal, "InstallStarted", "Installing Knative Serving %s", target)
+	}
+
+	if err := r.validateSpec(instance); err != nil {
+		return r.installFailed(instance, "InvalidSpec", err)
+	}
+	manifest, err := r.transform(instance, extensions)
+	if err != nil {
+		instance.Status.MarkTransformFailed(err.Error())
+		return r.installFailed(instance, "TransformFailed", err)
+	}
+	instance.Status.MarkTransformed()
+	if err := r.pinDigests(instance, &manifest); err != nil {
+		return r.installFailed(instance, "ImagePinningFailed", err)
+	}
+	if isUpgrade(instance) {
+		if err := r.preUpgrade(instance, &manifest); err != nil {
+			instance.Status.MarkVersionMigrationNotEligible(err.Error())
+			return r.installFailed(instance, "UpgradeBlocked", err)
+		}
+	}
+	if !instance.Status.IsVersionMigrating() {
+		// checkMigrations tracks the migration Jobs
+		instance.Status.MarkVersionMigrationEligible()
+	}
+	if err := r.adopt(instance, &manifest); err != nil {
+		instance.Status.MarkApplyFailed(err.Error())
+		return r.installFailed(instance, "AdoptionRequired", err)
+	}
+	r.reportDrift(instance, &manifest)
+	lastApplied := isLastApplied(instance, &manifest)
+	var drifted map[string]int
+	err = extensions.PreInstall(instance)
 	if err == nil {
-		err = extensions.PreInstall(instance)
+		var outOfSync map[string]int
+		outOfSync, err = applyChanged(&manifest, instance.Spec.ManifestPolicy, r.apply)
+		drifted = driftedResources(lastApplied, outOfSync)
+		recordDriftMetrics(drifted)
 		if err == nil {
-			err = r.config.ApplyAll()
-			if err == nil {
-				err = extensions.PostInstall(instance)
-			}
+			err = extensions.PostInstall(instance)
 		}
 	}
+	if err == nil {
+		err = r.prune(instance, &manifest)
+	}
+	if err == nil {
+		instance.Status.Manifests, err = r.appliedManifests(instance)
+		instance.Status.Resources = appliedResources(&manifest, instance.Spec.ManifestPolicy)
+		recordManifestHash(&instance.Status, &manifest)
+	}
+	instance.Status.FailedResources = nil
+	if failed, ok := cause(err).(applyError); ok {
+		instance.Status.FailedResources = failed
+	}
 	if err != nil {
-		instance.Status.MarkInstallFailed(err.Error())
-		return err
+		instance.Status.MarkApplyFailed(err.Error())
+		return r.installFailed(instance, "InstallFailed", err)
 	}
 
 	// Update status
-	instance.Status.Version = version.Version
-	log.Info("Install succeeded", "version", version.Version)
+	instance.Status.MarkApplied()
+	instance.Status.ObservedGeneration = instance.Generation
+	instance.Status.Version = target
+	recordInstallMetrics(len(manifest.Resources), target)
+	recordDriftRepairs(drifted)
+	log.Info("Install succeeded", "version", target)
 	instance.Status.MarkInstallSucceeded()
+	if !upToDate {
+		r.recorder.Eventf(instance, v1.EventTypeNormal, "InstallSucceeded", "Installed Knative Serving %s", target)
+	}
+	if err := r.exportManifest(instance, manifest.Resources); err != nil {
+		r.recorder.Eventf(instance, v1.EventTypeWarning, "ExportFailed", "Unable to export the manifest: %v", err)
+		return err
+	}
 	return nil
 }
 
+// Transform a copy so that every reconcile starts from the pristine
+// manifest, e.g. a key removed from spec.config reverts to upstream.
+// The images are those of the architecture the control plane runs on.
+// The additional manifests, NetworkPolicies, mesh and monitoring
+// resources, activator autoscaler and PodDisruptionBudgets are
+// transformed along with it.
+// The bundled ingress manifest manages its own namespaces, so it's
+// appended with only the common metadata, its config, including
+// Contour's, and the namespace Ambassador leaves to kubectl.
+func (r *ReconcileKnativeServing) transform(instance *servingv1alpha1.KnativeServing, extensions common.Extensions) (mf.Manifest, error) {
+	instance = withProfile(instance)
+	arch, archSelector, err := r.architecture(instance)
+	if err != nil {
+		return mf.Manifest{}, err
+	}
+	instance = withArchitecture(instance, arch)
+	core, ingress, err := r.selectIngress(instance)
+	if err != nil {
+		return mf.Manifest{}, err
+	}
+	if !migrating(instance) {
+		core = withoutMigrationJobs(core)
+	}
+	manifest := r.config
+	if crdsOnly(instance) {
+		// Nothing but the CRDs, of the ingress too
+		manifest.Resources, ingress = onlyCRDs(core), onlyCRDs(ingress)
+	} else {
+		additional, err := r.additionalResources(instance)
+		if err != nil {
+			return mf.Manifest{}, err
+		}
+		manifest.Resources = append(core[:len(core):len(core)], additional...)
+		if networkPoliciesEnabled(instance) {
+			manifest.Resources = append(manifest.Resources, networkPolicies()...)
+		}
+		if meshCompatibilityEnabled(instance) {
+			manifest.Resources = append(manifest.Resources, meshResources(instance)...)
+		}
+		if monitoringEnabled(instance) {
+			manifest.Resources = append(manifest.Resources, monitoringResources(instance, core, r.dashboards)...)
+		}
+		if activatorAutoscalingEnabled(instance) {
+			if manifest.Resources, err = withActivatorAutoscaler(instance, manifest.Resources); err != nil {
+				return mf.Manifest{}, err
+			}
+		}
+		if len(instance.Spec.PodDisruptionBudgets) > 0 {
+			budgets, err := podDisruptionBudgets(instance, core)
+			if err != nil {
+				return mf.Manifest{}, permanent(err)
+			}
+			manifest.Resources = append(manifest.Resources, budgets...)
+		}
+	}
+	if err := manifest.Transform(extensions.Transform(r.scheme, instance, manifest.Resources)...); err != nil {
+		// The transformers only fail on a spec they can't apply
+		return manifest, permanent(err)
+	}
+	if manifest.Resources, err = extensions.TransformManifest(instance, manifest.Resources); err != nil {
+		return manifest, err
+	}
+	// Whatever the plugins added is owned and installed like the rest
+	if err := manifest.Transform(common.InjectOwner(instance), mf.InjectNamespace(instance.InstallNamespace())); err != nil {
+		return manifest, permanent(err)
+	}
+	if manifest.Resources, err = r.withoutForeignNamespace(instance, manifest.Resources); err != nil {
+		return manifest, err
+	}
+	if archSelector != nil {
+		pinArchitecture(instance, manifest.Resources, archSelector)
+	}
+	stamps := []mf.Transformer{common.CommonMetadataTransform(instance, log)}
+	switch instance.Spec.Ingress.Name() {
+	case servingv1alpha1.AmbassadorIngress:
+		stamps = append(stamps, common.AmbassadorTransform(log))
+	case servingv1alpha1.ContourIngress:
+		stamps = append(stamps, common.ContourTransform(instance, log))
+	}
+	stamps = append(stamps, common.ConfigMapTransform(instance, log))
+	for i := range ingress {
+		u := ingress[i].DeepCopy()
+		for _, stamp := range stamps {
+			if err := stamp(u); err != nil {
+				return manifest, permanent(err)
+			}
+		}
+		manifest.Resources = append(manifest.Resources, *u)
+	}
+	if err := withConfigHashes(instance, manifest.Resources); err != nil {
+		return manifest, permanent(err)
+	}
+	labelRelease(manifest.Resources, targetVersion(instance))
+	labelOwner(manifest.Resources, instance)
+	return manifest, nil
+}
+
+// A copy of the instance with the presets of its profile filled in,
+// leaving the stored spec as the user wrote it
+func withProfile(instance *servingv1alpha1.KnativeServing) *servingv1alpha1.KnativeServing {
+	if instance.Spec.Profile == "" {
+		return instance
+	}
+	profiled := instance.DeepCopy()
+	profiled.Spec.ApplyProfile()
+	return profiled
+}
+
+// Record a failed install in the status and as an event
+func (r *ReconcileKnativeServing) installFailed(instance *servingv1alpha1.KnativeServing, reason string, err error) error {
+	r.recorder.Eventf(instance, v1.EventTypeWarning, reason, "Install failed: %v", err)
+	instance.Status.MarkInstallFailed(err.Error())
+	return err
+}
+
 // Check for all deployments available
 func (r *ReconcileKnativeServing) checkDeployments(instance *servingv1alpha1.KnativeServing) error {
 	log.V(1).Info("checkDeployments", "status", instance.Status)
 	defer r.updateStatus(instance)
-	available := func(d *appsv1.Deployment) bool {
-		for _, c := range d.Status.Conditions {
-			if c.Type == appsv1.DeploymentAvailable && c.Status == v1.ConditionTrue {
-				return true
+	core, ingress, err := r.selectIngress(instance)
+	if err != nil {
+		return err
+	}
+	// The manager's client reads the typed Deployments and DaemonSets
+	// from its shared informers, not the apiserver
+	var notReady []servingv1alpha1.DeploymentStatus
+	for _, key := range deploymentKeys(instance, core, ingress) {
+		deployment := &appsv1.Deployment{}
+		if err := r.client.Get(context.TODO(), key, deployment); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
 			}
+			notReady = append(notReady, servingv1alpha1.DeploymentStatus{
+				Name:    key.Name,
+				Reason:  "NotFound",
+				Message: "The deployment does not exist",
+			})
+			continue
+		}
+		if status, available := deploymentStatus(deployment); !available {
+			notReady = append(notReady, status)
+		}
+	}
+	// Only the Services of available deployments, which would otherwise
+	// be reported twice
+	waiting := map[string]bool{}
+	for _, d := range notReady {
+		waiting[d.Name] = true
+	}
+	for _, key := range endpointKeys(instance, core) {
+		if waiting[key.Name] {
+			continue
 		}
-		return false
-	}
-	deployment := &appsv1.Deployment{}
-	for _, u := range r.config.Resources {
-		if u.GetKind() == "Deployment" {
-			key := client.ObjectKey{Namespace: u.GetNamespace(), Name: u.GetName()}
-			if err := r.client.Get(context.TODO(), key, deployment); err != nil {
-				instance.Status.MarkDeploymentsNotReady()
-				if errors.IsNotFound(err) {
-					return nil
-				}
+		status, ready, err := r.endpointsStatus(key)
+		if err != nil {
+			return err
+		}
+		if !ready {
+			notReady = append(notReady, status)
+		}
+	}
+	var notReadyDaemonSets []servingv1alpha1.DaemonSetStatus
+	for _, key := range daemonSetKeys(instance, ingress) {
+		ds := &appsv1.DaemonSet{}
+		if err := r.client.Get(context.TODO(), key, ds); err != nil {
+			if !errors.IsNotFound(err) {
 				return err
 			}
-			if !available(deployment) {
-				instance.Status.MarkDeploymentsNotReady()
-				return nil
+			notReadyDaemonSets = append(notReadyDaemonSets, servingv1alpha1.DaemonSetStatus{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+				Reason:    "NotFound",
+				Message:   "The DaemonSet does not exist",
+			})
+			continue
+		}
+		if status, available := daemonSetStatus(ds); !available {
+			notReadyDaemonSets = append(notReadyDaemonSets, status)
+		}
+	}
+	instance.Status.Deployments = notReady
+	instance.Status.DaemonSets = notReadyDaemonSets
+	if len(notReady) > 0 || len(notReadyDaemonSets) > 0 {
+		var names []string
+		for _, d := range notReady {
+			names = append(names, d.Name)
+		}
+		for _, ds := range notReadyDaemonSets {
+			names = append(names, ds.Namespace+"/"+ds.Name)
+		}
+		// Only report the transition
+		if condition := instance.Status.GetCondition(servingv1alpha1.DeploymentsAvailable); !condition.IsFalse() {
+			eventType := v1.EventTypeNormal
+			if condition.IsTrue() {
+				eventType = v1.EventTypeWarning
 			}
+			r.recorder.Eventf(instance, eventType, "DeploymentsNotReady", "Waiting on deployments %s", strings.Join(names, ", "))
 		}
+		r.markDeploymentsNotReady(instance, names)
+		return nil
 	}
 	log.Info("All deployments are available")
 	instance.Status.MarkDeploymentsAvailable()
 	return nil
 }
 
-// Delete obsolete resources from previous versions
-func (r *ReconcileKnativeServing) deleteObsoleteResources(instance *servingv1alpha1.KnativeServing) error {
-	// istio-system resources from 0.3
-	resource := &unstructured.Unstructured{}
-	resource.SetNamespace("istio-system")
-	resource.SetName("knative-ingressgateway")
-	resource.SetAPIVersion("v1")
-	resource.SetKind("Service")
-	if err := r.config.Delete(resource); err != nil {
-		return err
+// Report whether the deployment is available and, if not, the
+// condition explaining why: a replica failure, e.g. exceeded quota,
+// is more telling than a stalled rollout, which is more telling than
+// the missing availability itself
+func deploymentStatus(d *appsv1.Deployment) (servingv1alpha1.DeploymentStatus, bool) {
+	status := servingv1alpha1.DeploymentStatus{
+		Name:    d.Name,
+		Reason:  "Pending",
+		Message: "The deployment has not reported its status",
 	}
-	resource.SetAPIVersion("apps/v1")
-	resource.SetKind("Deployment")
-	if err := r.config.Delete(resource); err != nil {
-		return err
+	rank := 0
+	for _, c := range d.Status.Conditions {
+		var r int
+		switch {
+		case c.Type == appsv1.DeploymentAvailable && c.Status == v1.ConditionTrue:
+			return servingv1alpha1.DeploymentStatus{Name: d.Name}, true
+		case c.Type == appsv1.DeploymentReplicaFailure && c.Status == v1.ConditionTrue:
+			r = 3
+		case c.Type == appsv1.DeploymentProgressing && c.Status == v1.ConditionFalse:
+			r = 2
+		case c.Type == appsv1.DeploymentAvailable:
+			r = 1
+		}
+		if r > rank {
+			rank = r
+			status.Reason = c.Reason
+			status.Message = c.Message
+		}
 	}
-	resource.SetAPIVersion("autoscaling/v1")
-	resource.SetKind("HorizontalPodAutoscaler")
-	if err := r.config.Delete(resource); err != nil {
-		return err
+	return status, false
+}
+
+// Report whether every scheduled pod of the DaemonSet runs its current
+// template and is available and, if not, why
+func daemonSetStatus(ds *appsv1.DaemonSet) (servingv1alpha1.DaemonSetStatus, bool) {
+	status := servingv1alpha1.DaemonSetStatus{Namespace: ds.Namespace, Name: ds.Name}
+	switch desired := ds.Status.DesiredNumberScheduled; {
+	case ds.Status.ObservedGeneration < ds.Generation:
+		status.Reason = "Pending"
+		status.Message = "The DaemonSet has not reported its status"
+	case ds.Status.UpdatedNumberScheduled < desired:
+		status.Reason = "RollingUpdate"
+		status.Message = fmt.Sprintf("%d of %d pods are updated", ds.Status.UpdatedNumberScheduled, desired)
+	case ds.Status.NumberAvailable < desired:
+		status.Reason = "Unavailable"
+		status.Message = fmt.Sprintf("%d of %d pods are available", ds.Status.NumberAvailable, desired)
+	default:
+		return status, true
 	}
-	// config-controller from 0.5
-	resource.SetNamespace(instance.GetNamespace())
-	resource.SetName("config-controller")
-	resource.SetAPIVersion("v1")
-	resource.SetKind("ConfigMap")
-	if err := r.config.Delete(resource); err != nil {
-		return err
+	return status, false
+}
+
+// Because it's effectively cluster-scoped, only a single KnativeServing
+// installs Knative Serving: the oldest in the watched namespaces, or
+// the one the operator is pinned to
+func (r *ReconcileKnativeServing) active() (*servingv1alpha1.KnativeServing, error) {
+	list := &servingv1alpha1.KnativeServingList{}
+	if err := r.client.List(context.TODO(), &client.ListOptions{}, list); err != nil {
+		return nil, err
 	}
-	return nil
+	var result *servingv1alpha1.KnativeServing
+	for i := range list.Items {
+		ks := &list.Items[i]
+		if scope.Selects(ks.GetNamespace(), ks.GetName()) && (result == nil || isOlder(ks, result)) {
+			result = ks
+		}
+	}
+	return result, nil
 }
 
-// Because it's effectively cluster-scoped, we only care about a
-// single, named resource: knative-serving/knative-serving
-func isInteresting(request reconcile.Request) bool {
-	return request.Namespace == operand && request.Name == operand
+// Creation order, then namespace and name for determinism
+func isOlder(a, b *servingv1alpha1.KnativeServing) bool {
+	ta, tb := a.GetCreationTimestamp(), b.GetCreationTimestamp()
+	if !ta.Equal(&tb) {
+		return ta.Before(&tb)
+	}
+	return a.GetNamespace()+"/"+a.GetName() < b.GetNamespace()+"/"+b.GetName()
+}
+
+// Why the KnativeServing isn't the active one, if it isn't
+func ignoredReason(instance, active *servingv1alpha1.KnativeServing) string {
+	if !scope.Watches(instance.GetNamespace()) {
+		return fmt.Sprintf("The operator doesn't watch namespace %s, only %s",
+			instance.GetNamespace(), strings.Join(scope.Namespaces(), ", "))
+	}
+	if namespace, name, ok := scope.Pinned(); ok && !scope.Selects(instance.GetNamespace(), instance.GetName()) {
+		return fmt.Sprintf("The operator only reconciles KnativeServing %s/%s", namespace, name)
+	}
+	if active != nil && (active.GetNamespace() != instance.GetNamespace() || active.GetName() != instance.GetName()) {
+		return fmt.Sprintf("KnativeServing %s/%s already installs Knative Serving, only one is allowed",
+			active.GetNamespace(), active.GetName())
+	}
+	return ""
 }
 
 // Reflect our ignorance in the KnativeServing status
-func (r *ReconcileKnativeServing) ignore(instance *servingv1alpha1.KnativeServing) (err error) {
+func (r *ReconcileKnativeServing) ignore(instance *servingv1alpha1.KnativeServing, reason string) (err error) {
 	err = r.initStatus(instance)
 	if err == nil {
-		msg := fmt.Sprintf("The only KnativeServing resource that matters is %s/%s", operand, operand)
-		instance.Status.MarkIgnored(msg)
+		instance.Status.MarkIgnored(reason)
 		err = r.updateStatus(instance)
 	}
 	return
 }
 
-// If we can't find knative-serving/knative-serving, create it
+// If no KnativeServing is watched, create knative-serving/knative-serving,
+// unless the operator is pinned to another or it's another shard's
 func (r *ReconcileKnativeServing) ensureKnativeServing() (err error) {
 	koDataDir := os.Getenv("KO_DATA_PATH")
 	const path = "serving_v1alpha1_knativeserving_cr.yaml"
-	instance := &servingv1alpha1.KnativeServing{}
-	key := client.ObjectKey{Namespace: operand, Name: operand}
-	if err = r.client.Get(context.TODO(), key, instance); err != nil {
+	if !scope.Selects(operand, operand) || !scope.InShard(operand) {
+		return nil
+	}
+	active, err := r.active()
+	if err == nil && active == nil {
 		var manifest mf.Manifest
 		manifest, err = mf.NewManifest(filepath.Join(koDataDir, path), false, r.client)
 		if err == nil {
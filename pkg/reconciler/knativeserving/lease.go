@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	coordinationv1beta1 "k8s.io/api/coordination/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Annotations on the reconcile Lease describing the last reconcile
+	lastReconcileResultAnnotation = "operator.knative.dev/last-reconcile-result"
+	lastReconcileErrorAnnotation  = "operator.knative.dev/last-reconcile-error"
+	lastReconcileTimeAnnotation   = "operator.knative.dev/last-reconcile-time"
+
+	reconcileSucceeded = "Succeeded"
+	reconcileFailed    = "Failed"
+)
+
+var (
+	reconcileLease = flag.String("reconcile-lease", "",
+		"Name of a Lease in the knative-serving namespace to annotate with the outcome of every reconcile; disabled if empty")
+)
+
+// Record the outcome of a reconcile on the Lease so external watchdogs
+// can monitor the operator's health without scraping metrics
+func (r *ReconcileKnativeServing) recordReconcile(reconcileErr error) error {
+	if r.leaseName == "" {
+		return nil
+	}
+	lease := &coordinationv1beta1.Lease{}
+	key := client.ObjectKey{Namespace: operand, Name: r.leaseName}
+	create := false
+	if err := r.client.Get(context.TODO(), key, lease); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		create = true
+		lease.Namespace = key.Namespace
+		lease.Name = key.Name
+	}
+
+	now := metav1.NowMicro()
+	lease.Spec.RenewTime = &now
+	annotations := lease.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[lastReconcileTimeAnnotation] = now.UTC().Format(time.RFC3339)
+	if reconcileErr == nil {
+		annotations[lastReconcileResultAnnotation] = reconcileSucceeded
+		delete(annotations, lastReconcileErrorAnnotation)
+	} else {
+		annotations[lastReconcileResultAnnotation] = reconcileFailed
+		annotations[lastReconcileErrorAnnotation] = reconcileErr.Error()
+	}
+	lease.SetAnnotations(annotations)
+
+	if create {
+		return r.client.Create(context.TODO(), lease)
+	}
+	return r.client.Update(context.TODO(), lease)
+}
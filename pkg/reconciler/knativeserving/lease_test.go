@@ -0,0 +1,105 @@
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	coordinationv1beta1 "k8s.io/api/coordination/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const testLease = "knative-serving-operator-health"
+
+func getLease(t *testing.T, c client.Client) *coordinationv1beta1.Lease {
+	lease := &coordinationv1beta1.Lease{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: testLease}, lease); err != nil {
+		t.Fatalf("Lease not found: %v", err)
+	}
+	return lease
+}
+
+func TestRecordReconcileCreatesLease(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c, leaseName: testLease}
+
+	if err := r.recordReconcile(nil); err != nil {
+		t.Fatalf("recordReconcile() = %v", err)
+	}
+	lease := getLease(t, c)
+	if got := lease.Annotations[lastReconcileResultAnnotation]; got != reconcileSucceeded {
+		t.Errorf("result annotation = %q, want %q", got, reconcileSucceeded)
+	}
+	if lease.Annotations[lastReconcileTimeAnnotation] == "" {
+		t.Error("time annotation not set")
+	}
+	if lease.Spec.RenewTime == nil {
+		t.Error("renewTime not set")
+	}
+}
+
+func TestRecordReconcileUpdatesLease(t *testing.T) {
+	existing := &coordinationv1beta1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: operand,
+			Name:      testLease,
+			Annotations: map[string]string{
+				lastReconcileResultAnnotation: reconcileSucceeded,
+				"unrelated":                   "kept",
+			},
+		},
+	}
+	c := newFakeClient(newTestScheme(), existing)
+	r := &ReconcileKnativeServing{client: c, leaseName: testLease}
+
+	if err := r.recordReconcile(fmt.Errorf("boom")); err != nil {
+		t.Fatalf("recordReconcile() = %v", err)
+	}
+	lease := getLease(t, c)
+	if got := lease.Annotations[lastReconcileResultAnnotation]; got != reconcileFailed {
+		t.Errorf("result annotation = %q, want %q", got, reconcileFailed)
+	}
+	if got := lease.Annotations[lastReconcileErrorAnnotation]; got != "boom" {
+		t.Errorf("error annotation = %q, want %q", got, "boom")
+	}
+	if got := lease.Annotations["unrelated"]; got != "kept" {
+		t.Errorf("unrelated annotation = %q, want %q", got, "kept")
+	}
+
+	if err := r.recordReconcile(nil); err != nil {
+		t.Fatalf("recordReconcile() = %v", err)
+	}
+	lease = getLease(t, c)
+	if _, ok := lease.Annotations[lastReconcileErrorAnnotation]; ok {
+		t.Error("error annotation should be cleared after a successful reconcile")
+	}
+}
+
+func TestRecordReconcileDisabled(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c}
+
+	if err := r.recordReconcile(nil); err != nil {
+		t.Fatalf("recordReconcile() = %v", err)
+	}
+	if len(c.objects) != 0 {
+		t.Errorf("expected no objects, got %v", c.objects)
+	}
+}
+
+func TestReconcileRecordsLease(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c, leaseName: testLease}
+
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "missing"}}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	lease := getLease(t, c)
+	if got := lease.Annotations[lastReconcileResultAnnotation]; got != reconcileSucceeded {
+		t.Errorf("result annotation = %q, want %q", got, reconcileSucceeded)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+// legacyResource is a resource an earlier release of Knative Serving
+// installed that later ones dropped. Installs predating the operator's
+// labels never recorded it, so only this list tells prune about it.
+type legacyResource struct {
+	// The first release without the resource
+	obsoleteSince string
+	apiVersion    string
+	kind          string
+	// Empty for the install namespace
+	namespace string
+	name      string
+}
+
+var legacyResources = []legacyResource{
+	// The ingress gateway of 0.3, replaced by istio-ingressgateway
+	{"0.4.0", "v1", "Service", "istio-system", "knative-ingressgateway"},
+	{"0.4.0", "apps/v1", "Deployment", "istio-system", "knative-ingressgateway"},
+	{"0.4.0", "autoscaling/v1", "HorizontalPodAutoscaler", "istio-system", "knative-ingressgateway"},
+	// Merged into config-deployment
+	{"0.6.0", "v1", "ConfigMap", "", "config-controller"},
+}
+
+// Whether the install deletes legacy resources, by default
+func cleanupLegacyResources(instance *servingv1alpha1.KnativeServing) bool {
+	cleanup := instance.Spec.CleanupLegacyResources
+	return cleanup == nil || *cleanup
+}
+
+// The legacy resources obsolete as of the version, in its install namespace
+func obsoleteResources(version, namespace string) []unstructured.Unstructured {
+	var result []unstructured.Unstructured
+	for _, legacy := range legacyResources {
+		if compareVersions(version, legacy.obsoleteSince) < 0 {
+			continue
+		}
+		u := unstructured.Unstructured{}
+		u.SetAPIVersion(legacy.apiVersion)
+		u.SetKind(legacy.kind)
+		u.SetNamespace(legacy.namespace)
+		if legacy.namespace == "" {
+			u.SetNamespace(namespace)
+		}
+		u.SetName(legacy.name)
+		result = append(result, u)
+	}
+	return result
+}
+
+// Delete the resources of earlier releases the installed one dropped,
+// unless the install applies them again, e.g. from an additional manifest
+func (r *ReconcileKnativeServing) deleteLegacyResources(instance *servingv1alpha1.KnativeServing) error {
+	if !cleanupLegacyResources(instance) || !instance.Status.IsInstalled() {
+		return nil
+	}
+	current := map[string]bool{}
+	for _, u := range r.allResources(instance.InstallNamespace()) {
+		current[resourceKey(&u)] = true
+	}
+	for _, applied := range instance.Status.Resources {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(applied.APIVersion)
+		u.SetKind(applied.Kind)
+		u.SetNamespace(applied.Namespace)
+		u.SetName(applied.Name)
+		current[resourceKey(u)] = true
+	}
+	manifest := r.config
+	obsolete := obsoleteResources(instance.Status.Version, instance.InstallNamespace())
+	for i := range obsolete {
+		u := &obsolete[i]
+		if current[resourceKey(u)] {
+			continue
+		}
+		existing, err := manifest.Get(u)
+		if err != nil {
+			if meta.IsNoMatchError(err) || errors.IsForbidden(err) {
+				// The API was never installed, or the namespace is out of reach
+				continue
+			}
+			return err
+		}
+		if existing == nil {
+			continue
+		}
+		if err := r.pruneResource(instance, &manifest, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package knativeserving
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newLegacyService() *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("Service")
+	u.SetNamespace("istio-system")
+	u.SetName("knative-ingressgateway")
+	return u
+}
+
+func TestObsoleteResources(t *testing.T) {
+	for _, tt := range []struct {
+		version string
+		want    int
+	}{
+		{"0.3.0", 0},
+		{"0.5.0", 3},
+		{"0.7.0", 4},
+	} {
+		got := obsoleteResources(tt.version, operand)
+		if len(got) != tt.want {
+			t.Errorf("obsoleteResources(%s) = %d resources, want %d", tt.version, len(got), tt.want)
+		}
+		for _, u := range got {
+			if u.GetNamespace() == "" {
+				t.Errorf("obsoleteResources(%s) = %s without a namespace", tt.version, u.GetName())
+			}
+		}
+	}
+}
+
+func TestDeleteLegacyResources(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		name    string
+		cleanup *bool
+		version string
+		deleted bool
+	}{{
+		name:    "obsolete",
+		version: "0.7.0",
+		deleted: true,
+	}, {
+		name:    "disabled",
+		cleanup: &disabled,
+		version: "0.7.0",
+	}, {
+		name:    "not yet obsolete",
+		version: "0.3.0",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFakeClient(newTestScheme(),
+				newLegacyService(),
+				newReleaseConfigMap("config-controller", ""),
+			)
+			recorder := record.NewFakeRecorder(10)
+			r := &ReconcileKnativeServing{client: c, recorder: recorder, config: newTestManifest(t, testManifest, c)}
+			instance := &servingv1alpha1.KnativeServing{
+				Spec: servingv1alpha1.KnativeServingSpec{CleanupLegacyResources: tt.cleanup},
+			}
+			instance.SetNamespace(operand)
+			instance.Status.InitializeConditions()
+			instance.Status.MarkInstallSucceeded()
+			instance.Status.Version = tt.version
+
+			if err := r.deleteLegacyResources(instance); err != nil {
+				t.Fatalf("deleteLegacyResources() = %v", err)
+			}
+			err := c.Get(context.TODO(), client.ObjectKey{Namespace: "istio-system", Name: "knative-ingressgateway"}, newLegacyService())
+			if deleted := err != nil; deleted != tt.deleted {
+				t.Errorf("knative-ingressgateway deleted = %v, want %v", deleted, tt.deleted)
+			}
+			if tt.deleted {
+				expectEvent(t, recorder, "Normal ObsoleteResourceDeleted Deleted obsolete Service istio-system/knative-ingressgateway")
+				expectEvent(t, recorder, "Normal ObsoleteResourceDeleted Deleted obsolete ConfigMap knative-serving/config-controller")
+			}
+			expectNoEvent(t, recorder)
+		})
+	}
+}
+
+func TestDeleteLegacyResourcesKeepsApplied(t *testing.T) {
+	c := newFakeClient(newTestScheme(), newReleaseConfigMap("config-controller", ""))
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, recorder: recorder, config: newTestManifest(t, testManifest, c)}
+	instance := &servingv1alpha1.KnativeServing{}
+	instance.SetNamespace(operand)
+	instance.Status.InitializeConditions()
+	instance.Status.MarkInstallSucceeded()
+	instance.Status.Version = "0.7.0"
+	instance.Status.Resources = []servingv1alpha1.AppliedResource{{
+		APIVersion: "v1", Kind: "ConfigMap", Namespace: operand, Name: "config-controller",
+	}}
+
+	if err := r.deleteLegacyResources(instance); err != nil {
+		t.Fatalf("deleteLegacyResources() = %v", err)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "config-controller"}, newReleaseConfigMap("config-controller", "")); err != nil {
+		t.Errorf("config-controller should be kept: %v", err)
+	}
+	expectNoEvent(t, recorder)
+}
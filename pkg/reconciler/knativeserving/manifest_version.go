@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/version"
+)
+
+// The Serving version the resources declare with their release label,
+// the most common one should they disagree, if any
+func declaredVersion(resources []unstructured.Unstructured) string {
+	counts := map[string]int{}
+	result := ""
+	for _, u := range resources {
+		v := strings.TrimPrefix(u.GetLabels()[servingReleaseLabel], "v")
+		if v == "" {
+			continue
+		}
+		counts[v]++
+		if counts[v] > counts[result] || (counts[v] == counts[result] && v < result) {
+			result = v
+		}
+	}
+	return result
+}
+
+// Report the versions of the operator and of the Serving its bundled
+// manifest declares, and whether they match, warning when they stop
+// matching. The status is only written when that changes it.
+func (r *ReconcileKnativeServing) checkManifestVersion(instance *servingv1alpha1.KnativeServing) error {
+	status := &instance.Status
+	before := status.DeepCopy()
+	status.OperatorVersion, status.ServingVersion = version.Version, r.servingVersion
+	switch {
+	case r.servingVersion == "":
+		status.ClearManifestVersionMatches()
+	case r.servingVersion != version.Version:
+		// Only report the transition
+		if !status.GetCondition(servingv1alpha1.ManifestVersionMatches).IsFalse() {
+			r.recorder.Eventf(instance, v1.EventTypeWarning, "ManifestVersionMismatch",
+				"The operator %s bundles the manifest of Serving %s", version.Version, r.servingVersion)
+		}
+		status.MarkManifestVersionMismatch(version.Version, r.servingVersion)
+	default:
+		status.MarkManifestVersionMatches()
+	}
+	if equality.Semantic.DeepEqual(before, status) {
+		return nil
+	}
+	return r.updateStatus(instance)
+}
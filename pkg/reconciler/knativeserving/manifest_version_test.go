@@ -0,0 +1,90 @@
+package knativeserving
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/version"
+)
+
+func TestDeclaredVersion(t *testing.T) {
+	labeled := func(release string) unstructured.Unstructured {
+		u := unstructured.Unstructured{}
+		if release != "" {
+			u.SetLabels(map[string]string{servingReleaseLabel: release})
+		}
+		return u
+	}
+	tests := []struct {
+		name      string
+		resources []unstructured.Unstructured
+		want      string
+	}{
+		{"unlabeled", []unstructured.Unstructured{labeled("")}, ""},
+		{"labeled", []unstructured.Unstructured{labeled(""), labeled("v0.7.0")}, "0.7.0"},
+		{"most common", []unstructured.Unstructured{labeled("v0.7.0"), labeled("v0.8.0"), labeled("v0.8.0")}, "0.8.0"},
+	}
+	for _, tt := range tests {
+		if got := declaredVersion(tt.resources); got != tt.want {
+			t.Errorf("%s: declaredVersion() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckManifestVersion(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand}}
+	instance.Status.InitializeConditions()
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: newFakeClient(newTestScheme(), instance.DeepCopy()), recorder: recorder,
+		servingVersion: version.Version}
+
+	if err := r.checkManifestVersion(instance); err != nil {
+		t.Fatalf("checkManifestVersion() = %v", err)
+	}
+	if !instance.Status.GetCondition(servingv1alpha1.ManifestVersionMatches).IsTrue() {
+		t.Error("expected the versions to match")
+	}
+	if instance.Status.OperatorVersion != version.Version || instance.Status.ServingVersion != version.Version {
+		t.Errorf("versions = %s, %s, want %s", instance.Status.OperatorVersion, instance.Status.ServingVersion, version.Version)
+	}
+	expectNoEvent(t, recorder)
+
+	// Nothing changed, so nothing is written to the instance, now gone
+	if err := r.client.Delete(context.TODO(), instance.DeepCopy()); err != nil {
+		t.Fatalf("Delete() = %v", err)
+	}
+	if err := r.checkManifestVersion(instance); err != nil {
+		t.Fatalf("checkManifestVersion() = %v, want the status left alone", err)
+	}
+	if err := r.client.Create(context.TODO(), instance.DeepCopy()); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+
+	r.servingVersion = "0.6.0"
+	for i := 0; i < 2; i++ {
+		if err := r.checkManifestVersion(instance); err != nil {
+			t.Fatalf("checkManifestVersion() = %v", err)
+		}
+	}
+	if !instance.Status.GetCondition(servingv1alpha1.ManifestVersionMatches).IsFalse() || instance.Status.ServingVersion != "0.6.0" {
+		t.Errorf("status = %+v, want the mismatch", instance.Status)
+	}
+	if severity := instance.Status.GetCondition(servingv1alpha1.ManifestVersionMatches).Severity; severity != "Warning" {
+		t.Errorf("severity = %q, want the mismatch to only warn", severity)
+	}
+	// Only once
+	expectEvent(t, recorder, "Warning ManifestVersionMismatch")
+	expectNoEvent(t, recorder)
+
+	r.servingVersion = ""
+	if err := r.checkManifestVersion(instance); err != nil {
+		t.Fatalf("checkManifestVersion() = %v", err)
+	}
+	if instance.Status.GetCondition(servingv1alpha1.ManifestVersionMatches) != nil {
+		t.Error("expected no condition without a declared version")
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/pkg/reconciler/knativeserving/common"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	peerAuthenticationAPIVersion = "security.istio.io/v1beta1"
+	destinationRuleAPIVersion    = "networking.istio.io/v1alpha3"
+	meshResourceName             = "knative-serving"
+	webhookService               = "webhook"
+)
+
+// The PeerAuthentication and DestinationRule of spec.istio. With
+// sidecars, the namespace requires mutual TLS and calls into it
+// originate it, except on the webhook port the API server calls.
+// Without them, the namespace accepts plain text, whatever the mesh
+// defaults to, and calls into it don't attempt mutual TLS.
+func meshResources(instance *servingv1alpha1.KnativeServing) []unstructured.Unstructured {
+	inject := instance.Spec.Istio.SidecarInjection
+	mode, tls := "PERMISSIVE", "DISABLE"
+	if inject {
+		mode, tls = "STRICT", "ISTIO_MUTUAL"
+	}
+	mtls := map[string]interface{}{"mode": mode}
+	peerAuthentication := map[string]interface{}{"mtls": mtls}
+	if inject {
+		peerAuthentication["portLevelMtls"] = map[string]interface{}{
+			"8443": map[string]interface{}{"mode": "PERMISSIVE"},
+		}
+	}
+	namespace := instance.InstallNamespace()
+	host := "*." + namespace + ".svc.cluster.local"
+	return []unstructured.Unstructured{
+		meshResource(namespace, peerAuthenticationAPIVersion, "PeerAuthentication", peerAuthentication),
+		meshResource(namespace, destinationRuleAPIVersion, "DestinationRule", map[string]interface{}{
+			"host": host,
+			"trafficPolicy": map[string]interface{}{
+				"tls": map[string]interface{}{"mode": tls},
+			},
+		}),
+	}
+}
+
+func meshResource(namespace, apiVersion, kind string, spec map[string]interface{}) unstructured.Unstructured {
+	u := unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(meshResourceName)
+	return u
+}
+
+func meshCompatibilityEnabled(instance *servingv1alpha1.KnativeServing) bool {
+	return instance.Spec.Istio != nil && instance.Spec.Istio.MeshCompatibility
+}
+
+// Warn when the API server can't reach the webhook once the control
+// plane is available, the usual symptom of a mesh rejecting its calls.
+// A hibernating webhook is unreachable by design.
+func (r *ReconcileKnativeServing) checkMesh(instance *servingv1alpha1.KnativeServing) error {
+	if !meshCompatibilityEnabled(instance) || instance.Spec.Hibernate || !instance.Status.IsAvailable() {
+		return nil
+	}
+	endpoints := &corev1.Endpoints{}
+	key := client.ObjectKey{Namespace: instance.InstallNamespace(), Name: webhookService}
+	if err := r.client.Get(context.TODO(), key, endpoints); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return nil
+		}
+	}
+	r.recorder.Eventf(instance, corev1.EventTypeWarning, "WebhookUnreachable",
+		"The webhook service %s has no ready endpoints", key)
+	return nil
+}
+
+// Warn when the namespace Knative Serving is installed into isn't
+// labeled for the sidecar injection of spec.istio, e.g. a namespace
+// of spec.namespace the operator doesn't label, or one relabeled since
+func (r *ReconcileKnativeServing) checkSidecarInjection(instance *servingv1alpha1.KnativeServing) error {
+	ns := &corev1.Namespace{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Name: instance.InstallNamespace()}, ns); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	want := common.SidecarInjection(instance)
+	if got, ok := ns.Labels[common.InjectionLabel]; !ok || got != want {
+		r.recorder.Eventf(instance, corev1.EventTypeWarning, "SidecarInjectionMislabeled",
+			"Namespace %s should be labeled %s=%s, or sidecars may keep the API server from calling the webhook",
+			ns.Name, common.InjectionLabel, want)
+	}
+	return nil
+}
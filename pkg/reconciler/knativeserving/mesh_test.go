@@ -0,0 +1,127 @@
+package knativeserving
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func TestMeshResources(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Istio: &servingv1alpha1.Istio{MeshCompatibility: true, SidecarInjection: true},
+		},
+	}
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c, scheme: newTestScheme(), recorder: record.NewFakeRecorder(10), config: newTestManifest(t, testManifest, c)}
+
+	manifest, err := r.transform(instance, nil)
+	if err != nil {
+		t.Fatalf("transform() = %v", err)
+	}
+	kinds := map[string]*unstructured.Unstructured{}
+	for i := range manifest.Resources {
+		u := &manifest.Resources[i]
+		kinds[u.GetKind()] = u
+		if u.GetKind() != "Deployment" || u.GetName() != "webhook" {
+			continue
+		}
+		deployment := &appsv1.Deployment{}
+		if err := r.scheme.Convert(u, deployment, nil); err != nil {
+			t.Fatalf("Convert() = %v", err)
+		}
+		if got := deployment.Spec.Template.Annotations["traffic.sidecar.istio.io/excludeInboundPorts"]; got != "8443" {
+			t.Errorf("webhook excludeInboundPorts = %q, want 8443", got)
+		}
+	}
+	pa := kinds["PeerAuthentication"]
+	if pa == nil {
+		t.Fatal("expected a PeerAuthentication")
+	}
+	if mode, _, _ := unstructured.NestedString(pa.Object, "spec", "mtls", "mode"); mode != "STRICT" {
+		t.Errorf("PeerAuthentication mode = %q, want STRICT", mode)
+	}
+	if len(pa.GetOwnerReferences()) != 1 {
+		t.Errorf("PeerAuthentication owners = %v, want the KnativeServing", pa.GetOwnerReferences())
+	}
+	dr := kinds["DestinationRule"]
+	if dr == nil {
+		t.Fatal("expected a DestinationRule")
+	}
+	if host, _, _ := unstructured.NestedString(dr.Object, "spec", "host"); host != "*.knative-serving.svc.cluster.local" {
+		t.Errorf("DestinationRule host = %q", host)
+	}
+
+	// Without sidecars, the namespace stays out of mutual TLS
+	instance.Spec.Istio.SidecarInjection = false
+	for _, u := range meshResources(instance) {
+		if mode, _, _ := unstructured.NestedString(u.Object, "spec", "mtls", "mode"); u.GetKind() == "PeerAuthentication" && mode != "PERMISSIVE" {
+			t.Errorf("PeerAuthentication mode = %q, want PERMISSIVE", mode)
+		}
+	}
+}
+
+func TestCheckMesh(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Istio: &servingv1alpha1.Istio{MeshCompatibility: true},
+		},
+	}
+	instance.Status.MarkDeploymentsAvailable()
+	r, recorder := newTestReconciler(instance)
+
+	if err := r.checkMesh(instance); err != nil {
+		t.Fatalf("checkMesh() = %v", err)
+	}
+	expectEvent(t, recorder, "Warning WebhookUnreachable")
+
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "webhook"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+		}},
+	}
+	if err := r.client.Create(context.TODO(), endpoints); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	if err := r.checkMesh(instance); err != nil {
+		t.Fatalf("checkMesh() = %v", err)
+	}
+	expectNoEvent(t, recorder)
+}
+
+func TestCheckSidecarInjection(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+	}
+	r, recorder := newTestReconciler(instance)
+
+	// Nothing to check before the namespace exists
+	if err := r.checkSidecarInjection(instance); err != nil {
+		t.Fatalf("checkSidecarInjection() = %v", err)
+	}
+	expectNoEvent(t, recorder)
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: operand, Labels: map[string]string{"istio-injection": "enabled"}}}
+	if err := r.client.Create(context.TODO(), ns); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	if err := r.checkSidecarInjection(instance); err != nil {
+		t.Fatalf("checkSidecarInjection() = %v", err)
+	}
+	expectEvent(t, recorder, "Warning SidecarInjectionMislabeled")
+
+	instance.Spec.Istio = &servingv1alpha1.Istio{MeshCompatibility: true, SidecarInjection: true}
+	if err := r.checkSidecarInjection(instance); err != nil {
+		t.Fatalf("checkSidecarInjection() = %v", err)
+	}
+	expectNoEvent(t, recorder)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "knative_serving_operator"
+)
+
+var (
+	reconcileDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Time taken to reconcile a KnativeServing",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10),
+	})
+	reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_total",
+		Help:      "Number of KnativeServing reconciles by result",
+	}, []string{"result"})
+	resourcesApplied = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "resources_applied",
+		Help:      "Number of resources applied by the last successful install",
+	})
+	installedVersion = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "installed_version",
+		Help:      "The installed Knative Serving version, whose value is always 1",
+	}, []string{"version"})
+	resourcesDrifted = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "resources_drifted",
+		Help:      "Number of resources the last install found modified or deleted since they were applied, by kind",
+	}, []string{"kind"})
+	driftRepairsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "drift_repairs_total",
+		Help:      "Number of modified or deleted resources restored by an install, by kind",
+	}, []string{"kind"})
+	stageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "stage_duration_seconds",
+		Help:      "Time taken by each stage of a reconcile, by stage and result",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"stage", "result"})
+)
+
+func init() {
+	// Served by the manager along with the controller-runtime metrics
+	metrics.Registry.MustRegister(reconcileDuration, reconcileTotal, resourcesApplied, installedVersion,
+		resourcesDrifted, driftRepairsTotal, stageDuration)
+}
+
+// Record the duration and result of a reconcile
+func recordReconcileMetrics(start time.Time, err error) {
+	reconcileDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		reconcileTotal.WithLabelValues(reconcileFailed).Inc()
+	} else {
+		reconcileTotal.WithLabelValues(reconcileSucceeded).Inc()
+	}
+}
+
+// Record the duration and result of a reconcile stage
+func recordStageMetrics(stage string, start time.Time, err error) {
+	result := reconcileSucceeded
+	if err != nil {
+		result = reconcileFailed
+	}
+	stageDuration.WithLabelValues(stage, result).Observe(time.Since(start).Seconds())
+}
+
+// Record what a successful install applied
+func recordInstallMetrics(resources int, version string) {
+	resourcesApplied.Set(float64(resources))
+	installedVersion.Reset()
+	installedVersion.WithLabelValues(version).Set(1)
+}
+
+// Record the resources an install found out of sync, by kind, before
+// applying the manifest
+func recordDriftMetrics(drifted map[string]int) {
+	resourcesDrifted.Reset()
+	for kind, n := range drifted {
+		resourcesDrifted.WithLabelValues(kind).Set(float64(n))
+	}
+}
+
+// Record the drifted resources a successful apply restored
+func recordDriftRepairs(drifted map[string]int) {
+	for kind, n := range drifted {
+		driftRepairsTotal.WithLabelValues(kind).Add(float64(n))
+	}
+}
@@ -0,0 +1,72 @@
+package knativeserving
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func metricValue(t *testing.T, m prometheus.Metric) float64 {
+	metric := &dto.Metric{}
+	if err := m.Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	if metric.Counter != nil {
+		return metric.Counter.GetValue()
+	}
+	return metric.Gauge.GetValue()
+}
+
+func TestRecordReconcileMetrics(t *testing.T) {
+	succeeded := metricValue(t, reconcileTotal.WithLabelValues(reconcileSucceeded))
+	failed := metricValue(t, reconcileTotal.WithLabelValues(reconcileFailed))
+
+	recordReconcileMetrics(time.Now(), nil)
+	recordReconcileMetrics(time.Now(), errors.New("boom"))
+	recordReconcileMetrics(time.Now(), errors.New("boom"))
+
+	if got := metricValue(t, reconcileTotal.WithLabelValues(reconcileSucceeded)) - succeeded; got != 1 {
+		t.Errorf("succeeded reconciles = %v, want 1", got)
+	}
+	if got := metricValue(t, reconcileTotal.WithLabelValues(reconcileFailed)) - failed; got != 2 {
+		t.Errorf("failed reconciles = %v, want 2", got)
+	}
+}
+
+func TestRecordInstallMetrics(t *testing.T) {
+	recordInstallMetrics(3, "0.6.0")
+	recordInstallMetrics(42, "0.7.0")
+
+	if got := metricValue(t, resourcesApplied); got != 42 {
+		t.Errorf("resources applied = %v, want 42", got)
+	}
+	if got := metricValue(t, installedVersion.WithLabelValues("0.7.0")); got != 1 {
+		t.Errorf("installed version 0.7.0 = %v, want 1", got)
+	}
+	metrics := make(chan prometheus.Metric, 10)
+	installedVersion.Collect(metrics)
+	if len(metrics) != 1 {
+		t.Errorf("got %d installed versions, want 1", len(metrics))
+	}
+}
+
+func TestRecordDriftMetrics(t *testing.T) {
+	repairs := metricValue(t, driftRepairsTotal.WithLabelValues("ConfigMap"))
+
+	recordDriftMetrics(map[string]int{"ConfigMap": 2, "Service": 1})
+	recordDriftMetrics(map[string]int{"ConfigMap": 3})
+	recordDriftRepairs(map[string]int{"ConfigMap": 3})
+
+	if got := metricValue(t, resourcesDrifted.WithLabelValues("ConfigMap")); got != 3 {
+		t.Errorf("drifted ConfigMaps = %v, want 3", got)
+	}
+	if got := metricValue(t, resourcesDrifted.WithLabelValues("Service")); got != 0 {
+		t.Errorf("drifted Services = %v, want 0", got)
+	}
+	if got := metricValue(t, driftRepairsTotal.WithLabelValues("ConfigMap")) - repairs; got != 3 {
+		t.Errorf("ConfigMap repairs = %v, want 3", got)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Releases name the Jobs migrating their resources to the CRDs'
+	// new storage versions with this prefix, e.g.
+	// storage-version-migration-serving-0.14.0
+	migrationJobPrefix = "storage-version-migration"
+)
+
+func isMigrationJob(u *unstructured.Unstructured) bool {
+	return u.GetKind() == "Job" && strings.HasPrefix(u.GetName(), migrationJobPrefix)
+}
+
+// The migration Jobs only run as part of an upgrade, until it completes.
+// Otherwise they're left out, so those of the last upgrade are pruned.
+func migrating(instance *servingv1alpha1.KnativeServing) bool {
+	return isUpgrade(instance) || instance.Status.IsUpgradeInProgress()
+}
+
+func withoutMigrationJobs(resources []unstructured.Unstructured) []unstructured.Unstructured {
+	result := make([]unstructured.Unstructured, 0, len(resources))
+	for i := range resources {
+		if !isMigrationJob(&resources[i]) {
+			result = append(result, resources[i])
+		}
+	}
+	return result
+}
+
+// Track the migration Jobs of an upgrade, holding back readiness and
+// the upgrade's completion until they all succeed
+func (r *ReconcileKnativeServing) checkMigrations(instance *servingv1alpha1.KnativeServing) error {
+	if !instance.Status.IsUpgradeInProgress() {
+		return nil
+	}
+	core, _, err := r.selectIngress(instance)
+	if err != nil {
+		return err
+	}
+	var running, failed []string
+	for i := range core {
+		if !isMigrationJob(&core[i]) {
+			continue
+		}
+		job := &batchv1.Job{}
+		key := client.ObjectKey{Namespace: instance.InstallNamespace(), Name: core[i].GetName()}
+		if err := r.client.Get(context.TODO(), key, job); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			running = append(running, key.Name)
+			continue
+		}
+		switch {
+		case jobCondition(job, batchv1.JobFailed):
+			failed = append(failed, key.Name)
+		case !jobCondition(job, batchv1.JobComplete):
+			running = append(running, key.Name)
+		}
+	}
+	switch {
+	case len(failed) > 0:
+		msg := fmt.Sprintf("Storage version migration Jobs %s failed; delete them to retry", strings.Join(failed, ", "))
+		// Only report the transition
+		if condition := instance.Status.GetCondition(servingv1alpha1.VersionMigrationEligible); condition == nil || condition.Message != msg {
+			r.recorder.Event(instance, v1.EventTypeWarning, "MigrationFailed", msg)
+		}
+		instance.Status.MarkVersionMigrationFailed(msg)
+	case len(running) > 0:
+		instance.Status.MarkVersionMigrationRunning(strings.Join(running, ", "))
+	case instance.Status.IsVersionMigrating():
+		r.recorder.Event(instance, v1.EventTypeNormal, "MigrationSucceeded", "Storage version migration Jobs completed")
+		instance.Status.MarkVersionMigrationEligible()
+	default:
+		return nil
+	}
+	return r.updateStatus(instance)
+}
+
+func jobCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == conditionType && c.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
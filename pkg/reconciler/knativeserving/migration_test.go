@@ -0,0 +1,97 @@
+package knativeserving
+
+import (
+	"context"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/version"
+)
+
+const migrationJob = "storage-version-migration-serving"
+
+const migrationManifest = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: storage-version-migration-serving
+  namespace: knative-serving
+---
+` + upgradeManifest
+
+func TestMigrationJobsOnlyDuringUpgrade(t *testing.T) {
+	m := newTestManifest(t, migrationManifest, nil)
+	if got := len(withoutMigrationJobs(m.Resources)); got != len(m.Resources)-1 {
+		t.Errorf("got %d resources, want %d", got, len(m.Resources)-1)
+	}
+	if migrating(newInstance(withInstalled(version.Version))) {
+		t.Error("reapplying the same release doesn't migrate")
+	}
+	if !migrating(newInstance(withInstalled("0.6.0"))) {
+		t.Error("expected an upgrade from 0.6.0 to migrate")
+	}
+	ordered := upgradeOrder(m.Resources)
+	if got := ordered[len(ordered)-1].GetName(); got != migrationJob {
+		t.Errorf("last resource = %s, want the migration Job", got)
+	}
+}
+
+func TestCheckMigrations(t *testing.T) {
+	instance := newInstance(withInstalled(version.Version))
+	instance.Status.MarkUpgradeInProgress("0.6.0", version.Version)
+	instance.Status.MarkVersionMigrationEligible()
+	c := newFakeClient(newTestScheme(), instance.DeepCopy())
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, recorder: recorder, config: newTestManifest(t, migrationManifest, c)}
+
+	// Not yet created
+	if err := r.checkMigrations(instance); err != nil {
+		t.Fatalf("checkMigrations() = %v", err)
+	}
+	if condition := instance.Status.GetCondition(servingv1alpha1.VersionMigrationEligible); !condition.IsUnknown() {
+		t.Errorf("VersionMigrationEligible = %v, want Unknown while the Job runs", condition)
+	}
+
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: migrationJob}}
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue}}
+	if err := c.Create(context.TODO(), job); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	if err := r.checkMigrations(instance); err != nil {
+		t.Fatalf("checkMigrations() = %v", err)
+	}
+	if condition := instance.Status.GetCondition(servingv1alpha1.VersionMigrationEligible); !condition.IsFalse() || condition.Reason != "MigrationFailed" {
+		t.Errorf("VersionMigrationEligible = %v, want False with reason MigrationFailed", condition)
+	}
+	expectEvent(t, recorder, "Warning MigrationFailed")
+
+	// Holds back the upgrade's completion
+	instance.Status.MarkDeploymentsAvailable()
+	if err := r.completeUpgrade(instance); err != nil {
+		t.Fatalf("completeUpgrade() = %v", err)
+	}
+	if !instance.Status.IsUpgradeInProgress() {
+		t.Error("UpgradeInProgress should remain until the migration succeeds")
+	}
+
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+	if err := c.Update(context.TODO(), job); err != nil {
+		t.Fatalf("Update() = %v", err)
+	}
+	if err := r.checkMigrations(instance); err != nil {
+		t.Fatalf("checkMigrations() = %v", err)
+	}
+	if !instance.Status.GetCondition(servingv1alpha1.VersionMigrationEligible).IsTrue() {
+		t.Errorf("VersionMigrationEligible = %v, want True", instance.Status.GetCondition(servingv1alpha1.VersionMigrationEligible))
+	}
+	expectEvent(t, recorder, "Normal MigrationSucceeded")
+	if err := r.completeUpgrade(instance); err != nil {
+		t.Fatalf("completeUpgrade() = %v", err)
+	}
+	if instance.Status.IsUpgradeInProgress() {
+		t.Error("UpgradeInProgress should be cleared")
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
 	"knative.dev/serving-operator/pkg/reconciler/knativeserving/common"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
@@ -37,7 +38,7 @@ var (
 )
 
 // Configure minikube if we're soaking in it
-func Configure(c client.Client, _ *runtime.Scheme) (*common.Extension, error) {
+func Configure(c client.Client, _ discovery.DiscoveryInterface, _ *runtime.Scheme) (*common.Extension, error) {
 	node := &v1.Node{}
 	if err := c.Get(context.TODO(), types.NamespacedName{Name: "minikube"}, node); err != nil {
 		if !errors.IsNotFound(err) {
@@ -0,0 +1,159 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	monitoringAPIVersion = "monitoring.coreos.com/v1"
+	// Holds the Grafana dashboards, as JSON files
+	dashboardsDir = "monitoring/dashboards"
+	// Grafana's sidecar loads the ConfigMaps with this label
+	grafanaDashboardLabel = "grafana_dashboard"
+	dashboardPrefix       = "knative-serving-dashboard-"
+
+	revisionsPodMonitor = "knative-serving-revisions"
+	revisionLabel       = "serving.knative.dev/revision"
+	queueMetricsPort    = "queue-metrics"
+	// Differs between releases, so it's left out of the selectors
+	servingReleaseLabel = "serving.knative.dev/release"
+)
+
+var (
+	// The names of the Service ports serving Prometheus metrics
+	metricsPortNames = map[string]bool{"metrics": true, "http-metrics": true}
+)
+
+// Parse the bundled Grafana dashboards, keyed by file name without
+// the extension
+func loadDashboards(koDataDir string) (map[string]string, error) {
+	result := map[string]string{}
+	files, err := ioutil.ReadDir(filepath.Join(koDataDir, dashboardsDir))
+	if os.IsNotExist(err) {
+		return result, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(koDataDir, dashboardsDir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		result[strings.TrimSuffix(f.Name(), ".json")] = string(data)
+	}
+	return result, nil
+}
+
+// The resources of spec.monitoring: a ServiceMonitor for each of the
+// Services exposing metrics, a PodMonitor for the queue-proxy of every
+// revision and, with dashboards, a ConfigMap per bundled dashboard
+func monitoringResources(instance *servingv1alpha1.KnativeServing, core []unstructured.Unstructured, dashboards map[string]string) []unstructured.Unstructured {
+	var result []unstructured.Unstructured
+	for _, u := range core {
+		if u.GetKind() != "Service" {
+			continue
+		}
+		if monitor, ok := serviceMonitor(instance, &u); ok {
+			result = append(result, monitor)
+		}
+	}
+	result = append(result, monitor(instance, "PodMonitor", instance.InstallNamespace(), revisionsPodMonitor, map[string]interface{}{
+		"namespaceSelector": map[string]interface{}{"any": true},
+		"selector": map[string]interface{}{
+			"matchExpressions": []interface{}{
+				map[string]interface{}{"key": revisionLabel, "operator": "Exists"},
+			},
+		},
+		"podMetricsEndpoints": []interface{}{
+			map[string]interface{}{"port": queueMetricsPort},
+		},
+	}))
+	if !instance.Spec.Monitoring.Dashboards {
+		return result
+	}
+	names := make([]string, 0, len(dashboards))
+	for name := range dashboards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cm := unstructured.Unstructured{Object: map[string]interface{}{
+			"data": map[string]interface{}{name + ".json": dashboards[name]},
+		}}
+		cm.SetAPIVersion("v1")
+		cm.SetKind("ConfigMap")
+		cm.SetNamespace(instance.InstallNamespace())
+		cm.SetName(dashboardPrefix + name)
+		cm.SetLabels(map[string]string{grafanaDashboardLabel: "1"})
+		result = append(result, cm)
+	}
+	return result
+}
+
+// A ServiceMonitor scraping the metrics ports of the Service, selecting
+// it by its labels, if it has any
+func serviceMonitor(instance *servingv1alpha1.KnativeServing, svc *unstructured.Unstructured) (unstructured.Unstructured, bool) {
+	selector := map[string]interface{}{}
+	for k, v := range svc.GetLabels() {
+		if k != servingReleaseLabel {
+			selector[k] = v
+		}
+	}
+	var endpoints []interface{}
+	ports, _, _ := unstructured.NestedSlice(svc.Object, "spec", "ports")
+	for _, p := range ports {
+		if port, ok := p.(map[string]interface{}); ok {
+			if name, _ := port["name"].(string); metricsPortNames[name] {
+				endpoints = append(endpoints, map[string]interface{}{"port": name})
+			}
+		}
+	}
+	if len(selector) == 0 || len(endpoints) == 0 {
+		return unstructured.Unstructured{}, false
+	}
+	return monitor(instance, "ServiceMonitor", svc.GetNamespace(), svc.GetName(), map[string]interface{}{
+		"selector":  map[string]interface{}{"matchLabels": selector},
+		"endpoints": endpoints,
+	}), true
+}
+
+func monitor(instance *servingv1alpha1.KnativeServing, kind, namespace, name string, spec map[string]interface{}) unstructured.Unstructured {
+	u := unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	u.SetAPIVersion(monitoringAPIVersion)
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	if labels := instance.Spec.Monitoring.Labels; len(labels) > 0 {
+		u.SetLabels(labels)
+	}
+	return u
+}
+
+func monitoringEnabled(instance *servingv1alpha1.KnativeServing) bool {
+	return instance.Spec.Monitoring != nil && instance.Spec.Monitoring.Enabled
+}
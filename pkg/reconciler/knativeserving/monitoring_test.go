@@ -0,0 +1,125 @@
+package knativeserving
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const monitoringManifest = `apiVersion: v1
+kind: Service
+metadata:
+  name: controller
+  namespace: knative-serving
+  labels:
+    app: controller
+    serving.knative.dev/release: v0.7.0
+spec:
+  ports:
+  - name: metrics
+    port: 9090
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: webhook
+  namespace: knative-serving
+  labels:
+    role: webhook
+spec:
+  ports:
+  - port: 443
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: unlabeled
+  namespace: knative-serving
+spec:
+  ports:
+  - name: metrics
+    port: 9090
+`
+
+func TestMonitoringResources(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	core := newTestManifest(t, monitoringManifest, c).Resources
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Monitoring: &servingv1alpha1.Monitoring{
+				Enabled: true,
+				Labels:  map[string]string{"release": "prometheus"},
+			},
+		},
+	}
+	dashboards := map[string]string{"control-plane": "{}"}
+
+	resources := monitoringResources(instance, core, dashboards)
+	if len(resources) != 2 {
+		t.Fatalf("got %d resources, want a ServiceMonitor and a PodMonitor", len(resources))
+	}
+	monitor := resources[0]
+	if monitor.GetKind() != "ServiceMonitor" || monitor.GetName() != "controller" {
+		t.Errorf("got %s %s, want the controller's ServiceMonitor", monitor.GetKind(), monitor.GetName())
+	}
+	selector, _, _ := unstructured.NestedStringMap(monitor.Object, "spec", "selector", "matchLabels")
+	if want := map[string]string{"app": "controller"}; !reflect.DeepEqual(selector, want) {
+		t.Errorf("selector = %v, want %v", selector, want)
+	}
+	if got := monitor.GetLabels()["release"]; got != "prometheus" {
+		t.Errorf("release label = %q, want prometheus", got)
+	}
+	if pod := resources[1]; pod.GetKind() != "PodMonitor" || pod.GetName() != revisionsPodMonitor {
+		t.Errorf("got %s %s, want the revisions' PodMonitor", pod.GetKind(), pod.GetName())
+	}
+
+	instance.Spec.Monitoring.Dashboards = true
+	resources = monitoringResources(instance, core, dashboards)
+	if len(resources) != 3 {
+		t.Fatalf("got %d resources, want a dashboard too", len(resources))
+	}
+	dashboard := resources[2]
+	if dashboard.GetName() != "knative-serving-dashboard-control-plane" || dashboard.GetLabels()[grafanaDashboardLabel] != "1" {
+		t.Errorf("got ConfigMap %s labeled %v, want the labeled dashboard", dashboard.GetName(), dashboard.GetLabels())
+	}
+}
+
+func TestLoadDashboards(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kodata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dashboards, err := loadDashboards(dir)
+	if err != nil {
+		t.Fatalf("loadDashboards() = %v", err)
+	}
+	if len(dashboards) != 0 {
+		t.Errorf("got %d dashboards without a dashboards directory, want 0", len(dashboards))
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, dashboardsDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, dashboardsDir, "serving.json"), []byte(`{"title": "Serving"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, dashboardsDir, "README"), []byte("not a dashboard"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dashboards, err = loadDashboards(dir)
+	if err != nil {
+		t.Fatalf("loadDashboards() = %v", err)
+	}
+	if want := map[string]string{"serving": `{"title": "Serving"}`}; !reflect.DeepEqual(dashboards, want) {
+		t.Errorf("loadDashboards() = %v, want %v", dashboards, want)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Create the namespace Knative Serving is installed into, unless it
+// exists. It's labeled like the rest of the install, so that the
+// manifest's Namespace may take it over and uninstalling deletes it.
+func (r *ReconcileKnativeServing) ensureNamespace(instance *servingv1alpha1.KnativeServing) error {
+	name := instance.InstallNamespace()
+	ns := &v1.Namespace{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Name: name}, ns); !errors.IsNotFound(err) {
+		return err
+	}
+	ns.Name = name
+	ns.Labels = map[string]string{releaseLabel: targetVersion(instance)}
+	log.Info("Creating namespace", "name", name)
+	if err := r.client.Create(context.TODO(), ns); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+	r.recorder.Eventf(instance, v1.EventTypeNormal, "NamespaceCreated", "Created namespace %s", name)
+	return nil
+}
+
+// A namespace named by spec.namespace that the operator didn't create
+// is only installed into, not taken over: its labels are left alone
+// and uninstalling keeps it
+func (r *ReconcileKnativeServing) isForeignNamespace(instance *servingv1alpha1.KnativeServing) (bool, error) {
+	name := instance.InstallNamespace()
+	if name == instance.GetNamespace() {
+		return false, nil
+	}
+	ns := &v1.Namespace{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Name: name}, ns); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	_, ok := ns.Labels[releaseLabel]
+	return !ok, nil
+}
+
+// Drop the manifest's Namespace from the resources if it's foreign
+func (r *ReconcileKnativeServing) withoutForeignNamespace(instance *servingv1alpha1.KnativeServing, resources []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	foreign, err := r.isForeignNamespace(instance)
+	if err != nil || !foreign {
+		return resources, err
+	}
+	result := make([]unstructured.Unstructured, 0, len(resources))
+	for _, u := range resources {
+		if u.GetKind() != "Namespace" || u.GetName() != instance.InstallNamespace() {
+			result = append(result, u)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package knativeserving
+
+import (
+	"context"
+	"testing"
+
+	mf "github.com/jcrossley3/manifestival"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestEnsureNamespaceCreatesLabeledNamespace(t *testing.T) {
+	instance := newInstance(withSpec(servingv1alpha1.KnativeServingSpec{Namespace: "serving-system"}))
+	r, recorder := newTestReconciler(instance)
+	if err := r.ensureNamespace(instance); err != nil {
+		t.Fatalf("ensureNamespace() = %v", err)
+	}
+	ns := &corev1.Namespace{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Name: "serving-system"}, ns); err != nil {
+		t.Fatalf("the namespace wasn't created: %v", err)
+	}
+	if _, ok := ns.Labels[releaseLabel]; !ok {
+		t.Errorf("labels = %v, want %s", ns.Labels, releaseLabel)
+	}
+	expectEvent(t, recorder, "Normal NamespaceCreated")
+
+	if err := r.ensureNamespace(instance); err != nil {
+		t.Fatalf("ensureNamespace() = %v", err)
+	}
+	expectNoEvent(t, recorder)
+}
+
+func TestForeignNamespaceIsntTakenOver(t *testing.T) {
+	instance := newInstance(withSpec(servingv1alpha1.KnativeServingSpec{Namespace: "shared"}))
+	r, _ := newTestReconciler(instance)
+	r.client.Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shared"}})
+	manifest := newTestManifest(t, testManifest, r.client)
+	manifest.Transform(mf.InjectNamespace("shared"))
+
+	resources, err := r.withoutForeignNamespace(instance, manifest.Resources)
+	if err != nil {
+		t.Fatalf("withoutForeignNamespace() = %v", err)
+	}
+	if len(resources) != len(manifest.Resources)-1 || resources[0].GetKind() == "Namespace" {
+		t.Errorf("withoutForeignNamespace() kept the Namespace: %v", resources)
+	}
+
+	// The CR's own namespace is managed as it always was
+	instance.Spec.Namespace = ""
+	if resources, _ := r.withoutForeignNamespace(instance, manifest.Resources); len(resources) != len(manifest.Resources) {
+		t.Errorf("withoutForeignNamespace() dropped resources of the CR's own namespace")
+	}
+}
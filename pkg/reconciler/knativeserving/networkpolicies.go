@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	metricsPort = 9090
+)
+
+// The NetworkPolicies of spec.security.networkPolicies, allowing only
+// the traffic each control plane component serves. The activator
+// isn't restricted, since it's reached by the ingress wherever that
+// runs, and neither are the ports the API server calls, which no
+// selector can single out. Metrics stay open for Prometheus.
+func networkPolicies() []unstructured.Unstructured {
+	policies := []*networkingv1.NetworkPolicy{
+		networkPolicy("webhook", map[string]string{"role": "webhook"},
+			networkingv1.NetworkPolicyIngressRule{Ports: tcpPorts(8443)}),
+		networkPolicy("autoscaler", map[string]string{"app": "autoscaler"},
+			networkingv1.NetworkPolicyIngressRule{
+				From: []networkingv1.NetworkPolicyPeer{{
+					PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "activator"}},
+				}},
+				Ports: tcpPorts(8080),
+			},
+			// The custom metrics API served to the API server
+			networkingv1.NetworkPolicyIngressRule{Ports: tcpPorts(8443)},
+			networkingv1.NetworkPolicyIngressRule{Ports: tcpPorts(metricsPort)}),
+		networkPolicy("controller", map[string]string{"app": "controller"},
+			networkingv1.NetworkPolicyIngressRule{Ports: tcpPorts(metricsPort)}),
+		networkPolicy("networking-istio", map[string]string{"app": "networking-istio"},
+			networkingv1.NetworkPolicyIngressRule{Ports: tcpPorts(metricsPort)}),
+		networkPolicy("networking-certmanager", map[string]string{"app": "networking-certmanager"},
+			networkingv1.NetworkPolicyIngressRule{Ports: tcpPorts(metricsPort)}),
+	}
+	result := make([]unstructured.Unstructured, len(policies))
+	for i, p := range policies {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(p)
+		if err != nil {
+			// Only a bug in the policies above could fail to convert
+			panic(err)
+		}
+		result[i] = unstructured.Unstructured{Object: obj}
+	}
+	return result
+}
+
+func networkPolicy(name string, selector map[string]string, rules ...networkingv1.NetworkPolicyIngressRule) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: operand,
+			Name:      name,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: selector},
+			Ingress:     rules,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+func tcpPorts(ports ...int) []networkingv1.NetworkPolicyPort {
+	tcp := corev1.ProtocolTCP
+	result := make([]networkingv1.NetworkPolicyPort, len(ports))
+	for i, p := range ports {
+		port := intstr.FromInt(p)
+		result[i] = networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: &port}
+	}
+	return result
+}
+
+func networkPoliciesEnabled(instance *servingv1alpha1.KnativeServing) bool {
+	return instance.Spec.Security != nil && instance.Spec.Security.NetworkPolicies
+}
@@ -0,0 +1,69 @@
+package knativeserving
+
+import (
+	"context"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func countNetworkPolicies(resources []unstructured.Unstructured) int {
+	count := 0
+	for _, u := range resources {
+		if u.GetKind() == "NetworkPolicy" {
+			count++
+		}
+	}
+	return count
+}
+
+func TestNetworkPolicies(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Security: &servingv1alpha1.Security{NetworkPolicies: true},
+		},
+	}
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c, scheme: newTestScheme(), recorder: record.NewFakeRecorder(10), config: newTestManifest(t, testManifest, c)}
+
+	manifest, err := r.transform(instance, nil)
+	if err != nil {
+		t.Fatalf("transform() = %v", err)
+	}
+	if got, want := countNetworkPolicies(manifest.Resources), len(networkPolicies()); got != want {
+		t.Fatalf("got %d NetworkPolicies, want %d", got, want)
+	}
+	if _, err := applyChanged(&manifest, nil, nil); err != nil {
+		t.Fatalf("applyChanged() = %v", err)
+	}
+	policy := &networkingv1.NetworkPolicy{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "autoscaler"}, policy); err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	from := policy.Spec.Ingress[0].From
+	if len(from) != 1 || from[0].PodSelector.MatchLabels["app"] != "activator" {
+		t.Errorf("autoscaler ingress = %v, want only from the activator", policy.Spec.Ingress[0])
+	}
+
+	// Disabling them prunes them
+	instance.Spec.Security = nil
+	manifest, err = r.transform(instance, nil)
+	if err != nil {
+		t.Fatalf("transform() = %v", err)
+	}
+	if got := countNetworkPolicies(manifest.Resources); got != 0 {
+		t.Errorf("got %d NetworkPolicies once disabled, want 0", got)
+	}
+	if err := r.prune(instance, &manifest); err != nil {
+		t.Fatalf("prune() = %v", err)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "autoscaler"}, policy); err == nil {
+		t.Error("expected the NetworkPolicy to be pruned")
+	}
+}
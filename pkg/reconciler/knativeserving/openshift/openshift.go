@@ -34,6 +34,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 )
@@ -47,13 +48,20 @@ const (
 	// Relaxing security constraints is only necessary during the OpenShift Service Mesh Technology Preview phase (as per the docs).
 	serviceAccountName = "system:serviceaccount:knative-serving:controller"
 	sccName            = "privileged"
+
+	// Exposes the istio ingress gateway outside the cluster
+	gatewayRouteName  = "knative-ingress-gateway"
+	gatewayTargetPort = "http2"
+
+	// Maistra only injects sidecars into the namespaces in its member roll
+	memberRollName = "default"
 )
 
 var (
 	extension = common.Extension{
 		Transformers: []mf.Transformer{ingress, egress, deploymentController},
-		PreInstalls:  []common.Extender{ensureMaistra, caBundleConfigMap, addUserToSCC},
-		PostInstalls: []common.Extender{ensureOpenshiftIngress},
+		PreInstalls:  []common.Extender{ensureMaistra, caBundleConfigMap, addUserToSCC, addToMemberRoll},
+		PostInstalls: []common.Extender{ensureOpenshiftIngress, ensureGatewayRoute},
 	}
 	log    = logf.Log.WithName("openshift")
 	api    client.Client
@@ -61,8 +69,8 @@ var (
 )
 
 // Configure OpenShift if we're soaking in it
-func Configure(c client.Client, s *runtime.Scheme) (*common.Extension, error) {
-	if routeExists, err := anyKindExists(c, "", schema.GroupVersionKind{"route.openshift.io", "v1", "route"}); err != nil {
+func Configure(c client.Client, dc discovery.DiscoveryInterface, s *runtime.Scheme) (*common.Extension, error) {
+	if routeExists, err := groupVersionExists(dc, "route.openshift.io/v1"); err != nil {
 		return nil, err
 	} else if !routeExists {
 		// Not running in OpenShift
@@ -202,7 +210,8 @@ func installMaistraControlPlane(c client.Client) error {
 }
 
 func ingress(u *unstructured.Unstructured) error {
-	if u.GetKind() == "ConfigMap" && u.GetName() == "config-domain" {
+	// A default domain in the spec takes precedence over the cluster's
+	if u.GetKind() == "ConfigMap" && u.GetName() == "config-domain" && !common.HasDefaultDomain(u) {
 		ingressConfig := &configv1.Ingress{}
 		if err := api.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, ingressConfig); err != nil {
 			if !meta.IsNoMatchError(err) {
@@ -247,17 +256,9 @@ func addUserToSCC(instance *servingv1alpha1.KnativeServing) error {
 		return err
 	}
 	// Verify if SA has already been assigned to the SCC
-	existing, exists, _ := unstructured.NestedStringSlice(scc.UnstructuredContent(), "users")
-	if exists {
-		for _, e := range existing {
-			if e == serviceAccountName {
-				return nil
-			}
-		}
-		existing = append(existing, serviceAccountName)
+	if !addToList(scc, serviceAccountName, "users") {
+		return nil
 	}
-
-	unstructured.SetNestedStringSlice(scc.UnstructuredContent(), existing, "users")
 	err = api.Update(context.TODO(), scc)
 	if err != nil {
 		return err
@@ -266,6 +267,71 @@ func addUserToSCC(instance *servingv1alpha1.KnativeServing) error {
 	return nil
 }
 
+// addToMemberRoll enrolls the namespace in the Maistra ServiceMeshMemberRoll, if there is one,
+// so that the sidecars of the activator and autoscaler are injected
+func addToMemberRoll(instance *servingv1alpha1.KnativeServing) error {
+	roll := &unstructured.Unstructured{}
+	roll.SetAPIVersion("maistra.io/v1")
+	roll.SetKind("ServiceMeshMemberRoll")
+	key := client.ObjectKey{Namespace: maistraControlPlaneNamespace, Name: memberRollName}
+	if err := api.Get(context.TODO(), key, roll); err != nil {
+		if meta.IsNoMatchError(err) || errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !addToList(roll, instance.InstallNamespace(), "spec", "members") {
+		return nil
+	}
+	if err := api.Update(context.TODO(), roll); err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("Added namespace %q to ServiceMeshMemberRoll %q", instance.InstallNamespace(), memberRollName))
+	return nil
+}
+
+// addToList adds the value to the string slice at fields, returning whether it was missing
+func addToList(u *unstructured.Unstructured, value string, fields ...string) bool {
+	existing, _, _ := unstructured.NestedStringSlice(u.Object, fields...)
+	for _, e := range existing {
+		if e == value {
+			return false
+		}
+	}
+	unstructured.SetNestedStringSlice(u.Object, append(existing, value), fields...)
+	return true
+}
+
+// ensureGatewayRoute exposes the istio ingress gateway through an OpenShift Route, leaving
+// an existing Route as the admin configured it
+func ensureGatewayRoute(instance *servingv1alpha1.KnativeServing) error {
+	if instance.Spec.Ingress.Name() != servingv1alpha1.IstioIngress {
+		return nil
+	}
+	route := gatewayRoute(instance)
+	current := route.DeepCopy()
+	if err := api.Get(context.TODO(), client.ObjectKey{Namespace: route.GetNamespace(), Name: route.GetName()}, current); err == nil {
+		return nil
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	log.Info("Creating Route for the ingress gateway", "namespace", route.GetNamespace(), "name", route.GetName())
+	return api.Create(context.TODO(), route)
+}
+
+func gatewayRoute(instance *servingv1alpha1.KnativeServing) *unstructured.Unstructured {
+	service, namespace := common.GatewayService(instance, common.KnativeIngressGateway)
+	route := &unstructured.Unstructured{}
+	route.SetAPIVersion("route.openshift.io/v1")
+	route.SetKind("Route")
+	route.SetNamespace(namespace)
+	route.SetName(gatewayRouteName)
+	unstructured.SetNestedField(route.Object, "Service", "spec", "to", "kind")
+	unstructured.SetNestedField(route.Object, service, "spec", "to", "name")
+	unstructured.SetNestedField(route.Object, gatewayTargetPort, "spec", "port", "targetPort")
+	return route
+}
+
 func deploymentController(u *unstructured.Unstructured) error {
 	const volumeName = "service-ca"
 	if u.GetKind() == "Deployment" && u.GetName() == "controller" {
@@ -310,14 +376,16 @@ func deploymentController(u *unstructured.Unstructured) error {
 
 func caBundleConfigMap(instance *servingv1alpha1.KnativeServing) error {
 	cm := &v1.ConfigMap{}
-	if err := api.Get(context.TODO(), types.NamespacedName{Name: caBundleConfigMapName, Namespace: instance.GetNamespace()}, cm); err != nil {
+	if err := api.Get(context.TODO(), types.NamespacedName{Name: caBundleConfigMapName, Namespace: instance.InstallNamespace()}, cm); err != nil {
 		if errors.IsNotFound(err) {
 			// Define a new configmap
 			cm.Name = caBundleConfigMapName
 			cm.Annotations = make(map[string]string)
 			cm.Annotations["service.alpha.openshift.io/inject-cabundle"] = "true"
-			cm.Namespace = instance.GetNamespace()
-			cm.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(instance, instance.GroupVersionKind())})
+			cm.Namespace = instance.InstallNamespace()
+			if cm.Namespace == instance.GetNamespace() {
+				cm.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(instance, instance.GroupVersionKind())})
+			}
 			err = api.Create(context.TODO(), cm)
 			if err != nil {
 				return err
@@ -331,6 +399,18 @@ func caBundleConfigMap(instance *servingv1alpha1.KnativeServing) error {
 	return nil
 }
 
+// groupVersionExists returns true if the API server serves the groupVersion
+func groupVersionExists(dc discovery.DiscoveryInterface, groupVersion string) (bool, error) {
+	if _, err := dc.ServerResourcesForGroupVersion(groupVersion); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	log.Info("Detected", "groupVersion", groupVersion)
+	return true, nil
+}
+
 // anyKindExists returns true if any of the gvks (GroupVersionKind) exist
 func anyKindExists(c client.Client, namespace string, gvks ...schema.GroupVersionKind) (bool, error) {
 	for _, gvk := range gvks {
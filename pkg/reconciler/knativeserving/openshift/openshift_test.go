@@ -0,0 +1,59 @@
+package openshift
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func TestAddToList(t *testing.T) {
+	scc := &unstructured.Unstructured{Object: map[string]interface{}{}}
+
+	// A missing list is created
+	if !addToList(scc, serviceAccountName, "users") {
+		t.Error("expected the user to be added")
+	}
+	if addToList(scc, serviceAccountName, "users") {
+		t.Error("expected the user to be added only once")
+	}
+	if !addToList(scc, "system:serviceaccount:default:other", "users") {
+		t.Error("expected another user to be added")
+	}
+	users, _, _ := unstructured.NestedStringSlice(scc.Object, "users")
+	if want := []string{serviceAccountName, "system:serviceaccount:default:other"}; !reflect.DeepEqual(users, want) {
+		t.Errorf("users = %v, want %v", users, want)
+	}
+}
+
+func TestGatewayRoute(t *testing.T) {
+	route := gatewayRoute(&servingv1alpha1.KnativeServing{})
+	if route.GetNamespace() != "istio-system" || route.GetName() != "knative-ingress-gateway" {
+		t.Errorf("route = %s/%s, want istio-system/knative-ingress-gateway", route.GetNamespace(), route.GetName())
+	}
+	if name, _, _ := unstructured.NestedString(route.Object, "spec", "to", "name"); name != "istio-ingressgateway" {
+		t.Errorf("route targets %q, want istio-ingressgateway", name)
+	}
+	if port, _, _ := unstructured.NestedString(route.Object, "spec", "port", "targetPort"); port != "http2" {
+		t.Errorf("route port = %q, want http2", port)
+	}
+}
+
+func TestGatewayRouteFollowsOverride(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			KnativeIngressGateway: servingv1alpha1.IstioGatewayOverride{
+				Name:      "custom-ingressgateway",
+				Namespace: "custom-istio",
+			},
+		},
+	}
+	route := gatewayRoute(instance)
+	if route.GetNamespace() != "custom-istio" {
+		t.Errorf("route namespace = %q, want custom-istio", route.GetNamespace())
+	}
+	if name, _, _ := unstructured.NestedString(route.Object, "spec", "to", "name"); name != "custom-ingressgateway" {
+		t.Errorf("route targets %q, want custom-ingressgateway", name)
+	}
+}
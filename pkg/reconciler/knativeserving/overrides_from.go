@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// Merge the overrides of spec.overridesFrom over the spec. That's only
+// in memory, after the finalizer's update, so the values of the Secret
+// never get written to the KnativeServing. Fields of the overrides
+// replace those of the spec, except the objects they merge into, and
+// null removes a field.
+func (r *ReconcileKnativeServing) mergeOverrides(instance *servingv1alpha1.KnativeServing) error {
+	source := instance.Spec.OverridesFrom
+	if source == nil || source.SecretRef == nil {
+		return nil
+	}
+	ref := source.SecretRef
+	optional := ref.Optional != nil && *ref.Optional
+	secret := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: instance.GetNamespace(), Name: ref.Name}, secret); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		if optional {
+			return nil
+		}
+		return r.overridesInvalid(instance, fmt.Errorf("the overrides Secret %s does not exist", ref.Name))
+	}
+	data, ok := secret.Data[ref.Key]
+	if !ok {
+		if optional {
+			return nil
+		}
+		return r.overridesInvalid(instance, fmt.Errorf("no key %s in the overrides Secret %s", ref.Key, ref.Name))
+	}
+	spec, err := mergeSpec(instance.Spec, data)
+	if err != nil {
+		return r.overridesInvalid(instance, fmt.Errorf("invalid overrides in key %s of Secret %s: %v", ref.Key, ref.Name, err))
+	}
+	// The overrides can't refer elsewhere
+	spec.OverridesFrom = source
+	instance.Spec = spec
+	return nil
+}
+
+// Apply the overrides to the spec as a JSON merge patch, once they're
+// known to be a valid spec themselves
+func mergeSpec(spec servingv1alpha1.KnativeServingSpec, overrides []byte) (servingv1alpha1.KnativeServingSpec, error) {
+	result := servingv1alpha1.KnativeServingSpec{}
+	if err := yaml.UnmarshalStrict(overrides, &result); err != nil {
+		return result, err
+	}
+	patch, err := yaml.YAMLToJSON(overrides)
+	if err != nil {
+		return result, err
+	}
+	original, err := json.Marshal(spec)
+	if err != nil {
+		return result, err
+	}
+	merged, err := jsonpatch.MergePatch(original, patch)
+	if err != nil {
+		return result, err
+	}
+	result = servingv1alpha1.KnativeServingSpec{}
+	return result, json.Unmarshal(merged, &result)
+}
+
+// Retrying won't help until the Secret changes
+func (r *ReconcileKnativeServing) overridesInvalid(instance *servingv1alpha1.KnativeServing, err error) error {
+	instance.Status.MarkSpecInvalid(err.Error())
+	return permanent(err)
+}
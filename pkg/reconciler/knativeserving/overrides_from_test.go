@@ -0,0 +1,95 @@
+package knativeserving
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newOverridesSecret(overrides string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "serving-overrides"},
+		Data:       map[string][]byte{"overrides.yaml": []byte(overrides)},
+	}
+}
+
+func TestMergeOverrides(t *testing.T) {
+	instance := newInstance(withOverridesFrom())
+	r := &ReconcileKnativeServing{client: newFakeClient(newTestScheme(), instance.DeepCopy(), newOverridesSecret(`
+registry:
+  imagePullSecrets:
+  - name: private-registry
+config:
+  network:
+    domainTemplate: "{{.Name}}.{{.Namespace}}.internal"
+overridesFrom:
+  secretRef:
+    name: elsewhere
+    key: overrides.yaml
+`))}
+
+	if err := r.mergeOverrides(instance); err != nil {
+		t.Fatalf("mergeOverrides() = %v", err)
+	}
+	registry := instance.Spec.Registry
+	if registry.Default != "gcr.io/public/${NAME}:latest" || len(registry.ImagePullSecrets) != 1 || registry.ImagePullSecrets[0].Name != "private-registry" {
+		t.Errorf("Registry = %+v, want the pull secret merged into it", registry)
+	}
+	network := instance.Spec.Config["network"]
+	if network["istio.sidecar.includeOutboundIPRanges"] != "*" || network["domainTemplate"] == "" {
+		t.Errorf("network config = %v, want both entries", network)
+	}
+	if name := instance.Spec.OverridesFrom.SecretRef.Name; name != "serving-overrides" {
+		t.Errorf("overridesFrom = %s, want it kept", name)
+	}
+
+	// Only in memory
+	stored := &servingv1alpha1.KnativeServing{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: operand}, stored); err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Spec.Registry.ImagePullSecrets) != 0 {
+		t.Error("expected the overrides not to be stored in the KnativeServing")
+	}
+}
+
+func TestMergeOverridesInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		objects []string
+	}{
+		{"missing Secret", nil},
+		{"unknown field", []string{"registri:\n  default: example.com\n"}},
+		{"not YAML", []string{"registry: ["}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := newInstance(withOverridesFrom())
+			c := newFakeClient(newTestScheme(), instance.DeepCopy())
+			for _, overrides := range tt.objects {
+				c = newFakeClient(newTestScheme(), instance.DeepCopy(), newOverridesSecret(overrides))
+			}
+			r := &ReconcileKnativeServing{client: c}
+			err := r.mergeOverrides(instance)
+			if !isPermanent(err) {
+				t.Fatalf("mergeOverrides() = %v, want a permanent error", err)
+			}
+			if !instance.Status.GetCondition(servingv1alpha1.SpecValidated).IsFalse() {
+				t.Error("expected the spec to be marked invalid")
+			}
+		})
+	}
+
+	// Unless optional
+	instance := newInstance(withOverridesFrom())
+	optional := true
+	instance.Spec.OverridesFrom.SecretRef.Optional = &optional
+	r := &ReconcileKnativeServing{client: newFakeClient(newTestScheme(), instance.DeepCopy())}
+	if err := r.mergeOverrides(instance); err != nil {
+		t.Errorf("mergeOverrides() = %v, want an optional Secret to be skipped", err)
+	}
+}
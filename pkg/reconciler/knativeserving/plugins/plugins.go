@@ -0,0 +1,167 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/pkg/reconciler/knativeserving/common"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	sigyaml "sigs.k8s.io/yaml"
+)
+
+var (
+	dir = flag.String("transformer-plugins", "",
+		"A directory of executables transforming the manifest, run in the order of their names, each reading the resources as a stream of YAML documents on stdin and writing the result to stdout")
+	timeout = flag.Duration("transformer-plugin-timeout", 30*time.Second,
+		"How long a transformer plugin may run before it's killed and considered failed")
+	ignoreFailures = flag.Bool("ignore-transformer-plugin-failures", false,
+		"Carry on without the changes of a failed transformer plugin, rather than failing the install")
+
+	log = logf.Log.WithName("plugins")
+)
+
+// Configure the executables in the --transformer-plugins directory, if
+// any, to transform the manifest. The directory is read on every install,
+// so that plugins mounted from a ConfigMap take effect without a restart.
+func Configure(client.Client, discovery.DiscoveryInterface, *runtime.Scheme) (*common.Extension, error) {
+	if *dir == "" {
+		return nil, nil
+	}
+	paths, err := executables(*dir)
+	if err != nil || len(paths) == 0 {
+		return nil, err
+	}
+	extension := &common.Extension{}
+	for _, path := range paths {
+		extension.ManifestTransformers = append(extension.ManifestTransformers, transformer(path))
+	}
+	return extension, nil
+}
+
+// The executable files of the directory, in the order of their names,
+// skipping hidden ones, e.g. the ..data link of a ConfigMap volume
+func executables(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		// Follow the links a ConfigMap volume consists of
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0 {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// The transformer running the plugin, or passing the resources through
+// unchanged if it fails and failures are ignored
+func transformer(path string) common.ManifestTransformer {
+	return func(instance *servingv1alpha1.KnativeServing, resources []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+		result, err := run(path, instance, resources)
+		if err != nil {
+			if *ignoreFailures {
+				log.Error(err, "Ignoring failed transformer plugin", "plugin", path)
+				return resources, nil
+			}
+			return nil, err
+		}
+		return result, nil
+	}
+}
+
+func run(path string, instance *servingv1alpha1.KnativeServing, resources []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	var in bytes.Buffer
+	for _, u := range resources {
+		doc, err := sigyaml.Marshal(u.Object)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&in, "---\n%s", doc)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	var out, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = &in, &out, &stderr
+	cmd.Env = append(os.Environ(),
+		"KNATIVE_SERVING_NAME="+instance.GetName(),
+		"KNATIVE_SERVING_NAMESPACE="+instance.GetNamespace(),
+		"KNATIVE_SERVING_INSTALL_NAMESPACE="+instance.InstallNamespace(),
+	)
+	log.V(1).Info("Running transformer plugin", "plugin", path, "resources", len(resources))
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %v", *timeout)
+		}
+		return nil, fmt.Errorf("transformer plugin %s failed: %v: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	result, err := decode(&out)
+	if err != nil {
+		return nil, fmt.Errorf("transformer plugin %s returned invalid YAML: %v", path, err)
+	}
+	return result, nil
+}
+
+// decode the resources of a stream of YAML documents, each of which
+// must at least name its kind
+func decode(r io.Reader) ([]unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLToJSONDecoder(r)
+	var result []unstructured.Unstructured
+	for {
+		u := unstructured.Unstructured{}
+		if err := decoder.Decode(&u.Object); err != nil {
+			if err == io.EOF {
+				return result, nil
+			}
+			return nil, err
+		}
+		if len(u.Object) == 0 {
+			continue
+		}
+		if u.GetAPIVersion() == "" || u.GetKind() == "" || u.GetName() == "" {
+			return nil, fmt.Errorf("resource %d lacks an apiVersion, kind or name", len(result)+1)
+		}
+		result = append(result, u)
+	}
+}
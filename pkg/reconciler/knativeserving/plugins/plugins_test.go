@@ -0,0 +1,154 @@
+package plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/pkg/reconciler/knativeserving/common"
+)
+
+var instance = &servingv1alpha1.KnativeServing{
+	ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving", Name: "knative-serving"},
+}
+
+// pluginDir points --transformer-plugins at a directory of the shell
+// scripts, returning the function restoring it
+func pluginDir(t *testing.T, plugins map[string]string) func() {
+	t.Helper()
+	d, err := ioutil.TempDir("", "plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, script := range plugins {
+		mode := os.FileMode(0755)
+		if strings.HasSuffix(name, ".txt") {
+			mode = 0644
+		}
+		if err := ioutil.WriteFile(filepath.Join(d, name), []byte("#!/bin/sh\n"+script), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldDir := *dir
+	*dir = d
+	return func() {
+		*dir = oldDir
+		os.RemoveAll(d)
+	}
+}
+
+func configMap(name string) unstructured.Unstructured {
+	u := unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("ConfigMap")
+	u.SetNamespace("knative-serving")
+	u.SetName(name)
+	return u
+}
+
+func transform(t *testing.T, resources ...unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	t.Helper()
+	extension, err := Configure(nil, nil, nil)
+	if err != nil || extension == nil {
+		t.Fatalf("Configure() = %v, %v", extension, err)
+	}
+	return common.Extensions{*extension}.TransformManifest(instance, resources)
+}
+
+func TestConfigureWithoutPlugins(t *testing.T) {
+	defer pluginDir(t, map[string]string{"README.txt": ""})()
+	if extension, err := Configure(nil, nil, nil); extension != nil || err != nil {
+		t.Errorf("Configure() = %v, %v, want nothing", extension, err)
+	}
+	*dir = ""
+	if extension, err := Configure(nil, nil, nil); extension != nil || err != nil {
+		t.Errorf("Configure() = %v, %v, want nothing", extension, err)
+	}
+}
+
+func TestPluginsRunInOrder(t *testing.T) {
+	defer pluginDir(t, map[string]string{
+		"20-second":      "sed 's/name: first/name: second/'",
+		"10-first":       "sed 's/name: config-network/name: first/'",
+		"15-ignored.txt": "exit 1",
+		".hidden":        "exit 1",
+	})()
+	result, err := transform(t, configMap("config-network"), configMap("config-domain"))
+	if err != nil {
+		t.Fatalf("TransformManifest() = %v", err)
+	}
+	if len(result) != 2 || result[0].GetName() != "second" || result[1].GetName() != "config-domain" {
+		t.Errorf("TransformManifest() = %v, want config-network renamed twice", result)
+	}
+}
+
+func TestPluginAddsResources(t *testing.T) {
+	defer pluginDir(t, map[string]string{
+		"10-add": `cat
+cat <<EOF
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: $KNATIVE_SERVING_NAME-extra
+  namespace: $KNATIVE_SERVING_INSTALL_NAMESPACE
+EOF
+`,
+	})()
+	result, err := transform(t, configMap("config-network"))
+	if err != nil {
+		t.Fatalf("TransformManifest() = %v", err)
+	}
+	if len(result) != 2 || result[1].GetName() != "knative-serving-extra" || result[1].GetNamespace() != "knative-serving" {
+		t.Errorf("TransformManifest() = %v, want an extra ConfigMap", result)
+	}
+}
+
+func TestPluginFailures(t *testing.T) {
+	oldTimeout := *timeout
+	defer func() { *timeout = oldTimeout }()
+	*timeout = 100 * time.Millisecond
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{{
+		name:   "exit status",
+		script: "echo broken >&2; exit 1",
+		want:   "broken",
+	}, {
+		name:   "timeout",
+		script: "exec sleep 5",
+		want:   "timed out",
+	}, {
+		name:   "invalid output",
+		script: "echo '- not a resource'",
+		want:   "invalid YAML",
+	}, {
+		name:   "resource without a name",
+		script: "printf 'apiVersion: v1\\nkind: ConfigMap\\n'",
+		want:   "lacks an apiVersion, kind or name",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer pluginDir(t, map[string]string{"10-plugin": tt.script})()
+			_, err := transform(t, configMap("config-network"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("TransformManifest() = %v, want an error containing %q", err, tt.want)
+			}
+
+			*ignoreFailures = true
+			defer func() { *ignoreFailures = false }()
+			result, err := transform(t, configMap("config-network"))
+			if err != nil || len(result) != 1 || result[0].GetName() != "config-network" {
+				t.Errorf("TransformManifest() = %v, %v, want the resources unchanged", result, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/version"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// The oldest Kubernetes the bundled releases run on
+	minKubernetesMajor = 1
+	minKubernetesMinor = 11
+
+	// Mark the default StorageClass, the beta annotation on older clusters
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// Check that the cluster can run the install before touching it,
+// failing fast with PreflightSucceeded False until it can
+func (r *ReconcileKnativeServing) preflight(instance *servingv1alpha1.KnativeServing) error {
+	failures, err := r.preflightFailures(withProfile(instance))
+	if err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		msg := strings.Join(failures, "; ")
+		// Only report the transition
+		if condition := instance.Status.GetCondition(servingv1alpha1.PreflightSucceeded); !condition.IsFalse() {
+			r.recorder.Eventf(instance, v1.EventTypeWarning, "PreflightFailed", "Preflight checks failed: %s", msg)
+		}
+		instance.Status.MarkPreflightFailed(msg)
+		if err := r.updateStatus(instance); err != nil {
+			return err
+		}
+		return fmt.Errorf("preflight checks failed: %s", msg)
+	}
+	if instance.Status.GetCondition(servingv1alpha1.PreflightSucceeded).IsTrue() {
+		return nil
+	}
+	instance.Status.MarkPreflightSucceeded()
+	return r.updateStatus(instance)
+}
+
+// What the cluster lacks: a recent enough Kubernetes, the APIs the
+// install uses and, if it claims volumes, a default StorageClass
+func (r *ReconcileKnativeServing) preflightFailures(instance *servingv1alpha1.KnativeServing) ([]string, error) {
+	var failures []string
+	info, err := r.discovery.ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+	if !atLeastVersion(info, minKubernetesMajor, minKubernetesMinor) {
+		failures = append(failures, fmt.Sprintf("Kubernetes %s is older than %d.%d",
+			info.GitVersion, minKubernetesMajor, minKubernetesMinor))
+	}
+	if crdsOnly(instance) {
+		return failures, nil
+	}
+
+	groups, err := r.discovery.ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+	served := map[string]bool{}
+	for _, group := range groups.Groups {
+		for _, v := range group.Versions {
+			served[v.GroupVersion] = true
+		}
+	}
+	for _, gv := range requiredGroupVersions(instance) {
+		if !served[gv] {
+			failures = append(failures, fmt.Sprintf("the %s API isn't served", gv))
+		}
+	}
+
+	claims, err := r.claimsDefaultStorage(instance)
+	if err != nil {
+		return nil, err
+	}
+	if claims {
+		ok, err := r.hasDefaultStorageClass()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			failures = append(failures, "volumes are claimed without a storageClassName but there's no default StorageClass")
+		}
+	}
+	return failures, nil
+}
+
+// The API group versions the install uses that a cluster may not serve
+func requiredGroupVersions(instance *servingv1alpha1.KnativeServing) []string {
+	result := []string{"admissionregistration.k8s.io/v1beta1"}
+	if !instance.Spec.IsDisabled(servingv1alpha1.AutoscalerHPAComponent) {
+		result = append(result, "autoscaling/v2beta2")
+	}
+	if instance.Spec.Ingress.Name() == servingv1alpha1.IstioIngress {
+		result = append(result, istioGatewayAPIVersion)
+	}
+	return result
+}
+
+// Whether the resources to install, of the release, the selected
+// ingress and the additional manifests, claim volumes of the default
+// StorageClass. Additional manifests failing to load are left to the
+// transform to report.
+func (r *ReconcileKnativeServing) claimsDefaultStorage(instance *servingv1alpha1.KnativeServing) (bool, error) {
+	core, ingress, err := r.selectIngress(instance)
+	if err != nil {
+		return false, err
+	}
+	resources := append(core[:len(core):len(core)], ingress...)
+	if additional, err := r.additionalResources(instance); err == nil {
+		resources = append(resources, additional...)
+	}
+	for i := range resources {
+		if claimsDefaultStorage(&resources[i]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// A PersistentVolumeClaim, or a StatefulSet's volumeClaimTemplate,
+// without a storageClassName
+func claimsDefaultStorage(u *unstructured.Unstructured) bool {
+	switch u.GetKind() {
+	case "PersistentVolumeClaim":
+		_, found, _ := unstructured.NestedString(u.Object, "spec", "storageClassName")
+		return !found
+	case "StatefulSet":
+		templates, _, _ := unstructured.NestedSlice(u.Object, "spec", "volumeClaimTemplates")
+		for _, t := range templates {
+			template, ok := t.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if _, found, _ := unstructured.NestedString(template, "spec", "storageClassName"); !found {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *ReconcileKnativeServing) hasDefaultStorageClass() (bool, error) {
+	classes := &storagev1.StorageClassList{}
+	if err := r.client.List(context.TODO(), &client.ListOptions{}, classes); err != nil {
+		return false, err
+	}
+	for _, class := range classes.Items {
+		if class.Annotations[defaultStorageClassAnnotation] == "true" ||
+			class.Annotations[betaDefaultStorageClassAnnotation] == "true" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Compare the server's version, whose minor may carry a suffix, e.g.
+// "14+" on GKE, to the given one
+func atLeastVersion(info *version.Info, major, minor int) bool {
+	trim := func(s string) int {
+		n, _ := strconv.Atoi(strings.TrimRightFunc(s, func(r rune) bool { return r < '0' || r > '9' }))
+		return n
+	}
+	if got := trim(info.Major); got != major {
+		return got > major
+	}
+	return trim(info.Minor) >= minor
+}
@@ -0,0 +1,132 @@
+package knativeserving
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+// Serves a version and group versions, failing anything else
+type fakeDiscovery struct {
+	discovery.DiscoveryInterface
+	version       version.Info
+	groupVersions []string
+}
+
+func (d *fakeDiscovery) ServerVersion() (*version.Info, error) {
+	return &d.version, nil
+}
+
+func (d *fakeDiscovery) ServerGroups() (*metav1.APIGroupList, error) {
+	list := &metav1.APIGroupList{}
+	for _, gv := range d.groupVersions {
+		list.Groups = append(list.Groups, metav1.APIGroup{
+			Versions: []metav1.GroupVersionForDiscovery{{GroupVersion: gv}},
+		})
+	}
+	return list, nil
+}
+
+const claimManifest = `
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+  namespace: knative-serving
+spec:
+  resources:
+    requests:
+      storage: 1Gi
+`
+
+func newPreflightReconciler(t *testing.T, instance *servingv1alpha1.KnativeServing, manifest string, d *fakeDiscovery) *ReconcileKnativeServing {
+	r, _ := newTestReconciler(instance)
+	r.discovery = d
+	r.config = newTestManifest(t, manifest, r.client)
+	return r
+}
+
+func newPreflightDiscovery() *fakeDiscovery {
+	return &fakeDiscovery{
+		version:       version.Info{Major: "1", Minor: "14+", GitVersion: "v1.14.8-gke.12"},
+		groupVersions: []string{"admissionregistration.k8s.io/v1beta1", "autoscaling/v2beta2", "networking.istio.io/v1alpha3"},
+	}
+}
+
+func TestPreflightSucceeds(t *testing.T) {
+	instance := newInstance(withCertManager(""))
+	r := newPreflightReconciler(t, instance, testManifest, newPreflightDiscovery())
+
+	if err := r.preflight(instance); err != nil {
+		t.Fatalf("preflight() = %v", err)
+	}
+	if !instance.Status.GetCondition(servingv1alpha1.PreflightSucceeded).IsTrue() {
+		t.Errorf("PreflightSucceeded = %v, want True", instance.Status.GetCondition(servingv1alpha1.PreflightSucceeded))
+	}
+}
+
+func TestPreflightFails(t *testing.T) {
+	d := newPreflightDiscovery()
+	d.version = version.Info{Major: "1", Minor: "10", GitVersion: "v1.10.0"}
+	d.groupVersions = []string{"admissionregistration.k8s.io/v1beta1"}
+	instance := newInstance(withCertManager(""))
+	r := newPreflightReconciler(t, instance, testManifest+"\n---"+claimManifest, d)
+
+	if err := r.preflight(instance); err == nil {
+		t.Fatal("expected preflight() to fail")
+	}
+	condition := instance.Status.GetCondition(servingv1alpha1.PreflightSucceeded)
+	if !condition.IsFalse() {
+		t.Fatalf("PreflightSucceeded = %v, want False", condition)
+	}
+	for _, want := range []string{
+		"Kubernetes v1.10.0 is older than 1.11",
+		"the autoscaling/v2beta2 API isn't served",
+		"the networking.istio.io/v1alpha3 API isn't served",
+		"no default StorageClass",
+	} {
+		if !strings.Contains(condition.Message, want) {
+			t.Errorf("Message = %q, want it to contain %q", condition.Message, want)
+		}
+	}
+	if instance.Status.IsReady() {
+		t.Error("expected a failed preflight to affect readiness")
+	}
+}
+
+func TestPreflightDefaultStorageClass(t *testing.T) {
+	instance := newInstance(withCertManager(""))
+	r := newPreflightReconciler(t, instance, testManifest+"\n---"+claimManifest, newPreflightDiscovery())
+	class := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "standard",
+			Annotations: map[string]string{defaultStorageClassAnnotation: "true"},
+		},
+	}
+	if err := r.client.Create(context.TODO(), class); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+
+	if err := r.preflight(instance); err != nil {
+		t.Fatalf("preflight() = %v", err)
+	}
+}
+
+func TestPreflightRequiredGroupVersions(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Ingress:            &servingv1alpha1.IngressConfigs{Kourier: servingv1alpha1.KourierIngressConfiguration{Enabled: true}},
+			DisabledComponents: []string{servingv1alpha1.AutoscalerHPAComponent},
+		},
+	}
+	got := strings.Join(requiredGroupVersions(instance), ",")
+	if want := "admissionregistration.k8s.io/v1beta1"; got != want {
+		t.Errorf("requiredGroupVersions() = %s, want %s", got, want)
+	}
+}
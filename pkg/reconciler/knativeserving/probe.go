@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// The Secret the Serving webhook provisions its certificate into
+	webhookCertsSecret = "webhook-certs"
+	webhookCACertKey   = "ca-cert.pem"
+	// How soon a failed probe is retried
+	probeInterval = 5 * time.Second
+)
+
+var probeServing = flag.Bool("probe-serving", true,
+	"Only report a KnativeServing Ready once its webhook and controller respond; "+
+		"disable when the operator runs outside the cluster and can't reach their services")
+
+// prober requests the URL, trusting the CA certificate if any, and
+// fails unless it responds
+type prober func(url string, caCert []byte) error
+
+// Probe the webhook and controller services of an available install.
+// The webhook is only trusted with the CA of the certificate it
+// provisioned, so it doesn't respond until it has.
+func (r *ReconcileKnativeServing) probeServing(instance *servingv1alpha1.KnativeServing) error {
+	if !instance.Status.IsAvailable() {
+		return nil
+	}
+	if crdsOnly(instance) {
+		return r.probeCRDs(instance)
+	}
+	if r.probe == nil || instance.Spec.Hibernate {
+		instance.Status.MarkProbesSucceeded()
+		return r.updateStatus(instance)
+	}
+	namespace := instance.InstallNamespace()
+	secret := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: webhookCertsSecret}, secret)
+	switch {
+	case errors.IsNotFound(err) || (err == nil && len(secret.Data[webhookCACertKey]) == 0):
+		err = fmt.Errorf("the webhook certificate isn't provisioned yet")
+	case err == nil:
+		if err = r.probe(fmt.Sprintf("https://webhook.%s.svc/", namespace), secret.Data[webhookCACertKey]); err != nil {
+			err = fmt.Errorf("the webhook doesn't respond: %v", err)
+		}
+	default:
+		return err
+	}
+	if err == nil {
+		if err = r.probe(fmt.Sprintf("http://controller.%s.svc:%d/metrics", namespace, metricsPort), nil); err != nil {
+			err = fmt.Errorf("the controller doesn't respond: %v", err)
+		}
+	}
+	if err != nil {
+		log.Info("Probe failed", "error", err.Error())
+		instance.Status.MarkProbesFailed(err.Error())
+	} else {
+		instance.Status.MarkProbesSucceeded()
+	}
+	return r.updateStatus(instance)
+}
+
+// Probe again soon, since nothing else triggers a reconcile
+func requeueProbe(instance *servingv1alpha1.KnativeServing, result reconcile.Result) reconcile.Result {
+	if !instance.Status.GetCondition(servingv1alpha1.ProbesSucceeded).IsFalse() || result.Requeue {
+		return result
+	}
+	if result.RequeueAfter == 0 || result.RequeueAfter > probeInterval {
+		result.RequeueAfter = probeInterval
+	}
+	return result
+}
+
+func newProber() prober {
+	if !*probeServing {
+		return nil
+	}
+	return httpProbe
+}
+
+// Any response will do, even an error status: it's served
+func httpProbe(url string, caCert []byte) error {
+	transport := &http.Transport{}
+	if caCert != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return fmt.Errorf("invalid CA certificate")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	c := &http.Client{Transport: transport, Timeout: probeInterval}
+	resp, err := c.Get(url)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
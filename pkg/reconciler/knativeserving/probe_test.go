@@ -0,0 +1,85 @@
+package knativeserving
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestProbeServing(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand}}
+	instance.Status.InitializeConditions()
+	instance.Status.MarkDeploymentsAvailable()
+	r, _ := newTestReconciler(instance)
+	probed := map[string][]byte{}
+	var failing error
+	r.probe = func(url string, caCert []byte) error {
+		probed[url] = caCert
+		return failing
+	}
+
+	if err := r.probeServing(instance); err != nil {
+		t.Fatalf("probeServing() = %v", err)
+	}
+	if !instance.Status.GetCondition(servingv1alpha1.ProbesSucceeded).IsFalse() || len(probed) != 0 {
+		t.Errorf("expected the probes to fail without the webhook certificate, probed %v", probed)
+	}
+	if got := requeueProbe(instance, reconcile.Result{}); got.RequeueAfter != probeInterval {
+		t.Errorf("requeueProbe() = %+v, want a requeue after %v", got, probeInterval)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: webhookCertsSecret},
+		Data:       map[string][]byte{webhookCACertKey: []byte("ca")},
+	}
+	if err := r.client.Create(context.TODO(), secret); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	failing = fmt.Errorf("connection refused")
+	if err := r.probeServing(instance); err != nil {
+		t.Fatalf("probeServing() = %v", err)
+	}
+	if !instance.Status.GetCondition(servingv1alpha1.ProbesSucceeded).IsFalse() {
+		t.Error("expected the probes to fail while the webhook doesn't respond")
+	}
+
+	failing = nil
+	if err := r.probeServing(instance); err != nil {
+		t.Fatalf("probeServing() = %v", err)
+	}
+	if !instance.Status.GetCondition(servingv1alpha1.ProbesSucceeded).IsTrue() {
+		t.Error("expected the probes to succeed")
+	}
+	if ca := probed["https://webhook.knative-serving.svc/"]; string(ca) != "ca" {
+		t.Errorf("webhook probed with CA %q, want the provisioned one", ca)
+	}
+	if _, ok := probed["http://controller.knative-serving.svc:9090/metrics"]; !ok {
+		t.Errorf("probed %v, want the controller's metrics", probed)
+	}
+	if got := requeueProbe(instance, reconcile.Result{}); got != (reconcile.Result{}) {
+		t.Errorf("requeueProbe() = %+v, want no requeue once responsive", got)
+	}
+}
+
+func TestHTTPProbe(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+
+	if err := httpProbe(ts.URL, ca); err != nil {
+		t.Errorf("httpProbe() = %v, want any response to do", err)
+	}
+	if err := httpProbe(ts.URL, nil); err == nil {
+		t.Error("expected an untrusted certificate to fail the probe")
+	}
+}
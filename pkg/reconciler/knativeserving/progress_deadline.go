@@ -0,0 +1,63 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"flag"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// How often deployments that aren't available are checked again
+	rolloutInterval = 10 * time.Second
+)
+
+var progressDeadline = flag.Duration("deployment-progress-deadline", 10*time.Minute,
+	"How long deployments may stay unavailable before DeploymentsAvailable reports ProgressDeadlineExceeded; "+
+		"disabled if 0")
+
+// Mark the deployments not ready, or past their progress deadline once
+// they've been waited on for longer than it
+func (r *ReconcileKnativeServing) markDeploymentsNotReady(instance *servingv1alpha1.KnativeServing, names []string) {
+	condition := instance.Status.GetCondition(servingv1alpha1.DeploymentsAvailable)
+	switch {
+	case !condition.IsFalse() || r.progressDeadline <= 0:
+		instance.Status.MarkDeploymentsNotReady()
+	case instance.Status.IsProgressDeadlineExceeded():
+		// Already reported
+	case time.Since(condition.LastTransitionTime.Inner.Time) > r.progressDeadline:
+		log.Info("Deployments progress deadline exceeded", "deadline", r.progressDeadline, "deployments", names)
+		instance.Status.MarkDeploymentsProgressDeadlineExceeded(r.progressDeadline, names)
+		r.recorder.Eventf(instance, v1.EventTypeWarning, "ProgressDeadlineExceeded",
+			"Deployments not available within %v", r.progressDeadline)
+	}
+}
+
+// Check the deployments again soon, rather than wait on their events,
+// which a stalled rollout may never send
+func requeueRollout(instance *servingv1alpha1.KnativeServing, result reconcile.Result) reconcile.Result {
+	if !instance.Status.GetCondition(servingv1alpha1.DeploymentsAvailable).IsFalse() || result.Requeue {
+		return result
+	}
+	if result.RequeueAfter == 0 || result.RequeueAfter > rolloutInterval {
+		result.RequeueAfter = rolloutInterval
+	}
+	return result
+}
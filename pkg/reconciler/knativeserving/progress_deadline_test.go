@@ -0,0 +1,110 @@
+package knativeserving
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/apis"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Backdate the transition of DeploymentsAvailable
+func backdateDeployments(instance *servingv1alpha1.KnativeServing, age time.Duration) {
+	conditions := instance.Status.GetConditions()
+	for i := range conditions {
+		if conditions[i].Type == servingv1alpha1.DeploymentsAvailable {
+			conditions[i].LastTransitionTime = apis.VolatileTime{Inner: metav1.NewTime(time.Now().Add(-age))}
+		}
+	}
+	instance.Status.SetConditions(conditions)
+}
+
+func TestCheckDeploymentsProgressDeadline(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+	}
+	instance.Status.InitializeConditions()
+	c := newFakeClient(newTestScheme(), instance.DeepCopy())
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, recorder: recorder, config: newTestManifest(t, testManifest, c),
+		progressDeadline: 10 * time.Minute}
+
+	if err := r.checkDeployments(instance); err != nil {
+		t.Fatalf("checkDeployments() = %v", err)
+	}
+	expectEvent(t, recorder, "Normal DeploymentsNotReady")
+	if instance.Status.IsProgressDeadlineExceeded() {
+		t.Fatal("expected the deadline not to be exceeded yet")
+	}
+
+	// Within the deadline
+	backdateDeployments(instance, 5*time.Minute)
+	if err := r.checkDeployments(instance); err != nil {
+		t.Fatalf("checkDeployments() = %v", err)
+	}
+	if instance.Status.IsProgressDeadlineExceeded() {
+		t.Fatal("expected the deadline not to be exceeded yet")
+	}
+	expectNoEvent(t, recorder)
+
+	backdateDeployments(instance, 11*time.Minute)
+	if err := r.checkDeployments(instance); err != nil {
+		t.Fatalf("checkDeployments() = %v", err)
+	}
+	if !instance.Status.IsProgressDeadlineExceeded() {
+		t.Fatalf("DeploymentsAvailable = %+v, want ProgressDeadlineExceeded",
+			instance.Status.GetCondition(servingv1alpha1.DeploymentsAvailable))
+	}
+	expectEvent(t, recorder, "Warning ProgressDeadlineExceeded")
+
+	// Reported once
+	if err := r.checkDeployments(instance); err != nil {
+		t.Fatalf("checkDeployments() = %v", err)
+	}
+	if !instance.Status.IsProgressDeadlineExceeded() {
+		t.Error("expected the deadline to stay exceeded")
+	}
+	expectNoEvent(t, recorder)
+}
+
+func TestCheckDeploymentsWithoutProgressDeadline(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+	}
+	instance.Status.InitializeConditions()
+	instance.Status.MarkDeploymentsNotReady()
+	backdateDeployments(instance, 24*time.Hour)
+	c := newFakeClient(newTestScheme(), instance.DeepCopy())
+	r := &ReconcileKnativeServing{client: c, recorder: record.NewFakeRecorder(10), config: newTestManifest(t, testManifest, c)}
+
+	if err := r.checkDeployments(instance); err != nil {
+		t.Fatalf("checkDeployments() = %v", err)
+	}
+	if instance.Status.IsProgressDeadlineExceeded() {
+		t.Error("expected no deadline when disabled")
+	}
+}
+
+func TestRequeueRollout(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{}
+	instance.Status.InitializeConditions()
+	if got := requeueRollout(instance, reconcile.Result{}); got.RequeueAfter != 0 {
+		t.Errorf("requeueRollout() = %+v, want no requeue before any deployment is checked", got)
+	}
+
+	instance.Status.MarkDeploymentsNotReady()
+	if got := requeueRollout(instance, reconcile.Result{}); got.RequeueAfter != rolloutInterval {
+		t.Errorf("requeueRollout() = %+v, want a requeue after %v", got, rolloutInterval)
+	}
+	if got := requeueRollout(instance, reconcile.Result{RequeueAfter: time.Second}); got.RequeueAfter != time.Second {
+		t.Errorf("requeueRollout() = %+v, want the sooner requeue kept", got)
+	}
+
+	instance.Status.MarkDeploymentsAvailable()
+	if got := requeueRollout(instance, reconcile.Result{}); got.RequeueAfter != 0 {
+		t.Errorf("requeueRollout() = %+v, want no requeue once available", got)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"strings"
+
+	mf "github.com/jcrossley3/manifestival"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Marks every resource the operator applies with the release it came from
+	releaseLabel = "operator.knative.dev/release"
+)
+
+// Label the resources so they can be pruned once a later manifest drops them
+func labelRelease(resources []unstructured.Unstructured, release string) {
+	for i := range resources {
+		u := &resources[i]
+		l := u.GetLabels()
+		if l == nil {
+			l = map[string]string{}
+		}
+		l[releaseLabel] = release
+		u.SetLabels(l)
+	}
+}
+
+// Delete the labeled resources that the applied manifest no longer
+// contains, e.g. those dropped by a newer release or belonging to a
+// previously selected ingress, along with those the last install
+// recorded in the status. Only the kinds the operator may have applied
+// are searched for labels.
+func (r *ReconcileKnativeServing) prune(instance *servingv1alpha1.KnativeServing, manifest *mf.Manifest) error {
+	current := map[string]bool{}
+	for i := range manifest.Resources {
+		current[resourceKey(&manifest.Resources[i])] = true
+	}
+	kinds := map[schema.GroupVersionKind]bool{}
+	for _, u := range append(r.allResources(instance.InstallNamespace()), manifest.Resources...) {
+		kinds[u.GroupVersionKind()] = true
+	}
+	selector, err := labels.Parse(releaseLabel)
+	if err != nil {
+		return err
+	}
+	for gvk := range kinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := r.client.List(context.TODO(), &client.ListOptions{LabelSelector: selector}, list); err != nil {
+			if meta.IsNoMatchError(err) {
+				// The API was never installed
+				continue
+			}
+			return err
+		}
+		for i := range list.Items {
+			u := &list.Items[i]
+			if current[resourceKey(u)] {
+				continue
+			}
+			if err := r.pruneResource(instance, manifest, u); err != nil {
+				return err
+			}
+			current[resourceKey(u)] = true
+		}
+	}
+	// The last install recorded what it applied, labeled or not
+	for _, applied := range instance.Status.Resources {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(applied.APIVersion)
+		u.SetKind(applied.Kind)
+		u.SetNamespace(applied.Namespace)
+		u.SetName(applied.Name)
+		if current[resourceKey(u)] {
+			continue
+		}
+		existing, err := manifest.Get(u)
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return err
+		}
+		if existing == nil {
+			continue
+		}
+		if err := r.pruneResource(instance, manifest, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ReconcileKnativeServing) pruneResource(instance *servingv1alpha1.KnativeServing, manifest *mf.Manifest, u *unstructured.Unstructured) error {
+	if u.GetKind() == "Namespace" {
+		keep, err := r.keepsNamespace(instance, u.GetName())
+		if err != nil || keep {
+			return err
+		}
+	}
+	log.Info("Pruning", "kind", u.GetKind(), "namespace", u.GetNamespace(), "name", u.GetName())
+	if err := manifest.Delete(u); err != nil {
+		return err
+	}
+	r.recorder.Eventf(instance, v1.EventTypeNormal, "ObsoleteResourceDeleted",
+		"Deleted obsolete %s %s", u.GetKind(), strings.TrimPrefix(u.GetNamespace()+"/"+u.GetName(), "/"))
+	return nil
+}
+
+// Whether the namespace must survive pruning: with only the CRDs
+// installed, the manifest has no Namespace to compare with, and a
+// namespace holding a KnativeServing, the instance's own included,
+// would take the KnativeServing with it
+func (r *ReconcileKnativeServing) keepsNamespace(instance *servingv1alpha1.KnativeServing, name string) (bool, error) {
+	if crdsOnly(instance) || name == instance.GetNamespace() {
+		return true, nil
+	}
+	list := &servingv1alpha1.KnativeServingList{}
+	if err := r.client.List(context.TODO(), &client.ListOptions{Namespace: name}, list); err != nil {
+		return false, err
+	}
+	return len(list.Items) > 0, nil
+}
+
+func resourceKey(u *unstructured.Unstructured) string {
+	gvk := u.GroupVersionKind()
+	return strings.Join([]string{gvk.Group, gvk.Kind, u.GetNamespace(), u.GetName()}, "/")
+}
@@ -0,0 +1,129 @@
+package knativeserving
+
+import (
+	"context"
+	"testing"
+
+	mf "github.com/jcrossley3/manifestival"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newReleaseConfigMap(name, release string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("ConfigMap")
+	u.SetNamespace(operand)
+	u.SetName(name)
+	if release != "" {
+		u.SetLabels(map[string]string{releaseLabel: release})
+	}
+	return u
+}
+
+func TestLabelRelease(t *testing.T) {
+	resources := []unstructured.Unstructured{*newReleaseConfigMap("config-network", "")}
+	resources[0].SetLabels(map[string]string{"app": "kept"})
+	labelRelease(resources, "0.7.0")
+	labels := resources[0].GetLabels()
+	if labels[releaseLabel] != "0.7.0" {
+		t.Errorf("release label not set: %v", labels)
+	}
+	if labels["app"] != "kept" {
+		t.Errorf("existing labels not preserved: %v", labels)
+	}
+}
+
+func TestPruneDeletesObsoleteResources(t *testing.T) {
+	c := newFakeClient(newTestScheme(),
+		newReleaseConfigMap("config-network", "v0.6.0"),
+		newReleaseConfigMap("config-obsolete", "v0.6.0"),
+		newReleaseConfigMap("config-unmanaged", ""))
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, recorder: recorder}
+	manifest := newTestManifest(t, testManifest, c)
+	instance := &servingv1alpha1.KnativeServing{}
+
+	if err := r.prune(instance, &manifest); err != nil {
+		t.Fatalf("prune() = %v", err)
+	}
+	expectEvent(t, recorder, "Normal ObsoleteResourceDeleted Deleted obsolete ConfigMap knative-serving/config-obsolete")
+	expectNoEvent(t, recorder)
+
+	for name, kept := range map[string]bool{"config-network": true, "config-obsolete": false, "config-unmanaged": true} {
+		err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: name}, newReleaseConfigMap(name, ""))
+		if kept && err != nil {
+			t.Errorf("%s should be kept: %v", name, err)
+		}
+		if !kept && err == nil {
+			t.Errorf("%s should be pruned", name)
+		}
+	}
+}
+
+func TestPruneDeletesRecordedResources(t *testing.T) {
+	c := newFakeClient(newTestScheme(),
+		newReleaseConfigMap("config-network", ""),
+		newReleaseConfigMap("config-unlabeled", ""))
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, recorder: recorder}
+	manifest := newTestManifest(t, testManifest, c)
+	instance := &servingv1alpha1.KnativeServing{}
+	instance.Status.Resources = []servingv1alpha1.AppliedResource{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: operand, Name: "config-network"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: operand, Name: "config-unlabeled"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: operand, Name: "config-gone"},
+	}
+
+	if err := r.prune(instance, &manifest); err != nil {
+		t.Fatalf("prune() = %v", err)
+	}
+	expectEvent(t, recorder, "Normal ObsoleteResourceDeleted Deleted obsolete ConfigMap knative-serving/config-unlabeled")
+	expectNoEvent(t, recorder)
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "config-network"}, newReleaseConfigMap("", "")); err != nil {
+		t.Errorf("config-network should be kept: %v", err)
+	}
+}
+
+func TestPruneKeepsNamespacesOfKnativeServings(t *testing.T) {
+	labeled := func(name string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{releaseLabel: "v0.7.0"}}}
+	}
+	// Installed into its own namespace, then spec.namespace moved it
+	instance := newInstance(withSpec(servingv1alpha1.KnativeServingSpec{Namespace: "serving-system"}))
+	instance.Status.Version = "0.7.0"
+	other := newInstance()
+	other.Namespace = "other"
+	c := newFakeClient(newTestScheme(), instance, other,
+		labeled(operand), labeled("other"), labeled("obsolete"), labeled("serving-system"))
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, recorder: recorder, config: newTestManifest(t, testManifest, c)}
+	manifest := newTestManifest(t, testManifest, c)
+	manifest.Transform(mf.InjectNamespace("serving-system"))
+
+	if err := r.prune(instance, &manifest); err != nil {
+		t.Fatalf("prune() = %v", err)
+	}
+	expectEvent(t, recorder, "Normal ObsoleteResourceDeleted Deleted obsolete Namespace obsolete")
+	expectNoEvent(t, recorder)
+	for _, name := range []string{operand, "other", "serving-system"} {
+		if err := c.Get(context.TODO(), client.ObjectKey{Name: name}, &corev1.Namespace{}); err != nil {
+			t.Errorf("namespace %s should be kept: %v", name, err)
+		}
+	}
+
+	// Only the CRDs leave no Namespace in the manifest to compare with
+	instance.Spec.CRDsOnly = true
+	manifest.Resources = onlyCRDs(manifest.Resources)
+	if err := r.prune(instance, &manifest); err != nil {
+		t.Fatalf("prune() = %v", err)
+	}
+	expectNoEvent(t, recorder)
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: "serving-system"}, &corev1.Namespace{}); err != nil {
+		t.Errorf("the install namespace should be kept with only the CRDs: %v", err)
+	}
+}
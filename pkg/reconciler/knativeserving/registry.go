@@ -0,0 +1,368 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	dockerHub                 = "index.docker.io"
+	registryTimeout           = 30 * time.Second
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+)
+
+// The manifest media types a tag may resolve to, image indexes first
+// so that a multi-arch image pins to its index rather than the
+// platform the registry picks
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// imageReference is a parsed image name, e.g.
+// gcr.io/knative-releases/controller:v0.7.0
+type imageReference struct {
+	registry   string
+	repository string
+	tag        string
+	digest     string
+}
+
+// Parse the image the way the container runtime would, defaulting
+// to Docker Hub and the latest tag
+func parseImageReference(image string) (imageReference, error) {
+	ref := imageReference{}
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.digest = name[:i], name[i+1:]
+		if !strings.HasPrefix(ref.digest, "sha256:") {
+			return ref, fmt.Errorf("unsupported digest in image %q", image)
+		}
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.tag = name[:i], name[i+1:]
+	}
+	if ref.tag == "" && ref.digest == "" {
+		ref.tag = "latest"
+	}
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.registry, ref.repository = parts[0], parts[1]
+	} else {
+		ref.registry, ref.repository = dockerHub, name
+		if len(parts) == 1 {
+			ref.repository = "library/" + name
+		}
+	}
+	if ref.repository == "" || strings.ToLower(ref.repository) != ref.repository {
+		return ref, fmt.Errorf("invalid image %q", image)
+	}
+	return ref, nil
+}
+
+// The name of the image without its tag or digest
+func (ref imageReference) name() string {
+	return ref.registry + "/" + ref.repository
+}
+
+// registryCredentials authenticate to a registry, as found in an
+// image pull secret
+type registryCredentials struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+func (c registryCredentials) basicAuth() (string, string, bool) {
+	if c.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(c.Auth)
+		if err != nil {
+			return "", "", false
+		}
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return "", "", false
+		}
+		return parts[0], parts[1], true
+	}
+	return c.Username, c.Password, c.Username != ""
+}
+
+// Parse the .dockerconfigjson or legacy .dockercfg of an image pull
+// secret into the credentials by registry host
+func parseDockerConfig(data []byte, legacy bool) (map[string]registryCredentials, error) {
+	auths := map[string]registryCredentials{}
+	var err error
+	if legacy {
+		err = json.Unmarshal(data, &auths)
+	} else {
+		config := struct {
+			Auths map[string]registryCredentials `json:"auths"`
+		}{}
+		err = json.Unmarshal(data, &config)
+		auths = config.Auths
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]registryCredentials, len(auths))
+	for server, creds := range auths {
+		// Keys may be URLs, e.g. https://index.docker.io/v1/
+		host := server
+		if u, err := url.Parse(server); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		host = strings.SplitN(host, "/", 2)[0]
+		if host == "docker.io" || host == "registry-1.docker.io" {
+			host = dockerHub
+		}
+		result[host] = creds
+	}
+	return result, nil
+}
+
+// registryClient speaks the registry HTTP API v2, authenticating with
+// the credentials of each registry if any, anonymously otherwise
+type registryClient struct {
+	client      *http.Client
+	credentials map[string]registryCredentials
+	// Bearer tokens, by repository
+	tokens map[string]string
+}
+
+func newRegistryClient(credentials map[string]registryCredentials) *registryClient {
+	return &registryClient{
+		client:      &http.Client{Timeout: registryTimeout},
+		credentials: credentials,
+		tokens:      map[string]string{},
+	}
+}
+
+// Resolve the reference to the digest of the manifest it names
+func (c *registryClient) digest(ref imageReference) (string, error) {
+	if ref.digest != "" {
+		return ref.digest, nil
+	}
+	resp, err := c.do(ref, http.MethodHead, "manifests/"+ref.tag, manifestMediaTypes)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if digest := resp.Header.Get("Docker-Content-Digest"); strings.HasPrefix(digest, "sha256:") {
+		return digest, nil
+	}
+	// Not every registry sets the header, hash the manifest instead
+	resp, err = c.do(ref, http.MethodGet, "manifests/"+ref.tag, manifestMediaTypes)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, resp.Body); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
+
+// cosignSignature is a signed payload and its signature
+type cosignSignature struct {
+	payload   []byte
+	signature []byte
+}
+
+// Fetch the cosign signatures of the digest, stored in the image
+// repository under the tag derived from the digest
+func (c *registryClient) signatures(ref imageReference, digest string) ([]cosignSignature, error) {
+	tag := strings.Replace(digest, ":", "-", 1) + ".sig"
+	resp, err := c.do(ref, http.MethodGet, "manifests/"+tag, manifestMediaTypes[2:])
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	manifest := struct {
+		Layers []struct {
+			Digest      string            `json:"digest"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"layers"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid signature manifest %s:%s: %v", ref.name(), tag, err)
+	}
+	var result []cosignSignature
+	for _, layer := range manifest.Layers {
+		encoded, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+		signature, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+		payload, err := c.blob(ref, layer.Digest)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, cosignSignature{payload: payload, signature: signature})
+	}
+	return result, nil
+}
+
+// Fetch a blob, checking its content matches the digest
+func (c *registryClient) blob(ref imageReference, digest string) ([]byte, error) {
+	resp, err := c.do(ref, http.MethodGet, "blobs/"+digest, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if got := fmt.Sprintf("sha256:%x", sha256.Sum256(data)); got != digest {
+		return nil, fmt.Errorf("blob %s@%s has digest %s", ref.name(), digest, got)
+	}
+	return data, nil
+}
+
+// Request the path of the repository, authenticating once challenged
+func (c *registryClient) do(ref imageReference, method, path string, accept []string) (*http.Response, error) {
+	u := fmt.Sprintf("%s://%s/v2/%s/%s", registryScheme(ref.registry), ref.registry, ref.repository, path)
+	var resp *http.Response
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := http.NewRequest(method, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(accept) > 0 {
+			req.Header.Set("Accept", strings.Join(accept, ","))
+		}
+		if token, ok := c.tokens[ref.name()]; ok {
+			req.Header.Set("Authorization", token)
+		}
+		resp, err = c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusUnauthorized || attempt > 0 {
+			break
+		}
+		resp.Body.Close()
+		if err := c.authenticate(ref, resp.Header.Get("WWW-Authenticate")); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s", method, u, resp.Status)
+	}
+	return resp, nil
+}
+
+// Answer the challenge of the registry with the credentials of the
+// registry, or an anonymous token
+func (c *registryClient) authenticate(ref imageReference, challenge string) error {
+	creds := c.credentials[ref.registry]
+	username, password, hasCreds := creds.basicAuth()
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if !hasCreds {
+			return fmt.Errorf("no credentials for registry %s", ref.registry)
+		}
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(username, password)
+		c.tokens[ref.name()] = req.Header.Get("Authorization")
+		return nil
+	case "bearer":
+	default:
+		return fmt.Errorf("unsupported authentication challenge %q from registry %s", challenge, ref.registry)
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return fmt.Errorf("invalid token realm %q from registry %s", params["realm"], ref.registry)
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", ref.repository))
+	realm.RawQuery = query.Encode()
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if hasCreds {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("token request to %s: %s", realm.Host, resp.Status)
+	}
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return err
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	c.tokens[ref.name()] = "Bearer " + token.Token
+	return nil
+}
+
+// Split a WWW-Authenticate header into its lowercased scheme and its
+// parameters, e.g. Bearer realm="https://gcr.io/v2/token",service="gcr.io"
+func parseChallenge(challenge string) (string, map[string]string) {
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	params := map[string]string{}
+	if len(parts) == 2 {
+		for _, param := range strings.Split(parts[1], ",") {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 {
+				params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+			}
+		}
+	}
+	return strings.ToLower(parts[0]), params
+}
+
+// Registries on the loopback interface, e.g. a local development
+// registry, are spoken to in plain text, like the container runtime
+func registryScheme(registry string) string {
+	host := strings.SplitN(registry, ":", 2)[0]
+	if host == "localhost" || host == "127.0.0.1" {
+		return "http"
+	}
+	return "https"
+}
@@ -0,0 +1,77 @@
+package knativeserving
+
+import (
+	"testing"
+)
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image   string
+		want    imageReference
+		wantErr bool
+	}{{
+		image: "gcr.io/knative-releases/controller:v0.7.0",
+		want:  imageReference{registry: "gcr.io", repository: "knative-releases/controller", tag: "v0.7.0"},
+	}, {
+		image: "gcr.io/knative-releases/queue@sha256:e007c0a7",
+		want:  imageReference{registry: "gcr.io", repository: "knative-releases/queue", digest: "sha256:e007c0a7"},
+	}, {
+		image: "localhost:5000/controller",
+		want:  imageReference{registry: "localhost:5000", repository: "controller", tag: "latest"},
+	}, {
+		image: "istio/proxyv2:1.1.7",
+		want:  imageReference{registry: dockerHub, repository: "istio/proxyv2", tag: "1.1.7"},
+	}, {
+		image: "busybox",
+		want:  imageReference{registry: dockerHub, repository: "library/busybox", tag: "latest"},
+	}, {
+		image:   "gcr.io/knative-releases/queue@md5:e007c0a7",
+		wantErr: true,
+	}, {
+		image:   "gcr.io/Knative/Controller",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			got, err := parseImageReference(tt.image)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseImageReference() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseImageReference() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDockerConfig(t *testing.T) {
+	config := `{"auths":{"https://index.docker.io/v1/":{"auth":"dXNlcjpzZWNyZXQ="},"quay.io":{"username":"robot","password":"token"}}}`
+	got, err := parseDockerConfig([]byte(config), false)
+	if err != nil {
+		t.Fatalf("parseDockerConfig() = %v", err)
+	}
+	if user, pass, ok := got[dockerHub].basicAuth(); !ok || user != "user" || pass != "secret" {
+		t.Errorf("Docker Hub credentials = %q, %q, %v, want user, secret", user, pass, ok)
+	}
+	if user, pass, ok := got["quay.io"].basicAuth(); !ok || user != "robot" || pass != "token" {
+		t.Errorf("quay.io credentials = %q, %q, %v, want robot, token", user, pass, ok)
+	}
+
+	legacy, err := parseDockerConfig([]byte(`{"gcr.io":{"auth":"X2pzb25fa2V5Ont9"}}`), true)
+	if err != nil {
+		t.Fatalf("parseDockerConfig() = %v", err)
+	}
+	if user, _, ok := legacy["gcr.io"].basicAuth(); !ok || user != "_json_key" {
+		t.Errorf("gcr.io user = %q, want _json_key", user)
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://gcr.io/v2/token",service="gcr.io",scope="repository:knative:pull"`)
+	if scheme != "bearer" {
+		t.Errorf("scheme = %q, want bearer", scheme)
+	}
+	if params["realm"] != "https://gcr.io/v2/token" || params["service"] != "gcr.io" {
+		t.Errorf("params = %v", params)
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	mf "github.com/jcrossley3/manifestival"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// A bundled release is a file or directory named for its version,
+	// e.g. 0.7.0.yaml
+	releaseName = regexp.MustCompile(`^v?(\d+\.\d+\.\d+)(\.yaml|\.yml)?$`)
+	semver      = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)$`)
+)
+
+// Load the releases at path, keyed by version. A directory holding
+// nothing but releases provides each of them, anything else is the
+// operator's own release. Subdirectories of a release aren't parsed,
+// --manifest lists them instead.
+func loadReleases(path string, c client.Client) (map[string]mf.Manifest, error) {
+	result := map[string]mf.Manifest{}
+	if names := releaseEntries(path); len(names) > 0 {
+		for name, v := range names {
+			m, err := mf.NewManifest(filepath.Join(path, name), false, c)
+			if err != nil {
+				return nil, err
+			}
+			result[v] = m
+		}
+		return result, nil
+	}
+	m, err := mf.NewManifest(path, false, c)
+	if err != nil {
+		return nil, err
+	}
+	result[version.Version] = m
+	return result, nil
+}
+
+// The entries of the directory mapped to their versions, if every one
+// of them is named for a release
+func releaseEntries(path string) map[string]string {
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return nil
+	}
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil
+	}
+	result := map[string]string{}
+	for _, e := range entries {
+		m := releaseName.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil
+		}
+		result[e.Name()] = m[1]
+	}
+	return result
+}
+
+// The bundled versions, in order
+func (r *ReconcileKnativeServing) versions() []string {
+	var result []string
+	for v := range r.releases {
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return compareVersions(result[i], result[j]) < 0
+	})
+	return result
+}
+
+// The release the spec asks for, the operator's own by default
+func targetVersion(instance *servingv1alpha1.KnativeServing) string {
+	if v := instance.Spec.Version; v != "" {
+		return strings.TrimPrefix(v, "v")
+	}
+	return version.Version
+}
+
+// The bundled manifest of the target release
+func (r *ReconcileKnativeServing) manifestFor(instance *servingv1alpha1.KnativeServing) (mf.Manifest, error) {
+	v := targetVersion(instance)
+	if m, ok := r.releases[v]; ok {
+		return m, nil
+	}
+	if v == version.Version {
+		return r.config, nil
+	}
+	if len(r.releases) < 2 {
+		// Nothing to choose from
+		return mf.Manifest{}, permanent(fmt.Errorf("version %s is not bundled, only the operator's own release %s is, see ./hack/update-serving.sh", v, version.Version))
+	}
+	return mf.Manifest{}, permanent(fmt.Errorf("version %s is not bundled, available: %s", v, strings.Join(r.versions(), ", ")))
+}
+
+// Only an upgrade to the next minor or major release, or to a later
+// patch of the installed one, is supported. Versions that aren't
+// major.minor.patch can't be judged, so they're let through.
+func checkVersionHop(from, to string) error {
+	f, fok := parseVersion(from)
+	t, tok := parseVersion(to)
+	if !fok || !tok {
+		log.Info("Unable to check the upgrade path", "from", from, "to", to)
+		return nil
+	}
+	if compareVersions(to, from) < 0 {
+		return fmt.Errorf("downgrading from %s to %s is not supported", from, to)
+	}
+	if t[0] == f[0] && (t[1] == f[1] || t[1] == f[1]+1) {
+		return nil
+	}
+	if t[0] == f[0]+1 && t[1] == 0 {
+		return nil
+	}
+	return fmt.Errorf("upgrading from %s to %s is not supported, upgrade one minor version at a time", from, to)
+}
+
+func parseVersion(v string) ([3]int, bool) {
+	var result [3]int
+	m := semver.FindStringSubmatch(v)
+	if m == nil {
+		return result, false
+	}
+	for i := range result {
+		result[i], _ = strconv.Atoi(m[i+1])
+	}
+	return result, true
+}
+
+// Compare versions numerically, falling back to their strings
+func compareVersions(a, b string) int {
+	x, xok := parseVersion(a)
+	y, yok := parseVersion(b)
+	if !xok || !yok {
+		return strings.Compare(a, b)
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			if x[i] < y[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
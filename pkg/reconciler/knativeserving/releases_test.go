@@ -0,0 +1,154 @@
+package knativeserving
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	mf "github.com/jcrossley3/manifestival"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/version"
+)
+
+func writeReleases(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "releases")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadReleases(t *testing.T) {
+	dir := writeReleases(t, map[string]string{
+		"0.6.0.yaml":  upgradeManifest,
+		"v0.7.0.yaml": testManifest,
+	})
+	defer os.RemoveAll(dir)
+
+	releases, err := loadReleases(dir, newFakeClient(newTestScheme()))
+	if err != nil {
+		t.Fatalf("loadReleases() = %v", err)
+	}
+	r := &ReconcileKnativeServing{releases: releases}
+	if got, want := r.versions(), []string{"0.6.0", "0.7.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("versions() = %v, want %v", got, want)
+	}
+	if got := len(releases["0.6.0"].Resources); got != 4 {
+		t.Errorf("0.6.0 has %d resources, want 4", got)
+	}
+}
+
+func TestLoadReleasesSingleManifest(t *testing.T) {
+	dir := writeReleases(t, map[string]string{"serving.yaml": testManifest})
+	defer os.RemoveAll(dir)
+
+	releases, err := loadReleases(dir, newFakeClient(newTestScheme()))
+	if err != nil {
+		t.Fatalf("loadReleases() = %v", err)
+	}
+	if len(releases) != 1 || len(releases[version.Version].Resources) != 4 {
+		t.Errorf("expected the manifest as release %s, got %v", version.Version, releases)
+	}
+}
+
+func TestBundledReleases(t *testing.T) {
+	releases, err := loadReleases("../../../cmd/manager/kodata/knative-serving", newFakeClient(newTestScheme()))
+	if err != nil {
+		t.Fatalf("loadReleases() = %v", err)
+	}
+	r := &ReconcileKnativeServing{releases: releases}
+	versions := r.versions()
+	if _, ok := releases[version.Version]; !ok {
+		t.Errorf("versions() = %v, want the operator's own release %s", versions, version.Version)
+	}
+	// Every bundled release is reachable from the one before it
+	for i := 1; i < len(versions); i++ {
+		if err := checkVersionHop(versions[i-1], versions[i]); err != nil {
+			t.Errorf("checkVersionHop(%s, %s) = %v", versions[i-1], versions[i], err)
+		}
+	}
+	// Any other release may be chosen, if there is one
+	instance := &servingv1alpha1.KnativeServing{}
+	for _, v := range versions {
+		if v == version.Version {
+			continue
+		}
+		instance.Spec.Version = v
+		if m, err := r.manifestFor(instance); err != nil || len(m.Resources) == 0 {
+			t.Errorf("manifestFor(%s) = %v, %v, want the bundled release", v, m.Resources, err)
+		}
+	}
+	if len(versions) == 1 {
+		instance.Spec.Version = "0.0.1"
+		if _, err := r.manifestFor(instance); err == nil || !strings.Contains(err.Error(), "only the operator's own release") {
+			t.Errorf("manifestFor() = %v, want an error saying only one release is bundled", err)
+		}
+	}
+}
+
+func TestManifestFor(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{
+		config: newTestManifest(t, testManifest, c),
+	}
+	r.releases = map[string]mf.Manifest{
+		version.Version: r.config,
+		"0.6.0":         newTestManifest(t, upgradeManifest, c),
+	}
+
+	instance := &servingv1alpha1.KnativeServing{}
+	if m, err := r.manifestFor(instance); err != nil || len(m.Resources) != len(r.config.Resources) {
+		t.Errorf("manifestFor() = %v, %v, want the operator's own release", m.Resources, err)
+	}
+	instance.Spec.Version = "v0.6.0"
+	if m, err := r.manifestFor(instance); err != nil || m.Resources[0].GetName() != "webhook" {
+		t.Errorf("manifestFor() = %v, %v, want release 0.6.0", m.Resources, err)
+	}
+	instance.Spec.Version = "0.5.0"
+	if _, err := r.manifestFor(instance); err == nil || !strings.Contains(err.Error(), "0.6.0, "+version.Version) {
+		t.Errorf("manifestFor() = %v, want an error listing the bundled versions", err)
+	}
+}
+
+func TestCheckVersionHop(t *testing.T) {
+	tests := []struct {
+		from, to string
+		ok       bool
+	}{
+		{"0.6.0", "0.7.0", true},
+		{"0.7.0", "0.7.1", true},
+		{"0.7.1", "0.7.1", true},
+		{"0.6.2", "0.7.0", true},
+		{"0.5.0", "0.7.0", false},
+		{"0.7.0", "0.6.0", false},
+		{"0.7.1", "0.7.0", false},
+		{"0.9.0", "1.0.0", true},
+		{"0.9.0", "1.1.0", false},
+		{"devel", "0.7.0", true},
+	}
+	for _, tt := range tests {
+		if err := checkVersionHop(tt.from, tt.to); (err == nil) != tt.ok {
+			t.Errorf("checkVersionHop(%s, %s) = %v, want ok %v", tt.from, tt.to, err, tt.ok)
+		}
+	}
+}
+
+func TestPreUpgradeRejectsSkippedVersion(t *testing.T) {
+	instance := newInstance(withInstalled("0.5.0"))
+	r, _ := newTestReconciler(instance)
+	manifest := newTestManifest(t, testManifest, r.client)
+	if err := r.preUpgrade(instance, &manifest); err == nil {
+		t.Error("expected an upgrade skipping a minor version to be rejected")
+	}
+	if instance.Status.IsUpgradeInProgress() {
+		t.Error("a rejected upgrade shouldn't be in progress")
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Render the manifest the operator would apply for the KnativeServing,
+// defaulted and validated as the webhook would, without a cluster: as
+// if nothing were installed yet and no platform were detected.
+func Render(instance *servingv1alpha1.KnativeServing) ([]unstructured.Unstructured, error) {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		return nil, err
+	}
+	if err := servingv1alpha1.SchemeBuilder.AddToScheme(s); err != nil {
+		return nil, err
+	}
+	if *manifestConfigMap != "" {
+		return nil, fmt.Errorf("--manifest-configmap can't be rendered offline")
+	}
+	source, err := newLocalSource()
+	if err != nil {
+		return nil, err
+	}
+	c := &offlineClient{scheme: s}
+	r := &ReconcileKnativeServing{client: c, scheme: s, source: source}
+	if err := r.loadManifests(c); err != nil {
+		return nil, err
+	}
+
+	if instance.GetNamespace() == "" {
+		// Where the KnativeServing is customarily created
+		instance.SetNamespace(operand)
+	}
+	ctx := context.TODO()
+	instance.SetDefaults(ctx)
+	if err := instance.Validate(ctx); err != nil {
+		return nil, err
+	}
+	manifest, err := r.transform(instance, nil)
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Resources, nil
+}
+
+// offlineClient stands in for a cluster with nothing installed: every
+// read finds nothing and every write fails
+type offlineClient struct {
+	scheme *runtime.Scheme
+}
+
+var _ client.Client = &offlineClient{}
+
+func (c *offlineClient) Get(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return err
+	}
+	return errors.NewNotFound(gvk.GroupVersion().WithResource(gvk.Kind).GroupResource(), key.Name)
+}
+
+func (c *offlineClient) List(context.Context, *client.ListOptions, runtime.Object) error {
+	return nil
+}
+
+func (c *offlineClient) Create(context.Context, runtime.Object) error {
+	return errOffline
+}
+
+func (c *offlineClient) Delete(context.Context, runtime.Object, ...client.DeleteOptionFunc) error {
+	return errOffline
+}
+
+func (c *offlineClient) Update(context.Context, runtime.Object) error {
+	return errOffline
+}
+
+func (c *offlineClient) Status() client.StatusWriter {
+	return c
+}
+
+var errOffline = fmt.Errorf("no cluster to write to when rendering offline")
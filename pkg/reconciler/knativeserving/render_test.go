@@ -0,0 +1,55 @@
+package knativeserving
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func TestRender(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kodata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "knative-serving"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "knative-serving", "serving.yaml"), []byte(testManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("KO_DATA_PATH", os.Getenv("KO_DATA_PATH"))
+	os.Setenv("KO_DATA_PATH", dir)
+
+	instance := &servingv1alpha1.KnativeServing{}
+	instance.Name = "knative-serving"
+	resources, err := Render(instance)
+	if err != nil {
+		t.Fatalf("Render() = %v", err)
+	}
+	if got, want := len(resources), 4; got != want {
+		t.Fatalf("got %d resources, want %d", got, want)
+	}
+	for _, u := range resources {
+		if u.GetKind() != "ConfigMap" || u.GetName() != "config-network" {
+			continue
+		}
+		// Defaulted as the webhook would
+		data := u.Object["data"].(map[string]interface{})
+		if got := data["ingress.class"]; got != "istio.ingress.networking.knative.dev" {
+			t.Errorf("ingress.class = %v, want the istio default", got)
+		}
+		if got := u.GetNamespace(); got != operand {
+			t.Errorf("namespace = %q, want %q", got, operand)
+		}
+	}
+
+	invalid := &servingv1alpha1.KnativeServing{}
+	invalid.Spec.Namespace = "Not_A_Namespace"
+	if _, err := Render(invalid); err == nil {
+		t.Error("expected an invalid spec to fail to render")
+	}
+}
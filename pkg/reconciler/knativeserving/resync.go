@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"flag"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	resyncPeriod = flag.Duration("resync-period", 10*time.Minute,
+		"How often an installed KnativeServing is reconciled without any event, repairing drift of resources "+
+			"the operator doesn't watch; disabled if 0")
+	backoffBase = flag.Duration("reconcile-backoff-base", 5*time.Millisecond,
+		"Delay before retrying a failed reconcile, doubled on every consecutive failure")
+	backoffMax = flag.Duration("reconcile-backoff-max", 1000*time.Second,
+		"Longest delay between retries of a failed reconcile")
+)
+
+func newBackoff() workqueue.RateLimiter {
+	return workqueue.NewItemExponentialFailureRateLimiter(*backoffBase, *backoffMax)
+}
+
+// Retry a failed request after its backoff, which resets once it
+// succeeds. A permanent failure isn't retried until the next event or
+// resync.
+func (r *ReconcileKnativeServing) backoff(request reconcile.Request, result reconcile.Result, err error, reqLogger logr.Logger) (reconcile.Result, error) {
+	if isPermanent(err) {
+		// Retrying would only fail the same way
+		reqLogger.Error(err, "Reconcile failed permanently")
+		if r.retries != nil {
+			r.retries.Forget(request)
+		}
+		return result, nil
+	}
+	if r.retries == nil {
+		return result, err
+	}
+	if err == nil {
+		r.retries.Forget(request)
+		return result, nil
+	}
+	delay := r.retries.When(request)
+	reqLogger.Error(err, "Reconcile failed", "retryAfter", delay.String(), "failures", r.retries.NumRequeues(request))
+	return reconcile.Result{RequeueAfter: delay}, nil
+}
+
+// Reconcile again after the resync period, unless that happens sooner anyway
+func (r *ReconcileKnativeServing) resync(result reconcile.Result) reconcile.Result {
+	if r.resyncPeriod <= 0 || result.Requeue {
+		return result
+	}
+	if result.RequeueAfter == 0 || result.RequeueAfter > r.resyncPeriod {
+		result.RequeueAfter = r.resyncPeriod
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package knativeserving
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestResync(t *testing.T) {
+	r := &ReconcileKnativeServing{resyncPeriod: 10 * time.Minute}
+	tests := []struct {
+		name string
+		in   reconcile.Result
+		want reconcile.Result
+	}{
+		{"Idle", reconcile.Result{}, reconcile.Result{RequeueAfter: 10 * time.Minute}},
+		{"Sooner", reconcile.Result{RequeueAfter: time.Minute}, reconcile.Result{RequeueAfter: time.Minute}},
+		{"Later", reconcile.Result{RequeueAfter: time.Hour}, reconcile.Result{RequeueAfter: 10 * time.Minute}},
+		{"Requeue", reconcile.Result{Requeue: true}, reconcile.Result{Requeue: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.resync(tt.in); got != tt.want {
+				t.Errorf("resync(%+v) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	r.resyncPeriod = 0
+	if got := r.resync(reconcile.Result{}); got != (reconcile.Result{}) {
+		t.Errorf("resync() = %+v, want no requeue when disabled", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	r := &ReconcileKnativeServing{retries: workqueue.NewItemExponentialFailureRateLimiter(time.Second, 3*time.Second)}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: operand, Name: operand}}
+
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		result, err := r.backoff(request, reconcile.Result{}, fmt.Errorf("boom"), log)
+		if err != nil {
+			t.Fatalf("backoff() = %v, want the error to be handled", err)
+		}
+		if result.RequeueAfter != want {
+			t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, want)
+		}
+	}
+
+	// Success resets the backoff
+	if _, err := r.backoff(request, reconcile.Result{}, nil, log); err != nil {
+		t.Fatalf("backoff() = %v", err)
+	}
+	result, _ := r.backoff(request, reconcile.Result{}, fmt.Errorf("boom"), log)
+	if result.RequeueAfter != time.Second {
+		t.Errorf("RequeueAfter = %v, want %v after a success", result.RequeueAfter, time.Second)
+	}
+}
+
+func TestBackoffSkipsPermanentErrors(t *testing.T) {
+	r := &ReconcileKnativeServing{retries: workqueue.NewItemExponentialFailureRateLimiter(time.Second, 3*time.Second)}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: operand, Name: operand}}
+
+	r.backoff(request, reconcile.Result{}, fmt.Errorf("boom"), log)
+	result, err := r.backoff(request, reconcile.Result{}, permanent(fmt.Errorf("invalid")), log)
+	if err != nil || result != (reconcile.Result{}) {
+		t.Errorf("backoff() = %+v, %v, want no retry", result, err)
+	}
+	if n := r.retries.NumRequeues(request); n != 0 {
+		t.Errorf("NumRequeues() = %d, want the backoff reset", n)
+	}
+}
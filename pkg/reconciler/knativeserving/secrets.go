@@ -0,0 +1,202 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/pkg/scope"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Marks the copies of the image pull secrets the operator made in the
+// namespace Knative Serving is installed into
+const secretCopyLabel = "operator.knative.dev/secret-copy"
+
+// The Secrets the spec refers to: the image pull secrets, both the
+// originals and their copies, the overrides, the cosign public key and
+// the custom certs of the controller
+func referencedSecrets(instance *servingv1alpha1.KnativeServing) []types.NamespacedName {
+	ns, install := instance.GetNamespace(), instance.InstallNamespace()
+	var result []types.NamespacedName
+	for _, ref := range instance.Spec.Registry.ImagePullSecrets {
+		result = append(result, types.NamespacedName{Namespace: install, Name: ref.Name})
+		if install != ns {
+			result = append(result, types.NamespacedName{Namespace: ns, Name: ref.Name})
+		}
+	}
+	if from := instance.Spec.OverridesFrom; from != nil && from.SecretRef != nil {
+		result = append(result, types.NamespacedName{Namespace: ns, Name: from.SecretRef.Name})
+	}
+	if pinning := instance.Spec.DigestPinning; pinning != nil && pinning.CosignPublicKey != nil {
+		result = append(result, types.NamespacedName{Namespace: ns, Name: pinning.CosignPublicKey.Name})
+	}
+	if certs := instance.Spec.ControllerCustomCerts; certs != nil && certs.Type == "Secret" {
+		result = append(result, types.NamespacedName{Namespace: install, Name: certs.Name})
+	}
+	return result
+}
+
+// Watch the Secrets the spec refers to, so that e.g. rotated registry
+// credentials are copied and pinned with promptly
+func watchSecrets(mgr manager.Manager, c controller.Controller) error {
+	return c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: enqueueSecretReferences(mgr.GetClient()),
+	})
+}
+
+// Enqueue the owner of a copy, or else the watched KnativeServings
+// referring to the Secret. Secrets of the manifest, e.g. the webhook's
+// certs, change on their own and are left to the other watches.
+func enqueueSecretReferences(c client.Client) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []reconcile.Request {
+		if o.Meta == nil {
+			return nil
+		}
+		if _, ok := o.Meta.GetLabels()[secretCopyLabel]; ok {
+			return enqueueOwner(nil)(o)
+		}
+		list := &servingv1alpha1.KnativeServingList{}
+		if err := c.List(context.TODO(), &client.ListOptions{}, list); err != nil {
+			log.Error(err, "Failed to list KnativeServings")
+			return nil
+		}
+		secret := types.NamespacedName{Namespace: o.Meta.GetNamespace(), Name: o.Meta.GetName()}
+		var result []reconcile.Request
+		for i := range list.Items {
+			ks := &list.Items[i]
+			if !scope.Owns(ks.GetNamespace()) {
+				continue
+			}
+			for _, ref := range referencedSecrets(ks) {
+				if ref == secret {
+					result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: ks.GetNamespace(), Name: ks.GetName()}})
+					break
+				}
+			}
+		}
+		return result
+	}
+}
+
+// Copy the image pull secrets of spec.registry from the namespace of
+// the KnativeServing into the one Knative Serving is installed into,
+// keeping the copies in step as the originals rotate. A Secret missing
+// from the former may have been created in the latter by hand.
+func (r *ReconcileKnativeServing) syncSecrets(instance *servingv1alpha1.KnativeServing) error {
+	ns, install := instance.GetNamespace(), instance.InstallNamespace()
+	copied := map[string]bool{}
+	if install != ns {
+		for _, ref := range instance.Spec.Registry.ImagePullSecrets {
+			original := &corev1.Secret{}
+			if err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: ns, Name: ref.Name}, original); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return err
+			}
+			if err := r.copySecret(instance, original); err != nil {
+				return err
+			}
+			copied[ref.Name] = true
+		}
+	}
+	return r.deleteSecretCopies(instance, copied)
+}
+
+// Create or update the copy of the Secret in the install namespace,
+// leaving one the operator didn't make alone
+func (r *ReconcileKnativeServing) copySecret(instance *servingv1alpha1.KnativeServing, original *corev1.Secret) error {
+	install := instance.InstallNamespace()
+	secret := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: install, Name: original.Name}, secret)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	if exists {
+		if _, ok := secret.Labels[secretCopyLabel]; !ok {
+			log.Info("Not overwriting a Secret the operator didn't copy", "namespace", install, "name", original.Name)
+			return nil
+		}
+		if secret.Type == original.Type && reflect.DeepEqual(secret.Data, original.Data) {
+			return nil
+		}
+		if secret.Type != original.Type {
+			// The type is immutable
+			if err := r.client.Delete(context.TODO(), secret); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			secret, exists = &corev1.Secret{}, false
+		}
+	}
+	secret.Namespace, secret.Name = install, original.Name
+	secret.Labels = map[string]string{
+		secretCopyLabel:     "true",
+		ownerNameLabel:      instance.GetName(),
+		ownerNamespaceLabel: instance.GetNamespace(),
+	}
+	secret.Type, secret.Data = original.Type, original.Data
+	if exists {
+		log.Info("Updating the copy of Secret", "namespace", install, "name", original.Name)
+		if err := r.client.Update(context.TODO(), secret); err != nil {
+			return err
+		}
+		r.recorder.Eventf(instance, corev1.EventTypeNormal, "SecretCopied", "Updated the copy of Secret %s in namespace %s", original.Name, install)
+		return nil
+	}
+	log.Info("Copying Secret", "namespace", install, "name", original.Name)
+	if err := r.client.Create(context.TODO(), secret); err != nil {
+		return err
+	}
+	r.recorder.Eventf(instance, corev1.EventTypeNormal, "SecretCopied", "Copied Secret %s to namespace %s", original.Name, install)
+	return nil
+}
+
+// Delete the copies of the KnativeServing's Secrets but the kept ones,
+// e.g. those no longer referred to
+func (r *ReconcileKnativeServing) deleteSecretCopies(instance *servingv1alpha1.KnativeServing, keep map[string]bool) error {
+	list := &corev1.SecretList{}
+	selector := labels.SelectorFromSet(labels.Set{
+		ownerNameLabel:      instance.GetName(),
+		ownerNamespaceLabel: instance.GetNamespace(),
+	})
+	if err := r.client.List(context.TODO(), &client.ListOptions{Namespace: instance.InstallNamespace(), LabelSelector: selector}, list); err != nil {
+		return err
+	}
+	for i := range list.Items {
+		secret := &list.Items[i]
+		if _, ok := secret.Labels[secretCopyLabel]; !ok || keep[secret.Name] {
+			continue
+		}
+		log.Info("Deleting the copy of Secret", "namespace", secret.Namespace, "name", secret.Name)
+		if err := r.client.Delete(context.TODO(), secret); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
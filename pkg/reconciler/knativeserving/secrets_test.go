@@ -0,0 +1,141 @@
+package knativeserving
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func newPullSecret(namespace, name, config string) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Type:       v1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{v1.DockerConfigJsonKey: []byte(config)},
+	}
+}
+
+func TestSyncSecrets(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Namespace: operand,
+			Registry: servingv1alpha1.Registry{
+				ImagePullSecrets: []v1.LocalObjectReference{{Name: "registry"}, {Name: "by-hand"}, {Name: "missing"}},
+			},
+		},
+	}
+	byHand := newPullSecret(operand, "by-hand", "{}")
+	c := newFakeClient(newTestScheme(),
+		newPullSecret("team-a", "registry", `{"auths":{}}`),
+		newPullSecret("team-a", "by-hand", `{"auths":{}}`),
+		byHand)
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, scheme: newTestScheme(), recorder: recorder}
+	copied := func() *v1.Secret {
+		t.Helper()
+		secret := &v1.Secret{}
+		if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "registry"}, secret); err != nil {
+			t.Fatalf("Get() = %v", err)
+		}
+		return secret
+	}
+
+	if err := r.syncSecrets(instance); err != nil {
+		t.Fatalf("syncSecrets() = %v", err)
+	}
+	expectEvent(t, recorder, "Normal SecretCopied Copied Secret registry")
+	expectNoEvent(t, recorder)
+	secret := copied()
+	if string(secret.Data[v1.DockerConfigJsonKey]) != `{"auths":{}}` || secret.Type != v1.SecretTypeDockerConfigJson {
+		t.Errorf("copy = %v, want the original's type and data", secret)
+	}
+	if secret.Labels[secretCopyLabel] == "" || secret.Labels[ownerNameLabel] != operand || secret.Labels[ownerNamespaceLabel] != "team-a" {
+		t.Errorf("copy labels = %v, want the copy and owner labels", secret.Labels)
+	}
+	kept := &v1.Secret{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "by-hand"}, kept); err != nil || string(kept.Data[v1.DockerConfigJsonKey]) != "{}" {
+		t.Errorf("Secret created by hand = %v, %v, want it left alone", kept, err)
+	}
+
+	// Rotating the original updates the copy, once
+	rotated := newPullSecret("team-a", "registry", `{"auths":{"gcr.io":{}}}`)
+	if err := c.Update(context.TODO(), rotated); err != nil {
+		t.Fatalf("Update() = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := r.syncSecrets(instance); err != nil {
+			t.Fatalf("syncSecrets() = %v", err)
+		}
+	}
+	expectEvent(t, recorder, "Normal SecretCopied Updated the copy of Secret registry")
+	expectNoEvent(t, recorder)
+	if got := string(copied().Data[v1.DockerConfigJsonKey]); got != `{"auths":{"gcr.io":{}}}` {
+		t.Errorf("copy data = %s, want the rotated credentials", got)
+	}
+
+	// No longer referring to the Secret deletes the copy only
+	instance.Spec.Registry.ImagePullSecrets = nil
+	if err := r.syncSecrets(instance); err != nil {
+		t.Fatalf("syncSecrets() = %v", err)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "registry"}, &v1.Secret{}); err == nil {
+		t.Error("expected the copy to be deleted")
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: "by-hand"}, &v1.Secret{}); err != nil {
+		t.Errorf("Get() = %v, want the Secret created by hand kept", err)
+	}
+}
+
+func TestEnqueueSecretReferences(t *testing.T) {
+	c := newFakeClient(newTestScheme(),
+		&servingv1alpha1.KnativeServing{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+			Spec: servingv1alpha1.KnativeServingSpec{
+				Registry:              servingv1alpha1.Registry{ImagePullSecrets: []v1.LocalObjectReference{{Name: "registry"}}},
+				ControllerCustomCerts: &servingv1alpha1.CustomCerts{Type: "Secret", Name: "certs"},
+			},
+		})
+	enqueue := enqueueSecretReferences(c)
+	tests := []struct {
+		name string
+		meta metav1.ObjectMeta
+		want bool
+	}{{
+		name: "image pull secret",
+		meta: metav1.ObjectMeta{Namespace: operand, Name: "registry"},
+		want: true,
+	}, {
+		name: "custom certs",
+		meta: metav1.ObjectMeta{Namespace: operand, Name: "certs"},
+		want: true,
+	}, {
+		name: "same name in another namespace",
+		meta: metav1.ObjectMeta{Namespace: "team-a", Name: "registry"},
+	}, {
+		name: "unrelated",
+		meta: metav1.ObjectMeta{Namespace: operand, Name: "webhook-certs", Labels: map[string]string{ownerNameLabel: operand, ownerNamespaceLabel: operand}},
+	}, {
+		name: "copy",
+		meta: metav1.ObjectMeta{Namespace: "team-a", Name: "other", Labels: map[string]string{secretCopyLabel: "true", ownerNameLabel: operand, ownerNamespaceLabel: operand}},
+		want: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := enqueue(handler.MapObject{Meta: &tt.meta})
+			want := types.NamespacedName{Namespace: operand, Name: operand}
+			if tt.want && (len(requests) != 1 || requests[0].NamespacedName != want) {
+				t.Errorf("enqueued %v, want %v", requests, want)
+			}
+			if !tt.want && len(requests) != 0 {
+				t.Errorf("enqueued %v, want nothing", requests)
+			}
+		})
+	}
+}
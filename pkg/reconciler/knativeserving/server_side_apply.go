@@ -0,0 +1,223 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	mf "github.com/jcrossley3/manifestival"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// The manager of the fields the operator applies
+	fieldManager = "knative-serving-operator"
+	// Not among the patch types of this client-go release
+	applyPatchType types.PatchType = "application/apply-patch+yaml"
+	// The cause of a conflict with the fields of another manager
+	fieldManagerConflict = "FieldManagerConflict"
+)
+
+var serverSideApply = flag.Bool("server-side-apply", false,
+	"Apply the manifest with server-side apply, as field manager "+fieldManager+", leaving the fields "+
+		"other managers own, e.g. HPA replicas, to them; requires Kubernetes 1.16 or later")
+
+// applyFunc creates or updates a resource of the manifest
+type applyFunc func(manifest *mf.Manifest, u *unstructured.Unstructured) error
+
+// Applies resources with server-side apply
+type serverSideApplier struct {
+	client rest.Interface
+	mapper meta.RESTMapper
+}
+
+func newApplier(client rest.Interface, mapper meta.RESTMapper) applyFunc {
+	if !*serverSideApply {
+		return nil
+	}
+	return (&serverSideApplier{client: client, mapper: mapper}).apply
+}
+
+// Apply the resource without forcing: the fields another manager owns
+// are dropped from it and left to that manager, rather than taken over
+// only to be changed back by it
+func (a *serverSideApplier) apply(_ *mf.Manifest, u *unstructured.Unstructured) error {
+	gvk := u.GroupVersionKind()
+	mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+	obj := u.DeepCopy()
+	for {
+		body, err := json.Marshal(obj.Object)
+		if err != nil {
+			return err
+		}
+		err = a.client.Patch(applyPatchType).
+			AbsPath(resourcePath(mapping, obj)...).
+			Param("fieldManager", fieldManager).
+			Body(body).
+			Do().
+			Error()
+		conflicts := conflictingFields(err)
+		if len(conflicts) == 0 {
+			return err
+		}
+		log.Info("Leaving fields to their other managers", "kind", u.GetKind(), "namespace", u.GetNamespace(),
+			"name", u.GetName(), "fields", conflicts)
+		for _, path := range conflicts {
+			if !removeField(obj.Object, path) {
+				return err
+			}
+		}
+	}
+}
+
+// The URL path of the resource
+func resourcePath(mapping *meta.RESTMapping, u *unstructured.Unstructured) []string {
+	gv := mapping.GroupVersionKind.GroupVersion()
+	path := []string{"/apis", gv.Group, gv.Version}
+	if gv.Group == "" {
+		path = []string{"/api", gv.Version}
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		path = append(path, "namespaces", u.GetNamespace())
+	}
+	return append(path, mapping.Resource.Resource, u.GetName())
+}
+
+// The fields of the apply's conflicts with other managers, if it failed
+// with any
+func conflictingFields(err error) []string {
+	status, ok := err.(errors.APIStatus)
+	if !ok || !errors.IsConflict(err) || status.Status().Details == nil {
+		return nil
+	}
+	var result []string
+	for _, cause := range status.Status().Details.Causes {
+		if string(cause.Type) == fieldManagerConflict && cause.Field != "" {
+			result = append(result, cause.Field)
+		}
+	}
+	return result
+}
+
+// A field of a path, and the keys of the list item it selects, if any
+type pathElement struct {
+	field    string
+	selector map[string]string
+}
+
+// Remove the field at the path of a conflict, e.g.
+// .spec.template.spec.containers[name="activator"].image, reporting
+// whether it was found
+func removeField(obj map[string]interface{}, path string) bool {
+	elements, ok := parseFieldPath(path)
+	if !ok || len(elements) == 0 {
+		return false
+	}
+	current := obj
+	for i, e := range elements {
+		last := i == len(elements)-1
+		if e.selector == nil {
+			if last {
+				_, found := current[e.field]
+				delete(current, e.field)
+				return found
+			}
+			next, ok := current[e.field].(map[string]interface{})
+			if !ok {
+				return false
+			}
+			current = next
+			continue
+		}
+		list, _ := current[e.field].([]interface{})
+		index := -1
+		for j := range list {
+			if item, ok := list[j].(map[string]interface{}); ok && selects(e.selector, item) {
+				index = j
+				break
+			}
+		}
+		if index < 0 {
+			return false
+		}
+		if last {
+			current[e.field] = append(list[:index:index], list[index+1:]...)
+			return true
+		}
+		current = list[index].(map[string]interface{})
+	}
+	return false
+}
+
+func selects(selector map[string]string, item map[string]interface{}) bool {
+	for k, v := range selector {
+		if fmt.Sprint(item[k]) != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse a path like .a.b[k="v",n=1].c
+func parseFieldPath(path string) ([]pathElement, bool) {
+	var result []pathElement
+	for path != "" {
+		if path[0] != '.' {
+			return nil, false
+		}
+		path = path[1:]
+		end := strings.IndexAny(path, ".[")
+		if end < 0 {
+			end = len(path)
+		}
+		e := pathElement{field: path[:end]}
+		path = path[end:]
+		if strings.HasPrefix(path, "[") {
+			closing := strings.Index(path, "]")
+			if closing < 0 {
+				return nil, false
+			}
+			e.selector = map[string]string{}
+			for _, kv := range strings.Split(path[1:closing], ",") {
+				parts := strings.SplitN(kv, "=", 2)
+				if len(parts) != 2 || parts[0] == "" {
+					return nil, false
+				}
+				if v, err := strconv.Unquote(parts[1]); err == nil {
+					parts[1] = v
+				}
+				e.selector[parts[0]] = parts[1]
+			}
+			path = path[closing+1:]
+		}
+		if e.field == "" {
+			return nil, false
+		}
+		result = append(result, e)
+	}
+	return result, true
+}
@@ -0,0 +1,157 @@
+package knativeserving
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+const conflictStatus = `{
+  "kind": "Status",
+  "apiVersion": "v1",
+  "status": "Failure",
+  "reason": "Conflict",
+  "code": 409,
+  "details": {
+    "causes": [{
+      "reason": "FieldManagerConflict",
+      "message": "conflict with \"kube-controller-manager\" using apps/v1",
+      "field": ".spec.replicas"
+    }, {
+      "reason": "FieldManagerConflict",
+      "message": "conflict with \"istio-injector\" using apps/v1",
+      "field": ".spec.template.spec.containers[name=\"activator\"].image"
+    }]
+  }
+}`
+
+func TestServerSideApplyLeavesConflictsToOtherManagers(t *testing.T) {
+	var bodies []map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPatch || req.URL.Path != "/apis/apps/v1/namespaces/knative-serving/deployments/activator" {
+			t.Errorf("request = %s %s", req.Method, req.URL.Path)
+		}
+		if got := req.Header.Get("Content-Type"); got != string(applyPatchType) {
+			t.Errorf("Content-Type = %q, want %q", got, applyPatchType)
+		}
+		if got := req.URL.Query().Get("fieldManager"); got != fieldManager {
+			t.Errorf("fieldManager = %q, want %q", got, fieldManager)
+		}
+		b, _ := ioutil.ReadAll(req.Body)
+		body := map[string]interface{}{}
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Fatalf("invalid body: %v", err)
+		}
+		bodies = append(bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(conflictStatus))
+			return
+		}
+		w.Write(b)
+	}))
+	defer ts.Close()
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	a := &serverSideApplier{
+		client: discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: ts.URL}).RESTClient(),
+		mapper: mapper,
+	}
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": "knative-serving", "name": "activator"},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"template": map[string]interface{}{"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "activator", "image": "activator:v1"},
+				},
+			}},
+		},
+	}}
+	if err := a.apply(nil, u); err != nil {
+		t.Fatalf("apply() = %v", err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("applied %d times, want a retry after the conflict", len(bodies))
+	}
+	want := map[string]interface{}{
+		"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": "activator"}},
+		}},
+	}
+	if got := bodies[1]["spec"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("retried spec = %v, want %v", got, want)
+	}
+	if _, ok, _ := unstructured.NestedInt64(u.Object, "spec", "replicas"); !ok {
+		t.Error("the manifest's resource should be left as it is")
+	}
+}
+
+func TestRemoveField(t *testing.T) {
+	obj := func() map[string]interface{} {
+		return map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": int64(2),
+				"ports": []interface{}{
+					map[string]interface{}{"containerPort": int64(8080), "protocol": "TCP", "name": "http"},
+					map[string]interface{}{"containerPort": int64(9090), "protocol": "TCP", "name": "metrics"},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		path  string
+		found bool
+		check func(map[string]interface{}) bool
+	}{{
+		path:  ".spec.replicas",
+		found: true,
+		check: func(o map[string]interface{}) bool {
+			_, ok, _ := unstructured.NestedFieldNoCopy(o, "spec", "replicas")
+			return !ok
+		},
+	}, {
+		path:  `.spec.ports[containerPort=9090,protocol="TCP"].name`,
+		found: true,
+		check: func(o map[string]interface{}) bool {
+			ports, _, _ := unstructured.NestedSlice(o, "spec", "ports")
+			_, named := ports[1].(map[string]interface{})["name"]
+			return !named
+		},
+	}, {
+		path:  `.spec.ports[containerPort=8080,protocol="TCP"]`,
+		found: true,
+		check: func(o map[string]interface{}) bool {
+			ports, _, _ := unstructured.NestedSlice(o, "spec", "ports")
+			return len(ports) == 1
+		},
+	}, {
+		path: ".spec.missing.field",
+	}, {
+		path: `.spec.ports[containerPort=1]`,
+	}, {
+		path: "spec[",
+	}}
+	for _, tt := range tests {
+		o := obj()
+		if got := removeField(o, tt.path); got != tt.found {
+			t.Errorf("removeField(%s) = %v, want %v", tt.path, got, tt.found)
+		}
+		if tt.check != nil && !tt.check(o) {
+			t.Errorf("removeField(%s) left %v", tt.path, o)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var (
+	manifestList = flag.String("manifest", os.Getenv("KNATIVE_SERVING_MANIFEST"),
+		"Comma-separated files, directories or HTTPS URLs of a Knative Serving manifest to install instead of the bundled one; relative paths are in KO_DATA_PATH")
+	manifestURL = flag.String("manifest-url", "",
+		"HTTPS URL of a Knative Serving manifest to install instead of the bundled one")
+	manifestSHA256 = flag.String("manifest-sha256", "",
+		"The expected SHA-256 checksum of the manifest at manifest-url")
+	manifestConfigMap = flag.String("manifest-configmap", "",
+		"The namespace/name of a ConfigMap whose data holds a Knative Serving manifest to install instead of the bundled one")
+)
+
+// ManifestSource provides the Knative Serving manifest to install
+type ManifestSource interface {
+	// Fetch makes the manifest available at a local path, and returns
+	// a func to release it once parsed
+	Fetch() (string, func(), error)
+}
+
+// Choose the source of the manifest from the flags, defaulting to the
+// manifest bundled in the operator image
+func newManifestSource(mgr manager.Manager) (ManifestSource, error) {
+	set := 0
+	for _, value := range []string{*manifestList, *manifestURL, *manifestConfigMap} {
+		if value != "" {
+			set++
+		}
+	}
+	switch {
+	case set > 1:
+		return nil, fmt.Errorf("only one of --manifest, --manifest-url and --manifest-configmap may be set")
+	case *manifestConfigMap != "":
+		// Bypass the cache, which isn't started when the manifest is loaded
+		c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme()})
+		if err != nil {
+			return nil, err
+		}
+		return newConfigMapSource(*manifestConfigMap, c)
+	}
+	return newLocalSource()
+}
+
+// The manifest of --manifest or --manifest-url, or else the bundled
+// one, none of which needs a cluster
+func newLocalSource() (ManifestSource, error) {
+	if *manifestList != "" {
+		return newListSource(*manifestList, os.Getenv("KO_DATA_PATH"))
+	}
+	if *manifestURL != "" {
+		return newURLSource(*manifestURL, *manifestSHA256, http.DefaultClient)
+	}
+	return &dirSource{path: filepath.Join(os.Getenv("KO_DATA_PATH"), "knative-serving/")}, nil
+}
+
+// dirSource is a local file or directory, e.g. in KO_DATA_PATH
+type dirSource struct {
+	path string
+}
+
+func (s *dirSource) Fetch() (string, func(), error) {
+	return s.path, func() {}, nil
+}
+
+// listSource is an explicit list of files, directories and URLs that
+// manifestival parses as one manifest, e.g. the bundled release slimmed
+// down or augmented from the operator's Deployment
+type listSource struct {
+	paths []string
+}
+
+func newListSource(list, koDataDir string) (*listSource, error) {
+	var paths []string
+	for _, path := range strings.Split(list, ",") {
+		path = strings.TrimSpace(path)
+		switch {
+		case path == "":
+			continue
+		case strings.Contains(path, "://"):
+			// Unlike --manifest-url, not verified against a checksum
+			if u, err := url.Parse(path); err != nil || u.Scheme != "https" {
+				return nil, fmt.Errorf("manifest URL %s must use https", path)
+			}
+		case !filepath.IsAbs(path):
+			path = filepath.Join(koDataDir, path)
+		}
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("manifest list %q names no files, directories or URLs", list)
+	}
+	return &listSource{paths: paths}, nil
+}
+
+func (s *listSource) Fetch() (string, func(), error) {
+	return strings.Join(s.paths, ","), func() {}, nil
+}
+
+// urlSource is downloaded over HTTPS and verified against its checksum
+type urlSource struct {
+	url    string
+	sha256 string
+	client *http.Client
+}
+
+func newURLSource(rawURL, checksum string, c *http.Client) (*urlSource, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("manifest URL %s must use https", rawURL)
+	}
+	if checksum == "" {
+		return nil, fmt.Errorf("a SHA-256 checksum is required for manifest URL %s", rawURL)
+	}
+	return &urlSource{url: rawURL, sha256: strings.ToLower(checksum), client: c}, nil
+}
+
+func (s *urlSource) Fetch() (string, func(), error) {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("fetching manifest %s: %s", s.url, resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	sum := sha256.Sum256(body)
+	if actual := hex.EncodeToString(sum[:]); actual != s.sha256 {
+		return "", nil, fmt.Errorf("manifest %s has checksum %s, expected %s", s.url, actual, s.sha256)
+	}
+	return writeManifest(map[string]string{"manifest.yaml": string(body)})
+}
+
+// configMapSource is the data of a ConfigMap, one manifest per key
+type configMapSource struct {
+	key    client.ObjectKey
+	client client.Client
+}
+
+func newConfigMapSource(namespacedName string, c client.Client) (*configMapSource, error) {
+	parts := strings.Split(namespacedName, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("manifest ConfigMap %q must be of the form namespace/name", namespacedName)
+	}
+	return &configMapSource{key: client.ObjectKey{Namespace: parts[0], Name: parts[1]}, client: c}, nil
+}
+
+func (s *configMapSource) Fetch() (string, func(), error) {
+	cm := &corev1.ConfigMap{}
+	if err := s.client.Get(context.TODO(), s.key, cm); err != nil {
+		return "", nil, err
+	}
+	if len(cm.Data) == 0 {
+		return "", nil, fmt.Errorf("manifest ConfigMap %s has no data", s.key)
+	}
+	return writeManifest(cm.Data)
+}
+
+// Write the files to a temporary directory for manifestival to parse
+func writeManifest(files map[string]string) (string, func(), error) {
+	dir, err := ioutil.TempDir("", "knative-serving-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.Base(name)), []byte(content), 0644); err != nil {
+			cleanup()
+			return "", nil, err
+		}
+	}
+	return dir, cleanup, nil
+}
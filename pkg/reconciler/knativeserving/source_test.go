@@ -0,0 +1,161 @@
+package knativeserving
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mf "github.com/jcrossley3/manifestival"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving-operator/pkg/health"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func parseSource(t *testing.T, source ManifestSource) mf.Manifest {
+	t.Helper()
+	path, release, err := source.Fetch()
+	if err != nil {
+		t.Fatalf("Fetch() = %v", err)
+	}
+	defer release()
+	m, err := mf.NewManifest(path, false, nil)
+	if err != nil {
+		t.Fatalf("NewManifest() = %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("fetched manifest missing before release: %v", err)
+	}
+	return m
+}
+
+func TestURLSource(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testManifest))
+	}))
+	defer server.Close()
+	sum := sha256.Sum256([]byte(testManifest))
+	checksum := hex.EncodeToString(sum[:])
+
+	source, err := newURLSource(server.URL, checksum, server.Client())
+	if err != nil {
+		t.Fatalf("newURLSource() = %v", err)
+	}
+	if m := parseSource(t, source); len(m.Resources) != 4 {
+		t.Errorf("got %d resources, want 4", len(m.Resources))
+	}
+
+	source, err = newURLSource(server.URL, "deadbeef", server.Client())
+	if err != nil {
+		t.Fatalf("newURLSource() = %v", err)
+	}
+	if _, _, err := source.Fetch(); err == nil {
+		t.Error("expected a checksum mismatch")
+	}
+}
+
+func TestURLSourceRequiresHTTPSAndChecksum(t *testing.T) {
+	if _, err := newURLSource("http://example.com/serving.yaml", "abc", nil); err == nil {
+		t.Error("expected plain http to be rejected")
+	}
+	if _, err := newURLSource("https://example.com/serving.yaml", "", nil); err == nil {
+		t.Error("expected a missing checksum to be rejected")
+	}
+}
+
+func TestListSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kodata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	extra := filepath.Join(dir, "extra")
+	if err := os.Mkdir(extra, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "serving.yaml"), []byte(testManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(extra, "extra.yaml"), []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: extra\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Relative paths are in KO_DATA_PATH
+	source, err := newListSource("serving.yaml, "+extra+",", dir)
+	if err != nil {
+		t.Fatalf("newListSource() = %v", err)
+	}
+	path, release, err := source.Fetch()
+	if err != nil {
+		t.Fatalf("Fetch() = %v", err)
+	}
+	defer release()
+	m, err := mf.NewManifest(path, false, nil)
+	if err != nil {
+		t.Fatalf("NewManifest() = %v", err)
+	}
+	if len(m.Resources) != 5 || m.Resources[4].GetName() != "extra" {
+		t.Errorf("got %d resources, want the 4 of serving.yaml and the extra one", len(m.Resources))
+	}
+}
+
+func TestListSourceRejectsInvalidLists(t *testing.T) {
+	for _, list := range []string{"", " , ", "serving.yaml,http://example.com/extra.yaml"} {
+		if _, err := newListSource(list, "/var/run/ko"); err == nil {
+			t.Errorf("newListSource(%q) succeeded, want an error", list)
+		}
+	}
+	source, err := newListSource("https://example.com/serving.yaml,/etc/extra", "/var/run/ko")
+	if err != nil {
+		t.Fatalf("newListSource() = %v", err)
+	}
+	if path, _, _ := source.Fetch(); path != "https://example.com/serving.yaml,/etc/extra" {
+		t.Errorf("Fetch() = %s, want the URL and absolute path unchanged", path)
+	}
+}
+
+func TestConfigMapSource(t *testing.T) {
+	c := newFakeClient(newTestScheme(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "serving-manifest"},
+		Data: map[string]string{
+			"serving.yaml": testManifest,
+			"extra.yaml":   upgradeManifest,
+		},
+	})
+
+	source, err := newConfigMapSource("default/serving-manifest", c)
+	if err != nil {
+		t.Fatalf("newConfigMapSource() = %v", err)
+	}
+	if m := parseSource(t, source); len(m.Resources) != 8 {
+		t.Errorf("got %d resources, want 8", len(m.Resources))
+	}
+
+	if _, err := newConfigMapSource("serving-manifest", c); err == nil {
+		t.Error("expected a name without a namespace to be rejected")
+	}
+	source, _ = newConfigMapSource("default/missing", c)
+	if _, _, err := source.Fetch(); err == nil {
+		t.Error("expected a missing ConfigMap to fail")
+	}
+}
+
+func TestInjectClientReportsMissingManifest(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c, source: &dirSource{path: "/no/such/kodata"}}
+	if err := r.InjectClient(c); err != nil {
+		t.Fatalf("InjectClient() = %v, want the failure left to the readiness probe", err)
+	}
+	if err := health.Ready(); err == nil || !strings.Contains(err.Error(), healthCheck) {
+		t.Errorf("health.Ready() = %v, want the manifest failure", err)
+	}
+	if _, err := r.reconcile(reconcile.Request{}, log); !isPermanent(err) {
+		t.Errorf("reconcile() = %v, want a permanent failure without a manifest", err)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"contrib.go.opencensus.io/exporter/stackdriver"
+	"go.opencensus.io/trace"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	// The span of a reconcile, the parent of those of its stages
+	reconcileSpan = "KnativeServing.Reconcile"
+)
+
+var (
+	traceExporter = flag.String("trace-exporter", "",
+		"Export the spans of the reconcile stages to stackdriver; none are exported when empty")
+	traceSampleRate = flag.Float64("trace-sample-rate", 1,
+		"The fraction of reconciles traced, from 0 to 1")
+	traceProjectID = flag.String("trace-stackdriver-project-id", "",
+		"The GCP project stackdriver traces are exported to; defaults to the one the operator runs in")
+)
+
+// Register the exporter of --trace-exporter, if any
+func setupTracing() error {
+	switch *traceExporter {
+	case "":
+		return nil
+	case "stackdriver":
+		exporter, err := stackdriver.NewExporter(stackdriver.Options{ProjectID: *traceProjectID})
+		if err != nil {
+			return fmt.Errorf("failed to create the stackdriver exporter: %v", err)
+		}
+		trace.RegisterExporter(exporter)
+	default:
+		return fmt.Errorf("unknown trace exporter %q", *traceExporter)
+	}
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(*traceSampleRate)})
+	return nil
+}
+
+// Run the stage in a span of the reconcile's, recording its duration
+func runStage(ctx context.Context, stage func(*servingv1alpha1.KnativeServing) error, instance *servingv1alpha1.KnativeServing) error {
+	name := stageName(stage)
+	_, span := trace.StartSpan(ctx, name)
+	defer span.End()
+	start := time.Now()
+	err := stage(instance)
+	recordStageMetrics(name, start, err)
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+	}
+	return err
+}
+
+// The name of the stage's method, e.g. install
+func stageName(stage interface{}) string {
+	// e.g. knative.dev/serving-operator/pkg/reconciler/knativeserving.(*ReconcileKnativeServing).install-fm
+	name := runtime.FuncForPC(reflect.ValueOf(stage).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"fmt"
+
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+// Report whether the zipkin collector of spec.tracing responds. The
+// collector is the user's, so an unreachable one only warns: knative
+// drops the spans rather than failing requests.
+func (r *ReconcileKnativeServing) checkTracingCollector(instance *servingv1alpha1.KnativeServing) error {
+	tracing := instance.Spec.Tracing
+	if tracing == nil || tracing.Backend != servingv1alpha1.ZipkinTracingBackend || r.probe == nil {
+		if instance.Status.GetCondition(servingv1alpha1.TracingCollectorReachable) == nil {
+			return nil
+		}
+		instance.Status.ClearTracingCollectorReachable()
+		return r.updateStatus(instance)
+	}
+	if err := r.probe(tracing.ZipkinEndpoint, nil); err != nil {
+		instance.Status.MarkTracingCollectorUnreachable(
+			fmt.Sprintf("The zipkin collector %s is unreachable: %v", tracing.ZipkinEndpoint, err))
+	} else {
+		instance.Status.MarkTracingCollectorReachable()
+	}
+	return r.updateStatus(instance)
+}
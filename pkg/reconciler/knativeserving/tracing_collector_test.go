@@ -0,0 +1,55 @@
+package knativeserving
+
+import (
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func TestCheckTracingCollector(t *testing.T) {
+	const endpoint = "http://zipkin.istio-system:9411/api/v2/spans"
+	instance := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand},
+		Spec: servingv1alpha1.KnativeServingSpec{
+			Tracing: &servingv1alpha1.Tracing{Backend: servingv1alpha1.ZipkinTracingBackend, ZipkinEndpoint: endpoint},
+		},
+	}
+	instance.Status.InitializeConditions()
+	r := &ReconcileKnativeServing{client: newFakeClient(newTestScheme(), instance.DeepCopy())}
+	var probed string
+	var failing error
+	r.probe = func(url string, caCert []byte) error {
+		probed = url
+		return failing
+	}
+
+	failing = fmt.Errorf("connection refused")
+	if err := r.checkTracingCollector(instance); err != nil {
+		t.Fatalf("checkTracingCollector() = %v", err)
+	}
+	if probed != endpoint {
+		t.Errorf("probed %q, want %q", probed, endpoint)
+	}
+	cond := instance.Status.GetCondition(servingv1alpha1.TracingCollectorReachable)
+	if !cond.IsFalse() || cond.Severity != "Warning" {
+		t.Errorf("condition = %+v, want a False warning", cond)
+	}
+
+	failing = nil
+	if err := r.checkTracingCollector(instance); err != nil {
+		t.Fatalf("checkTracingCollector() = %v", err)
+	}
+	if !instance.Status.GetCondition(servingv1alpha1.TracingCollectorReachable).IsTrue() {
+		t.Error("expected the collector to be reachable")
+	}
+
+	instance.Spec.Tracing.Backend = servingv1alpha1.NoTracingBackend
+	if err := r.checkTracingCollector(instance); err != nil {
+		t.Fatalf("checkTracingCollector() = %v", err)
+	}
+	if instance.Status.GetCondition(servingv1alpha1.TracingCollectorReachable) != nil {
+		t.Error("expected the condition to be cleared without the zipkin backend")
+	}
+}
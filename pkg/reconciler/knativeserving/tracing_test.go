@@ -0,0 +1,61 @@
+package knativeserving
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+func TestStageName(t *testing.T) {
+	r := &ReconcileKnativeServing{}
+	for _, tt := range []struct {
+		stage func(*servingv1alpha1.KnativeServing) error
+		want  string
+	}{
+		{r.install, "install"},
+		{r.checkDeployments, "checkDeployments"},
+		{r.initStatus, "initStatus"},
+	} {
+		if got := stageName(tt.stage); got != tt.want {
+			t.Errorf("stageName() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func stageCount(t *testing.T, stage, result string) uint64 {
+	metric := &dto.Metric{}
+	if err := stageDuration.WithLabelValues(stage, result).(interface {
+		Write(*dto.Metric) error
+	}).Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	return metric.Histogram.GetSampleCount()
+}
+
+func TestRunStageRecordsDuration(t *testing.T) {
+	r := &ReconcileKnativeServing{}
+	failing := errors.New("boom")
+	stage := func(*servingv1alpha1.KnativeServing) error { return failing }
+	name := stageName(stage)
+	before := stageCount(t, name, reconcileFailed)
+
+	if err := runStage(context.Background(), stage, &servingv1alpha1.KnativeServing{}); err != failing {
+		t.Errorf("runStage() = %v, want the stage's error", err)
+	}
+	if got := stageCount(t, name, reconcileFailed) - before; got != 1 {
+		t.Errorf("failed %s stages recorded = %d, want 1", name, got)
+	}
+
+	before = stageCount(t, "ensureFinalizer", reconcileSucceeded)
+	instance := &servingv1alpha1.KnativeServing{}
+	instance.Finalizers = []string{finalizerName}
+	if err := runStage(context.Background(), r.ensureFinalizer, instance); err != nil {
+		t.Fatalf("runStage() = %v", err)
+	}
+	if got := stageCount(t, "ensureFinalizer", reconcileSucceeded) - before; got != 1 {
+		t.Errorf("succeeded ensureFinalizer stages recorded = %d, want 1", got)
+	}
+}
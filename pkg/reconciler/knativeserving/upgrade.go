@@ -0,0 +1,151 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"fmt"
+
+	mf "github.com/jcrossley3/manifestival"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+)
+
+var (
+	// The spec.config keys the bundled release no longer reads, mapped
+	// to what replaces them
+	deprecatedConfigKeys = map[string]map[string]string{
+		"controller": {
+			"registriesSkippingTagResolving": "deployment.registriesSkippingTagResolving",
+			"queueSidecarImage":              "deployment.queueSidecarImage",
+		},
+	}
+)
+
+// An upgrade replaces a previously installed, different release
+func isUpgrade(instance *servingv1alpha1.KnativeServing) bool {
+	return instance.Status.Version != "" && instance.Status.Version != targetVersion(instance)
+}
+
+// Check the transformed manifest can safely replace the installed
+// release, then order it for the upgrade and mark it in progress
+func (r *ReconcileKnativeServing) preUpgrade(instance *servingv1alpha1.KnativeServing, manifest *mf.Manifest) error {
+	from, to := instance.Status.Version, targetVersion(instance)
+	log.Info("Checking upgrade", "from", from, "to", to)
+	if err := checkVersionHop(from, to); err != nil {
+		return permanent(err)
+	}
+	for i := range manifest.Resources {
+		u := &manifest.Resources[i]
+		if u.GetKind() == "CustomResourceDefinition" {
+			if err := checkCRDCompatibility(manifest, u); err != nil {
+				return err
+			}
+		}
+	}
+	r.reportDeprecatedConfig(instance)
+
+	manifest.Resources = upgradeOrder(manifest.Resources)
+	if !instance.Status.IsUpgradeInProgress() {
+		r.recorder.Eventf(instance, v1.EventTypeNormal, "UpgradeStarted", "Upgrading from %s to %s", from, to)
+	}
+	instance.Status.MarkUpgradeInProgress(from, to)
+	return nil
+}
+
+// Once the upgraded deployments are available and the migration Jobs
+// succeeded, the upgrade is done
+func (r *ReconcileKnativeServing) completeUpgrade(instance *servingv1alpha1.KnativeServing) error {
+	if !instance.Status.IsUpgradeInProgress() || !instance.Status.IsAvailable() || instance.Status.IsVersionMigrating() {
+		return nil
+	}
+	log.Info("Upgrade succeeded", "version", instance.Status.Version)
+	r.recorder.Eventf(instance, v1.EventTypeNormal, "UpgradeSucceeded", "Upgraded to %s", instance.Status.Version)
+	instance.Status.ClearUpgradeInProgress()
+	return r.updateStatus(instance)
+}
+
+// A CRD may not stop serving a version that existing resources are
+// still stored as, or they'd become unreadable
+func checkCRDCompatibility(manifest *mf.Manifest, crd *unstructured.Unstructured) error {
+	current, err := manifest.Get(crd)
+	if err != nil || current == nil {
+		return err
+	}
+	stored, _, _ := unstructured.NestedStringSlice(current.Object, "status", "storedVersions")
+	served := servedVersions(crd)
+	for _, v := range stored {
+		if !served[v] {
+			return fmt.Errorf("CRD %s no longer serves stored version %s; migrate its resources before upgrading", crd.GetName(), v)
+		}
+	}
+	return nil
+}
+
+func servedVersions(crd *unstructured.Unstructured) map[string]bool {
+	result := map[string]bool{}
+	if v, ok, _ := unstructured.NestedString(crd.Object, "spec", "version"); ok {
+		result[v] = true
+	}
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, x := range versions {
+		if m, ok := x.(map[string]interface{}); ok {
+			if name, ok := m["name"].(string); ok && m["served"] != false {
+				result[name] = true
+			}
+		}
+	}
+	return result
+}
+
+// Warn about spec.config keys the new release will ignore
+func (r *ReconcileKnativeServing) reportDeprecatedConfig(instance *servingv1alpha1.KnativeServing) {
+	for name, data := range instance.Spec.Config {
+		for key := range data {
+			if replacement, ok := deprecatedConfigKeys[name][key]; ok {
+				r.recorder.Eventf(instance, v1.EventTypeWarning, "DeprecatedConfig",
+					"spec.config.%s.%s is no longer used by %s, use spec.config.%s", name, key, targetVersion(instance), replacement)
+			}
+		}
+	}
+}
+
+// Order the resources such that the CRDs are applied first, so the
+// new versions of other resources are understood, and the webhooks
+// last, so they don't reject resources the new release introduces.
+// Namespaces still precede everything, and the migration Jobs follow
+// everything, so they migrate through the new release.
+func upgradeOrder(resources []unstructured.Unstructured) []unstructured.Unstructured {
+	var namespaces, crds, webhooks, jobs, others []unstructured.Unstructured
+	for _, u := range resources {
+		switch {
+		case u.GetKind() == "Namespace":
+			namespaces = append(namespaces, u)
+		case u.GetKind() == "CustomResourceDefinition":
+			crds = append(crds, u)
+		case isWebhook(&u):
+			webhooks = append(webhooks, u)
+		case isMigrationJob(&u):
+			jobs = append(jobs, u)
+		default:
+			others = append(others, u)
+		}
+	}
+	result := append(namespaces, crds...)
+	result = append(result, others...)
+	result = append(result, webhooks...)
+	return append(result, jobs...)
+}
@@ -0,0 +1,141 @@
+package knativeserving
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/version"
+)
+
+const upgradeManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: webhook
+  namespace: knative-serving
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: services.serving.knative.dev
+spec:
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: true
+  - name: v1beta1
+    served: true
+    storage: false
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: knative-serving
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller
+  namespace: knative-serving
+`
+
+func newStoredCRD(storedVersions ...interface{}) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1beta1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName("services.serving.knative.dev")
+	unstructured.SetNestedSlice(crd.Object, storedVersions, "status", "storedVersions")
+	return crd
+}
+
+func TestIsUpgrade(t *testing.T) {
+	if isUpgrade(newInstance()) {
+		t.Error("a fresh install isn't an upgrade")
+	}
+	if isUpgrade(newInstance(withInstalled(version.Version))) {
+		t.Error("reapplying the same release isn't an upgrade")
+	}
+	if !isUpgrade(newInstance(withInstalled("0.6.0"))) {
+		t.Error("expected an upgrade from 0.6.0")
+	}
+}
+
+func TestUpgradeOrder(t *testing.T) {
+	m := newTestManifest(t, upgradeManifest, nil)
+	ordered := upgradeOrder(m.Resources)
+	want := []string{"knative-serving", "services.serving.knative.dev", "controller", "webhook"}
+	if len(ordered) != len(want) {
+		t.Fatalf("got %d resources, want %d", len(ordered), len(want))
+	}
+	for i, name := range want {
+		if got := ordered[i].GetName(); got != name {
+			t.Errorf("resource %d = %s, want %s", i, got, name)
+		}
+	}
+}
+
+func TestPreUpgrade(t *testing.T) {
+	// Configuring a setting the release deprecates
+	instance := newInstance(withInstalled("0.6.0"), withSpec(servingv1alpha1.KnativeServingSpec{
+		Config: map[string]map[string]string{
+			"controller": {"registriesSkippingTagResolving": "ko.local"},
+		},
+	}))
+	c := newFakeClient(newTestScheme(), newStoredCRD("v1alpha1"))
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, recorder: recorder}
+	manifest := newTestManifest(t, upgradeManifest, c)
+
+	if err := r.preUpgrade(instance, &manifest); err != nil {
+		t.Fatalf("preUpgrade() = %v", err)
+	}
+	if !instance.Status.IsUpgradeInProgress() {
+		t.Error("UpgradeInProgress should be set")
+	}
+	if got := manifest.Resources[len(manifest.Resources)-1].GetName(); got != "webhook" {
+		t.Errorf("last resource = %s, want webhook", got)
+	}
+	expectEvent(t, recorder, "Warning DeprecatedConfig")
+	expectEvent(t, recorder, "Normal UpgradeStarted")
+	expectNoEvent(t, recorder)
+}
+
+func TestPreUpgradeBlockedByStoredVersion(t *testing.T) {
+	instance := newInstance(withInstalled("0.6.0"))
+	c := newFakeClient(newTestScheme(), newStoredCRD("v1alpha1", "v1alpha2"))
+	r := &ReconcileKnativeServing{client: c, recorder: record.NewFakeRecorder(10)}
+	manifest := newTestManifest(t, upgradeManifest, c)
+
+	if err := r.preUpgrade(instance, &manifest); err == nil {
+		t.Fatal("expected the dropped stored version to block the upgrade")
+	}
+	if instance.Status.IsUpgradeInProgress() {
+		t.Error("UpgradeInProgress should not be set for a blocked upgrade")
+	}
+}
+
+func TestCompleteUpgrade(t *testing.T) {
+	instance := newInstance(withInstalled(version.Version))
+	instance.Status.MarkUpgradeInProgress("0.6.0", version.Version)
+	c := newFakeClient(newTestScheme(), instance.DeepCopy())
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileKnativeServing{client: c, recorder: recorder}
+
+	// Still waiting on deployments
+	if err := r.completeUpgrade(instance); err != nil {
+		t.Fatalf("completeUpgrade() = %v", err)
+	}
+	if !instance.Status.IsUpgradeInProgress() {
+		t.Error("UpgradeInProgress should remain until deployments are available")
+	}
+
+	instance.Status.MarkDeploymentsAvailable()
+	if err := r.completeUpgrade(instance); err != nil {
+		t.Fatalf("completeUpgrade() = %v", err)
+	}
+	if instance.Status.IsUpgradeInProgress() {
+		t.Error("UpgradeInProgress should be cleared")
+	}
+	expectEvent(t, recorder, "Normal UpgradeSucceeded")
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"knative.dev/pkg/apis"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/pkg/reconciler/knativeserving/common"
+)
+
+// Check the spec before transforming the manifest with it, so that a
+// mistake is reported as such rather than as whatever it breaks: the
+// checks of the webhook, which may not have seen the spec, that every
+// spec.config entry names a ConfigMap the install has, and that only a
+// release electing leaders is replicated.
+func (r *ReconcileKnativeServing) validateSpec(instance *servingv1alpha1.KnativeServing) error {
+	errs := instance.Validate(context.TODO())
+	release, err := r.manifestFor(instance)
+	if err != nil {
+		errs = errs.Also(apis.ErrInvalidValue(instance.Spec.Version, "spec.version"))
+	} else {
+		for _, name := range r.unknownConfigs(instance, release.Resources) {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("no ConfigMap config-%s to configure", name),
+				Paths:   []string{"spec.config." + name},
+				Details: "the keys of spec.config are the names of the release's config-* ConfigMaps without the prefix",
+			})
+		}
+		if ha := instance.Spec.HighAvailability; ha != nil && ha.Replicas > 1 && !common.LeaderElected(release.Resources) {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("release %s doesn't elect leaders, so its controllers can't be replicated", targetVersion(instance)),
+				Paths:   []string{"spec.highAvailability.replicas"},
+				Details: "only a release shipping the ConfigMap config-leader-election runs more than one replica",
+			})
+		}
+	}
+	if errs != nil {
+		instance.Status.MarkSpecInvalid(errs.Error())
+		return permanent(errs)
+	}
+	instance.Status.MarkSpecValidated()
+	return nil
+}
+
+// The keys of spec.config without a ConfigMap among the release, the
+// bundled ingresses or, only loaded if needed, the additional
+// manifests. Those failing to load are left to the transform to report.
+func (r *ReconcileKnativeServing) unknownConfigs(instance *servingv1alpha1.KnativeServing, release []unstructured.Unstructured) []string {
+	var unknown []string
+	for name := range instance.Spec.Config {
+		if !hasConfigMap(release, name) && !r.ingressHasConfigMap(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 && len(instance.Spec.AdditionalManifests) > 0 {
+		additional, err := r.additionalResources(instance)
+		if err != nil {
+			return nil
+		}
+		var remaining []string
+		for _, name := range unknown {
+			if !hasConfigMap(additional, name) {
+				remaining = append(remaining, name)
+			}
+		}
+		unknown = remaining
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+func (r *ReconcileKnativeServing) ingressHasConfigMap(name string) bool {
+	for _, resources := range r.ingresses {
+		if hasConfigMap(resources, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasConfigMap(resources []unstructured.Unstructured, name string) bool {
+	for _, u := range resources {
+		if u.GetKind() == "ConfigMap" && u.GetName() == "config-"+name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package knativeserving
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/version"
+)
+
+func TestValidateSpec(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	r := &ReconcileKnativeServing{client: c, config: newTestManifest(t, testManifest, c)}
+	tests := []struct {
+		name    string
+		spec    servingv1alpha1.KnativeServingSpec
+		wantMsg string
+	}{{
+		name: "valid",
+		spec: servingv1alpha1.KnativeServingSpec{
+			Config: map[string]map[string]string{"network": {"domainTemplate": "{{.Name}}.{{.Domain}}"}},
+		},
+	}, {
+		name: "unknown ConfigMap",
+		spec: servingv1alpha1.KnativeServingSpec{
+			Config: map[string]map[string]string{"netwrk": {"domainTemplate": "{{.Name}}.{{.Domain}}"}},
+		},
+		wantMsg: "no ConfigMap config-netwrk to configure: spec.config.netwrk",
+	}, {
+		name: "conflicting ingresses",
+		spec: servingv1alpha1.KnativeServingSpec{
+			Ingress: &servingv1alpha1.IngressConfigs{
+				Kourier: servingv1alpha1.KourierIngressConfiguration{Enabled: true},
+				Contour: servingv1alpha1.ContourIngressConfiguration{Enabled: true},
+			},
+		},
+		wantMsg: "spec.ingress",
+	}, {
+		name:    "unbundled version",
+		spec:    servingv1alpha1.KnativeServingSpec{Version: "0.1.0"},
+		wantMsg: "spec.version",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &servingv1alpha1.KnativeServing{Spec: tt.spec}
+			instance.Status.InitializeConditions()
+			err := r.validateSpec(instance)
+			condition := instance.Status.GetCondition(servingv1alpha1.SpecValidated)
+			if tt.wantMsg == "" {
+				if err != nil || !condition.IsTrue() {
+					t.Errorf("validateSpec() = %v, SpecValidated = %v, want valid", err, condition)
+				}
+				return
+			}
+			if !isPermanent(err) {
+				t.Errorf("validateSpec() = %v, want a permanent error", err)
+			}
+			if !condition.IsFalse() || !strings.Contains(condition.Message, tt.wantMsg) {
+				t.Errorf("SpecValidated = %v, want False mentioning %q", condition, tt.wantMsg)
+			}
+		})
+	}
+}
+
+// The bundled release ships no config-leader-election, so its controllers
+// can't be replicated: an explicit spec.highAvailability is rejected, and
+// the production profile scales only the others
+func TestHighAvailabilityOfBundledRelease(t *testing.T) {
+	c := newFakeClient(newTestScheme())
+	releases, err := loadReleases("../../../cmd/manager/kodata/knative-serving", c)
+	if err != nil {
+		t.Fatalf("loadReleases() = %v", err)
+	}
+	release := releases[version.Version]
+	r := &ReconcileKnativeServing{client: c, scheme: newTestScheme(), recorder: record.NewFakeRecorder(10), releases: releases, config: release}
+
+	instance := &servingv1alpha1.KnativeServing{Spec: servingv1alpha1.KnativeServingSpec{
+		HighAvailability: &servingv1alpha1.HighAvailability{Replicas: 2},
+	}}
+	instance.Status.InitializeConditions()
+	err = r.validateSpec(instance)
+	if !isPermanent(err) || !strings.Contains(err.Error(), "spec.highAvailability.replicas") {
+		t.Errorf("validateSpec() = %v, want spec.highAvailability.replicas rejected", err)
+	}
+
+	instance = &servingv1alpha1.KnativeServing{Spec: servingv1alpha1.KnativeServingSpec{
+		Profile: servingv1alpha1.ProductionProfile,
+	}}
+	manifest, err := r.transform(instance, nil)
+	if err != nil {
+		t.Fatalf("transform() = %v", err)
+	}
+	want := map[string]int64{"activator": 3, "controller": 1}
+	for _, u := range manifest.Resources {
+		if u.GetKind() != "Deployment" {
+			continue
+		}
+		if replicas, ok := want[u.GetName()]; ok {
+			got, _, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+			if got != replicas {
+				t.Errorf("%s replicas = %d, want %d", u.GetName(), got, replicas)
+			}
+			delete(want, u.GetName())
+		}
+	}
+	if len(want) != 0 {
+		t.Errorf("deployments %v missing from the release", want)
+	}
+}
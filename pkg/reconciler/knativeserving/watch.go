@@ -0,0 +1,213 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"context"
+
+	"github.com/operator-framework/operator-sdk/pkg/predicate"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/pkg/scope"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	crpredicate "sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// Mark every resource the operator applies with the KnativeServing
+	// it belongs to, including those the KnativeServing can't own
+	ownerNameLabel      = "operator.knative.dev/owner-name"
+	ownerNamespaceLabel = "operator.knative.dev/owner-namespace"
+)
+
+// The namespaced kinds of the install, owned by the KnativeServing
+// when it's in the same namespace and labeled otherwise
+var namespaced = []runtime.Object{
+	&appsv1.Deployment{},
+	&corev1.ConfigMap{},
+	&corev1.Service{},
+	&corev1.ServiceAccount{},
+	&rbacv1.RoleBinding{},
+}
+
+// The cluster-scoped kinds of the install, which the namespaced
+// KnativeServing can't own
+var clusterScoped = []runtime.Object{
+	&apiextensionsv1beta1.CustomResourceDefinition{},
+	&admissionregistrationv1beta1.ValidatingWebhookConfiguration{},
+	&admissionregistrationv1beta1.MutatingWebhookConfiguration{},
+	&rbacv1.ClusterRole{},
+	&rbacv1.ClusterRoleBinding{},
+}
+
+// Label the resources with the KnativeServing they belong to
+func labelOwner(resources []unstructured.Unstructured, instance *servingv1alpha1.KnativeServing) {
+	for i := range resources {
+		u := &resources[i]
+		l := u.GetLabels()
+		if l == nil {
+			l = map[string]string{}
+		}
+		l[ownerNameLabel] = instance.GetName()
+		l[ownerNamespaceLabel] = instance.GetNamespace()
+		u.SetLabels(l)
+	}
+}
+
+// Watch the namespaced resources the operator applied: those the
+// KnativeServing owns, and those it can't own because they're in
+// another namespace by their labels
+func watchNamespaced(c controller.Controller) error {
+	for _, t := range namespaced {
+		err := c.Watch(&source.Kind{Type: t}, &handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &servingv1alpha1.KnativeServing{},
+		})
+		if err != nil {
+			return err
+		}
+		err = c.Watch(&source.Kind{Type: t}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: enqueueOwner(nil),
+		}, unownedPredicate)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Watch the cluster-scoped resources the operator applied, so that
+// deleting or editing one, e.g. the webhook configuration, is
+// repaired promptly rather than on the next change of the KnativeServing
+func watchClusterScoped(mgr manager.Manager, c controller.Controller) error {
+	if err := apiextensionsv1beta1.AddToScheme(mgr.GetScheme()); err != nil {
+		return err
+	}
+	for _, t := range clusterScoped {
+		err := c.Watch(&source.Kind{Type: t}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: enqueueOwner(enqueueKnativeServings(mgr.GetClient())),
+		}, installedPredicate{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Every installed resource belongs to the active KnativeServing, so
+// enqueue the watched ones of the shard to let it be found
+func enqueueKnativeServings(c client.Client) handler.ToRequestsFunc {
+	return func(handler.MapObject) []reconcile.Request {
+		list := &servingv1alpha1.KnativeServingList{}
+		if err := c.List(context.TODO(), &client.ListOptions{}, list); err != nil {
+			log.Error(err, "Failed to list KnativeServings")
+			return nil
+		}
+		var result []reconcile.Request
+		for _, ks := range list.Items {
+			if scope.Owns(ks.GetNamespace()) {
+				result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: ks.GetNamespace(), Name: ks.GetName()}})
+			}
+		}
+		return result
+	}
+}
+
+// Enqueue the KnativeServing of the owner labels, or else fall back,
+// e.g. for a resource applied before the labels were
+func enqueueOwner(fallback handler.ToRequestsFunc) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []reconcile.Request {
+		var name, namespace string
+		if o.Meta != nil {
+			name, namespace = o.Meta.GetLabels()[ownerNameLabel], o.Meta.GetLabels()[ownerNamespaceLabel]
+		}
+		if name == "" || namespace == "" {
+			if fallback == nil {
+				return nil
+			}
+			return fallback(o)
+		}
+		if !scope.Owns(namespace) {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+	}
+}
+
+// unownedPredicate passes the changes and deletions of the labeled
+// resources without an owner, which EnqueueRequestForOwner misses
+var unownedPredicate = crpredicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	UpdateFunc:  func(e event.UpdateEvent) bool { return isUnowned(e.MetaNew) },
+	DeleteFunc:  func(e event.DeleteEvent) bool { return isUnowned(e.Meta) },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+func isUnowned(m metav1.Object) bool {
+	return m != nil && m.GetLabels()[ownerNameLabel] != "" && metav1.GetControllerOf(m) == nil
+}
+
+// deletedPredicate only passes deletions
+var deletedPredicate = crpredicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	UpdateFunc:  func(event.UpdateEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// installedPredicate passes the deletion of the resources labeled by
+// the operator and the changes of their spec, but not their creation,
+// which the operator itself does
+type installedPredicate struct {
+	predicate.GenerationChangedPredicate
+}
+
+func (installedPredicate) Create(event.CreateEvent) bool {
+	return false
+}
+
+func (installedPredicate) Delete(e event.DeleteEvent) bool {
+	return isInstalled(e.Meta)
+}
+
+func (p installedPredicate) Update(e event.UpdateEvent) bool {
+	return isInstalled(e.MetaNew) && p.GenerationChangedPredicate.Update(e)
+}
+
+func (installedPredicate) Generic(event.GenericEvent) bool {
+	return false
+}
+
+func isInstalled(m metav1.Object) bool {
+	if m == nil {
+		return false
+	}
+	_, ok := m.GetLabels()[releaseLabel]
+	return ok
+}
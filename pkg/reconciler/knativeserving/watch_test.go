@@ -0,0 +1,197 @@
+package knativeserving
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving-operator/pkg/scope"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestInstalledPredicate(t *testing.T) {
+	installed := &metav1.ObjectMeta{Name: "webhook.serving.knative.dev", Labels: map[string]string{releaseLabel: "0.7.0"}, Generation: 1}
+	other := &metav1.ObjectMeta{Name: "istio-sidecar-injector", Generation: 1}
+	edited := installed.DeepCopy()
+	edited.Generation = 2
+	p := installedPredicate{}
+
+	if p.Create(event.CreateEvent{Meta: installed}) {
+		t.Error("the operator's own creations shouldn't trigger a reconcile")
+	}
+	if !p.Delete(event.DeleteEvent{Meta: installed}) {
+		t.Error("deleting an installed resource should trigger a reconcile")
+	}
+	if p.Delete(event.DeleteEvent{Meta: other}) {
+		t.Error("deleting an unrelated resource shouldn't trigger a reconcile")
+	}
+	if !p.Update(event.UpdateEvent{MetaOld: installed, MetaNew: edited, ObjectOld: &apiextensionsv1beta1.CustomResourceDefinition{}, ObjectNew: &apiextensionsv1beta1.CustomResourceDefinition{}}) {
+		t.Error("editing an installed resource should trigger a reconcile")
+	}
+	if p.Update(event.UpdateEvent{MetaOld: installed, MetaNew: installed, ObjectOld: &apiextensionsv1beta1.CustomResourceDefinition{}, ObjectNew: &apiextensionsv1beta1.CustomResourceDefinition{}}) {
+		t.Error("a status update shouldn't trigger a reconcile")
+	}
+}
+
+func TestEnqueueKnativeServings(t *testing.T) {
+	defer flag.Set("watch-namespaces", operand)
+	flag.Set("watch-namespaces", operand+",team-a")
+	c := newFakeClient(newTestScheme(),
+		&servingv1alpha1.KnativeServing{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand}},
+		&servingv1alpha1.KnativeServing{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "serving"}},
+		&servingv1alpha1.KnativeServing{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "serving"}})
+
+	requests := enqueueKnativeServings(c)(handler.MapObject{})
+	if len(requests) != 2 {
+		t.Errorf("enqueueKnativeServings() = %v, want those in %s and team-a", requests, operand)
+	}
+}
+
+func TestEnqueueKnativeServingsOfShard(t *testing.T) {
+	defer flag.Set("watch-namespaces", operand)
+	defer flag.Set("shards", "1")
+	defer flag.Set("shard-index", "-1")
+	flag.Set("watch-namespaces", "")
+	flag.Set("shards", "2")
+	flag.Set("shard-index", strconv.Itoa(scope.ShardOf("team-a")))
+	namespaces := []string{operand, "team-a", "team-b", "team-c"}
+	var objs []runtime.Object
+	want := 0
+	for _, ns := range namespaces {
+		objs = append(objs, &servingv1alpha1.KnativeServing{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "serving"}})
+		if scope.ShardOf(ns) == scope.ShardOf("team-a") {
+			want++
+		}
+	}
+	c := newFakeClient(newTestScheme(), objs...)
+
+	requests := enqueueKnativeServings(c)(handler.MapObject{})
+	if len(requests) != want {
+		t.Errorf("enqueueKnativeServings() = %v, want the %d of the shard", requests, want)
+	}
+	for _, request := range requests {
+		if !scope.InShard(request.Namespace) {
+			t.Errorf("enqueued %v of another shard", request)
+		}
+	}
+}
+
+func TestActiveIsOldestWatched(t *testing.T) {
+	defer flag.Set("watch-namespaces", operand)
+	flag.Set("watch-namespaces", "")
+	older := metav1.NewTime(time.Now().Add(-time.Hour))
+	first := &servingv1alpha1.KnativeServing{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "serving", CreationTimestamp: older}}
+	second := &servingv1alpha1.KnativeServing{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand, CreationTimestamp: metav1.Now()}}
+	r := &ReconcileKnativeServing{client: newFakeClient(newTestScheme(), first, second)}
+
+	active, err := r.active()
+	if err != nil || active.GetNamespace() != "team-a" {
+		t.Fatalf("active() = %v, %v, want team-a/serving", active, err)
+	}
+	if reason := ignoredReason(first, active); reason != "" {
+		t.Errorf("the active KnativeServing is ignored: %s", reason)
+	}
+	if reason := ignoredReason(second, active); !strings.Contains(reason, "team-a/serving") {
+		t.Errorf("ignoredReason() = %q, want the conflict with team-a/serving", reason)
+	}
+
+	flag.Set("watch-namespaces", operand)
+	if active, _ := r.active(); active.GetNamespace() != operand {
+		t.Errorf("active() = %v, want the only watched %s/%s", active, operand, operand)
+	}
+	if reason := ignoredReason(first, nil); !strings.Contains(reason, "doesn't watch") {
+		t.Errorf("ignoredReason() = %q, want the unwatched namespace", reason)
+	}
+}
+
+func TestActiveIsPinned(t *testing.T) {
+	defer flag.Set("knative-serving-name", "")
+	flag.Set("knative-serving-name", "tenant")
+	older := metav1.NewTime(time.Now().Add(-time.Hour))
+	first := &servingv1alpha1.KnativeServing{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand, CreationTimestamp: older}}
+	pinned := &servingv1alpha1.KnativeServing{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: "tenant", CreationTimestamp: metav1.Now()}}
+	r := &ReconcileKnativeServing{client: newFakeClient(newTestScheme(), first, pinned)}
+
+	active, err := r.active()
+	if err != nil || active.GetName() != "tenant" {
+		t.Fatalf("active() = %v, %v, want the pinned %s/tenant", active, err, operand)
+	}
+	if reason := ignoredReason(pinned, active); reason != "" {
+		t.Errorf("the pinned KnativeServing is ignored: %s", reason)
+	}
+	// Ignored even once the pinned one is gone
+	if reason := ignoredReason(first, nil); !strings.Contains(reason, "only reconciles KnativeServing "+operand+"/tenant") {
+		t.Errorf("ignoredReason() = %q, want the pinned KnativeServing", reason)
+	}
+}
+
+func TestEnqueueOwner(t *testing.T) {
+	defer flag.Set("watch-namespaces", operand)
+	flag.Set("watch-namespaces", operand+",team-a")
+	labeled := &metav1.ObjectMeta{Name: "config-network", Labels: map[string]string{
+		ownerNameLabel:      "serving",
+		ownerNamespaceLabel: "team-a",
+	}}
+	requests := enqueueOwner(nil)(handler.MapObject{Meta: labeled})
+	if len(requests) != 1 || requests[0].Namespace != "team-a" || requests[0].Name != "serving" {
+		t.Errorf("enqueueOwner() = %v, want team-a/serving", requests)
+	}
+
+	labeled.Labels[ownerNamespaceLabel] = "team-b"
+	if requests := enqueueOwner(nil)(handler.MapObject{Meta: labeled}); len(requests) != 0 {
+		t.Errorf("enqueueOwner() = %v, want none outside the watched namespaces", requests)
+	}
+
+	fellBack := false
+	fallback := func(handler.MapObject) []reconcile.Request {
+		fellBack = true
+		return nil
+	}
+	enqueueOwner(fallback)(handler.MapObject{Meta: &metav1.ObjectMeta{Name: "webhook.serving.knative.dev"}})
+	if !fellBack {
+		t.Error("expected an unlabeled resource to fall back")
+	}
+}
+
+func TestUnownedPredicate(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: operand}}
+	owned := &metav1.ObjectMeta{
+		Name:            "controller",
+		Labels:          map[string]string{ownerNameLabel: operand, ownerNamespaceLabel: operand},
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(instance, instance.GroupVersionKind())},
+	}
+	unowned := owned.DeepCopy()
+	unowned.OwnerReferences = nil
+
+	if unownedPredicate.Delete(event.DeleteEvent{Meta: owned}) {
+		t.Error("an owned resource is already enqueued for its owner")
+	}
+	if !unownedPredicate.Delete(event.DeleteEvent{Meta: unowned}) {
+		t.Error("deleting a labeled resource without an owner should trigger a reconcile")
+	}
+	if !unownedPredicate.Update(event.UpdateEvent{MetaOld: unowned, MetaNew: unowned}) {
+		t.Error("editing a labeled resource without an owner should trigger a reconcile")
+	}
+	if unownedPredicate.Create(event.CreateEvent{Meta: unowned}) {
+		t.Error("the operator's own creations shouldn't trigger a reconcile")
+	}
+}
+
+func TestLabelOwner(t *testing.T) {
+	instance := &servingv1alpha1.KnativeServing{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "serving"}}
+	resources := []unstructured.Unstructured{*newReleaseConfigMap("config-network", "0.7.0")}
+	labelOwner(resources, instance)
+	labels := resources[0].GetLabels()
+	if labels[ownerNameLabel] != "serving" || labels[ownerNamespaceLabel] != "team-a" || labels[releaseLabel] != "0.7.0" {
+		t.Errorf("labels = %v, want the owner added to the release", labels)
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package knativeserving
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"time"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// The rest of the Secret the Serving webhook provisions
+	webhookServerKeyKey  = "server-key.pem"
+	webhookServerCertKey = "server-cert.pem"
+	// The configuration the Serving webhook registers with its CA
+	webhookConfiguration = "webhook.serving.knative.dev"
+	webhookDeployment    = "webhook"
+	// Changing it rolls the pods of a deployment
+	restartedAtAnnotation = "operator.knative.dev/restarted-at"
+)
+
+var webhookCertRenewBefore = flag.Duration("webhook-cert-renew-before", 7*24*time.Hour,
+	"Regenerate the Serving webhook's certificate once it expires within this duration")
+
+// Repair the Serving webhook's TLS, e.g. after a restore: a certificate
+// that's invalid or about to expire is deleted, for the webhook to
+// generate anew when restarted, and a webhook registered with a CA
+// other than its certificate's is restarted to register again
+func (r *ReconcileKnativeServing) checkWebhookCerts(instance *servingv1alpha1.KnativeServing) error {
+	if instance.Spec.Hibernate {
+		return nil
+	}
+	namespace := instance.InstallNamespace()
+	deployment := &appsv1.Deployment{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: webhookDeployment}, deployment); errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if rollingOut(deployment) {
+		// It may not have provisioned or registered its certificate yet
+		return nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: webhookCertsSecret}, secret); errors.IsNotFound(err) {
+		// The webhook provisions it when it starts
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if problem := webhookCertProblem(secret, namespace, time.Now()); problem != "" {
+		log.Info("Regenerating the webhook certificate", "reason", problem)
+		if err := r.client.Delete(context.TODO(), secret); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		r.recorder.Eventf(instance, corev1.EventTypeWarning, "WebhookCertRegenerated",
+			"Regenerating the webhook certificate: %s", problem)
+		return r.restartDeployment(deployment)
+	}
+	registered, err := r.webhookCABundles()
+	if err != nil {
+		return err
+	}
+	for _, bundle := range registered {
+		if !bytes.Equal(bundle, secret.Data[webhookCACertKey]) {
+			log.Info("Restarting the webhook to register its CA")
+			r.recorder.Event(instance, corev1.EventTypeWarning, "WebhookRestarted",
+				"Restarting the webhook, registered with a CA other than its certificate's")
+			return r.restartDeployment(deployment)
+		}
+	}
+	return nil
+}
+
+// Why the webhook's certificate can't serve, if it can't: it's
+// malformed, doesn't match its key, isn't signed by the CA for the
+// webhook's service or expires within --webhook-cert-renew-before
+func webhookCertProblem(secret *corev1.Secret, namespace string, now time.Time) string {
+	certPEM, keyPEM, caPEM := secret.Data[webhookServerCertKey], secret.Data[webhookServerKeyKey], secret.Data[webhookCACertKey]
+	if len(certPEM) == 0 || len(keyPEM) == 0 || len(caPEM) == 0 {
+		return "the certificate, its key or its CA is missing"
+	}
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return fmt.Sprintf("the certificate doesn't match its key: %v", err)
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return "the certificate isn't PEM encoded"
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Sprintf("the certificate is malformed: %v", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return "the CA certificate is malformed"
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		DNSName:     fmt.Sprintf("%s.%s.svc", webhookDeployment, namespace),
+		Roots:       roots,
+		CurrentTime: now,
+	})
+	if err != nil {
+		return fmt.Sprintf("the certificate isn't valid: %v", err)
+	}
+	if expiry := cert.NotAfter; now.Add(*webhookCertRenewBefore).After(expiry) {
+		return fmt.Sprintf("the certificate expires at %s", expiry.UTC().Format(time.RFC3339))
+	}
+	return ""
+}
+
+// The CA bundles the webhook registered, if it has yet
+func (r *ReconcileKnativeServing) webhookCABundles() ([][]byte, error) {
+	config := &admissionregistrationv1beta1.MutatingWebhookConfiguration{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Name: webhookConfiguration}, config); errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var result [][]byte
+	for _, webhook := range config.Webhooks {
+		result = append(result, webhook.ClientConfig.CABundle)
+	}
+	return result, nil
+}
+
+// Roll the pods of the deployment, as kubectl rollout restart does
+func (r *ReconcileKnativeServing) restartDeployment(deployment *appsv1.Deployment) error {
+	if deployment.Spec.Template.Annotations == nil {
+		deployment.Spec.Template.Annotations = map[string]string{}
+	}
+	deployment.Spec.Template.Annotations[restartedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	return r.client.Update(context.TODO(), deployment)
+}
+
+// Whether the deployment's latest pods aren't all updated yet
+func rollingOut(deployment *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	return status.ObservedGeneration < deployment.Generation || status.UpdatedReplicas < replicas ||
+		status.Replicas > status.UpdatedReplicas
+}
@@ -0,0 +1,200 @@
+package knativeserving
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// A Secret like the Serving webhook provisions, for the given DNS name
+func newWebhookCerts(t *testing.T, dnsName string, notAfter time.Time) *corev1.Secret {
+	t.Helper()
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"knative.dev"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{Organization: []string{"knative.dev"}},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	serverDER, err := x509.CreateCertificate(rand.Reader, serverTemplate, caTemplate, &serverKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: webhookCertsSecret},
+		Data: map[string][]byte{
+			webhookServerKeyKey:  pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(serverKey)}),
+			webhookServerCertKey: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverDER}),
+			webhookCACertKey:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		},
+	}
+}
+
+func newWebhookDeployment() *appsv1.Deployment {
+	replicas := int32(1)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: webhookDeployment},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1},
+	}
+}
+
+func newWebhookConfiguration(caBundle []byte) *admissionregistrationv1beta1.MutatingWebhookConfiguration {
+	return &admissionregistrationv1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: webhookConfiguration},
+		Webhooks: []admissionregistrationv1beta1.Webhook{{
+			Name:         webhookConfiguration,
+			ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{CABundle: caBundle},
+		}},
+	}
+}
+
+func TestWebhookCertProblem(t *testing.T) {
+	now := time.Now()
+	valid := newWebhookCerts(t, "webhook.knative-serving.svc", now.Add(365*24*time.Hour))
+	mismatched := valid.DeepCopy()
+	mismatched.Data[webhookServerKeyKey] = newWebhookCerts(t, "webhook.knative-serving.svc", now.Add(time.Hour)).Data[webhookServerKeyKey]
+
+	tests := []struct {
+		name    string
+		secret  *corev1.Secret
+		problem bool
+	}{{
+		name:   "valid",
+		secret: valid,
+	}, {
+		name:    "empty",
+		secret:  &corev1.Secret{},
+		problem: true,
+	}, {
+		name:    "mismatched key",
+		secret:  mismatched,
+		problem: true,
+	}, {
+		name:    "wrong name",
+		secret:  newWebhookCerts(t, "webhook.default.svc", now.Add(365*24*time.Hour)),
+		problem: true,
+	}, {
+		name:    "expiring",
+		secret:  newWebhookCerts(t, "webhook.knative-serving.svc", now.Add(24*time.Hour)),
+		problem: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problem := webhookCertProblem(test.secret, "knative-serving", now)
+			if (problem != "") != test.problem {
+				t.Errorf("webhookCertProblem() = %q, want a problem: %v", problem, test.problem)
+			}
+		})
+	}
+}
+
+func TestCheckWebhookCertsRegenerates(t *testing.T) {
+	instance := newInstance(withInstalled("0.7.0"))
+	r, recorder := newTestReconciler(instance)
+	secret := newWebhookCerts(t, "webhook.knative-serving.svc", time.Now().Add(time.Hour))
+	r.client = newFakeClient(newTestScheme(), instance.DeepCopy(), newWebhookDeployment(), secret,
+		newWebhookConfiguration(secret.Data[webhookCACertKey]))
+
+	if err := r.checkWebhookCerts(instance); err != nil {
+		t.Fatalf("checkWebhookCerts() = %v", err)
+	}
+	err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: webhookCertsSecret}, &corev1.Secret{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("Get(secret) = %v, want the expiring certificate deleted", err)
+	}
+	expectRestarted(t, r)
+	expectEvent(t, recorder, "Warning WebhookCertRegenerated")
+}
+
+func TestCheckWebhookCertsRestartsForCABundle(t *testing.T) {
+	instance := newInstance(withInstalled("0.7.0"))
+	r, recorder := newTestReconciler(instance)
+	secret := newWebhookCerts(t, "webhook.knative-serving.svc", time.Now().Add(365*24*time.Hour))
+	r.client = newFakeClient(newTestScheme(), instance.DeepCopy(), newWebhookDeployment(), secret,
+		newWebhookConfiguration([]byte("restored")))
+
+	if err := r.checkWebhookCerts(instance); err != nil {
+		t.Fatalf("checkWebhookCerts() = %v", err)
+	}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: webhookCertsSecret}, &corev1.Secret{}); err != nil {
+		t.Errorf("Get(secret) = %v, want the valid certificate kept", err)
+	}
+	expectRestarted(t, r)
+	expectEvent(t, recorder, "Warning WebhookRestarted")
+}
+
+func TestCheckWebhookCertsHealthy(t *testing.T) {
+	instance := newInstance(withInstalled("0.7.0"))
+	r, recorder := newTestReconciler(instance)
+	secret := newWebhookCerts(t, "webhook.knative-serving.svc", time.Now().Add(365*24*time.Hour))
+	r.client = newFakeClient(newTestScheme(), instance.DeepCopy(), newWebhookDeployment(), secret,
+		newWebhookConfiguration(secret.Data[webhookCACertKey]))
+
+	if err := r.checkWebhookCerts(instance); err != nil {
+		t.Fatalf("checkWebhookCerts() = %v", err)
+	}
+	expectNoEvent(t, recorder)
+}
+
+func TestCheckWebhookCertsWaitsForRollout(t *testing.T) {
+	instance := newInstance(withInstalled("0.7.0"))
+	r, recorder := newTestReconciler(instance)
+	deployment := newWebhookDeployment()
+	deployment.Status.UpdatedReplicas = 0
+	r.client = newFakeClient(newTestScheme(), instance.DeepCopy(), deployment, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operand, Name: webhookCertsSecret},
+	})
+
+	if err := r.checkWebhookCerts(instance); err != nil {
+		t.Fatalf("checkWebhookCerts() = %v", err)
+	}
+	expectNoEvent(t, recorder)
+}
+
+func expectRestarted(t *testing.T, r *ReconcileKnativeServing) {
+	t.Helper()
+	deployment := &appsv1.Deployment{}
+	if err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: operand, Name: webhookDeployment}, deployment); err != nil {
+		t.Fatalf("Get(deployment) = %v", err)
+	}
+	if _, ok := deployment.Spec.Template.Annotations[restartedAtAnnotation]; !ok {
+		t.Errorf("expected the webhook deployment to be restarted")
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scope
+
+import (
+	"flag"
+	"os"
+	"strings"
+)
+
+const (
+	// Where the KnativeServing resource is expected by default
+	defaultNamespace = "knative-serving"
+)
+
+var (
+	namespaces = flag.String("watch-namespaces", defaultNamespace,
+		"Comma-separated namespaces whose KnativeServing resources the operator reconciles; empty for all of them")
+	pinnedName = flag.String("knative-serving-name", os.Getenv("KNATIVE_SERVING_NAME"),
+		"The name of the only KnativeServing the operator reconciles, ignoring any other; $KNATIVE_SERVING_NAME by default")
+	pinnedNamespace = flag.String("knative-serving-namespace", os.Getenv("KNATIVE_SERVING_NAMESPACE"),
+		"The namespace of the --knative-serving-name KnativeServing, knative-serving if empty; $KNATIVE_SERVING_NAMESPACE by default")
+)
+
+// Namespaces returns the namespaces whose KnativeServing resources the
+// operator reconciles, or nil for all of them
+func Namespaces() []string {
+	var result []string
+	for _, ns := range strings.Split(*namespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			result = append(result, ns)
+		}
+	}
+	return result
+}
+
+// Watches reports whether a KnativeServing in the namespace is
+// reconciled
+func Watches(namespace string) bool {
+	list := Namespaces()
+	if len(list) == 0 {
+		return true
+	}
+	for _, ns := range list {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// Pinned returns the namespace and name of the only KnativeServing the
+// operator reconciles, if it's pinned to one
+func Pinned() (namespace, name string, ok bool) {
+	if *pinnedName == "" {
+		return "", "", false
+	}
+	namespace = *pinnedNamespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	return namespace, *pinnedName, true
+}
+
+// Selects reports whether the KnativeServing may install Knative
+// Serving: it's watched and, if the operator is pinned, the one
+func Selects(namespace, name string) bool {
+	if !Watches(namespace) {
+		return false
+	}
+	pinnedNS, pinned, ok := Pinned()
+	return !ok || (namespace == pinnedNS && name == pinned)
+}
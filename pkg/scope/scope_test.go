@@ -0,0 +1,62 @@
+package scope
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestNamespaces(t *testing.T) {
+	defer flag.Set("watch-namespaces", defaultNamespace)
+
+	tests := []struct {
+		flag    string
+		want    []string
+		watched string
+		ignored string
+	}{
+		{defaultNamespace, []string{defaultNamespace}, defaultNamespace, "default"},
+		{"team-a, team-b,", []string{"team-a", "team-b"}, "team-b", defaultNamespace},
+		{"", nil, "anywhere", ""},
+	}
+	for _, tt := range tests {
+		flag.Set("watch-namespaces", tt.flag)
+		if got := Namespaces(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Namespaces() = %v for %q, want %v", got, tt.flag, tt.want)
+		}
+		if !Watches(tt.watched) {
+			t.Errorf("Watches(%s) = false for %q", tt.watched, tt.flag)
+		}
+		if tt.ignored != "" && Watches(tt.ignored) {
+			t.Errorf("Watches(%s) = true for %q", tt.ignored, tt.flag)
+		}
+	}
+}
+
+func TestPinned(t *testing.T) {
+	defer flag.Set("knative-serving-name", "")
+	defer flag.Set("knative-serving-namespace", "")
+
+	if _, _, ok := Pinned(); ok {
+		t.Error("expected no pin by default")
+	}
+	if !Selects(defaultNamespace, "anything") {
+		t.Error("expected any watched KnativeServing to be selected without a pin")
+	}
+
+	flag.Set("knative-serving-name", "tenant")
+	if namespace, name, ok := Pinned(); !ok || namespace != defaultNamespace || name != "tenant" {
+		t.Errorf("Pinned() = %s, %s, %v, want %s, tenant, true", namespace, name, ok, defaultNamespace)
+	}
+	if !Selects(defaultNamespace, "tenant") {
+		t.Error("expected the pinned KnativeServing to be selected")
+	}
+	if Selects(defaultNamespace, "knative-serving") {
+		t.Error("expected another KnativeServing to be ignored")
+	}
+
+	flag.Set("knative-serving-namespace", "team-a")
+	if Selects("team-a", "tenant") {
+		t.Error("expected a KnativeServing outside the watched namespaces to be ignored")
+	}
+}
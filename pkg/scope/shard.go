@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package scope
+
+import (
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	shards = flag.Int("shards", 1,
+		"How many operators split the KnativeServing resources of the watched namespaces, by namespace hash")
+	shardIndex = flag.Int("shard-index", -1,
+		"This operator's shard, from 0 to --shards - 1; if negative, the ordinal suffix of its hostname, "+
+			"e.g. 2 for the knative-serving-operator-2 pod of a StatefulSet")
+)
+
+// Sharded reports whether several operators split the KnativeServing
+// resources
+func Sharded() bool {
+	return *shards > 1
+}
+
+// ShardIndex returns the shard of the operator, 0 unless sharded
+func ShardIndex() (int, error) {
+	if !Sharded() {
+		return 0, nil
+	}
+	index := *shardIndex
+	if index < 0 {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return 0, err
+		}
+		if index, err = ordinal(hostname); err != nil {
+			return 0, fmt.Errorf("--shard-index not set and %v", err)
+		}
+	}
+	if index >= *shards {
+		return 0, fmt.Errorf("shard %d out of range of %d shards", index, *shards)
+	}
+	return index, nil
+}
+
+// The ordinal suffix a StatefulSet gives the names of its pods
+func ordinal(hostname string) (int, error) {
+	i := strings.LastIndex(hostname, "-")
+	index, err := strconv.Atoi(hostname[i+1:])
+	if i < 0 || err != nil || index < 0 {
+		return 0, fmt.Errorf("hostname %s has no ordinal suffix", hostname)
+	}
+	return index, nil
+}
+
+// ShardOf returns the shard the KnativeServing resources of the
+// namespace belong to
+func ShardOf(namespace string) int {
+	if !Sharded() {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(namespace))
+	return int(h.Sum32() % uint32(*shards))
+}
+
+// InShard reports whether the namespace belongs to the operator's
+// shard, whether or not it's watched. Another shard handles the others.
+func InShard(namespace string) bool {
+	index, err := ShardIndex()
+	return err == nil && ShardOf(namespace) == index
+}
+
+// Owns reports whether the operator reconciles the KnativeServing
+// resources of the namespace: it's watched and in the operator's shard
+func Owns(namespace string) bool {
+	return Watches(namespace) && InShard(namespace)
+}
+
+// LockName returns the name of the operator's leader lock, one per
+// shard so that only a single replica reconciles each
+func LockName(name string) string {
+	if !Sharded() {
+		return name
+	}
+	index, _ := ShardIndex()
+	return fmt.Sprintf("%s-shard-%d", name, index)
+}
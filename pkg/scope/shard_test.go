@@ -0,0 +1,57 @@
+package scope
+
+import (
+	"flag"
+	"strconv"
+	"testing"
+)
+
+func TestShards(t *testing.T) {
+	defer flag.Set("shards", "1")
+	defer flag.Set("shard-index", "-1")
+	defer flag.Set("watch-namespaces", defaultNamespace)
+	flag.Set("watch-namespaces", "")
+
+	if Sharded() || !Owns("team-a") || LockName("lock") != "lock" {
+		t.Error("expected a single operator to own every watched namespace")
+	}
+
+	flag.Set("shards", "3")
+	namespaces := []string{"team-a", "team-b", "team-c", "team-d", "team-e", "team-f"}
+	owners := map[string]int{}
+	for index := 0; index < 3; index++ {
+		flag.Set("shard-index", strconv.Itoa(index))
+		if got := LockName("lock"); got != "lock-shard-"+strconv.Itoa(index) {
+			t.Errorf("LockName() = %s for shard %d", got, index)
+		}
+		for _, ns := range namespaces {
+			if Owns(ns) {
+				owners[ns]++
+				if ShardOf(ns) != index {
+					t.Errorf("shard %d owns %s of shard %d", index, ns, ShardOf(ns))
+				}
+			}
+		}
+	}
+	for _, ns := range namespaces {
+		if owners[ns] != 1 {
+			t.Errorf("%s owned by %d shards, want exactly one", ns, owners[ns])
+		}
+	}
+
+	flag.Set("shard-index", "3")
+	if _, err := ShardIndex(); err == nil {
+		t.Error("expected a shard out of range to be rejected")
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	if got, err := ordinal("knative-serving-operator-2"); err != nil || got != 2 {
+		t.Errorf("ordinal() = %d, %v, want 2", got, err)
+	}
+	for _, hostname := range []string{"operator", "knative-serving-operator-6d4c9b7f5-x2k8p"} {
+		if _, err := ordinal(hostname); err == nil {
+			t.Errorf("ordinal(%s) = nil, want an error", hostname)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Keys of the certificates secret
+	serverKeyKey  = "server-key.pem"
+	serverCertKey = "server-cert.pem"
+	caCertKey     = "ca-cert.pem"
+
+	certValidity = 10 * 365 * 24 * time.Hour
+)
+
+// certs holds the PEM encoded serving key pair and the CA that signed it
+type certs struct {
+	serverKey  []byte
+	serverCert []byte
+	caCert     []byte
+}
+
+// Read the certificates from their secret, generating it if necessary
+func getOrGenerateCerts(c client.Client, namespace string) (*certs, error) {
+	secret := &corev1.Secret{}
+	err := c.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: secretName}, secret)
+	if err == nil {
+		result := &certs{
+			serverKey:  secret.Data[serverKeyKey],
+			serverCert: secret.Data[serverCertKey],
+			caCert:     secret.Data[caCertKey],
+		}
+		if len(result.serverKey) == 0 || len(result.serverCert) == 0 || len(result.caCert) == 0 {
+			return nil, fmt.Errorf("secret %s/%s is missing certificates", namespace, secretName)
+		}
+		return result, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	log.Info("Generating webhook certificates", "secret", secretName)
+	result, err := createCerts(serviceName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	secret.Namespace = namespace
+	secret.Name = secretName
+	secret.Data = map[string][]byte{
+		serverKeyKey:  result.serverKey,
+		serverCertKey: result.serverCert,
+		caCertKey:     result.caCert,
+	}
+	if err := c.Create(context.TODO(), secret); err != nil {
+		if errors.IsAlreadyExists(err) {
+			// Another replica won the race
+			return getOrGenerateCerts(c, namespace)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// Create a self-signed CA and a serving certificate for the service
+func createCerts(service, namespace string) (*certs, error) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	caTemplate, err := certTemplate(fmt.Sprintf("%s.%s.svc", service, namespace))
+	if err != nil {
+		return nil, err
+	}
+	caTemplate.IsCA = true
+	caTemplate.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageCRLSign
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+
+	serverKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	serverTemplate, err := certTemplate(fmt.Sprintf("%s.%s.svc", service, namespace))
+	if err != nil {
+		return nil, err
+	}
+	serverTemplate.DNSNames = []string{
+		service,
+		fmt.Sprintf("%s.%s", service, namespace),
+		fmt.Sprintf("%s.%s.svc", service, namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", service, namespace),
+	}
+	serverTemplate.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	serverTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	serverDER, err := x509.CreateCertificate(rand.Reader, serverTemplate, ca, &serverKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &certs{
+		serverKey:  pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(serverKey)}),
+		serverCert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverDER}),
+		caCert:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+	}, nil
+}
+
+func certTemplate(commonName string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	return &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"knative.dev"}, CommonName: commonName},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(certValidity),
+		BasicConstraintsValid: true,
+	}, nil
+}
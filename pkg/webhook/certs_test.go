@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+func TestCreateCerts(t *testing.T) {
+	certs, err := createCerts("webhook", "operator")
+	if err != nil {
+		t.Fatalf("createCerts() = %v", err)
+	}
+	if _, err := tls.X509KeyPair(certs.serverCert, certs.serverKey); err != nil {
+		t.Fatalf("invalid key pair: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(certs.caCert) {
+		t.Fatal("invalid CA certificate")
+	}
+	block, _ := pem.Decode(certs.serverCert)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("invalid server certificate: %v", err)
+	}
+	opts := x509.VerifyOptions{
+		DNSName: "webhook.operator.svc",
+		Roots:   pool,
+	}
+	if _, err := cert.Verify(opts); err != nil {
+		t.Errorf("server certificate not signed by the CA for the service: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	servingv1beta1 "knative.dev/serving-operator/pkg/apis/serving/v1beta1"
+)
+
+// A KnativeServing of any served version
+type convertible interface {
+	apis.Convertible
+	runtime.Object
+}
+
+// The served versions of KnativeServing
+var versions = map[string]func() convertible{
+	servingv1alpha1.SchemeGroupVersion.String(): func() convertible { return &servingv1alpha1.KnativeServing{} },
+	servingv1beta1.SchemeGroupVersion.String():  func() convertible { return &servingv1beta1.KnativeServing{} },
+}
+
+// conversionHandler converts KnativeServings between the served versions
+// on behalf of the apiserver
+type conversionHandler struct{}
+
+var _ http.Handler = &conversionHandler{}
+
+func (h *conversionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	review := &apiextensionsv1beta1.ConversionReview{}
+	if err := json.NewDecoder(r.Body).Decode(review); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "missing conversion request", http.StatusBadRequest)
+		return
+	}
+	review.Response = convert(r.Context(), review.Request)
+	review.Request = nil
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		log.Error(err, "Unable to write the conversion response")
+	}
+}
+
+func convert(ctx context.Context, req *apiextensionsv1beta1.ConversionRequest) *apiextensionsv1beta1.ConversionResponse {
+	resp := &apiextensionsv1beta1.ConversionResponse{UID: req.UID}
+	for _, obj := range req.Objects {
+		raw, err := convertObject(ctx, obj.Raw, req.DesiredAPIVersion)
+		if err != nil {
+			log.Info("Rejecting conversion", "desiredAPIVersion", req.DesiredAPIVersion, "reason", err.Error())
+			resp.ConvertedObjects = nil
+			resp.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			return resp
+		}
+		resp.ConvertedObjects = append(resp.ConvertedObjects, runtime.RawExtension{Raw: raw})
+	}
+	resp.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return resp
+}
+
+func convertObject(ctx context.Context, raw []byte, desiredAPIVersion string) ([]byte, error) {
+	typeMeta := &metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, typeMeta); err != nil {
+		return nil, err
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+	newSource, ok := versions[typeMeta.APIVersion]
+	if !ok {
+		return nil, fmt.Errorf("unknown version %q", typeMeta.APIVersion)
+	}
+	newSink, ok := versions[desiredAPIVersion]
+	if !ok {
+		return nil, fmt.Errorf("unknown version %q", desiredAPIVersion)
+	}
+	source, sink := newSource(), newSink()
+	if err := json.Unmarshal(raw, source); err != nil {
+		return nil, err
+	}
+	// v1alpha1 is the lowest version, at one end of every conversion
+	var err error
+	if _, ok := source.(*servingv1alpha1.KnativeServing); ok {
+		err = source.ConvertUp(ctx, sink)
+	} else {
+		err = sink.ConvertDown(ctx, source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	typeMeta.APIVersion = desiredAPIVersion
+	sink.GetObjectKind().SetGroupVersionKind(typeMeta.GroupVersionKind())
+	return json.Marshal(sink)
+}
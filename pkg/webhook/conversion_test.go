@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingv1beta1 "knative.dev/serving-operator/pkg/apis/serving/v1beta1"
+)
+
+func review(t *testing.T, desiredAPIVersion string, objects ...string) *apiextensionsv1beta1.ConversionResponse {
+	t.Helper()
+	req := &apiextensionsv1beta1.ConversionReview{
+		Request: &apiextensionsv1beta1.ConversionRequest{
+			UID:               "test",
+			DesiredAPIVersion: desiredAPIVersion,
+		},
+	}
+	for _, obj := range objects {
+		req.Request.Objects = append(req.Request.Objects, runtime.RawExtension{Raw: []byte(obj)})
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	(&conversionHandler{}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/convert-knativeservings", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	resp := &apiextensionsv1beta1.ConversionReview{}
+	if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Response.UID != "test" {
+		t.Errorf("UID = %q, want test", resp.Response.UID)
+	}
+	return resp.Response
+}
+
+const alphaKnativeServing = `{
+	"apiVersion": "serving.knative.dev/v1alpha1",
+	"kind": "KnativeServing",
+	"metadata": {"namespace": "knative-serving", "name": "knative-serving"},
+	"spec": {
+		"knative-ingress-gateway": {"selector": {"istio": "custom"}},
+		"deploymentOverrides": [{"name": "controller"}]
+	}
+}`
+
+func TestConvertToV1beta1(t *testing.T) {
+	resp := review(t, "serving.knative.dev/v1beta1", alphaKnativeServing)
+	if resp.Result.Status != metav1.StatusSuccess {
+		t.Fatalf("conversion failed: %s", resp.Result.Message)
+	}
+	if len(resp.ConvertedObjects) != 1 {
+		t.Fatalf("got %d objects, want 1", len(resp.ConvertedObjects))
+	}
+	beta := &servingv1beta1.KnativeServing{}
+	if err := json.Unmarshal(resp.ConvertedObjects[0].Raw, beta); err != nil {
+		t.Fatal(err)
+	}
+	if beta.APIVersion != "serving.knative.dev/v1beta1" || beta.Kind != "KnativeServing" {
+		t.Errorf("converted to %s %s", beta.APIVersion, beta.Kind)
+	}
+	if beta.Name != "knative-serving" {
+		t.Errorf("name = %q, want knative-serving", beta.Name)
+	}
+	if got := beta.Spec.Ingress.Istio.KnativeIngressGateway.Selector["istio"]; got != "custom" {
+		t.Errorf("gateway selector = %q, want custom", got)
+	}
+	if len(beta.Spec.Deployments) != 1 || beta.Spec.Deployments[0].Name != "controller" {
+		t.Errorf("deployments = %+v", beta.Spec.Deployments)
+	}
+}
+
+func TestConvertToSameVersion(t *testing.T) {
+	resp := review(t, "serving.knative.dev/v1alpha1", alphaKnativeServing)
+	if resp.Result.Status != metav1.StatusSuccess {
+		t.Fatalf("conversion failed: %s", resp.Result.Message)
+	}
+	want := &bytes.Buffer{}
+	if err := json.Compact(want, []byte(alphaKnativeServing)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(resp.ConvertedObjects[0].Raw, want.Bytes()) {
+		t.Errorf("object changed: %s", resp.ConvertedObjects[0].Raw)
+	}
+}
+
+func TestConvertUnknownVersion(t *testing.T) {
+	resp := review(t, "serving.knative.dev/v2", alphaKnativeServing)
+	if resp.Result.Status != metav1.StatusFailure {
+		t.Errorf("status = %q, want Failure", resp.Result.Status)
+	}
+	if len(resp.ConvertedObjects) != 0 {
+		t.Errorf("unexpected objects: %d", len(resp.ConvertedObjects))
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	servingv1beta1 "knative.dev/serving-operator/pkg/apis/serving/v1beta1"
+	"knative.dev/serving-operator/pkg/scope"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+// knativeServingValidator rejects a KnativeServing that would fight
+// the existing one over the cluster-scoped manifest resources
+type knativeServingValidator struct {
+	client  client.Client
+	decoder types.Decoder
+}
+
+var _ admission.Handler = &knativeServingValidator{}
+
+func (v *knativeServingValidator) Handle(ctx context.Context, req types.Request) types.Response {
+	instance, err := v.decode(ctx, req, req.AdmissionRequest.Object)
+	if err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+	// An update is only checked for moving the install
+	if req.AdmissionRequest.Operation == admissionv1beta1.Update {
+		original, err := v.decode(ctx, req, req.AdmissionRequest.OldObject)
+		if err != nil {
+			return admission.ErrorResponse(http.StatusBadRequest, err)
+		}
+		if err := instance.CheckImmutableFields(ctx, original); err != nil {
+			log.Info("Rejecting KnativeServing", "namespace", instance.GetNamespace(), "name", instance.GetName(), "reason", err.Error())
+			return admission.ValidationResponse(false, err.Error())
+		}
+		return admission.ValidationResponse(true, "")
+	}
+	list := &servingv1alpha1.KnativeServingList{}
+	if err := v.client.List(ctx, &client.ListOptions{}, list); err != nil {
+		return admission.ErrorResponse(http.StatusInternalServerError, err)
+	}
+	// Those the operator doesn't watch, or isn't pinned to, don't
+	// install anything
+	var existing []servingv1alpha1.KnativeServing
+	for _, ks := range list.Items {
+		if scope.Selects(ks.GetNamespace(), ks.GetName()) {
+			existing = append(existing, ks)
+		}
+	}
+	ctx = servingv1alpha1.WithInstallation(ctx, scope.Namespaces(), existing)
+	if err := instance.Validate(apis.WithinCreate(ctx)); err != nil {
+		log.Info("Rejecting KnativeServing", "namespace", instance.GetNamespace(), "name", instance.GetName(), "reason", err.Error())
+		return admission.ValidationResponse(false, err.Error())
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+// Decode the object, or the old object, of the request as the v1alpha1
+// KnativeServing
+func (v *knativeServingValidator) decode(ctx context.Context, req types.Request, raw runtime.RawExtension) (*servingv1alpha1.KnativeServing, error) {
+	ar := *req.AdmissionRequest
+	ar.Object = raw
+	req.AdmissionRequest = &ar
+	instance := &servingv1alpha1.KnativeServing{}
+	if req.AdmissionRequest.Kind.Version == servingv1beta1.SchemeGroupVersion.Version {
+		// Validate the v1alpha1 equivalent
+		beta := &servingv1beta1.KnativeServing{}
+		if err := v.decoder.Decode(req, beta); err != nil {
+			return nil, err
+		}
+		if err := instance.ConvertDown(ctx, beta); err != nil {
+			return nil, err
+		}
+	} else if err := v.decoder.Decode(req, instance); err != nil {
+		return nil, err
+	}
+	// The namespace isn't always set in the object itself
+	if instance.GetNamespace() == "" {
+		instance.SetNamespace(req.AdmissionRequest.Namespace)
+	}
+	return instance, nil
+}
+
+// knativeServingDefaulter fills in the defaults of a KnativeServing's
+// spec, so that the effective spec shows on the resource itself
+type knativeServingDefaulter struct {
+	decoder types.Decoder
+}
+
+var _ admission.Handler = &knativeServingDefaulter{}
+
+func (d *knativeServingDefaulter) Handle(ctx context.Context, req types.Request) types.Response {
+	if req.AdmissionRequest.Kind.Version == servingv1beta1.SchemeGroupVersion.Version {
+		// Default the v1alpha1 equivalent
+		beta := &servingv1beta1.KnativeServing{}
+		if err := d.decoder.Decode(req, beta); err != nil {
+			return admission.ErrorResponse(http.StatusBadRequest, err)
+		}
+		instance := &servingv1alpha1.KnativeServing{}
+		if err := instance.ConvertDown(ctx, beta); err != nil {
+			return admission.ErrorResponse(http.StatusBadRequest, err)
+		}
+		instance.SetDefaults(ctx)
+		defaulted := beta.DeepCopy()
+		defaulted.Spec = servingv1beta1.KnativeServingSpec{}
+		instance.Spec.ConvertUp(ctx, &defaulted.Spec)
+		return admission.PatchResponse(beta, defaulted)
+	}
+	instance := &servingv1alpha1.KnativeServing{}
+	if err := d.decoder.Decode(req, instance); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+	defaulted := instance.DeepCopy()
+	defaulted.SetDefaults(ctx)
+	return admission.PatchResponse(instance, defaulted)
+}
@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/serving-operator/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+func defaultRequest(t *testing.T, version, object string) types.Response {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := apis.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := types.Request{AdmissionRequest: &admissionv1beta1.AdmissionRequest{
+		Kind:   metav1.GroupVersionKind{Group: "serving.knative.dev", Version: version, Kind: "KnativeServing"},
+		Object: runtime.RawExtension{Raw: []byte(object)},
+	}}
+	return (&knativeServingDefaulter{decoder: decoder}).Handle(context.Background(), req)
+}
+
+func TestDefaulterPatchesEffectiveSpec(t *testing.T) {
+	for _, version := range []string{"v1alpha1", "v1beta1"} {
+		resp := defaultRequest(t, version, `{
+  "apiVersion": "serving.knative.dev/`+version+`",
+  "kind": "KnativeServing",
+  "metadata": {"namespace": "knative-serving", "name": "knative-serving"},
+  "spec": {"ingress": {"kourier": {"enabled": true}}}
+}`)
+		if !resp.Response.Allowed {
+			t.Fatalf("%s: defaulting wasn't allowed: %v", version, resp.Response.Result)
+		}
+		var paths []string
+		for _, p := range resp.Patches {
+			paths = append(paths, p.Path)
+			if strings.HasPrefix(p.Path, "/apiVersion") || strings.HasPrefix(p.Path, "/kind") {
+				t.Errorf("%s: the type shouldn't be patched: %v", version, p)
+			}
+		}
+		if len(paths) != 1 || paths[0] != "/spec/config" {
+			t.Errorf("%s: patched %v, want /spec/config", version, paths)
+		}
+		config, _ := resp.Patches[0].Value.(map[string]interface{})
+		network, _ := config["network"].(map[string]interface{})
+		if network["ingress.class"] != "kourier.ingress.networking.knative.dev" {
+			t.Errorf("%s: config.network = %v, want kourier's ingress.class", version, network)
+		}
+	}
+}
+
+func TestValidatorRejectsMovingAnInstall(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := apis.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	object := func(version, namespace string) runtime.RawExtension {
+		return runtime.RawExtension{Raw: []byte(`{
+  "apiVersion": "serving.knative.dev/` + version + `",
+  "kind": "KnativeServing",
+  "metadata": {"namespace": "knative-serving", "name": "knative-serving"},
+  "spec": {"namespace": "` + namespace + `"},
+  "status": {"version": "0.7.0"}
+}`)}
+	}
+	for _, version := range []string{"v1alpha1", "v1beta1"} {
+		for namespace, allowed := range map[string]bool{"knative-serving": true, "serving-system": false} {
+			req := types.Request{AdmissionRequest: &admissionv1beta1.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Group: "serving.knative.dev", Version: version, Kind: "KnativeServing"},
+				Operation: admissionv1beta1.Update,
+				Object:    object(version, namespace),
+				OldObject: object(version, "knative-serving"),
+			}}
+			resp := (&knativeServingValidator{decoder: decoder}).Handle(context.Background(), req)
+			if resp.Response.Allowed != allowed {
+				t.Errorf("%s: moving the install to %s allowed = %v, want %v", version, namespace, resp.Response.Allowed, allowed)
+			}
+		}
+	}
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"knative.dev/serving-operator/pkg/health"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/types"
+)
+
+// Server serves the admission webhooks over TLS, bootstrapping its
+// certificates and registering the webhooks with the apiserver
+type Server struct {
+	Client    client.Client
+	Namespace string
+	Port      int
+	Webhooks  []*admission.Webhook
+	// Optionally serves the conversion webhook of a CRD
+	Conversion *Conversion
+}
+
+// Conversion converts a CRD's resources between its served versions
+type Conversion struct {
+	// The name of the CustomResourceDefinition
+	CRD     string
+	Path    string
+	Handler http.Handler
+}
+
+var _ manager.Runnable = &Server{}
+
+// Start implements manager.Runnable. The replica only reports ready
+// once it serves the registered webhooks, since the Service in front of
+// them selects every replica.
+func (s *Server) Start(stop <-chan struct{}) error {
+	err := s.start(stop)
+	health.SetReady(healthCheck, err)
+	return err
+}
+
+func (s *Server) start(stop <-chan struct{}) error {
+	certs, err := getOrGenerateCerts(s.Client, s.Namespace)
+	if err != nil {
+		log.Error(err, "Unable to configure webhook certificates")
+		return err
+	}
+	keyPair, err := tls.X509KeyPair(certs.serverCert, certs.serverKey)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	for _, wh := range s.Webhooks {
+		if err := wh.Validate(); err != nil {
+			return err
+		}
+		mux.Handle(wh.GetPath(), wh.Handler())
+	}
+	if s.Conversion != nil {
+		mux.Handle(s.Conversion.Path, s.Conversion.Handler)
+	}
+	server := &http.Server{
+		Addr:      fmt.Sprintf(":%d", s.Port),
+		Handler:   mux,
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{keyPair}},
+	}
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("Serving admission webhooks", "port", s.Port)
+		errCh <- server.ServeTLS(listener, "", "")
+	}()
+
+	if err := s.register(certs.caCert); err != nil {
+		log.Error(err, "Unable to register webhooks")
+		server.Close()
+		return err
+	}
+	if err := s.registerConversion(certs.caCert); err != nil {
+		log.Error(err, "Unable to register the conversion webhook")
+		server.Close()
+		return err
+	}
+	health.SetReady(healthCheck, nil)
+
+	select {
+	case <-stop:
+		return server.Close()
+	case err := <-errCh:
+		return err
+	}
+}
+
+// Create or update the Validating- and MutatingWebhookConfigurations
+// pointing at us, each holding the webhooks of its type
+func (s *Server) register(caCert []byte) error {
+	// Owned by our deployment, so they go away with the operator
+	deployment := &appsv1.Deployment{}
+	if err := s.Client.Get(context.TODO(), client.ObjectKey{Namespace: s.Namespace, Name: deploymentName}, deployment); err != nil {
+		return err
+	}
+	owner := []metav1.OwnerReference{
+		*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment")),
+	}
+
+	validating := &admissionregistrationv1beta1.ValidatingWebhookConfiguration{}
+	validating.Name = configName
+	validating.Webhooks = s.webhooks(types.WebhookTypeValidating, caCert)
+	validating.SetOwnerReferences(owner)
+	if err := s.registerValidating(validating); err != nil {
+		return err
+	}
+
+	mutating := &admissionregistrationv1beta1.MutatingWebhookConfiguration{}
+	mutating.Name = configName
+	mutating.Webhooks = s.webhooks(types.WebhookTypeMutating, caCert)
+	mutating.SetOwnerReferences(owner)
+	return s.registerMutating(mutating)
+}
+
+// The registrations of the webhooks of the type
+func (s *Server) webhooks(t types.WebhookType, caCert []byte) []admissionregistrationv1beta1.Webhook {
+	var result []admissionregistrationv1beta1.Webhook
+	for _, wh := range s.Webhooks {
+		if wh.GetType() != t {
+			continue
+		}
+		path := wh.GetPath()
+		result = append(result, admissionregistrationv1beta1.Webhook{
+			Name:          wh.GetName(),
+			Rules:         wh.Rules,
+			FailurePolicy: wh.FailurePolicy,
+			ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+				Service: &admissionregistrationv1beta1.ServiceReference{
+					Namespace: s.Namespace,
+					Name:      serviceName,
+					Path:      &path,
+				},
+				CABundle: caCert,
+			},
+		})
+	}
+	return result
+}
+
+func (s *Server) registerValidating(config *admissionregistrationv1beta1.ValidatingWebhookConfiguration) error {
+	if len(config.Webhooks) == 0 {
+		return nil
+	}
+	existing := &admissionregistrationv1beta1.ValidatingWebhookConfiguration{}
+	if err := s.Client.Get(context.TODO(), client.ObjectKey{Name: config.Name}, existing); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		log.Info("Creating ValidatingWebhookConfiguration", "name", config.Name)
+		return s.Client.Create(context.TODO(), config)
+	}
+	if equality.Semantic.DeepEqual(existing.Webhooks, config.Webhooks) {
+		return nil
+	}
+	log.Info("Updating ValidatingWebhookConfiguration", "name", config.Name)
+	existing.Webhooks = config.Webhooks
+	existing.SetOwnerReferences(config.GetOwnerReferences())
+	return s.Client.Update(context.TODO(), existing)
+}
+
+func (s *Server) registerMutating(config *admissionregistrationv1beta1.MutatingWebhookConfiguration) error {
+	if len(config.Webhooks) == 0 {
+		return nil
+	}
+	existing := &admissionregistrationv1beta1.MutatingWebhookConfiguration{}
+	if err := s.Client.Get(context.TODO(), client.ObjectKey{Name: config.Name}, existing); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		log.Info("Creating MutatingWebhookConfiguration", "name", config.Name)
+		return s.Client.Create(context.TODO(), config)
+	}
+	if equality.Semantic.DeepEqual(existing.Webhooks, config.Webhooks) {
+		return nil
+	}
+	log.Info("Updating MutatingWebhookConfiguration", "name", config.Name)
+	existing.Webhooks = config.Webhooks
+	existing.SetOwnerReferences(config.GetOwnerReferences())
+	return s.Client.Update(context.TODO(), existing)
+}
+
+// Point the CRD's conversion at us
+func (s *Server) registerConversion(caCert []byte) error {
+	if s.Conversion == nil {
+		return nil
+	}
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(apiextensionsv1beta1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+	if err := s.Client.Get(context.TODO(), client.ObjectKey{Name: s.Conversion.CRD}, crd); err != nil {
+		return err
+	}
+	conversion := map[string]interface{}{
+		"strategy": string(apiextensionsv1beta1.WebhookConverter),
+		"webhookClientConfig": map[string]interface{}{
+			"service": map[string]interface{}{
+				"namespace": s.Namespace,
+				"name":      serviceName,
+				"path":      s.Conversion.Path,
+			},
+			"caBundle": base64.StdEncoding.EncodeToString(caCert),
+		},
+	}
+	existing, _, _ := unstructured.NestedMap(crd.Object, "spec", "conversion")
+	if equality.Semantic.DeepEqual(existing, conversion) {
+		return nil
+	}
+	log.Info("Updating the conversion webhook", "crd", s.Conversion.CRD)
+	if err := unstructured.SetNestedMap(crd.Object, conversion, "spec", "conversion"); err != nil {
+		return err
+	}
+	return s.Client.Update(context.TODO(), crd)
+}
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/serving-operator/pkg/health"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// A client of an empty cluster but for the operator's Deployment, in
+// which every create succeeds
+type emptyClient struct {
+	client.Client
+}
+
+func (emptyClient) Get(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if _, ok := obj.(*appsv1.Deployment); ok {
+		return nil
+	}
+	return errors.NewNotFound(schema.GroupResource{}, key.Name)
+}
+
+func (emptyClient) Create(context.Context, runtime.Object) error {
+	return nil
+}
+
+// A client of an empty cluster, in which every create succeeds
+type failingClient struct {
+	client.Client
+}
+
+func (failingClient) Get(_ context.Context, key client.ObjectKey, _ runtime.Object) error {
+	return errors.NewNotFound(schema.GroupResource{}, key.Name)
+}
+
+func (failingClient) Create(context.Context, runtime.Object) error {
+	return nil
+}
+
+func TestServerIsReadyOnlyWhileServing(t *testing.T) {
+	health.NotReady(healthCheck)
+	server := &Server{Client: emptyClient{}, Namespace: "operator", Port: 0}
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Start(stop)
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for health.Ready() != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("never ready: %v", health.Ready())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("Start() = %v", err)
+	}
+}
+
+func TestServerIsntReadyWhenItFails(t *testing.T) {
+	health.NotReady(healthCheck)
+	// No operator Deployment to own the registrations
+	server := &Server{Client: failingClient{}, Namespace: "operator", Port: 0}
+	if err := server.Start(make(chan struct{})); err == nil {
+		t.Fatal("Start() = nil, want an error")
+	}
+	if health.Ready() == nil {
+		t.Error("ready after failing to start")
+	}
+	health.SetReady(healthCheck, nil)
+}
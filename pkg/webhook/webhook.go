@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package webhook
+
+import (
+	"flag"
+
+	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	servingv1alpha1 "knative.dev/serving-operator/pkg/apis/serving/v1alpha1"
+	servingv1beta1 "knative.dev/serving-operator/pkg/apis/serving/v1beta1"
+	"knative.dev/serving-operator/pkg/health"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/types"
+)
+
+const (
+	// Must match the operator's Deployment and Service in config/operator.yaml
+	deploymentName = "knative-serving-operator"
+	serviceName    = "knative-serving-operator-webhook"
+	secretName     = "knative-serving-operator-webhook-certs"
+	configName     = "knative-serving-operator"
+	crdName        = "knativeservings.serving.knative.dev"
+	// The readiness check of the webhook server
+	healthCheck = "webhook"
+)
+
+var (
+	enabled = flag.Bool("webhook", true,
+		"Serve the admission webhooks that default KnativeServing resources and reject conflicting ones, and the conversion webhook between their versions")
+	port = flag.Int("webhook-port", 8443,
+		"The port the admission webhook is served on")
+	log = logf.Log.WithName("webhook")
+)
+
+// AddToManager adds the admission webhook server to the Manager, which
+// starts it along with the controllers
+func AddToManager(mgr manager.Manager) error {
+	server, err := NewServer(mgr)
+	if err != nil || server == nil {
+		return err
+	}
+	return mgr.Add(server)
+}
+
+// NewServer builds the admission webhook server, nil when it's disabled.
+// It doesn't depend on the Manager being started, so that replicas
+// waiting to lead may serve it too.
+func NewServer(mgr manager.Manager) (*Server, error) {
+	if !*enabled {
+		log.Info("Admission webhook disabled")
+		return nil, nil
+	}
+	namespace, err := k8sutil.GetOperatorNamespace()
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := admission.NewDecoder(mgr.GetScheme())
+	if err != nil {
+		return nil, err
+	}
+	// Bypass the cache, which only the leader starts, for the few
+	// resources we read
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		return nil, err
+	}
+	failurePolicy := admissionregistrationv1beta1.Fail
+	validator := &admission.Webhook{
+		Name: "validation.knativeserving.serving.knative.dev",
+		Type: types.WebhookTypeValidating,
+		Path: "/validate-knativeservings",
+		Rules: []admissionregistrationv1beta1.RuleWithOperations{{
+			Operations: []admissionregistrationv1beta1.OperationType{
+				admissionregistrationv1beta1.Create,
+				admissionregistrationv1beta1.Update,
+			},
+			Rule: admissionregistrationv1beta1.Rule{
+				APIGroups:   []string{servingv1alpha1.SchemeGroupVersion.Group},
+				APIVersions: []string{servingv1alpha1.SchemeGroupVersion.Version, servingv1beta1.SchemeGroupVersion.Version},
+				Resources:   []string{"knativeservings"},
+			},
+		}},
+		FailurePolicy: &failurePolicy,
+		Handlers: []admission.Handler{
+			&knativeServingValidator{client: c, decoder: decoder},
+		},
+	}
+	defaulter := &admission.Webhook{
+		Name: "defaulting.knativeserving.serving.knative.dev",
+		Type: types.WebhookTypeMutating,
+		Path: "/default-knativeservings",
+		Rules: []admissionregistrationv1beta1.RuleWithOperations{{
+			Operations: []admissionregistrationv1beta1.OperationType{
+				admissionregistrationv1beta1.Create,
+				admissionregistrationv1beta1.Update,
+			},
+			Rule: admissionregistrationv1beta1.Rule{
+				APIGroups:   []string{servingv1alpha1.SchemeGroupVersion.Group},
+				APIVersions: []string{servingv1alpha1.SchemeGroupVersion.Version, servingv1beta1.SchemeGroupVersion.Version},
+				Resources:   []string{"knativeservings"},
+			},
+		}},
+		FailurePolicy: &failurePolicy,
+		Handlers: []admission.Handler{
+			&knativeServingDefaulter{decoder: decoder},
+		},
+	}
+	server := &Server{
+		Client:    c,
+		Namespace: namespace,
+		Port:      *port,
+		Webhooks:  []*admission.Webhook{validator, defaulter},
+		Conversion: &Conversion{
+			CRD:     crdName,
+			Path:    "/convert-knativeservings",
+			Handler: &conversionHandler{},
+		},
+	}
+	health.NotReady(healthCheck)
+	return server, nil
+}